@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/gunjan5/code-review-bot/review"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// writeAuthorStats writes the contribution statistics of every author of the
+// reviewed commits.
+func writeAuthorStats(w io.Writer, repo *git.Repository, o *review.Options) error {
+	stats, err := review.Authors(repo, o)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "AUTHOR\tCOMMITS\tFILES\tADDED\tREMOVED")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s <%s>\t%d\t%d\t%d\t%d\n", s.Name, s.Email, s.Commits, s.Files, s.Added, s.Removed)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,305 @@
+// Package blame finds the commit that last changed each line of a file, to
+// attribute the lines to their authors.
+package blame
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/mailmap"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// ErrBinary is returned by File for binary files, which have no lines.
+var ErrBinary = errors.New("binary file")
+
+// Line is a line of a blamed file and the commit that last changed it.
+type Line struct {
+	// Number is the 1-based number of the line in the blamed version.
+	Number int
+	Text   string
+	Commit core.Hash
+	Author git.Signature
+}
+
+// File returns the lines of the file at the path in the commit, each with
+// the commit that introduced it in its current form. History is followed
+// through every parent of merge commits and through renames, detected as
+// by diff.TreeDiff. Lines are blamed on the oldest commit reachable when
+// history is cut, at the boundary of a shallow clone. Authors are mapped to
+// their canonical identities by the .mailmap of the tree of the commit.
+func File(c *git.Commit, path string) ([]Line, error) {
+	f, err := c.File(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	mm, err := mailmap.FromTree(t)
+	if err != nil {
+		return nil, fmt.Errorf(".mailmap: %s", err)
+	}
+
+	if binary, err := rules.IsBinary(f); err != nil || binary {
+		if err == nil {
+			err = ErrBinary
+		}
+
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	text, err := f.Lines()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]Line, len(text))
+	first := &suspect{commit: c, path: path, file: f, lines: make(map[int]int)}
+	for i, t := range text {
+		lines[i] = Line{Number: i + 1, Text: t}
+		first.lines[i+1] = i
+	}
+
+	q := queue{}
+	q.push(first)
+	for len(q) > 0 {
+		s := q.pop()
+		parents, err := s.pass()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range parents {
+			q.push(p)
+		}
+
+		author := s.commit.Author
+		author.Name, author.Email = mm.Map(author.Name, author.Email)
+		for _, i := range s.lines {
+			lines[i].Commit = s.commit.Hash
+			lines[i].Author = author
+		}
+	}
+
+	return lines, nil
+}
+
+// suspect is a version of the blamed file in a commit and the lines still
+// to blame, by line number in that version, with their index in the result.
+type suspect struct {
+	commit *git.Commit
+	path   string
+	file   *git.File
+	lines  map[int]int
+}
+
+// pass moves the lines unchanged in the parents of the commit to new
+// suspects, in the order of the parents, leaving the lines the commit
+// changed.
+func (s *suspect) pass() ([]*suspect, error) {
+	var parents []*suspect
+	for i := 0; i < s.commit.NumParents() && len(s.lines) > 0; i++ {
+		p, err := s.parent(i)
+		if err != nil {
+			return nil, err
+		}
+
+		if p == nil {
+			continue
+		}
+
+		if p.file.Hash == s.file.Hash {
+			p.lines, s.lines = s.lines, nil
+			return append(parents, p), nil
+		}
+
+		patch, err := diff.UnifiedContext(p.file, s.file, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if patch.Binary != nil {
+			continue
+		}
+
+		for n, i := range s.lines {
+			if old := oldLine(patch.Hunks, n); old > 0 {
+				p.lines[old] = i
+				delete(s.lines, n)
+			}
+		}
+
+		if len(p.lines) > 0 {
+			parents = append(parents, p)
+		}
+	}
+
+	return parents, nil
+}
+
+// parent returns the version of the file in the i-th parent of the commit,
+// under the same path or the one it was renamed from, nil if the file
+// didn't exist or the parent is missing from a shallow clone.
+func (s *suspect) parent(i int) (*suspect, error) {
+	c, err := s.commit.Parent(i)
+	if rules.IsMissing(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	path := s.path
+	f, err := c.File(path)
+	if err == git.ErrFileNotFound {
+		if path, err = renamedFrom(c, s.commit, s.path); err != nil || path == "" {
+			return nil, err
+		}
+
+		f, err = c.File(path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &suspect{commit: c, path: path, file: f, lines: make(map[int]int)}, nil
+}
+
+// renamedFrom returns the path of the file renamed to path between the
+// commits, an empty string if it wasn't renamed.
+func renamedFrom(from, to *git.Commit, path string) (string, error) {
+	ft, err := from.Tree()
+	if err != nil {
+		return "", err
+	}
+
+	tt, err := to.Tree()
+	if err != nil {
+		return "", err
+	}
+
+	changes, err := diff.TreeDiff(ft, tt)
+	if err != nil {
+		return "", err
+	}
+
+	for _, ch := range changes {
+		if ch.Action == diff.Renamed && ch.To.Path == path {
+			return ch.From.Path, nil
+		}
+	}
+
+	return "", nil
+}
+
+// oldLine returns the number in the old file of the line n of the new file,
+// 0 if the line is part of a hunk. The hunks must have no context lines.
+func oldLine(hunks []diff.Hunk, n int) int {
+	offset := 0
+	for _, h := range hunks {
+		start := h.NewStart
+		if h.NewLines == 0 {
+			start++
+		}
+
+		if n < start {
+			break
+		}
+
+		if n < start+h.NewLines {
+			return 0
+		}
+
+		offset += h.OldLines - h.NewLines
+	}
+
+	return n + offset
+}
+
+// queue holds the suspects to process, the most recent commit first, so a
+// commit is only processed once all its descendants passed it their lines.
+type queue []*suspect
+
+// push adds the suspect, merging its lines with those of the same commit and
+// path already queued.
+func (q *queue) push(s *suspect) {
+	for _, o := range *q {
+		if o.commit.Hash == s.commit.Hash && o.path == s.path {
+			for n, i := range s.lines {
+				o.lines[n] = i
+			}
+
+			return
+		}
+	}
+
+	*q = append(*q, s)
+}
+
+// pop removes and returns the suspect with the most recent committer date.
+func (q *queue) pop() *suspect {
+	latest := 0
+	for i, s := range *q {
+		if s.commit.Committer.When.After((*q)[latest].commit.Committer.When) {
+			latest = i
+		}
+	}
+
+	s := (*q)[latest]
+	*q = append((*q)[:latest], (*q)[latest+1:]...)
+	return s
+}
+
+// Owner is an author and the number of lines of a file last changed by
+// them.
+type Owner struct {
+	Name  string
+	Email string
+	Lines int
+}
+
+// Owners returns the authors of the lines by decreasing number of lines,
+// those with the same number by email. Authors are told apart by email,
+// case-insensitively, the name being the one of their first line.
+func Owners(lines []Line) []Owner {
+	byEmail := make(map[string]int)
+	var owners []Owner
+	for _, l := range lines {
+		email := strings.ToLower(l.Author.Email)
+		i, ok := byEmail[email]
+		if !ok {
+			i = len(owners)
+			byEmail[email] = i
+			owners = append(owners, Owner{Name: l.Author.Name, Email: l.Author.Email})
+		}
+
+		owners[i].Lines++
+	}
+
+	sort.Sort(byLines(owners))
+	return owners
+}
+
+type byLines []Owner
+
+func (s byLines) Len() int      { return len(s) }
+func (s byLines) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s byLines) Less(i, j int) bool {
+	if s[i].Lines != s[j].Lines {
+		return s[i].Lines > s[j].Lines
+	}
+
+	return strings.ToLower(s[i].Email) < strings.ToLower(s[j].Email)
+}
@@ -0,0 +1,131 @@
+package blame
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/internal/testrepo"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// testRepo is a repository in memory whose commits are written by the
+// tests.
+type testRepo struct {
+	*testrepo.Repo
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	return &testRepo{testrepo.New(t)}
+}
+
+// commit writes a commit of the files by the author, "Name <email>", with
+// the parents, and returns it.
+func (tr *testRepo) commit(author string, files map[string]string, parents ...*git.Commit) *git.Commit {
+	hashes := make([]core.Hash, len(parents))
+	for i, p := range parents {
+		hashes[i] = p.Hash
+	}
+
+	tr.Author = author
+	return tr.CommitFiles(files, "m\n", hashes...)
+}
+
+// blamed returns the lines as "number text commit author", naming the
+// commits by names.
+func blamed(lines []Line, names map[core.Hash]string) []string {
+	var bs []string
+	for _, l := range lines {
+		bs = append(bs, fmt.Sprintf("%d %s %s %s <%s>", l.Number, l.Text, names[l.Commit], l.Author.Name, l.Author.Email))
+	}
+
+	return bs
+}
+
+func TestFile(t *testing.T) {
+	tr := newTestRepo(t)
+	const alice, bob = "Al <al@old.example.com>", "Bob <bob@example.com>"
+	c1 := tr.commit(alice, map[string]string{"f.txt": "1\n2\n3\n4\n5\n6\n"})
+	c2 := tr.commit(bob, map[string]string{"f.txt": "1\n2 bob\n3\n4\n5\n6\n7 bob\n"}, c1)
+	// c3 renames f.txt to g.txt and maps Al to Alice.
+	c3 := tr.commit(alice, map[string]string{
+		"g.txt":    "1\n2 bob\n3\n4 alice\n5\n6\n7 bob\n",
+		".mailmap": "Alice <alice@example.com> <al@old.example.com>\n",
+	}, c2)
+	names := map[core.Hash]string{c1.Hash: "c1", c2.Hash: "c2", c3.Hash: "c3"}
+
+	lines, err := File(c3, "g.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"1 1 c1 Alice <alice@example.com>",
+		"2 2 bob c2 Bob <bob@example.com>",
+		"3 3 c1 Alice <alice@example.com>",
+		"4 4 alice c3 Alice <alice@example.com>",
+		"5 5 c1 Alice <alice@example.com>",
+		"6 6 c1 Alice <alice@example.com>",
+		"7 7 bob c2 Bob <bob@example.com>",
+	}
+
+	if got := blamed(lines, names); !reflect.DeepEqual(got, want) {
+		t.Errorf("File(c3, g.txt) =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	wantOwners := []Owner{{Name: "Alice", Email: "alice@example.com", Lines: 5}, {Name: "Bob", Email: "bob@example.com", Lines: 2}}
+	if owners := Owners(lines); !reflect.DeepEqual(owners, wantOwners) {
+		t.Errorf("Owners = %+v, want %+v", owners, wantOwners)
+	}
+
+	// Without the .mailmap, c2 blames Al with the email of the commit.
+	lines, err = File(c2, "f.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := blamed(lines, names)[0]; got != "1 1 c1 Al <al@old.example.com>" {
+		t.Errorf("File(c2, f.txt) first line = %s, want it blamed on Al", got)
+	}
+}
+
+func TestFileMerge(t *testing.T) {
+	tr := newTestRepo(t)
+	const a, b, c = "A <a@example.com>", "B <b@example.com>", "C <c@example.com>"
+	base := tr.commit(a, map[string]string{"f.txt": "1\n2\n3\n4\n5\n"})
+	left := tr.commit(b, map[string]string{"f.txt": "1 left\n2\n3\n4\n5\n"}, base)
+	right := tr.commit(c, map[string]string{"f.txt": "1\n2\n3\n4\n5 right\n"}, base)
+	merge := tr.commit(a, map[string]string{"f.txt": "1 left\n2\n3 merge\n4\n5 right\n"}, left, right)
+	names := map[core.Hash]string{base.Hash: "base", left.Hash: "left", right.Hash: "right", merge.Hash: "merge"}
+
+	lines, err := File(merge, "f.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"1 1 left left B <b@example.com>",
+		"2 2 base A <a@example.com>",
+		"3 3 merge merge A <a@example.com>",
+		"4 4 base A <a@example.com>",
+		"5 5 right right C <c@example.com>",
+	}
+
+	if got := blamed(lines, names); !reflect.DeepEqual(got, want) {
+		t.Errorf("File(merge, f.txt) =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFileErrors(t *testing.T) {
+	tr := newTestRepo(t)
+	c := tr.commit("A <a@example.com>", map[string]string{"bin": "a\x00b\n"})
+	if _, err := File(c, "bin"); err == nil || !strings.Contains(err.Error(), ErrBinary.Error()) {
+		t.Errorf("File of a binary file = %v, want %v", err, ErrBinary)
+	}
+
+	if _, err := File(c, "missing"); err != git.ErrFileNotFound {
+		t.Errorf("File of a missing file = %v, want %v", err, git.ErrFileNotFound)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/review"
+)
+
+// checkFlags returns an error for each flag of the command with an unknown
+// value or combined with an incompatible option.
+func checkFlags(o *review.Options) optionErrors {
+	var errs optionErrors
+	if *indexFlag && (o.Commit != "" || o.SinceCommit != "" || o.BaseBranch != "") {
+		errs.add(errors.New("-index can't be combined with -commit, -since-commit or -base-branch"))
+	}
+
+	if *worktreeFlag && (*indexFlag || o.Commit != "" || o.SinceCommit != "" || o.BaseBranch != "") {
+		errs.add(errors.New("-worktree can't be combined with -index, -commit, -since-commit or -base-branch"))
+	}
+
+	if (*indexFlag || *worktreeFlag) && hasRemote(repoPaths) {
+		errs.add(errors.New("-index and -worktree can't review a -repo URL, which has no index nor working tree"))
+	}
+
+	if *patchFlag != "" && (len(repoPaths) > 0 || *indexFlag || *worktreeFlag || *changedFiles != "" || *serveAddr != "" ||
+		o.Ref != "" || o.Commit != "" || o.SinceCommit != "" || o.BaseBranch != "") {
+		errs.add(errors.New("-patch can't be combined with -repo, -index, -worktree, -changed-files, -serve, -ref, -commit, -since-commit, -base-branch or -range"))
+	}
+
+	if *githubStatus != "" && (len(repoPaths) > 1 || *indexFlag || *worktreeFlag || *patchFlag != "" || *serveAddr != "") {
+		errs.add(errors.New("-github-status sets the status of a single reviewed commit, it can't be combined with several -repo, -index, -worktree, -patch or -serve"))
+	}
+
+	if *githubStatus != "" && strings.Count(*githubStatus, "/") != 1 {
+		errs.add(fmt.Errorf("invalid -github-status %q, want OWNER/NAME", *githubStatus))
+	}
+
+	for _, glob := range serveAllow {
+		if _, err := path.Match(glob, ""); err != nil {
+			errs.add(fmt.Errorf("invalid -serve-allow glob %q: %s", glob, err))
+		}
+	}
+
+	if *cloneDepth < 0 {
+		errs.add(fmt.Errorf("invalid -clone-depth %d", *cloneDepth))
+	}
+
+	if *abbrev < 0 {
+		errs.add(fmt.Errorf("invalid -abbrev %d", *abbrev))
+	}
+
+	if *objectCache < 0 {
+		errs.add(fmt.Errorf("invalid -object-cache-size %d", *objectCache))
+	}
+
+	if o.MaxReadSize < 0 {
+		errs.add(fmt.Errorf("invalid -max-read-size %d", o.MaxReadSize))
+	}
+
+	if o.MaxBodyLineLength < 0 {
+		errs.add(fmt.Errorf("invalid -max-body-line-length %d", o.MaxBodyLineLength))
+	}
+
+	if o.MaxFileLines < 0 {
+		errs.add(fmt.Errorf("invalid -max-file-lines %d", o.MaxFileLines))
+	}
+
+	if o.MaxFuncLines < 0 {
+		errs.add(fmt.Errorf("invalid -max-func-lines %d", o.MaxFuncLines))
+	}
+
+	if o.MaxFuncStatements < 0 {
+		errs.add(fmt.Errorf("invalid -max-func-statements %d", o.MaxFuncStatements))
+	}
+
+	if o.MaxComplexity < 0 {
+		errs.add(fmt.Errorf("invalid -max-complexity %d", o.MaxComplexity))
+	}
+
+	if o.MaxAddedFileSize < 0 {
+		errs.add(fmt.Errorf("invalid -max-added-file-size %d", o.MaxAddedFileSize))
+	}
+
+	if *format != "text" && *format != "json" && *format != "junit" {
+		errs.add(fmt.Errorf("unknown format %q", *format))
+	}
+
+	if *colorFlag != "auto" && *colorFlag != "always" && *colorFlag != "never" {
+		errs.add(fmt.Errorf("unknown -color %q, want auto, always or never", *colorFlag))
+	}
+
+	if *manifest != "" && *manifest != "json" {
+		errs.add(fmt.Errorf("unknown manifest format %q", *manifest))
+	}
+
+	return errs
+}
+
+// checkConfig checks the options, their patterns, the files they name and, in
+// every repository, the revisions to review without running any check. The
+// problems found are written to w, one per line, and exitError is returned
+// if there is any. The options themselves were already resolved, invalid
+// values making the bot exit before.
+func checkConfig(w io.Writer, o *review.Options) int {
+	errs := checkFlags(o)
+	if _, err := loadBaseline(); err != nil {
+		errs.add(fmt.Errorf("baseline: %s", err))
+	}
+
+	if *changedFiles != "" {
+		var err error
+		if o.ChangedFiles, err = loadChangedFiles(*changedFiles); err != nil {
+			errs.add(fmt.Errorf("changed files: %s", err))
+		}
+	}
+
+	if o.SigningKeyring != "" {
+		if _, err := os.Stat(o.SigningKeyring); err != nil {
+			errs.add(fmt.Errorf("signing keyring: %s", err))
+		}
+	}
+
+	// A -patch is reviewed instead of the repository of the current
+	// directory, which isn't opened.
+	if len(repoPaths) == 0 && *patchFlag == "" {
+		repoPaths = commaList{"."}
+	}
+
+	for _, err := range review.CheckPatterns(o) {
+		errs.add(err)
+	}
+
+	for _, path := range repoPaths {
+		repo, err := openPath(path, *cloneDepth, o)
+		if err != nil {
+			errs.add(err)
+			continue
+		}
+
+		for _, err := range review.CheckRevisions(repo, o) {
+			if err == review.ErrEmptyRepository {
+				fmt.Fprintf(os.Stderr, "code-review-bot: %s: %s\n", path, err)
+				continue
+			}
+
+			errs.add(fmt.Errorf("%s: %s", path, err))
+		}
+	}
+
+	for _, err := range errs {
+		fmt.Fprintln(w, err)
+	}
+
+	if len(errs) > 0 {
+		return exitError
+	}
+
+	fmt.Fprintln(w, "configuration ok")
+	return exitOK
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/review"
+	"github.com/gunjan5/code-review-bot/rules"
+)
+
+func TestCheckConfig(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	r.commit(map[string]string{"a.go": "package a\n"}, "Add a")
+
+	defer func(saved commaList) { repoPaths = saved }(repoPaths)
+	for _, tc := range []struct {
+		name string
+		o    *review.Options
+		// want are the problems listed, none for a valid configuration.
+		want []string
+	}{
+		{"valid", &review.Options{Options: rules.Options{
+			TicketPattern:  `[A-Z]+-[0-9]+`,
+			SensitivePaths: []string{"deploy/**"},
+			DisabledRules:  []string{"secret"},
+		}}, nil},
+		{"bad regex", &review.Options{Options: rules.Options{TicketPattern: `(ABC-[0-9]+`}}, []string{"invalid ticket pattern: "}},
+		{"bad glob", &review.Options{Options: rules.Options{SensitivePaths: []string{"deploy/[a"}}}, []string{`invalid glob "deploy/[a": `}},
+		{"unknown rule", &review.Options{Options: rules.Options{DisabledRules: []string{"secret", "no-such-rule"}}}, []string{`unknown disabled rule "no-such-rule"`}},
+		{"several problems", &review.Options{
+			Ref:     "missing",
+			Options: rules.Options{TicketPattern: `(`, DisabledRules: []string{"nope"}},
+		}, []string{"invalid ticket pattern: ", `unknown disabled rule "nope"`, r.dir + `: `}},
+	} {
+		repoPaths = commaList{r.dir}
+		var buf bytes.Buffer
+		code := checkConfig(&buf, tc.o)
+		out := buf.String()
+		if tc.want == nil {
+			if code != exitOK || out != "configuration ok\n" {
+				t.Errorf("%s: checkConfig = %d, %q, want %d and configuration ok", tc.name, code, out, exitOK)
+			}
+
+			continue
+		}
+
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		if code != exitError || len(lines) != len(tc.want) {
+			t.Errorf("%s: checkConfig = %d, %q, want %d and %d problems", tc.name, code, out, exitError, len(tc.want))
+			continue
+		}
+
+		for i, want := range tc.want {
+			if !strings.HasPrefix(lines[i], want) {
+				t.Errorf("%s: problem %d = %q, want it to start with %q", tc.name, i+1, lines[i], want)
+			}
+		}
+	}
+}
+
+func TestCheckConfigPatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The current directory isn't a repository, and must not be opened.
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	defer func(saved commaList, patch string) {
+		repoPaths, *patchFlag = saved, patch
+	}(repoPaths, *patchFlag)
+	repoPaths, *patchFlag = nil, "x.diff"
+
+	var buf bytes.Buffer
+	if code := checkConfig(&buf, &review.Options{}); code != exitOK || buf.String() != "configuration ok\n" {
+		t.Errorf("checkConfig with -patch = %d, %q, want %d and configuration ok", code, buf.String(), exitOK)
+	}
+}
+
+func TestCheckFlagsAbbrev(t *testing.T) {
+	defer func(saved int) { *abbrev = saved }(*abbrev)
+	for _, tc := range []struct {
+		abbrev int
+		want   string
+	}{
+		{0, ""},
+		{7, ""},
+		{40, ""},
+		{-1, "invalid -abbrev -1"},
+	} {
+		*abbrev = tc.abbrev
+		got := ""
+		if errs := checkFlags(&review.Options{}); len(errs) > 0 {
+			got = errs.Error()
+		}
+
+		if got != tc.want {
+			t.Errorf("checkFlags with -abbrev %d = %q, want %q", tc.abbrev, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/fetch"
+	"github.com/gunjan5/code-review-bot/review"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/clients/common"
+	"gopkg.in/src-d/go-git.v4/clients/http"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// scpURL matches the scp-like syntax of ssh URLs, as in
+// git@github.com:org/repo.git, telling them apart from local paths by the
+// user or the colon before the first slash.
+var scpURL = regexp.MustCompile(`^([^@/]+@)?[^@/:]+:[^/]`)
+
+// isRemote returns true if the -repo value is the URL of a remote repository
+// to clone instead of a local path: an http, https or ssh URL, or the
+// scp-like syntax of ssh.
+func isRemote(path string) bool {
+	for _, scheme := range []string{"http://", "https://", "ssh://"} {
+		if strings.HasPrefix(path, scheme) {
+			return true
+		}
+	}
+
+	return strings.Contains(path, "@") && scpURL.MatchString(path)
+}
+
+// cloneRepo clones the repository at the URL in memory, only the last depth
+// commits of its branches if not 0.
+func cloneRepo(rawurl string, depth int) (*git.Repository, error) {
+	repo := git.NewMemoryRepository()
+	o := &git.CloneOptions{URL: rawurl, Depth: depth, Auth: remoteAuth(rawurl)}
+
+	// A remote without commits has no references to fetch, the repository
+	// is left empty as after git clone.
+	err := repo.Clone(o)
+	if perr, ok := err.(*core.PermanentError); ok && perr.Err == common.ErrEmptyGitUploadPack {
+		err = nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cloning %s: %s", rawurl, err)
+	}
+
+	return repo, nil
+}
+
+// remoteAuth returns the credentials of the remote repository at the URL:
+// the GitHub token, when set, for https URLs of github.com, nil otherwise.
+func remoteAuth(rawurl string) common.AuthMethod {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Scheme != "https" || u.Host != "github.com" || *githubToken == "" {
+		return nil
+	}
+
+	return http.NewBasicAuth("x-access-token", *githubToken)
+}
+
+// openPath opens the repository at the -repo path. URLs are cloned with
+// their history up to depth commits, 0 for all of it, or when the options
+// have a base branch, only the base branch and the reviewed reference are
+// fetched, as for a pull request.
+func openPath(path string, depth int, o *review.Options) (*git.Repository, error) {
+	if isRemote(path) && o.BaseBranch != "" {
+		return fetch.PullRequest(&fetch.Options{
+			URL:   path,
+			Base:  o.BaseBranch,
+			Head:  o.Ref,
+			Depth: depth,
+			Auth:  remoteAuth(path),
+		})
+	}
+
+	if isRemote(path) {
+		return cloneRepo(path, depth)
+	}
+
+	dir, err := gitDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git repository: %s", path, err)
+	}
+
+	repo, err := openGitDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening repository %s: %s", path, err)
+	}
+
+	return repo, nil
+}
+
+// hasRemote returns true if any of the -repo paths is a URL.
+func hasRemote(paths []string) bool {
+	for _, path := range paths {
+		if isRemote(path) {
+			return true
+		}
+	}
+
+	return false
+}
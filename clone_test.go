@@ -0,0 +1,129 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/internal/gitserver"
+	"github.com/gunjan5/code-review-bot/review"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+func TestIsRemote(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"https://github.com/org/repo.git", true},
+		{"http://localhost:8080/repo", true},
+		{"ssh://git@github.com/org/repo.git", true},
+		{"git@github.com:org/repo.git", true},
+		{"user@host:repo", true},
+		{"/srv/repo", false},
+		{"repo", false},
+		{"./dir:with/colon", false},
+		{"dir/user@host:repo", false},
+		{"file:///srv/repo", false},
+		{"C:/repo", false},
+	} {
+		if got := isRemote(tc.path); got != tc.want {
+			t.Errorf("isRemote(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestCloneRepo(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	s := gitserver.Serve(t, filepath.Dir(r.dir))
+	defer s.Close()
+	url := s.URL + "/" + filepath.Base(r.dir)
+
+	// The remote has no commit yet, the clone is empty.
+	repo, err := cloneRepo(url, 0)
+	if err != nil {
+		t.Fatalf("cloneRepo(empty remote): %s", err)
+	}
+
+	if empty, err := repo.IsEmpty(); err != nil || !empty {
+		t.Errorf("clone of an empty remote: IsEmpty() = %v, %v, want true", empty, err)
+	}
+
+	first := r.commit(map[string]string{"a.txt": "a\n"}, "Add a")
+	r.git("checkout", "-q", "-b", "topic")
+	topic := r.commit(map[string]string{"b.txt": "b\n"}, "Add b")
+	r.git("checkout", "-q", "main")
+	main := r.commit(map[string]string{"a.txt": "a2\n"}, "Change a")
+
+	for _, tc := range []struct {
+		depth   int
+		missing []string
+	}{
+		{0, nil},
+		{1, []string{first}},
+	} {
+		repo, err := cloneRepo(url, tc.depth)
+		if err != nil {
+			t.Fatalf("cloneRepo(depth %d): %s", tc.depth, err)
+		}
+
+		for rev, want := range map[string]string{"HEAD": main, "main": main, "origin/topic": topic} {
+			if h, err := review.ResolveRevision(repo, rev); err != nil || h.String() != want {
+				t.Errorf("cloneRepo(depth %d): %s = %s, %v, want %s", tc.depth, rev, h, err, want)
+			}
+		}
+
+		for _, h := range []string{first, topic, main} {
+			missing := false
+			for _, m := range tc.missing {
+				missing = missing || m == h
+			}
+
+			if _, err := repo.Commit(core.NewHash(h)); (err != nil) != missing {
+				t.Errorf("cloneRepo(depth %d): commit %s read with %v, want it missing %v", tc.depth, h[:7], err, missing)
+			}
+		}
+	}
+
+	if _, err := cloneRepo(s.URL+"/missing", 0); err == nil || !strings.Contains(err.Error(), "cloning "+s.URL+"/missing") {
+		t.Errorf("cloneRepo(missing) = %v, want a cloning error", err)
+	}
+}
+
+func TestOpenPathRemote(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	s := gitserver.Serve(t, filepath.Dir(r.dir))
+	defer s.Close()
+	url := s.URL + "/" + filepath.Base(r.dir)
+
+	r.commit(map[string]string{"a.txt": "a\n"}, "Add a")
+	r.git("checkout", "-q", "-b", "topic")
+	topic := r.commit(map[string]string{"b.txt": "b\n"}, "Add b")
+	r.git("checkout", "-q", "main")
+	main := r.commit(map[string]string{"a.txt": "a2\n"}, "Change a")
+
+	// With a base branch, only the base and the reviewed ref are fetched,
+	// under their name on the remote.
+	o := &review.Options{BaseBranch: "main", Ref: "topic"}
+	repo, err := openPath(url, 0, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for rev, want := range map[string]string{"HEAD": topic, "main": main, "topic": topic} {
+		if h, err := review.ResolveRevision(repo, rev); err != nil || h.String() != want {
+			t.Errorf("openPath with a base branch: %s = %s, %v, want %s", rev, h, err, want)
+		}
+	}
+
+	repo, err = openPath(url, 0, &review.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h, err := review.ResolveRevision(repo, "HEAD"); err != nil || h.String() != main {
+		t.Errorf("openPath of a clone: HEAD = %s, %v, want %s", h, err, main)
+	}
+}
@@ -0,0 +1,114 @@
+// Package config reads the YAML configuration files of the bot.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// Config is a parsed configuration file.
+type Config struct {
+	Path string
+	Root *Node
+}
+
+// Load reads and parses the configuration file at path.
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	c.Path = path
+	return c, nil
+}
+
+// Parse parses a configuration document, its top level must be a map.
+func Parse(data []byte) (*Config, error) {
+	root, err := ParseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.Kind != Map {
+		return nil, fmt.Errorf("line %d: the configuration must be a map", root.Line)
+	}
+
+	return &Config{Root: root}, nil
+}
+
+// Keys returns the top level keys in file order.
+func (c *Config) Keys() []string {
+	return c.Root.Keys
+}
+
+// String returns the scalar value of a top level key.
+func (c *Config) String(key string) (string, bool, error) {
+	n := c.Root.Get(key)
+	if n == nil {
+		return "", false, nil
+	}
+
+	if n.Kind != Scalar {
+		return "", false, fmt.Errorf("line %d: %s must be a single value", n.Line, key)
+	}
+
+	return n.Value, true, nil
+}
+
+// Strings returns the values of a top level key holding a list of scalars,
+// or a single scalar.
+func (c *Config) Strings(key string) ([]string, bool, error) {
+	n := c.Root.Get(key)
+	if n == nil {
+		return nil, false, nil
+	}
+
+	switch n.Kind {
+	case Scalar:
+		return []string{n.Value}, true, nil
+	case List:
+		values := make([]string, len(n.Items))
+		for i, item := range n.Items {
+			if item.Kind != Scalar {
+				return nil, false, fmt.Errorf("line %d: %s must be a list of values", item.Line, key)
+			}
+
+			values[i] = item.Value
+		}
+
+		return values, true, nil
+	default:
+		return nil, false, fmt.Errorf("line %d: %s must be a list of values", n.Line, key)
+	}
+}
+
+// Pairs returns the entries of a top level key holding a map of scalars as
+// KEY=VALUE strings, in file order.
+func (c *Config) Pairs(key string) ([]string, error) {
+	n := c.Root.Get(key)
+	if n == nil {
+		return nil, nil
+	}
+
+	if n.Kind != Map {
+		return nil, fmt.Errorf("line %d: %s must be a map", n.Line, key)
+	}
+
+	pairs := make([]string, len(n.Keys))
+	for i, k := range n.Keys {
+		v := n.Get(k)
+		if v.Kind != Scalar {
+			return nil, fmt.Errorf("line %d: %s.%s must be a single value", v.Line, key, k)
+		}
+
+		pairs[i] = k + "=" + v.Value
+	}
+
+	return pairs, nil
+}
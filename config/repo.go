@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+)
+
+// RepoFile is the name of the configuration file read at the root of the
+// reviewed repositories.
+const RepoFile = ".codereview.yaml"
+
+// Values turning a rule on or off in a repository configuration, the other
+// values being severities.
+const (
+	RuleOn  = "on"
+	RuleOff = "off"
+)
+
+// Repo is the configuration of the review of a repository, read from its
+// RepoFile:
+//
+//	rules:
+//	  ticket-reference: off
+//	  subject-length: error
+//	exclude:
+//	  - third_party
+//	forbidden:
+//	  - "*.sqlite"
+//	overrides:
+//	  - paths: [legacy/**]
+//	    rules:
+//	      trailing-whitespace: off
+type Repo struct {
+	// Rules are the settings of the rules, in file order.
+	Rules []RuleSetting
+	// Exclude are the globs of the paths left out of the review.
+	Exclude []string
+	// Forbidden are the globs of the files commits can't add, see
+	// rules.Options.ForbiddenFiles.
+	Forbidden []string
+	// Overrides change the settings of the rules for the files matching
+	// their paths, the later overrides winning.
+	Overrides []Override
+}
+
+// RuleSetting sets a rule to RuleOn, RuleOff or a severity.
+type RuleSetting struct {
+	ID    string
+	Value string
+	// Line is the line of the setting in the file.
+	Line int
+}
+
+// Override holds the settings of the rules for the files matching one of
+// the Paths globs.
+type Override struct {
+	Paths []string
+	Rules []RuleSetting
+	// Line is the line of the override in the file.
+	Line int
+}
+
+// ParseRepo parses a repository configuration, an empty document giving an
+// empty configuration.
+func ParseRepo(data []byte) (*Repo, error) {
+	c, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &Repo{}
+	for _, key := range c.Keys() {
+		n := c.Root.Get(key)
+		switch key {
+		case "rules":
+			repo.Rules, err = ruleSettings(n, key)
+		case "exclude":
+			repo.Exclude, _, err = c.Strings(key)
+		case "forbidden":
+			repo.Forbidden, _, err = c.Strings(key)
+		case "overrides":
+			repo.Overrides, err = overrides(n)
+		default:
+			err = fmt.Errorf("line %d: unknown key %q, expected rules, exclude, forbidden or overrides", n.Line, key)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return repo, nil
+}
+
+// ruleSettings reads the map of rule ids to values at the key.
+func ruleSettings(n *Node, key string) ([]RuleSetting, error) {
+	if n.Kind != Map {
+		return nil, fmt.Errorf("line %d: %s must be a map of rules", n.Line, key)
+	}
+
+	settings := make([]RuleSetting, len(n.Keys))
+	for i, id := range n.Keys {
+		v := n.Get(id)
+		if v.Kind != Scalar {
+			return nil, fmt.Errorf("line %d: %s.%s must be %s, %s or a severity", v.Line, key, id, RuleOn, RuleOff)
+		}
+
+		settings[i] = RuleSetting{ID: id, Value: v.Value, Line: v.Line}
+	}
+
+	return settings, nil
+}
+
+// overrides reads the list of overrides.
+func overrides(n *Node) ([]Override, error) {
+	if n.Kind != List {
+		return nil, fmt.Errorf("line %d: overrides must be a list", n.Line)
+	}
+
+	ovs := make([]Override, len(n.Items))
+	for i, item := range n.Items {
+		if item.Kind != Map {
+			return nil, fmt.Errorf("line %d: an override must be a map of paths and rules", item.Line)
+		}
+
+		ov := &Override{Line: item.Line}
+		for _, key := range item.Keys {
+			v := item.Get(key)
+			var err error
+			switch key {
+			case "paths":
+				ov.Paths, _, err = (&Config{Root: item}).Strings(key)
+			case "rules":
+				ov.Rules, err = ruleSettings(v, "overrides.rules")
+			default:
+				err = fmt.Errorf("line %d: unknown key %q in override, expected paths or rules", v.Line, key)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(ov.Paths) == 0 {
+			return nil, fmt.Errorf("line %d: override without paths", item.Line)
+		}
+
+		ovs[i] = *ov
+	}
+
+	return ovs, nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testRepoConfig = `rules:
+  ticket-reference: off
+  subject-length: error
+  todo-comment: on
+exclude:
+  - third_party
+  - "*.pb.go"
+forbidden: ["*.sqlite"]
+overrides:
+  - paths: [legacy/**]
+    rules:
+      trailing-whitespace: off
+  - paths:
+      - docs/*.md
+      - "*.txt"
+    rules:
+      line-length: warning
+`
+
+func TestParseRepo(t *testing.T) {
+	repo, err := ParseRepo([]byte(testRepoConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Repo{
+		Rules: []RuleSetting{
+			{ID: "ticket-reference", Value: RuleOff, Line: 2},
+			{ID: "subject-length", Value: "error", Line: 3},
+			{ID: "todo-comment", Value: RuleOn, Line: 4},
+		},
+		Exclude:   []string{"third_party", "*.pb.go"},
+		Forbidden: []string{"*.sqlite"},
+		Overrides: []Override{{
+			Paths: []string{"legacy/**"},
+			Rules: []RuleSetting{{ID: "trailing-whitespace", Value: RuleOff, Line: 12}},
+			Line:  10,
+		}, {
+			Paths: []string{"docs/*.md", "*.txt"},
+			Rules: []RuleSetting{{ID: "line-length", Value: "warning", Line: 17}},
+			Line:  13,
+		}},
+	}
+
+	if !reflect.DeepEqual(repo, want) {
+		t.Errorf("ParseRepo() = %+v, want %+v", repo, want)
+	}
+}
+
+func TestParseRepoEmpty(t *testing.T) {
+	for _, data := range []string{"", "# no settings\n", "---\n"} {
+		repo, err := ParseRepo([]byte(data))
+		if err != nil || !reflect.DeepEqual(repo, &Repo{}) {
+			t.Errorf("ParseRepo(%q) = %+v, %v, want an empty configuration", data, repo, err)
+		}
+	}
+}
+
+func TestParseRepoErrors(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		err  string
+	}{
+		{"disabled-rules: [todo-comment]\n", `line 1: unknown key "disabled-rules"`},
+		{"rules: [todo-comment]\n", "line 1: rules must be a map of rules"},
+		{"rules:\n  todo-comment: [on]\n", "line 2: rules.todo-comment must be on, off or a severity"},
+		{"overrides:\n  paths: [a]\n", "line 2: overrides must be a list"},
+		{"overrides:\n  - legacy/**\n", "line 2: an override must be a map of paths and rules"},
+		{"overrides:\n  - rules:\n      todo-comment: off\n", "line 2: override without paths"},
+		{"overrides:\n  - paths: [a]\n    exclude: [b]\n", `line 3: unknown key "exclude" in override`},
+		{"overrides:\n  - paths: [a]\n    rules: off\n", "line 3: overrides.rules must be a map of rules"},
+	} {
+		repo, err := ParseRepo([]byte(tc.data))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("ParseRepo(%q) = %+v, %v, want an error containing %q", tc.data, repo, err, tc.err)
+		}
+	}
+}
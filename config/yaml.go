@@ -0,0 +1,509 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the type of a Node.
+type Kind int
+
+const (
+	Scalar Kind = iota
+	List
+	Map
+)
+
+// Node is a value of a configuration file: a scalar, a list of nodes or a map
+// of nodes keeping the order of its keys.
+type Node struct {
+	Kind  Kind
+	Value string
+	Items []*Node
+	Keys  []string
+	Map   map[string]*Node
+	// Line is the line of the file where the node starts.
+	Line int
+}
+
+func newMap(line int) *Node {
+	return &Node{Kind: Map, Map: make(map[string]*Node), Line: line}
+}
+
+// Get returns the node of the given key in a map, or nil.
+func (n *Node) Get(key string) *Node {
+	if n == nil || n.Kind != Map {
+		return nil
+	}
+
+	return n.Map[key]
+}
+
+// set adds a key to a map, keeping the keys in file order.
+func (n *Node) set(key string, v *Node) error {
+	if _, ok := n.Map[key]; ok {
+		return fmt.Errorf("line %d: duplicate key %q", v.Line, key)
+	}
+
+	n.Keys = append(n.Keys, key)
+	n.Map[key] = v
+	return nil
+}
+
+type line struct {
+	num    int
+	indent int
+	text   string // without indentation
+	raw    string
+}
+
+// parser reads the subset of YAML used by the configuration files: block
+// maps and lists, flow lists, plain and quoted scalars, literal (|) and folded
+// (>) block scalars and comments. Anchors, tags and multiple documents are not
+// supported.
+type parser struct {
+	lines []line
+	pos   int
+}
+
+// ParseYAML parses a YAML document. An empty document returns an empty map.
+func ParseYAML(data []byte) (*Node, error) {
+	p := &parser{}
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		text := strings.TrimLeft(raw, " ")
+		p.lines = append(p.lines, line{
+			num:    i + 1,
+			indent: len(raw) - len(text),
+			text:   text,
+			raw:    raw,
+		})
+	}
+
+	p.skipBlank()
+	if p.eof() {
+		return newMap(1), nil
+	}
+
+	n, err := p.parseBlock(p.cur().indent)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+	if !p.eof() {
+		return nil, p.errorf("unexpected content")
+	}
+
+	return n, nil
+}
+
+func (p *parser) eof() bool  { return p.pos >= len(p.lines) }
+func (p *parser) cur() *line { return &p.lines[p.pos] }
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	num := len(p.lines)
+	if !p.eof() {
+		num = p.cur().num
+	}
+
+	return fmt.Errorf("line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+// skipBlank moves past empty lines, comments and document markers.
+func (p *parser) skipBlank() {
+	for ; !p.eof(); p.pos++ {
+		l := p.cur()
+		if strings.HasPrefix(l.text, "\t") {
+			return
+		}
+
+		t := stripComment(l.text)
+		if t != "" && t != "---" {
+			return
+		}
+	}
+}
+
+func (p *parser) parseBlock(indent int) (*Node, error) {
+	if strings.HasPrefix(p.cur().text, "\t") {
+		return nil, p.errorf("tabs are not allowed for indentation")
+	}
+
+	if isListItem(stripComment(p.cur().text)) {
+		return p.parseList(indent)
+	}
+
+	return p.parseMap(indent)
+}
+
+func (p *parser) parseMap(indent int) (*Node, error) {
+	m := newMap(p.cur().num)
+	for {
+		p.skipBlank()
+		if p.eof() || p.cur().indent < indent {
+			return m, nil
+		}
+
+		l := p.cur()
+		if strings.HasPrefix(l.text, "\t") {
+			return nil, p.errorf("tabs are not allowed for indentation")
+		}
+
+		if l.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		text := stripComment(l.text)
+		if isListItem(text) {
+			return m, nil
+		}
+
+		key, value, ok := splitKey(text)
+		if !ok {
+			return nil, p.errorf("expected \"key: value\", got %q", text)
+		}
+
+		p.pos++
+		v, err := p.parseValue(value, indent, l.num, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.set(key, v); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseList(indent int) (*Node, error) {
+	list := &Node{Kind: List, Line: p.cur().num}
+	for {
+		p.skipBlank()
+		if p.eof() || p.cur().indent < indent {
+			return list, nil
+		}
+
+		l := p.cur()
+		if strings.HasPrefix(l.text, "\t") {
+			return nil, p.errorf("tabs are not allowed for indentation")
+		}
+
+		text := stripComment(l.text)
+		if l.indent > indent || !isListItem(text) {
+			if l.indent == indent {
+				return list, nil
+			}
+
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		rest := strings.TrimLeft(text[1:], " ")
+		if _, _, ok := splitKey(rest); ok && !isQuoted(rest) {
+			// a map starting on the same line as the list item, the
+			// rest of its keys are aligned with the first one
+			offset := len(l.text) - len(strings.TrimLeft(l.text[1:], " "))
+			l.indent += offset
+			l.text = l.text[offset:]
+			item, err := p.parseMap(l.indent)
+			if err != nil {
+				return nil, err
+			}
+
+			list.Items = append(list.Items, item)
+			continue
+		}
+
+		p.pos++
+		item, err := p.parseValue(rest, indent, l.num, false)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Items = append(list.Items, item)
+	}
+}
+
+// parseValue parses the value following a key or a list marker. When it is
+// empty the value is the nested block, if any.
+func (p *parser) parseValue(value string, indent, num int, inMap bool) (*Node, error) {
+	switch {
+	case value == "":
+		p.skipBlank()
+		if p.eof() {
+			return &Node{Kind: Scalar, Line: num}, nil
+		}
+
+		next := p.cur()
+		if next.indent > indent {
+			return p.parseBlock(next.indent)
+		}
+
+		// lists are allowed at the same indentation than their key
+		if inMap && next.indent == indent && isListItem(stripComment(next.text)) {
+			return p.parseList(indent)
+		}
+
+		return &Node{Kind: Scalar, Line: num}, nil
+	case value[0] == '|' || value[0] == '>':
+		return p.parseBlockScalar(value, indent, num)
+	case value[0] == '[':
+		return parseFlowList(value, num)
+	case value == "{}":
+		return newMap(num), nil
+	default:
+		s, err := parseScalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", num, err)
+		}
+
+		return &Node{Kind: Scalar, Value: s, Line: num}, nil
+	}
+}
+
+// parseBlockScalar reads the lines of a literal or folded block scalar.
+func (p *parser) parseBlockScalar(header string, indent, num int) (*Node, error) {
+	folded := header[0] == '>'
+	chomp := strings.TrimSpace(header[1:])
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return nil, fmt.Errorf("line %d: unsupported block scalar header %q", num, header)
+	}
+
+	var lines []string
+	blockIndent := -1
+	for ; !p.eof(); p.pos++ {
+		l := p.cur()
+		if strings.TrimSpace(l.raw) == "" {
+			lines = append(lines, "")
+			continue
+		}
+
+		if l.indent <= indent {
+			break
+		}
+
+		if blockIndent < 0 {
+			blockIndent = l.indent
+		}
+
+		if l.indent < blockIndent {
+			return nil, p.errorf("block scalar lines must be indented consistently")
+		}
+
+		lines = append(lines, l.raw[blockIndent:])
+	}
+
+	// trailing blank lines belong to the chomping, not to the content
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var s string
+	if folded {
+		s = fold(lines)
+	} else {
+		s = strings.Join(lines, "\n")
+	}
+
+	switch chomp {
+	case "":
+		if len(lines) > 0 {
+			s += "\n"
+		}
+	case "+":
+		s += strings.Repeat("\n", trailing+1)
+	}
+
+	return &Node{Kind: Scalar, Value: s, Line: num}, nil
+}
+
+// fold joins consecutive lines with spaces, empty lines become newlines.
+func fold(lines []string) string {
+	var out []string
+	var para []string
+	for _, l := range lines {
+		if l == "" {
+			out = append(out, strings.Join(para, " "))
+			para = nil
+			continue
+		}
+
+		para = append(para, l)
+	}
+
+	out = append(out, strings.Join(para, " "))
+	return strings.Join(out, "\n")
+}
+
+func parseFlowList(value string, num int) (*Node, error) {
+	if !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("line %d: unterminated flow list %q", num, value)
+	}
+
+	list := &Node{Kind: List, Line: num}
+	inner := strings.TrimSpace(value[1 : len(value)-1])
+	if inner == "" {
+		return list, nil
+	}
+
+	for _, item := range splitOutsideQuotes(inner, ',') {
+		s, err := parseScalar(strings.TrimSpace(item))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", num, err)
+		}
+
+		list.Items = append(list.Items, &Node{Kind: Scalar, Value: s, Line: num})
+	}
+
+	return list, nil
+}
+
+// parseScalar unquotes a scalar, the null values ~ and null are empty.
+func parseScalar(s string) (string, error) {
+	switch {
+	case s == "~" || s == "null":
+		return "", nil
+	case strings.HasPrefix(s, `"`):
+		if len(s) < 2 || !strings.HasSuffix(s, `"`) {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+
+		return unescape(s[1 : len(s)-1])
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	default:
+		return s, nil
+	}
+}
+
+func unescape(s string) (string, error) {
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out = append(out, s[i])
+			continue
+		}
+
+		i++
+		if i == len(s) {
+			return "", fmt.Errorf("invalid escape at end of string")
+		}
+
+		switch s[i] {
+		case 'n':
+			out = append(out, '\n')
+		case 't':
+			out = append(out, '\t')
+		case 'r':
+			out = append(out, '\r')
+		case '0':
+			out = append(out, 0)
+		case '\\', '"', '/':
+			out = append(out, s[i])
+		default:
+			return "", fmt.Errorf("unsupported escape \\%c", s[i])
+		}
+	}
+
+	return string(out), nil
+}
+
+func isListItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isQuoted(s string) bool {
+	return strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'")
+}
+
+// splitKey splits a "key: value" line. Keys can be quoted.
+func splitKey(text string) (key, value string, ok bool) {
+	if isQuoted(text) {
+		q := text[0]
+		end := strings.IndexByte(text[1:], q)
+		if end < 0 {
+			return "", "", false
+		}
+
+		rest := text[end+2:]
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+
+		k, err := parseScalar(text[:end+2])
+		if err != nil {
+			return "", "", false
+		}
+
+		return k, strings.TrimSpace(rest[1:]), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != ':' {
+			continue
+		}
+
+		if i+1 == len(text) || text[i+1] == ' ' {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), i > 0
+		}
+	}
+
+	return "", "", false
+}
+
+// stripComment removes a trailing comment and surrounding spaces. Comments
+// start with a # at the beginning of the text or after a space, outside of
+// quoted strings.
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			switch {
+			case quote == '"' && c == '\\':
+				i++
+			case quote == '\'' && c == '\'' && i+1 < len(text) && text[i+1] == '\'':
+				i++
+			case c == quote:
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || text[i-1] == ' ' || text[i-1] == '[' || text[i-1] == ',' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return strings.TrimSpace(text[:i])
+		}
+	}
+
+	return strings.TrimSpace(text)
+}
+
+func splitOutsideQuotes(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
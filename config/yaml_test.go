@@ -0,0 +1,203 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testConfig = `# review settings
+---
+max-file-size: 1048576
+ticket-pattern: "\\bJIRA-\\d+\\b"   # a quoted value
+quoted: 'it''s # not a comment'
+empty:
+null-value: ~
+disabled-rules:
+- trailing-whitespace
+- "line-length"   # comment
+inline-list: [a, "b, c", 'd']
+empty-list: []
+severity:
+  todo-comment: error
+  "file-size": info
+nested:
+  overrides:
+    - paths: [docs/**]
+      disabled: {}
+    - paths:
+        - "*.md"
+  list-at-key-indent:
+  - x
+license-template: |
+  // Copyright {year}
+  // All rights reserved.
+
+folded: >-
+  one
+  two
+
+  three
+keep: |+
+  kept
+
+last: end
+`
+
+func TestParseYAML(t *testing.T) {
+	c, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantKeys := []string{"max-file-size", "ticket-pattern", "quoted", "empty", "null-value", "disabled-rules", "inline-list", "empty-list", "severity", "nested", "license-template", "folded", "keep", "last"}
+	if !reflect.DeepEqual(c.Keys(), wantKeys) {
+		t.Errorf("Keys() = %q, want %q", c.Keys(), wantKeys)
+	}
+
+	for key, want := range map[string]string{
+		"max-file-size":    "1048576",
+		"ticket-pattern":   `\bJIRA-\d+\b`,
+		"quoted":           "it's # not a comment",
+		"empty":            "",
+		"null-value":       "",
+		"license-template": "// Copyright {year}\n// All rights reserved.\n",
+		"folded":           "one two\nthree",
+		"keep":             "kept\n\n",
+		"last":             "end",
+	} {
+		got, ok, err := c.String(key)
+		if err != nil || !ok || got != want {
+			t.Errorf("String(%q) = %q, %v, %v, want %q", key, got, ok, err, want)
+		}
+	}
+
+	for key, want := range map[string][]string{
+		"disabled-rules": {"trailing-whitespace", "line-length"},
+		"inline-list":    {"a", "b, c", "d"},
+		"empty-list":     {},
+		"last":           {"end"},
+	} {
+		got, ok, err := c.Strings(key)
+		if err != nil || !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("Strings(%q) = %q, %v, %v, want %q", key, got, ok, err, want)
+		}
+	}
+
+	pairs, err := c.Pairs("severity")
+	if want := []string{"todo-comment=error", "file-size=info"}; err != nil || !reflect.DeepEqual(pairs, want) {
+		t.Errorf("Pairs(severity) = %q, %v, want %q", pairs, err, want)
+	}
+
+	overrides := c.Root.Get("nested").Get("overrides")
+	if overrides == nil || overrides.Kind != List || len(overrides.Items) != 2 {
+		t.Fatalf("nested.overrides = %+v, want a list of 2 maps", overrides)
+	}
+
+	first := overrides.Items[0]
+	if first.Kind != Map || !reflect.DeepEqual(first.Keys, []string{"paths", "disabled"}) || first.Get("disabled").Kind != Map || first.Line != 18 {
+		t.Errorf("nested.overrides[0] = %+v", first)
+	}
+
+	paths := overrides.Items[1].Get("paths")
+	if paths == nil || paths.Kind != List || len(paths.Items) != 1 || paths.Items[0].Value != "*.md" {
+		t.Errorf("nested.overrides[1].paths = %+v", paths)
+	}
+
+	if l := c.Root.Get("nested").Get("list-at-key-indent"); l == nil || l.Kind != List || len(l.Items) != 1 {
+		t.Errorf("nested.list-at-key-indent = %+v, want a list", l)
+	}
+
+	if _, ok, err := c.String("missing"); ok || err != nil {
+		t.Errorf("String(missing) = %v, %v, want not found", ok, err)
+	}
+}
+
+func TestParseYAMLEmpty(t *testing.T) {
+	for _, doc := range []string{"", "\n\n", "# only a comment\n---\n"} {
+		c, err := Parse([]byte(doc))
+		if err != nil {
+			t.Errorf("Parse(%q): %s", doc, err)
+			continue
+		}
+
+		if len(c.Keys()) != 0 {
+			t.Errorf("Parse(%q) keys = %q, want none", doc, c.Keys())
+		}
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	for _, tc := range []struct {
+		doc, err string
+	}{
+		{"- a\n- b\n", "line 1: the configuration must be a map"},
+		{"a: 1\na: 2\n", "line 2: duplicate key \"a\""},
+		{"a:\n \t- b\n", "line 2: tabs are not allowed"},
+		{"a:\n\tb: c\n", "line 2: tabs are not allowed"},
+		{"a:\n  - b\n\t- c\n", "line 3: tabs are not allowed"},
+		{"a: [b, c\n", "line 1: unterminated flow list"},
+		{"a: \"b\n", "line 1: unterminated string"},
+		{"a: \"\\q\"\n", "line 1: unsupported escape"},
+		{"a: |x\n  b\n", "line 1: unsupported block scalar header"},
+		{"a: 1\n  b: 2\n", "line 2: "},
+		{"a:\n  - b\n  c: d\n", "line 3: "},
+	} {
+		_, err := Parse([]byte(tc.doc))
+		if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+			t.Errorf("Parse(%q) = %v, want an error starting with %q", tc.doc, err, tc.err)
+		}
+	}
+}
+
+func TestConfigAccessorErrors(t *testing.T) {
+	c, err := Parse([]byte("list: [a]\nmap:\n  k: v\nnested:\n  k: [v]\nitems:\n  - [a]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := c.String("list"); err == nil {
+		t.Error("String of a list succeeded")
+	}
+
+	if _, _, err := c.Strings("map"); err == nil {
+		t.Error("Strings of a map succeeded")
+	}
+
+	if _, _, err := c.Strings("items"); err == nil {
+		t.Error("Strings of a list of lists succeeded")
+	}
+
+	if _, err := c.Pairs("list"); err == nil {
+		t.Error("Pairs of a list succeeded")
+	}
+
+	if _, err := c.Pairs("nested"); err == nil {
+		t.Error("Pairs of a map of lists succeeded")
+	}
+}
+
+func TestPairs(t *testing.T) {
+	c, err := Parse([]byte("indent:\n  '*.go': tabs\n  '*.py': spaces\nlist:\n  - a\nnested:\n  a:\n    b: c\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pairs, err := c.Pairs("indent")
+	if want := []string{"*.go=tabs", "*.py=spaces"}; err != nil || !reflect.DeepEqual(pairs, want) {
+		t.Errorf("Pairs(indent) = %q, %v, want %q", pairs, err, want)
+	}
+
+	if pairs, err := c.Pairs("missing"); err != nil || pairs != nil {
+		t.Errorf("Pairs(missing) = %q, %v, want nothing", pairs, err)
+	}
+
+	for key, want := range map[string]string{
+		"list":   "line 5: list must be a map",
+		"nested": "line 8: nested.a must be a single value",
+	} {
+		if _, err := c.Pairs(key); err == nil || err.Error() != want {
+			t.Errorf("Pairs(%s) = %v, want %s", key, err, want)
+		}
+	}
+}
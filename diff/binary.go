@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// binarySniffLen is the number of bytes inspected to tell binary files
+// apart, the same as git.
+const binarySniffLen = 8000
+
+// binaryExtensions are the extensions of the files always handled as binary,
+// whatever their first bytes, such as images and archives.
+var binaryExtensions = map[string]bool{
+	".7z": true, ".a": true, ".avi": true, ".bmp": true, ".bz2": true,
+	".class": true, ".dll": true, ".dylib": true, ".eot": true, ".exe": true,
+	".flac": true, ".gif": true, ".gz": true, ".ico": true, ".jar": true,
+	".jpeg": true, ".jpg": true, ".mov": true, ".mp3": true, ".mp4": true,
+	".o": true, ".ogg": true, ".otf": true, ".pdf": true, ".png": true,
+	".psd": true, ".pyc": true, ".rar": true, ".so": true, ".tar": true,
+	".tgz": true, ".tif": true, ".tiff": true, ".ttf": true, ".war": true,
+	".wasm": true, ".wav": true, ".webm": true, ".webp": true, ".woff": true,
+	".woff2": true, ".xz": true, ".zip": true,
+}
+
+// IsBinaryPath returns true if the extension of the path is the one of a
+// binary format, compared case-insensitively.
+func IsBinaryPath(name string) bool {
+	return binaryExtensions[strings.ToLower(path.Ext(name))]
+}
+
+// IsBinary returns true if the file has the extension of a binary format or
+// contains a NUL byte in its first bytes, only those being read.
+func IsBinary(f *git.File) (bool, error) {
+	if IsBinaryPath(f.Name) {
+		return true, nil
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+
+	buf := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+
+	return bytes.IndexByte(buf[:n], 0) >= 0, nil
+}
+
+// BinaryChange is the change of a binary file, whose lines aren't compared.
+// OldSize is 0 for an added file and NewSize for a deleted one.
+type BinaryChange struct {
+	OldSize int64
+	NewSize int64
+}
+
+// Delta returns the number of bytes the file grew by, negative if it shrank.
+func (c *BinaryChange) Delta() int64 {
+	return c.NewSize - c.OldSize
+}
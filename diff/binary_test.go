@@ -0,0 +1,73 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func TestIsBinary(t *testing.T) {
+	for _, tc := range []struct {
+		name, content string
+		want          bool
+	}{
+		{"empty.txt", "", false},
+		{"a.txt", "text\n", false},
+		{"nul.txt", "text\x00more\n", true},
+		{"nul-first.dat", "\x00", true},
+		{"latin1.txt", "caf\xe9\n", false},
+		{"utf16.txt", "\xff\xfea\x00b\x00", true},
+		{"large.txt", strings.Repeat("line of text\n", 10000), false},
+		// Only the first bytes are inspected, as git does.
+		{"late-nul.txt", strings.Repeat("x", binarySniffLen-1) + "\x00", true},
+		{"later-nul.txt", strings.Repeat("x", binarySniffLen) + "\x00", false},
+		// Binary formats are binary whatever their content.
+		{"logo.png", "not really an image\n", true},
+		{"empty.PNG", "", true},
+		{"lib.so", "text\n", true},
+		{"archive.tar.gz", "text\n", true},
+		{"png", "text\n", false},
+	} {
+		got, err := IsBinary(testFile(t, tc.name, tc.content))
+		if err != nil || got != tc.want {
+			t.Errorf("IsBinary(%s) = %v, %v, want %v", tc.name, got, err, tc.want)
+		}
+	}
+}
+
+func TestUnifiedBinary(t *testing.T) {
+	text := testFile(t, "f", "text\n")
+	bin := testFile(t, "f", "bin\x00ary")
+	empty := testFile(t, "f", "")
+	for _, tc := range []struct {
+		name     string
+		from, to *git.File
+		want     *BinaryChange
+	}{
+		{"modified", bin, testFile(t, "f", "bin\x00ary, longer"), &BinaryChange{OldSize: 7, NewSize: 15}},
+		{"added", nil, bin, &BinaryChange{NewSize: 7}},
+		{"deleted", bin, nil, &BinaryChange{OldSize: 7}},
+		{"text to binary", text, bin, &BinaryChange{OldSize: 5, NewSize: 7}},
+		{"binary to empty", bin, empty, &BinaryChange{OldSize: 7}},
+		{"text", text, empty, nil},
+		{"unchanged", bin, bin, nil},
+	} {
+		p, err := Unified(tc.from, tc.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if (p.Binary == nil) != (tc.want == nil) || (p.Binary != nil && *p.Binary != *tc.want) {
+			t.Errorf("%s: Unified binary change = %+v, want %+v", tc.name, p.Binary, tc.want)
+		}
+
+		if p.Binary != nil && len(p.Hunks) != 0 {
+			t.Errorf("%s: Unified of a binary file has hunks %+v", tc.name, p.Hunks)
+		}
+	}
+
+	if d := (&BinaryChange{OldSize: 10, NewSize: 4}).Delta(); d != -6 {
+		t.Errorf("Delta() of a shrunk file = %d, want -6", d)
+	}
+}
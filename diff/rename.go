@@ -0,0 +1,290 @@
+package diff
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// DefaultRenameThreshold is the Similarity above which TreeDiff reports a
+// deleted and an added file as a rename, the same as git.
+const DefaultRenameThreshold = 50
+
+// maxRenameCandidates is the number of deleted or added files above which
+// only identical files are paired, git's default diff.renameLimit, as every
+// deleted file is compared to every added one.
+const maxRenameCandidates = 1000
+
+// Options configures the detection of renames and copies by TreeDiffWith.
+type Options struct {
+	// RenameThreshold is the minimum Similarity of a deleted and an added
+	// file for them to be reported as a rename, as git diff -M. Only
+	// identical files are paired if 0.
+	RenameThreshold int
+	// Copies reports the added files similar enough to a file modified,
+	// deleted or renamed by the changes as a copy of it, as git diff -C.
+	Copies bool
+}
+
+// detect pairs the deleted and added files into renames, then the added
+// files left into copies of the modified and deleted files.
+func detect(old, new *git.Tree, changes []Change, o *Options) ([]Change, error) {
+	changes = pairIdentical(changes)
+	b := &blobs{old: old, new: new, contents: make(map[core.Hash]string)}
+	threshold := o.RenameThreshold
+	if threshold > 0 {
+		var err error
+		if changes, err = pairSimilar(changes, b, threshold); err != nil {
+			return nil, err
+		}
+	}
+
+	if !o.Copies {
+		return changes, nil
+	}
+
+	if threshold <= 0 {
+		threshold = 100
+	}
+
+	return findCopies(changes, b, threshold)
+}
+
+// pairIdentical merges the deleted and added files with the same hash and
+// mode into renames. When several files share the content, the deleted and
+// added paths are paired in order.
+func pairIdentical(changes []Change) []Change {
+	sort.Sort(byPath(changes))
+	deleted := make(map[Entry][]int)
+	for i, ch := range changes {
+		if ch.Action == Deleted {
+			k := Entry{Mode: ch.From.Mode, Hash: ch.From.Hash}
+			deleted[k] = append(deleted[k], i)
+		}
+	}
+
+	paired := make(map[int]bool)
+	for i, ch := range changes {
+		k := Entry{Mode: ch.To.Mode, Hash: ch.To.Hash}
+		if ch.Action != Added || len(deleted[k]) == 0 {
+			continue
+		}
+
+		j := deleted[k][0]
+		deleted[k] = deleted[k][1:]
+		paired[j] = true
+		changes[i] = Change{Action: Renamed, From: changes[j].From, To: ch.To, Similarity: 100}
+	}
+
+	return without(changes, paired)
+}
+
+// candidate is a deleted and an added file similar enough to be a rename.
+type candidate struct {
+	from, to int
+	score    int
+}
+
+// pairSimilar merges the deleted and added files into renames, the most
+// similar first, when their similarity reaches the threshold.
+func pairSimilar(changes []Change, b *blobs, threshold int) ([]Change, error) {
+	var deleted, added []int
+	for i, ch := range changes {
+		switch ch.Action {
+		case Deleted:
+			deleted = append(deleted, i)
+		case Added:
+			added = append(added, i)
+		}
+	}
+
+	if len(deleted) == 0 || len(added) == 0 || len(deleted) > maxRenameCandidates || len(added) > maxRenameCandidates {
+		return changes, nil
+	}
+
+	var candidates []candidate
+	for _, d := range deleted {
+		for _, a := range added {
+			score, err := b.similarity(changes[d].From, changes[a].To, threshold)
+			if err != nil {
+				return nil, err
+			}
+
+			if score >= threshold {
+				candidates = append(candidates, candidate{from: d, to: a, score: score})
+			}
+		}
+	}
+
+	sort.Stable(byScore(candidates))
+	paired := make(map[int]bool)
+	renamed := make(map[int]bool)
+	for _, c := range candidates {
+		if renamed[c.from] || paired[c.to] {
+			continue
+		}
+
+		// The added file holds the rename, the deleted one is dropped.
+		renamed[c.from], paired[c.to] = true, true
+		changes[c.to] = Change{Action: Renamed, From: changes[c.from].From, To: changes[c.to].To, Similarity: c.score}
+	}
+
+	return without(changes, renamed), nil
+}
+
+// findCopies reports the added files whose similarity with a file modified,
+// deleted or renamed by the changes reaches the threshold as copies of the
+// most similar.
+func findCopies(changes []Change, b *blobs, threshold int) ([]Change, error) {
+	var sources []Entry
+	var added []int
+	for i, ch := range changes {
+		switch ch.Action {
+		case Modified, Deleted, Renamed:
+			sources = append(sources, ch.From)
+		case Added:
+			added = append(added, i)
+		}
+	}
+
+	if len(sources) > maxRenameCandidates || len(added) > maxRenameCandidates {
+		return changes, nil
+	}
+
+	for _, a := range added {
+		best := -1
+		score := 0
+		for i, src := range sources {
+			s, err := b.similarity(src, changes[a].To, threshold)
+			if err != nil {
+				return nil, err
+			}
+
+			if s >= threshold && s > score {
+				best, score = i, s
+			}
+		}
+
+		if best >= 0 {
+			changes[a] = Change{Action: Copied, From: sources[best], To: changes[a].To, Similarity: score}
+		}
+	}
+
+	return changes, nil
+}
+
+// blobs reads the contents of the files of the old and new trees, once per
+// blob.
+type blobs struct {
+	old, new *git.Tree
+	contents map[core.Hash]string
+}
+
+// similarity returns the percentage of the content of the larger of the
+// files found in the other, comparing lines, 0 if the files are of different
+// kinds or their sizes are too different to reach the threshold. Files with
+// a binary extension are only similar when identical, without being read.
+func (b *blobs) similarity(from, to Entry, threshold int) (int, error) {
+	if from.Mode&os.ModeSymlink != to.Mode&os.ModeSymlink {
+		return 0, nil
+	}
+
+	if from.Hash == to.Hash {
+		return 100, nil
+	}
+
+	if IsBinaryPath(from.Path) || IsBinaryPath(to.Path) {
+		return 0, nil
+	}
+
+	src, err := b.read(b.old, from)
+	if err != nil {
+		return 0, err
+	}
+
+	dst, err := b.read(b.new, to)
+	if err != nil {
+		return 0, err
+	}
+
+	small, large := len(src), len(dst)
+	if small > large {
+		small, large = large, small
+	}
+
+	if large == 0 || small*100/large < threshold {
+		return 0, nil
+	}
+
+	lines := make(map[string]int)
+	forEachLine(src, func(l string) {
+		lines[l]++
+	})
+
+	common := 0
+	forEachLine(dst, func(l string) {
+		if lines[l] > 0 {
+			lines[l]--
+			common += len(l)
+		}
+	})
+
+	return common * 100 / large, nil
+}
+
+func (b *blobs) read(t *git.Tree, e Entry) (string, error) {
+	if s, ok := b.contents[e.Hash]; ok {
+		return s, nil
+	}
+
+	f, err := t.File(e.Path)
+	if err != nil {
+		return "", err
+	}
+
+	s, err := f.Contents()
+	if err != nil {
+		return "", err
+	}
+
+	b.contents[e.Hash] = s
+	return s, nil
+}
+
+// forEachLine calls cb with every line of s, its newline included.
+func forEachLine(s string, cb func(string)) {
+	for s != "" {
+		i := strings.IndexByte(s, '\n') + 1
+		if i == 0 {
+			i = len(s)
+		}
+
+		cb(s[:i])
+		s = s[i:]
+	}
+}
+
+// without returns the changes whose index isn't in drop, reusing the slice.
+func without(changes []Change, drop map[int]bool) []Change {
+	if len(drop) == 0 {
+		return changes
+	}
+
+	kept := changes[:0]
+	for i, ch := range changes {
+		if !drop[i] {
+			kept = append(kept, ch)
+		}
+	}
+
+	return kept
+}
+
+type byScore []candidate
+
+func (s byScore) Len() int           { return len(s) }
+func (s byScore) Less(i, j int) bool { return s[i].score > s[j].score }
+func (s byScore) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
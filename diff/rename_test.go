@@ -0,0 +1,224 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// numbered returns n lines of 8 bytes, "line NN\n", the lines listed in
+// changed replaced by "edit NN\n".
+func numbered(n int, changed ...int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		word := "line"
+		for _, c := range changed {
+			if c == i {
+				word = "edit"
+			}
+		}
+
+		fmt.Fprintf(&b, "%s %02d\n", word, i)
+	}
+
+	return b.String()
+}
+
+func TestRenames(t *testing.T) {
+	tr := newTestRepo(t)
+	ten := numbered(10)
+	for _, tc := range []struct {
+		name     string
+		old, new map[string]string
+		o        *Options
+		want     []string
+	}{
+		{
+			"exact rename",
+			map[string]string{"old.txt": ten, "keep.txt": "keep\n"},
+			map[string]string{"dir/new.txt": ten, "keep.txt": "keep\n"},
+			nil,
+			[]string{"renamed old.txt -> dir/new.txt (100%)"},
+		},
+		{
+			"exact rename with a mode change",
+			map[string]string{"old.sh": ten},
+			map[string]string{"100755 new.sh": ten},
+			nil,
+			[]string{"renamed old.sh -> new.sh (100%)"},
+		},
+		{
+			"at the threshold",
+			map[string]string{"old.txt": ten},
+			map[string]string{"new.txt": numbered(10, 0, 2, 4, 6, 8)},
+			nil,
+			[]string{"renamed old.txt -> new.txt (50%)"},
+		},
+		{
+			"above the threshold",
+			map[string]string{"old.txt": ten},
+			map[string]string{"new.txt": numbered(10, 3)},
+			nil,
+			[]string{"renamed old.txt -> new.txt (90%)"},
+		},
+		{
+			"below the threshold",
+			map[string]string{"old.txt": ten},
+			map[string]string{"new.txt": numbered(10, 0, 1, 2, 4, 6, 8)},
+			nil,
+			[]string{"added new.txt", "deleted old.txt"},
+		},
+		{
+			"custom threshold",
+			map[string]string{"old.txt": ten},
+			map[string]string{"new.txt": numbered(10, 3)},
+			&Options{RenameThreshold: 95},
+			[]string{"added new.txt", "deleted old.txt"},
+		},
+		{
+			"identical only",
+			map[string]string{"old.txt": ten, "same.txt": "same\n"},
+			map[string]string{"new.txt": numbered(10, 3), "moved.txt": "same\n"},
+			&Options{},
+			[]string{"renamed same.txt -> moved.txt (100%)", "added new.txt", "deleted old.txt"},
+		},
+		{
+			"sizes too different",
+			map[string]string{"old.txt": numbered(4)},
+			map[string]string{"new.txt": numbered(10)},
+			nil,
+			[]string{"added new.txt", "deleted old.txt"},
+		},
+		{
+			"binary extension",
+			map[string]string{"old.png": ten},
+			map[string]string{"new.png": numbered(10, 3)},
+			nil,
+			[]string{"added new.png", "deleted old.png"},
+		},
+		{
+			"symbolic link and file",
+			map[string]string{"120000 old": "target"},
+			map[string]string{"new": "target"},
+			nil,
+			[]string{"added new", "deleted old"},
+		},
+		{
+			"identical tie between deleted files",
+			map[string]string{"a.txt": ten, "b.txt": ten},
+			map[string]string{"c.txt": ten},
+			nil,
+			[]string{"deleted b.txt", "renamed a.txt -> c.txt (100%)"},
+		},
+		{
+			"identical ties paired in order",
+			map[string]string{"a.txt": ten, "b.txt": ten},
+			map[string]string{"c.txt": ten, "d.txt": ten},
+			nil,
+			[]string{"renamed a.txt -> c.txt (100%)", "renamed b.txt -> d.txt (100%)"},
+		},
+		{
+			"similar tie between deleted files",
+			map[string]string{"a.txt": numbered(10, 1), "b.txt": numbered(10, 2)},
+			map[string]string{"c.txt": ten},
+			nil,
+			[]string{"deleted b.txt", "renamed a.txt -> c.txt (90%)"},
+		},
+		{
+			"similar tie between added files",
+			map[string]string{"a.txt": ten},
+			map[string]string{"b.txt": numbered(10, 1), "c.txt": numbered(10, 2)},
+			nil,
+			[]string{"renamed a.txt -> b.txt (90%)", "added c.txt"},
+		},
+		{
+			"most similar first",
+			map[string]string{"a.txt": numbered(10, 1, 2), "b.txt": numbered(10, 1)},
+			map[string]string{"c.txt": ten},
+			nil,
+			[]string{"deleted a.txt", "renamed b.txt -> c.txt (90%)"},
+		},
+	} {
+		o := tc.o
+		if o == nil {
+			o = &Options{RenameThreshold: DefaultRenameThreshold}
+		}
+
+		changes, err := TreeDiffWith(tr.tree(tc.old), tr.tree(tc.new), o)
+		if got := describe(changes); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: TreeDiffWith = %q, %v, want %q", tc.name, got, err, tc.want)
+		}
+	}
+}
+
+func TestCopies(t *testing.T) {
+	tr := newTestRepo(t)
+	ten := numbered(10)
+	for _, tc := range []struct {
+		name     string
+		old, new map[string]string
+		o        *Options
+		want     []string
+	}{
+		{
+			"copy of a modified file",
+			map[string]string{"src.txt": ten},
+			map[string]string{"src.txt": numbered(10, 9), "copy.txt": numbered(10, 3)},
+			&Options{RenameThreshold: DefaultRenameThreshold, Copies: true},
+			[]string{"copied src.txt -> copy.txt (90%)", "modified src.txt"},
+		},
+		{
+			"copy of a renamed file",
+			map[string]string{"src.txt": ten},
+			map[string]string{"moved.txt": ten, "copy.txt": ten},
+			&Options{RenameThreshold: DefaultRenameThreshold, Copies: true},
+			[]string{"renamed src.txt -> copy.txt (100%)", "copied src.txt -> moved.txt (100%)"},
+		},
+		{
+			"most similar source",
+			map[string]string{"a.txt": ten, "b.txt": numbered(10, 4)},
+			map[string]string{"a.txt": ten + "a\n", "b.txt": numbered(10, 4) + "b\n", "copy.txt": numbered(10, 5)},
+			&Options{RenameThreshold: DefaultRenameThreshold, Copies: true},
+			[]string{"modified a.txt", "modified b.txt", "copied a.txt -> copy.txt (90%)"},
+		},
+		{
+			"copy of an unchanged file",
+			map[string]string{"src.txt": ten},
+			map[string]string{"src.txt": ten, "copy.txt": ten},
+			&Options{RenameThreshold: DefaultRenameThreshold, Copies: true},
+			[]string{"added copy.txt"},
+		},
+		{
+			"too different",
+			map[string]string{"src.txt": ten},
+			map[string]string{"src.txt": numbered(10, 9), "copy.txt": numbered(10, 0, 1, 2, 4, 6, 8)},
+			&Options{RenameThreshold: DefaultRenameThreshold, Copies: true},
+			[]string{"added copy.txt", "modified src.txt"},
+		},
+		{
+			"identical only without a rename threshold",
+			map[string]string{"src.txt": ten},
+			map[string]string{"src.txt": numbered(10, 9), "copy.txt": numbered(10, 3), "exact.txt": ten},
+			&Options{Copies: true},
+			[]string{"added copy.txt", "copied src.txt -> exact.txt (100%)", "modified src.txt"},
+		},
+		{
+			"copies off",
+			map[string]string{"src.txt": ten},
+			map[string]string{"src.txt": numbered(10, 9), "copy.txt": ten},
+			nil,
+			[]string{"added copy.txt", "modified src.txt"},
+		},
+	} {
+		o := tc.o
+		if o == nil {
+			o = &Options{RenameThreshold: DefaultRenameThreshold}
+		}
+
+		changes, err := TreeDiffWith(tr.tree(tc.old), tr.tree(tc.new), o)
+		if got := describe(changes); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: TreeDiffWith = %q, %v, want %q", tc.name, got, err, tc.want)
+		}
+	}
+}
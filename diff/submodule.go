@@ -0,0 +1,55 @@
+package diff
+
+import (
+	"os"
+	"sort"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// SubmoduleChange is a submodule whose commit differs between two trees.
+// OldHash is the zero hash for an added submodule and NewHash for a removed
+// one.
+type SubmoduleChange struct {
+	Path    string
+	OldHash core.Hash
+	NewHash core.Hash
+}
+
+// Action returns Added, Deleted or Modified for a submodule moved to
+// another commit.
+func (c *SubmoduleChange) Action() Action {
+	switch {
+	case c.OldHash == core.ZeroHash:
+		return Added
+	case c.NewHash == core.ZeroHash:
+		return Deleted
+	}
+
+	return Modified
+}
+
+// SubmoduleDiff returns the submodules added, removed or moved to another
+// commit between the old and new trees, either nil, sorted by path.
+func SubmoduleDiff(old, new *git.Tree) ([]SubmoduleChange, error) {
+	d := &treeDiff{}
+	if err := d.walk(old, new, ""); err != nil {
+		return nil, err
+	}
+
+	sort.Sort(bySubmodulePath(d.submodules))
+	return d.submodules, nil
+}
+
+// IsSubmodule returns true if the mode is the one of a submodule entry, whose
+// hash is a commit of another repository.
+func IsSubmodule(m os.FileMode) bool {
+	return uint32(m)&0170000 == 0160000
+}
+
+type bySubmodulePath []SubmoduleChange
+
+func (s bySubmodulePath) Len() int           { return len(s) }
+func (s bySubmodulePath) Less(i, j int) bool { return s[i].Path < s[j].Path }
+func (s bySubmodulePath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func TestSubmoduleDiff(t *testing.T) {
+	const (
+		m1 = "1111111111111111111111111111111111111111"
+		m2 = "2222222222222222222222222222222222222222"
+	)
+
+	tr := newTestRepo(t)
+	old := tr.tree(map[string]string{
+		"160000 bumped":         m1,
+		"160000 same":           m1,
+		"160000 removed":        m1,
+		"160000 vendor/lib":     m1,
+		"160000 became-file":    m1,
+		"became-submodule/a.go": "package a\n",
+		"a.txt":                 "a\n",
+	})
+	new := tr.tree(map[string]string{
+		"160000 bumped":           m2,
+		"160000 same":             m1,
+		"160000 added":            m2,
+		"160000 vendor/lib":       m2,
+		"became-file":             "file\n",
+		"160000 became-submodule": m2,
+		"a.txt":                   "changed\n",
+	})
+
+	subs, err := SubmoduleDiff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, sc := range subs {
+		got = append(got, fmt.Sprintf("%s %s %s..%s", sc.Action(), sc.Path, sc.OldHash.String()[:4], sc.NewHash.String()[:4]))
+	}
+
+	want := []string{
+		"added added 0000..2222",
+		"deleted became-file 1111..0000",
+		"added became-submodule 0000..2222",
+		"modified bumped 1111..2222",
+		"deleted removed 1111..0000",
+		"modified vendor/lib 1111..2222",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SubmoduleDiff = %q, want %q", got, want)
+	}
+
+	// The files replacing a submodule or replaced by one are changes of
+	// their own.
+	changes, err := TreeDiff(old, new)
+	if want := []string{"modified a.txt", "added became-file", "deleted became-submodule/a.go"}; err != nil || !reflect.DeepEqual(describe(changes), want) {
+		t.Errorf("TreeDiff = %q, %v, want %q", describe(changes), err, want)
+	}
+
+	for _, trees := range [][2]*git.Tree{{old, old}, {nil, nil}} {
+		if subs, err := SubmoduleDiff(trees[0], trees[1]); err != nil || subs != nil {
+			t.Errorf("SubmoduleDiff of the same trees = %+v, %v, want nil", subs, err)
+		}
+	}
+}
+
+func TestIsSubmodule(t *testing.T) {
+	for _, tc := range []struct {
+		mode os.FileMode
+		want bool
+	}{
+		{0160000, true},
+		{0100644, false},
+		{0100755, false},
+		{0120000, false},
+		{040000, false},
+	} {
+		if got := IsSubmodule(tc.mode); got != tc.want {
+			t.Errorf("IsSubmodule(%o) = %v, want %v", tc.mode, got, tc.want)
+		}
+	}
+}
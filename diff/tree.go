@@ -0,0 +1,209 @@
+// Package diff compares the trees of two commits, listing the files added,
+// modified, deleted or renamed between them, and the versions of a file,
+// grouping the changed lines in hunks.
+package diff
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// Action is the kind of a change.
+type Action int
+
+const (
+	Added Action = iota
+	Modified
+	Deleted
+	Renamed
+	Copied
+)
+
+func (a Action) String() string {
+	switch a {
+	case Added:
+		return "added"
+	case Modified:
+		return "modified"
+	case Deleted:
+		return "deleted"
+	case Renamed:
+		return "renamed"
+	case Copied:
+		return "copied"
+	}
+
+	return fmt.Sprintf("action %d", int(a))
+}
+
+// Entry is a file on one side of a change.
+type Entry struct {
+	Path string
+	Mode os.FileMode
+	Hash core.Hash
+}
+
+// Change is a file differing between two trees. From is the zero Entry for
+// an added file and To for a deleted one.
+type Change struct {
+	Action Action
+	From   Entry
+	To     Entry
+	// Similarity is the percentage of the content of the file kept by a
+	// rename or a copy, 100 if unchanged, 0 for the other actions.
+	Similarity int
+}
+
+// Path returns the path of the file in the new tree, its old path if it was
+// deleted.
+func (c *Change) Path() string {
+	if c.Action == Deleted {
+		return c.From.Path
+	}
+
+	return c.To.Path
+}
+
+// TreeDiff returns the changes turning the old tree into the new one, sorted
+// by Path, detecting the renames with DefaultRenameThreshold. A nil tree is
+// handled as an empty one. Subtrees with the same hash on both sides are
+// skipped without being read. Submodules are left to SubmoduleDiff.
+func TreeDiff(old, new *git.Tree) ([]Change, error) {
+	return TreeDiffWith(old, new, &Options{RenameThreshold: DefaultRenameThreshold})
+}
+
+// TreeDiffWith is like TreeDiff with the rename and copy detection
+// configured by o.
+func TreeDiffWith(old, new *git.Tree, o *Options) ([]Change, error) {
+	d := &treeDiff{}
+	if err := d.walk(old, new, ""); err != nil {
+		return nil, err
+	}
+
+	changes, err := detect(old, new, d.changes, o)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(byPath(changes))
+	return changes, nil
+}
+
+// treeDiff collects the differences of two trees, the changes of the files
+// and those of the submodules apart.
+type treeDiff struct {
+	changes    []Change
+	submodules []SubmoduleChange
+}
+
+// walk adds the differences between the trees a and b, either nil. base is
+// the path of the trees.
+func (d *treeDiff) walk(a, b *git.Tree, base string) error {
+	if a != nil && b != nil && a.Hash == b.Hash {
+		return nil
+	}
+
+	as, bs := entries(a), entries(b)
+	for _, name := range names(as, bs) {
+		ae, aok := as[name]
+		be, bok := bs[name]
+		if aok && bok && ae.Hash == be.Hash && ae.Mode == be.Mode {
+			continue
+		}
+
+		p := path.Join(base, name)
+		aSub, bSub := aok && IsSubmodule(ae.Mode), bok && IsSubmodule(be.Mode)
+		if aSub || bSub {
+			sc := SubmoduleChange{Path: p}
+			if aSub {
+				sc.OldHash = ae.Hash
+			}
+
+			if bSub {
+				sc.NewHash = be.Hash
+			}
+
+			d.submodules = append(d.submodules, sc)
+			aok, bok = aok && !aSub, bok && !bSub
+		}
+
+		aDir, bDir := aok && ae.Mode.IsDir(), bok && be.Mode.IsDir()
+		if aDir || bDir {
+			var at, bt *git.Tree
+			var err error
+			if aDir {
+				if at, err = a.Dir(name); err != nil {
+					return fmt.Errorf("tree %s: %s", p, err)
+				}
+			}
+
+			if bDir {
+				if bt, err = b.Dir(name); err != nil {
+					return fmt.Errorf("tree %s: %s", p, err)
+				}
+			}
+
+			if err := d.walk(at, bt, p); err != nil {
+				return err
+			}
+		}
+
+		aFile, bFile := aok && !aDir, bok && !bDir
+		switch {
+		case aFile && bFile:
+			d.changes = append(d.changes, Change{Action: Modified, From: entry(p, ae), To: entry(p, be)})
+		case aFile:
+			d.changes = append(d.changes, Change{Action: Deleted, From: entry(p, ae)})
+		case bFile:
+			d.changes = append(d.changes, Change{Action: Added, To: entry(p, be)})
+		}
+	}
+
+	return nil
+}
+
+// entries returns the entries of the tree by name.
+func entries(t *git.Tree) map[string]git.TreeEntry {
+	m := make(map[string]git.TreeEntry)
+	if t == nil {
+		return m
+	}
+
+	for _, e := range t.Entries {
+		m[e.Name] = e
+	}
+
+	return m
+}
+
+// names returns the names of the entries of both trees, sorted.
+func names(a, b map[string]git.TreeEntry) []string {
+	var ns []string
+	for name := range a {
+		ns = append(ns, name)
+	}
+
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			ns = append(ns, name)
+		}
+	}
+
+	sort.Strings(ns)
+	return ns
+}
+
+func entry(p string, e git.TreeEntry) Entry {
+	return Entry{Path: p, Mode: e.Mode, Hash: e.Hash}
+}
+
+type byPath []Change
+
+func (s byPath) Len() int           { return len(s) }
+func (s byPath) Less(i, j int) bool { return s[i].Path() < s[j].Path() }
+func (s byPath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
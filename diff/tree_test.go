@@ -0,0 +1,186 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/internal/testrepo"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// testRepo is a repository in memory whose trees are written by the tests.
+type testRepo struct {
+	*testrepo.Repo
+	t *testing.T
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	return &testRepo{Repo: testrepo.New(t), t: t}
+}
+
+// tree writes the tree of the files, as testrepo.Repo.WriteTree, and
+// returns it.
+func (tr *testRepo) tree(files map[string]string) *git.Tree {
+	t, err := tr.Tree(tr.WriteTree(files))
+	if err != nil {
+		tr.t.Fatal(err)
+	}
+
+	return t
+}
+
+// describe returns the changes as "action path", "action from -> to
+// (similarity%)" for renames and copies, and the git modes of both sides
+// when they differ.
+func describe(changes []Change) []string {
+	var ds []string
+	for _, ch := range changes {
+		d := fmt.Sprintf("%s %s", ch.Action, ch.Path())
+		if ch.Action == Renamed || ch.Action == Copied {
+			d = fmt.Sprintf("%s %s -> %s (%d%%)", ch.Action, ch.From.Path, ch.To.Path, ch.Similarity)
+		}
+
+		if ch.Action == Modified && ch.From.Mode != ch.To.Mode {
+			d += fmt.Sprintf(" %o -> %o", uint32(ch.From.Mode)&0177777, uint32(ch.To.Mode)&0177777)
+		}
+
+		ds = append(ds, d)
+	}
+
+	return ds
+}
+
+func TestTreeDiff(t *testing.T) {
+	tr := newTestRepo(t)
+	const module = "0123456789012345678901234567890123456789"
+	old := tr.tree(map[string]string{
+		"a.txt":              "a\n",
+		"changed.txt":        "old\n",
+		"deleted.txt":        "deleted\n",
+		"run.sh":             "#!/bin/sh\n",
+		"dir/same.txt":       "same\n",
+		"dir/sub/nested.txt": "old nested\n",
+		"dir/sub/gone.txt":   "gone\n",
+		"unchanged/x.txt":    "x\n",
+		"160000 lib":         module,
+	})
+	new := tr.tree(map[string]string{
+		"a.txt":              "a\n",
+		"changed.txt":        "new\n",
+		"added.txt":          "added\n",
+		"100755 run.sh":      "#!/bin/sh\n",
+		"dir/same.txt":       "same\n",
+		"dir/sub/nested.txt": "new nested\n",
+		"dir/new/file.txt":   "new file\n",
+		"unchanged/x.txt":    "x\n",
+		"160000 lib":         "9876543210987654321098765432109876543210",
+	})
+
+	changes, err := TreeDiff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"added added.txt",
+		"modified changed.txt",
+		"deleted deleted.txt",
+		"added dir/new/file.txt",
+		"deleted dir/sub/gone.txt",
+		"modified dir/sub/nested.txt",
+		"modified run.sh 100644 -> 100755",
+	}
+
+	if got := describe(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("TreeDiff = %q, want %q", got, want)
+	}
+
+	for _, ch := range changes {
+		if ch.Path() == "run.sh" && ch.From.Hash != ch.To.Hash {
+			t.Errorf("mode change of run.sh changed its hash from %s to %s", ch.From.Hash, ch.To.Hash)
+		}
+	}
+
+	subs, err := SubmoduleDiff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(subs) != 1 || subs[0].Path != "lib" || subs[0].Action() != Modified || subs[0].OldHash != core.NewHash(module) {
+		t.Errorf("SubmoduleDiff = %+v, want lib moved from %s", subs, module)
+	}
+
+	if changes, err := TreeDiff(old, old); err != nil || len(changes) != 0 {
+		t.Errorf("TreeDiff of a tree with itself = %q, %v", describe(changes), err)
+	}
+}
+
+func TestTreeDiffEmpty(t *testing.T) {
+	tr := newTestRepo(t)
+	tree := tr.tree(map[string]string{
+		"a.txt":      "a\n",
+		"dir/b.txt":  "b\n",
+		"160000 lib": "0123456789012345678901234567890123456789",
+	})
+
+	changes, err := TreeDiff(nil, tree)
+	if want := []string{"added a.txt", "added dir/b.txt"}; err != nil || !reflect.DeepEqual(describe(changes), want) {
+		t.Errorf("TreeDiff(nil, tree) = %q, %v, want %q", describe(changes), err, want)
+	}
+
+	changes, err = TreeDiff(tree, nil)
+	if want := []string{"deleted a.txt", "deleted dir/b.txt"}; err != nil || !reflect.DeepEqual(describe(changes), want) {
+		t.Errorf("TreeDiff(tree, nil) = %q, %v, want %q", describe(changes), err, want)
+	}
+
+	subs, err := SubmoduleDiff(tree, nil)
+	if err != nil || len(subs) != 1 || subs[0].Action() != Deleted {
+		t.Errorf("SubmoduleDiff(tree, nil) = %+v, %v, want lib deleted", subs, err)
+	}
+}
+
+func TestTreeDiffKindChanges(t *testing.T) {
+	tr := newTestRepo(t)
+	old := tr.tree(map[string]string{
+		"file.txt":     "was a file\n",
+		"dir/a.txt":    "was a directory\n",
+		"160000 mod":   "0123456789012345678901234567890123456789",
+		"120000 link":  "target",
+		"plain/x.txt":  "x\n",
+		"becomes-link": "content\n",
+	})
+	new := tr.tree(map[string]string{
+		"file.txt/a.txt":      "now a directory\n",
+		"dir":                 "now a file\n",
+		"mod/x.txt":           "submodule vendored\n",
+		"120000 link":         "other target",
+		"plain/x.txt":         "x\n",
+		"120000 becomes-link": "content\n",
+	})
+
+	changes, err := TreeDiffWith(old, new, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"modified becomes-link 100644 -> 120000",
+		"added dir",
+		"deleted dir/a.txt",
+		"deleted file.txt",
+		"added file.txt/a.txt",
+		"modified link",
+		"added mod/x.txt",
+	}
+
+	if got := describe(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("TreeDiffWith = %q, want %q", got, want)
+	}
+
+	subs, err := SubmoduleDiff(old, new)
+	if err != nil || len(subs) != 1 || subs[0].Path != "mod" || subs[0].Action() != Deleted {
+		t.Errorf("SubmoduleDiff = %+v, %v, want mod deleted", subs, err)
+	}
+}
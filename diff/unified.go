@@ -0,0 +1,296 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"gopkg.in/src-d/go-git.v4"
+	linediff "gopkg.in/src-d/go-git.v4/diff"
+)
+
+// DefaultContext is the number of unchanged lines kept around the changes of
+// the hunks returned by Unified, the same as git diff.
+const DefaultContext = 3
+
+// LineKind tells whether a line of a hunk is unchanged, added or removed. Its
+// value is the prefix of the line in a unified diff.
+type LineKind byte
+
+const (
+	Context LineKind = ' '
+	Insert  LineKind = '+'
+	Delete  LineKind = '-'
+)
+
+// Line is a line of a hunk. OldLine is its 1-based line number in the old
+// file, 0 for an inserted line, and NewLine its number in the new file, 0
+// for a deleted line.
+type Line struct {
+	Kind    LineKind
+	Text    string
+	OldLine int
+	NewLine int
+	// NoNewline is set for the last line of a file not ending with a
+	// newline.
+	NoNewline bool
+}
+
+// Hunk is a group of changed lines with the unchanged lines around them.
+// OldStart and NewStart are the 1-based numbers of the first lines of the
+// hunk in each file, or the number of the line before the hunk when it has
+// no line on that side.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Header returns the header of the hunk in a unified diff.
+func (h *Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+func hunkRange(start, n int) string {
+	if n == 1 {
+		return fmt.Sprint(start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, n)
+}
+
+// Patch is the difference between two versions of a file. From and To are
+// the names of the files, empty for an added or deleted file. Binary is set
+// instead of the hunks for binary files.
+type Patch struct {
+	From   string
+	To     string
+	Binary *BinaryChange
+	Hunks  []Hunk
+}
+
+// String returns the patch in the unified diff format.
+func (p *Patch) String() string {
+	var buf bytes.Buffer
+	from, to := "/dev/null", "/dev/null"
+	if p.From != "" {
+		from = "a/" + p.From
+	}
+
+	if p.To != "" {
+		to = "b/" + p.To
+	}
+
+	if p.Binary != nil {
+		fmt.Fprintf(&buf, "Binary files %s and %s differ\n", from, to)
+		return buf.String()
+	}
+
+	if len(p.Hunks) == 0 {
+		return ""
+	}
+
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", from, to)
+	for _, h := range p.Hunks {
+		fmt.Fprintln(&buf, h.Header())
+		for _, l := range h.Lines {
+			fmt.Fprintf(&buf, "%c%s\n", l.Kind, l.Text)
+			if l.NoNewline {
+				fmt.Fprintln(&buf, `\ No newline at end of file`)
+			}
+		}
+	}
+
+	return buf.String()
+}
+
+// Unified returns the line changes turning the file a into b, either nil
+// for an added or deleted file, in hunks with DefaultContext unchanged lines
+// around the changes.
+func Unified(a, b *git.File) (*Patch, error) {
+	return UnifiedContext(a, b, DefaultContext)
+}
+
+// UnifiedContext is like Unified with n unchanged lines around the changes.
+// Hunks closer than 2n lines are merged. Binary files, as told by IsBinary,
+// aren't read beyond their first bytes.
+func UnifiedContext(a, b *git.File, n int) (*Patch, error) {
+	p := &Patch{}
+	if a != nil {
+		p.From = a.Name
+	}
+
+	if b != nil {
+		p.To = b.Name
+	}
+
+	if a != nil && b != nil && a.Hash == b.Hash {
+		return p, nil
+	}
+
+	src, srcBin, err := contents(a)
+	if err != nil {
+		return nil, err
+	}
+
+	dst, dstBin, err := contents(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if srcBin || dstBin {
+		p.Binary = &BinaryChange{}
+		if a != nil {
+			p.Binary.OldSize = a.Size
+		}
+
+		if b != nil {
+			p.Binary.NewSize = b.Size
+		}
+
+		return p, nil
+	}
+
+	p.Hunks = hunks(lines(src, dst), n)
+	return p, nil
+}
+
+// UnifiedText returns the hunks of the changes from src to dst, the old and
+// new contents of the file name, with DefaultContext unchanged lines around
+// them.
+func UnifiedText(name, src, dst string) *Patch {
+	p := &Patch{From: name, To: name}
+	if src != dst {
+		p.Hunks = hunks(lines(src, dst), DefaultContext)
+	}
+
+	return p
+}
+
+// contents returns the contents of the file, empty if nil, or true if it is
+// binary.
+func contents(f *git.File) (string, bool, error) {
+	if f == nil {
+		return "", false, nil
+	}
+
+	if binary, err := IsBinary(f); err != nil || binary {
+		return "", binary, err
+	}
+
+	s, err := f.Contents()
+	return s, false, err
+}
+
+// lines returns the lines of src and dst, in order, numbered and marked as
+// unchanged, inserted or deleted.
+func lines(src, dst string) []Line {
+	var ls []Line
+	var old, new int
+	for _, d := range linediff.Do(src, dst) {
+		text := d.Text
+		for text != "" {
+			l := Line{Kind: Context}
+			i := strings.IndexByte(text, '\n')
+			if i < 0 {
+				l.Text, l.NoNewline, text = text, true, ""
+			} else {
+				l.Text, text = text[:i], text[i+1:]
+			}
+
+			switch d.Type {
+			case diffmatchpatch.DiffInsert:
+				l.Kind = Insert
+				new++
+				l.NewLine = new
+			case diffmatchpatch.DiffDelete:
+				l.Kind = Delete
+				old++
+				l.OldLine = old
+			default:
+				old++
+				new++
+				l.OldLine, l.NewLine = old, new
+			}
+
+			ls = append(ls, l)
+		}
+	}
+
+	return ls
+}
+
+// hunks groups the changed lines with up to n unchanged lines around them.
+func hunks(ls []Line, n int) []Hunk {
+	var hs []Hunk
+	var old, new, last int
+	for i := 0; i < len(ls); {
+		if ls[i].Kind == Context {
+			i++
+			continue
+		}
+
+		start := i - n
+		if start < last {
+			start = last
+		}
+
+		end := i
+		for end < len(ls) {
+			if ls[end].Kind != Context {
+				end++
+				continue
+			}
+
+			j := end
+			for j < len(ls) && ls[j].Kind == Context {
+				j++
+			}
+
+			if j == len(ls) || j-end > 2*n {
+				if end += n; end > len(ls) {
+					end = len(ls)
+				}
+
+				break
+			}
+
+			end = j
+		}
+
+		o, nw := count(ls[last:start])
+		old, new = old+o, new+nw
+		h := Hunk{Lines: ls[start:end]}
+		h.OldLines, h.NewLines = count(h.Lines)
+		h.OldStart, h.NewStart = old, new
+		if h.OldLines > 0 {
+			h.OldStart++
+		}
+
+		if h.NewLines > 0 {
+			h.NewStart++
+		}
+
+		old, new = old+h.OldLines, new+h.NewLines
+		hs = append(hs, h)
+		last, i = end, end
+	}
+
+	return hs
+}
+
+// count returns the number of lines of the old and new file among ls.
+func count(ls []Line) (old, new int) {
+	for _, l := range ls {
+		if l.Kind != Insert {
+			old++
+		}
+
+		if l.Kind != Delete {
+			new++
+		}
+	}
+
+	return old, new
+}
@@ -0,0 +1,195 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// testFile returns a regular file of the content, out of any repository.
+func testFile(t *testing.T, name, content string) *git.File {
+	obj := &core.MemoryObject{}
+	obj.SetType(core.BlobObject)
+	obj.SetSize(int64(len(content)))
+	if _, err := obj.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &git.Blob{}
+	if err := b.Decode(obj); err != nil {
+		t.Fatal(err)
+	}
+
+	return git.NewFile(name, 0644, b)
+}
+
+// lettered returns the lines "1" to "n", the lines listed in changed being
+// replaced by their number followed by a star.
+func lettered(n int, changed ...int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		star := ""
+		for _, c := range changed {
+			if c == i {
+				star = "*"
+			}
+		}
+
+		fmt.Fprintf(&b, "%d%s\n", i, star)
+	}
+
+	return b.String()
+}
+
+func TestUnifiedText(t *testing.T) {
+	for _, tc := range []struct {
+		name, src, dst string
+		want           string
+	}{
+		{
+			"nearby changes merged",
+			lettered(20), lettered(20, 4, 10),
+			"--- a/f\n+++ b/f\n@@ -1,13 +1,13 @@\n 1\n 2\n 3\n-4\n+4*\n 5\n 6\n 7\n 8\n 9\n-10\n+10*\n 11\n 12\n 13\n",
+		},
+		{
+			"distant changes apart",
+			lettered(20), lettered(20, 4, 12),
+			"--- a/f\n+++ b/f\n@@ -1,7 +1,7 @@\n 1\n 2\n 3\n-4\n+4*\n 5\n 6\n 7\n@@ -9,7 +9,7 @@\n 9\n 10\n 11\n-12\n+12*\n 13\n 14\n 15\n",
+		},
+		{
+			"first line",
+			lettered(10), lettered(10, 1),
+			"--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-1\n+1*\n 2\n 3\n 4\n",
+		},
+		{
+			"insertion before the first line",
+			lettered(10), "0\n" + lettered(10),
+			"--- a/f\n+++ b/f\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n",
+		},
+		{
+			"last line",
+			lettered(10), lettered(10, 10),
+			"--- a/f\n+++ b/f\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+10*\n",
+		},
+		{
+			"deletion of the last lines",
+			lettered(10), lettered(8),
+			"--- a/f\n+++ b/f\n@@ -6,5 +6,3 @@\n 6\n 7\n 8\n-9\n-10\n",
+		},
+		{
+			"added content",
+			"", "1\n2\n",
+			"--- a/f\n+++ b/f\n@@ -0,0 +1,2 @@\n+1\n+2\n",
+		},
+		{
+			"deleted content",
+			"1\n2\n", "",
+			"--- a/f\n+++ b/f\n@@ -1,2 +0,0 @@\n-1\n-2\n",
+		},
+		{
+			"newline added at the end",
+			"1\n2\n3", "1\n2\n3\n",
+			"--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n 1\n 2\n-3\n\\ No newline at end of file\n+3\n",
+		},
+		{
+			"newline removed at the end",
+			"1\n2\n3\n", "1\n2\n3",
+			"--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n 1\n 2\n-3\n+3\n\\ No newline at end of file\n",
+		},
+		{
+			"last line without newline changed",
+			"1\n2\n3", "1\n2\n3*",
+			"--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n 1\n 2\n-3\n\\ No newline at end of file\n+3*\n\\ No newline at end of file\n",
+		},
+		{
+			"unchanged last line without newline",
+			"1\n2\n3", "1*\n2\n3",
+			"--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n-1\n+1*\n 2\n 3\n\\ No newline at end of file\n",
+		},
+		{
+			"no change",
+			lettered(3), lettered(3),
+			"",
+		},
+	} {
+		if got := UnifiedText("f", tc.src, tc.dst).String(); got != tc.want {
+			t.Errorf("%s: UnifiedText =\n%s\nwant\n%s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestUnifiedLineNumbers(t *testing.T) {
+	p := UnifiedText("f", lettered(10), "0\n"+lettered(10, 10))
+	if len(p.Hunks) != 2 {
+		t.Fatalf("UnifiedText = %d hunks, want 2", len(p.Hunks))
+	}
+
+	first, last := p.Hunks[0], p.Hunks[1]
+	if l := first.Lines[0]; l.Kind != Insert || l.OldLine != 0 || l.NewLine != 1 {
+		t.Errorf("inserted first line = %+v, want new line 1", l)
+	}
+
+	if l := first.Lines[1]; l.Kind != Context || l.OldLine != 1 || l.NewLine != 2 {
+		t.Errorf("line after the insertion = %+v, want old line 1, new line 2", l)
+	}
+
+	n := len(last.Lines)
+	if l := last.Lines[n-2]; l.Kind != Delete || l.OldLine != 10 || l.NewLine != 0 {
+		t.Errorf("deleted last line = %+v, want old line 10", l)
+	}
+
+	if l := last.Lines[n-1]; l.Kind != Insert || l.OldLine != 0 || l.NewLine != 11 {
+		t.Errorf("inserted last line = %+v, want new line 11", l)
+	}
+
+	if h := last.Header(); h != "@@ -7,4 +8,4 @@" {
+		t.Errorf("last hunk header = %s, want @@ -7,4 +8,4 @@", h)
+	}
+}
+
+func TestUnifiedContext(t *testing.T) {
+	src, dst := lettered(20), lettered(20, 4, 10)
+	for _, tc := range []struct {
+		n       int
+		headers []string
+	}{
+		{0, []string{"@@ -4 +4 @@", "@@ -10 +10 @@"}},
+		{2, []string{"@@ -2,5 +2,5 @@", "@@ -8,5 +8,5 @@"}},
+		{3, []string{"@@ -1,13 +1,13 @@"}},
+	} {
+		p, err := UnifiedContext(testFile(t, "f", src), testFile(t, "f", dst), tc.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var headers []string
+		for _, h := range p.Hunks {
+			headers = append(headers, h.Header())
+		}
+
+		if strings.Join(headers, " ") != strings.Join(tc.headers, " ") {
+			t.Errorf("UnifiedContext(%d) hunks = %q, want %q", tc.n, headers, tc.headers)
+		}
+	}
+}
+
+func TestUnifiedAddedDeleted(t *testing.T) {
+	f := testFile(t, "f", "1\n2")
+	for _, tc := range []struct {
+		a, b *git.File
+		want string
+	}{
+		{nil, f, "--- /dev/null\n+++ b/f\n@@ -0,0 +1,2 @@\n+1\n+2\n\\ No newline at end of file\n"},
+		{f, nil, "--- a/f\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-1\n-2\n\\ No newline at end of file\n"},
+		{f, f, ""},
+		{testFile(t, "f", "a\x00b"), f, "Binary files a/f and b/f differ\n"},
+	} {
+		p, err := Unified(tc.a, tc.b)
+		if err != nil || p.String() != tc.want {
+			t.Errorf("Unified = %q, %v, want %q", p, err, tc.want)
+		}
+	}
+}
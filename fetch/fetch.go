@@ -0,0 +1,116 @@
+// Package fetch fetches in memory only the references of a remote repository
+// needed to review a pull request, its base and head, and as few of their
+// commits as asked, instead of cloning the whole repository.
+package fetch
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/clients/common"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// refPrefixes are tried in order to find the remote reference of a short
+// name, as git does, so pull/12/head names refs/pull/12/head.
+var refPrefixes = []string{"refs/heads/", "refs/tags/", "refs/"}
+
+// Options describes the pull request to fetch.
+type Options struct {
+	// URL is the http, https or ssh URL of the remote repository.
+	URL string
+	// Base and Head are the references of the pull request on the remote,
+	// full or short names such as main or refs/pull/12/head. Head defaults
+	// to HEAD, the default branch.
+	Base string
+	Head string
+	// Depth is the number of commits fetched from the base and the head,
+	// 0 for their whole history. The merge base must be among them for
+	// the changes of the pull request to be known.
+	Depth int
+	// Auth authenticates to the remote, nil for anonymous fetches.
+	Auth common.AuthMethod
+}
+
+// PullRequest fetches the base and head of the pull request in a new memory
+// repository, under their full name on the remote, HEAD being detached at
+// the head. Their short names thus resolve in the repository as on the
+// remote.
+func PullRequest(o *Options) (*git.Repository, error) {
+	if o.URL == "" || o.Base == "" {
+		return nil, fmt.Errorf("fetch: the URL and the base are required")
+	}
+
+	head := o.Head
+	if head == "" {
+		head = core.HEAD.String()
+	}
+
+	r := git.NewMemoryRepository()
+	remote, err := r.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URL: o.URL})
+	if err != nil {
+		return nil, err
+	}
+
+	remote.SetAuth(o.Auth)
+	if err := remote.Connect(); err != nil {
+		return nil, fmt.Errorf("fetching %s: %s", o.URL, err)
+	}
+	defer remote.Disconnect()
+
+	baseRef, err := remoteRef(remote, o.Base)
+	if err != nil {
+		return nil, err
+	}
+
+	headRef, err := remoteRef(remote, head)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := []config.RefSpec{
+		refSpec(baseRef.Name(), baseRef.Name()),
+		refSpec(headRef.Name(), headRef.Name()),
+		refSpec(headRef.Name(), core.HEAD),
+	}
+
+	if err := remote.Fetch(&git.FetchOptions{RefSpecs: specs, Depth: o.Depth}); err != nil {
+		return nil, fmt.Errorf("fetching %s: %s", o.URL, err)
+	}
+
+	return r, nil
+}
+
+// remoteRef returns the reference of the remote with the name, the target
+// of symbolic references such as HEAD.
+func remoteRef(remote *git.Remote, name string) (*core.Reference, error) {
+	names := []string{name}
+	if !strings.HasPrefix(name, "refs/") && name != core.HEAD.String() {
+		names = nil
+		for _, prefix := range refPrefixes {
+			names = append(names, prefix+name)
+		}
+	}
+
+	for _, n := range names {
+		ref, err := remote.Ref(core.ReferenceName(n), true)
+		if err == core.ErrReferenceNotFound {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return ref, nil
+	}
+
+	return nil, fmt.Errorf("fetching %s: no reference %s on the remote", remote.Config().URL, name)
+}
+
+// refSpec fetches the remote reference src as the local reference dst.
+func refSpec(src, dst core.ReferenceName) config.RefSpec {
+	return config.RefSpec(fmt.Sprintf("+%s:%s", src, dst))
+}
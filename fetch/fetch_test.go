@@ -0,0 +1,139 @@
+package fetch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/internal/gitserver"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// remote is a repository served over HTTP to the tests.
+type remote struct {
+	t       *testing.T
+	dir     string
+	URL     string
+	commits int
+}
+
+// git runs git in the repository and returns its trimmed output.
+func (r *remote) git(args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	when := fmt.Sprintf("%d +0000", 1500000000+60*r.commits)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=A", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_AUTHOR_DATE="+when,
+		"GIT_COMMITTER_NAME=A", "GIT_COMMITTER_EMAIL=a@example.com", "GIT_COMMITTER_DATE="+when,
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+r.dir)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %q: %s\n%s", args, err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// commit commits the file with the content and returns the hash of the
+// commit.
+func (r *remote) commit(name, content string) string {
+	if err := ioutil.WriteFile(filepath.Join(r.dir, name), []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+
+	r.commits++
+	r.git("add", name)
+	r.git("commit", "-q", "-m", "Change "+name)
+	return r.git("rev-parse", "HEAD")
+}
+
+func TestPullRequest(t *testing.T) {
+	root, err := ioutil.TempDir("", "fetch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	s := gitserver.Serve(t, root)
+	defer s.Close()
+
+	r := &remote{t: t, dir: filepath.Join(root, "repo"), URL: s.URL + "/repo"}
+	if err := os.Mkdir(r.dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// base - main
+	//     \
+	//      topic1 - topic2, also refs/pull/12/head
+	r.git("init", "-q", "-b", "main")
+	base := r.commit("a", "a\n")
+	r.git("tag", "v1")
+	r.git("checkout", "-q", "-b", "topic")
+	topic1 := r.commit("b", "b\n")
+	topic2 := r.commit("b", "b2\n")
+	r.git("update-ref", "refs/pull/12/head", topic2)
+	r.git("checkout", "-q", "main")
+	main := r.commit("a", "a2\n")
+
+	for _, tc := range []struct {
+		base, head string
+		depth      int
+		// refs are the references fetched, HEAD at the head, and missing
+		// the commits not fetched.
+		refs    map[string]string
+		missing []string
+	}{
+		{"main", "topic", 0, map[string]string{"refs/heads/main": main, "refs/heads/topic": topic2, "HEAD": topic2}, nil},
+		{"refs/heads/main", "pull/12/head", 0, map[string]string{"refs/heads/main": main, "refs/pull/12/head": topic2, "HEAD": topic2}, nil},
+		{"v1", "", 0, map[string]string{"refs/tags/v1": base, "refs/heads/main": main, "HEAD": main}, []string{topic1, topic2}},
+		{"main", "topic", 1, map[string]string{"refs/heads/main": main, "refs/heads/topic": topic2, "HEAD": topic2}, []string{topic1, base}},
+	} {
+		repo, err := PullRequest(&Options{URL: r.URL, Base: tc.base, Head: tc.head, Depth: tc.depth})
+		if err != nil {
+			t.Fatalf("PullRequest(%s, %s, depth %d): %s", tc.base, tc.head, tc.depth, err)
+		}
+
+		for name, want := range tc.refs {
+			ref, err := repo.Ref(core.ReferenceName(name), true)
+			if err != nil || ref.Hash().String() != want {
+				t.Errorf("PullRequest(%s, %s, depth %d): %s = %v, %v, want %s", tc.base, tc.head, tc.depth, name, ref, err, want)
+			}
+		}
+
+		for _, h := range []string{base, topic1, topic2, main} {
+			_, err := repo.Commit(core.NewHash(h))
+			if missing := contains(tc.missing, h); (err != nil) != missing {
+				t.Errorf("PullRequest(%s, %s, depth %d): commit %s read with %v, want it missing %v", tc.base, tc.head, tc.depth, h[:7], err, missing)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		o   Options
+		err string
+	}{
+		{Options{URL: r.URL}, "the URL and the base are required"},
+		{Options{Base: "main"}, "the URL and the base are required"},
+		{Options{URL: r.URL, Base: "missing"}, "no reference missing on the remote"},
+		{Options{URL: r.URL, Base: "main", Head: "pull/13/head"}, "no reference pull/13/head on the remote"},
+		{Options{URL: s.URL + "/missing", Base: "main"}, "fetching " + s.URL + "/missing"},
+	} {
+		if _, err := PullRequest(&tc.o); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("PullRequest(%+v) = %v, want an error containing %q", tc.o, err, tc.err)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
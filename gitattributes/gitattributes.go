@@ -0,0 +1,195 @@
+// Package gitattributes reads the attributes given to paths by .gitattributes
+// files, as described in gitattributes(5).
+package gitattributes
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// Values returned by Attributes.Value for attributes without a value.
+const (
+	Set   = "true"
+	Unset = "false"
+)
+
+// rule is a line of a .gitattributes file. Attributes reset to unspecified
+// with !attr have an empty value.
+type rule struct {
+	pattern pattern
+	attrs   map[string]string
+	// line is the line number of the rule in the file and text the pattern
+	// as written.
+	line int
+	text string
+}
+
+// Attributes is a parsed .gitattributes file. The zero value gives no
+// attribute to any path.
+type Attributes struct {
+	rules []rule
+}
+
+// Parse reads a .gitattributes file. Macro definitions and quoted patterns
+// are not supported and ignored.
+func Parse(r io.Reader) (*Attributes, error) {
+	a := &Attributes{}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") ||
+			strings.HasPrefix(fields[0], "[attr]") || strings.HasPrefix(fields[0], `"`) {
+			continue
+		}
+
+		rl := rule{pattern: newPattern(fields[0]), attrs: make(map[string]string), line: n, text: fields[0]}
+		for _, attr := range fields[1:] {
+			switch {
+			case strings.HasPrefix(attr, "-"):
+				rl.attrs[attr[1:]] = Unset
+			case strings.HasPrefix(attr, "!"):
+				rl.attrs[attr[1:]] = ""
+			case strings.Contains(attr, "="):
+				i := strings.IndexByte(attr, '=')
+				rl.attrs[attr[:i]] = attr[i+1:]
+			default:
+				rl.attrs[attr] = Set
+			}
+		}
+
+		a.rules = append(a.rules, rl)
+	}
+
+	return a, s.Err()
+}
+
+// Value returns the value of the attribute for the path: Set or Unset for
+// attributes without value, an empty string if unspecified. As in git, the
+// last matching line mentioning the attribute takes precedence, so the more
+// specific patterns are expected to come last.
+func (a *Attributes) Value(name, attr string) string {
+	if a == nil {
+		return ""
+	}
+
+	for i := len(a.rules) - 1; i >= 0; i-- {
+		rl := &a.rules[i]
+		value, ok := rl.attrs[attr]
+		if ok && rl.pattern.match(name) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// Match is a line of a .gitattributes file giving a value to an attribute of
+// a path.
+type Match struct {
+	Line    int
+	Pattern string
+	Value   string
+}
+
+// Matches returns the lines giving a value to the attribute for the path, in
+// file order, the last one deciding the Value.
+func (a *Attributes) Matches(name, attr string) []Match {
+	if a == nil {
+		return nil
+	}
+
+	var ms []Match
+	for i := range a.rules {
+		rl := &a.rules[i]
+		value, ok := rl.attrs[attr]
+		if ok && rl.pattern.match(name) {
+			ms = append(ms, Match{Line: rl.line, Pattern: rl.text, Value: value})
+		}
+	}
+
+	return ms
+}
+
+// IsTrue returns true if the attribute is set, or has a true value, for the
+// path.
+func (a *Attributes) IsTrue(name, attr string) bool {
+	v := a.Value(name, attr)
+	return v == Set || v == "1"
+}
+
+// FromTree reads the .gitattributes file at the root of the tree. Empty
+// Attributes are returned if there is none.
+func FromTree(t *git.Tree) (*Attributes, error) {
+	f, err := t.File(".gitattributes")
+	if err == git.ErrFileNotFound {
+		return &Attributes{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return Parse(r)
+}
+
+// pattern is a path pattern, split in slash separated segments matched with
+// path.Match. A ** segment matches any number of directories.
+type pattern struct {
+	segments []string
+	// basename is true for the patterns without slash, matched against the
+	// base name of the paths in any directory.
+	basename bool
+}
+
+func newPattern(p string) pattern {
+	if !strings.Contains(p, "/") {
+		return pattern{segments: []string{p}, basename: true}
+	}
+
+	return pattern{segments: strings.Split(strings.TrimPrefix(p, "/"), "/")}
+}
+
+func (p pattern) match(name string) bool {
+	if p.basename {
+		ok, _ := path.Match(p.segments[0], path.Base(name))
+		return ok
+	}
+
+	return matchSegments(p.segments, strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,77 @@
+package gitattributes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testAttributes = `# generated code
+*.pb.go linguist-generated
+/gen/** linguist-generated=true
+vendor/** linguist-vendored
+vendor/own/** -linguist-vendored
+docs/*.md text eol=lf
+gen/keep.go !linguist-generated
+"quoted name" linguist-generated
+[attr]binary -diff -merge -text
+`
+
+func TestValue(t *testing.T) {
+	a, err := Parse(strings.NewReader(testAttributes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name, attr, want string
+		isTrue           bool
+	}{
+		{"api.pb.go", "linguist-generated", Set, true},
+		{"pkg/api/api.pb.go", "linguist-generated", Set, true},
+		{"api.go", "linguist-generated", "", false},
+		{"gen/a.go", "linguist-generated", "true", true},
+		{"gen/sub/b.go", "linguist-generated", "true", true},
+		{"src/gen/a.go", "linguist-generated", "", false},
+		{"gen/keep.go", "linguist-generated", "", false},
+		{"vendor/lib/a.go", "linguist-vendored", Set, true},
+		{"vendor/own/a.go", "linguist-vendored", Unset, false},
+		{"docs/a.md", "eol", "lf", false},
+		{"docs/a.md", "text", Set, true},
+		{"docs/sub/a.md", "text", "", false},
+		{"quoted name", "linguist-generated", "", false},
+	} {
+		if got := a.Value(tc.name, tc.attr); got != tc.want {
+			t.Errorf("Value(%q, %q) = %q, want %q", tc.name, tc.attr, got, tc.want)
+		}
+
+		if got := a.IsTrue(tc.name, tc.attr); got != tc.isTrue {
+			t.Errorf("IsTrue(%q, %q) = %v, want %v", tc.name, tc.attr, got, tc.isTrue)
+		}
+	}
+
+	var none *Attributes
+	if v := none.Value("api.pb.go", "linguist-generated"); v != "" {
+		t.Errorf("Value of nil attributes = %q", v)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	a, err := Parse(strings.NewReader(testAttributes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Match{
+		{Line: 4, Pattern: "vendor/**", Value: Set},
+		{Line: 5, Pattern: "vendor/own/**", Value: Unset},
+	}
+
+	if got := a.Matches("vendor/own/a.go", "linguist-vendored"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Matches() = %+v, want %+v", got, want)
+	}
+
+	if got := a.Matches("main.go", "linguist-vendored"); got != nil {
+		t.Errorf("Matches() of an unmatched path = %+v", got)
+	}
+}
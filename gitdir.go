@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/objcache"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+	"gopkg.in/src-d/go-git.v4/utils/fs"
+)
+
+// gitdirPrefix starts the content of the .git file of submodules and linked
+// worktrees.
+const gitdirPrefix = "gitdir:"
+
+// gitDir returns the git directory of the worktree at path: its .git
+// directory, or the directory named by its .git file, relative to the
+// worktree, in submodules and linked worktrees. Without a .git, path itself
+// is returned if it is a git directory, as a bare repository.
+func gitDir(path string) (string, error) {
+	dotgit := filepath.Join(path, ".git")
+	fi, err := os.Stat(dotgit)
+	if os.IsNotExist(err) && isGitDir(path) {
+		return path, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if fi.IsDir() {
+		return dotgit, nil
+	}
+
+	data, err := ioutil.ReadFile(dotgit)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, gitdirPrefix) {
+		return "", fmt.Errorf("%s: missing %s line", dotgit, gitdirPrefix)
+	}
+
+	dir := strings.TrimSpace(strings.TrimPrefix(line, gitdirPrefix))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("%s: %s", dotgit, err)
+	}
+
+	return dir, nil
+}
+
+// isGitDir returns true if dir is a git directory, holding a HEAD file and
+// the objects and refs directories, or the commondir file naming them in the
+// git directory of a linked worktree.
+func isGitDir(dir string) bool {
+	if fi, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || !fi.Mode().IsRegular() {
+		return false
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "commondir")); err == nil {
+		return true
+	}
+
+	for _, name := range []string{"objects", "refs"} {
+		if fi, err := os.Stat(filepath.Join(dir, name)); err != nil || !fi.IsDir() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// openGitDir opens the repository stored in the git directory, its objects
+// cached up to -object-cache-size bytes. The git directory of a linked
+// worktree only holds its HEAD, the objects and other references are in the
+// directory of the main worktree named by its commondir file.
+func openGitDir(dir string) (*git.Repository, error) {
+	var gitFS fs.Filesystem = fs.NewOS(dir)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "commondir"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(dir, common)
+		}
+
+		gitFS = &worktreeFS{Filesystem: fs.NewOS(common), head: gitFS}
+	}
+
+	s, err := filesystem.NewStorage(gitFS)
+	if err != nil {
+		return nil, err
+	}
+
+	if *objectCache <= 0 {
+		return git.NewRepository(s)
+	}
+
+	return git.NewRepository(objcache.New(s, *objectCache))
+}
+
+// worktreeFS is the git directory of the main worktree, with the HEAD of a
+// linked worktree.
+type worktreeFS struct {
+	fs.Filesystem
+	head fs.Filesystem
+}
+
+func (w *worktreeFS) Open(filename string) (fs.File, error) {
+	if filename == "HEAD" {
+		return w.head.Open(filename)
+	}
+
+	return w.Filesystem.Open(filename)
+}
+
+func (w *worktreeFS) Stat(filename string) (fs.FileInfo, error) {
+	if filename == "HEAD" {
+		return w.head.Stat(filename)
+	}
+
+	return w.Filesystem.Stat(filename)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// headOf opens the repository of the worktree at path and returns the hash
+// of its HEAD.
+func headOf(t *testing.T, path string) string {
+	dir, err := gitDir(path)
+	if err != nil {
+		t.Fatalf("gitDir(%s): %s", path, err)
+	}
+
+	repo, err := openGitDir(dir)
+	if err != nil {
+		t.Fatalf("openGitDir(%s): %s", dir, err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("%s: HEAD: %s", path, err)
+	}
+
+	return head.Hash().String()
+}
+
+func TestGitDirLinkedWorktree(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	mainHead := r.commit(map[string]string{"a": "a\n"}, "Add a")
+
+	r.git("worktree", "add", "-q", "-b", "topic", "wt")
+	wt := &gitRepo{t: t, dir: filepath.Join(r.dir, "wt"), commits: r.commits}
+	topic := wt.commit(map[string]string{"b": "b\n"}, "Add b")
+
+	if got := headOf(t, r.dir); got != mainHead {
+		t.Errorf("HEAD of the main worktree = %s, want %s", got, mainHead)
+	}
+
+	if got := headOf(t, wt.dir); got != topic {
+		t.Errorf("HEAD of the linked worktree = %s, want %s", got, topic)
+	}
+
+	dir, err := gitDir(wt.dir)
+	if want := filepath.Join(r.dir, ".git", "worktrees", "wt"); err != nil || dir != want {
+		t.Errorf("gitDir of the linked worktree = %s, %v, want %s", dir, err, want)
+	}
+}
+
+func TestGitDirRelative(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	head := r.commit(map[string]string{"a": "a\n"}, "Add a")
+
+	// A checkout whose .git file names the git directory relatively, as
+	// written by git submodule.
+	sub := filepath.Join(r.dir, "sub", "checkout")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(sub, ".git"), []byte("gitdir: ../../.git\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := os.Chdir(filepath.Join(r.dir, "sub")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"..", "checkout", "./checkout/", filepath.Join("..", ".git")} {
+		if got := headOf(t, path); got != head {
+			t.Errorf("HEAD of %s = %s, want %s", path, got, head)
+		}
+	}
+}
+
+func TestGitDirBare(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	head := r.commit(map[string]string{"a": "a\n"}, "Add a")
+
+	bare := filepath.Join(r.dir, "bare.git")
+	r.git("clone", "-q", "--bare", r.dir, bare)
+	if dir, err := gitDir(bare); err != nil || dir != bare {
+		t.Errorf("gitDir of a bare repository = %s, %v, want %s", dir, err, bare)
+	}
+
+	if got := headOf(t, bare); got != head {
+		t.Errorf("HEAD of the bare repository = %s, want %s", got, head)
+	}
+
+	// A directory holding only some of the files of a git directory isn't
+	// one.
+	for _, missing := range []string{"objects", "refs"} {
+		kept := "objects"
+		if missing == kept {
+			kept = "refs"
+		}
+
+		partial := filepath.Join(r.dir, "without-"+missing)
+		if err := os.MkdirAll(filepath.Join(partial, kept), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(partial, "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if dir, err := gitDir(partial); err == nil {
+			t.Errorf("gitDir of a directory without %s = %s, want an error", missing, dir)
+		}
+	}
+}
+
+func TestGitDirErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"nogitdir": "not a git file\n",
+		"missing":  "gitdir: ../nowhere\n",
+	} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(dir, name, ".git"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for path, want := range map[string]string{
+		dir:                            ".git: no such file or directory",
+		filepath.Join(dir, "nogitdir"): ".git: missing gitdir: line",
+		filepath.Join(dir, "missing"):  "nowhere: no such file or directory",
+	} {
+		if _, err := gitDir(path); err == nil || !strings.HasSuffix(err.Error(), want) {
+			t.Errorf("gitDir(%s) = %v, want an error ending with %q", path, err, want)
+		}
+	}
+}
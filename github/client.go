@@ -0,0 +1,243 @@
+// Package github is a small client of the GitHub API, retrying the requests
+// failing because of transient errors or rate limits.
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the URL of the public GitHub API.
+const DefaultBaseURL = "https://api.github.com"
+
+// Defaults of the retry settings of a Client.
+const (
+	DefaultRetries = 3
+	DefaultBackoff = time.Second
+	// maxWait caps the wait before a retry, whether computed or asked by
+	// a Retry-After header.
+	maxWait = time.Minute
+)
+
+// Client sends authenticated requests to the GitHub API. Idempotent requests
+// are retried up to Retries times on network errors, server errors and rate
+// limits, waiting Backoff, then twice as long after each attempt, or the
+// time given by the Retry-After header of the response.
+type Client struct {
+	// BaseURL is the URL of the API, DefaultBaseURL if empty.
+	BaseURL string
+	// Token authenticates the requests, they are anonymous if empty.
+	Token string
+	// HTTPClient sends the requests, http.DefaultClient if nil. Its
+	// transport can be replaced to simulate the API.
+	HTTPClient *http.Client
+	Retries    int
+	Backoff    time.Duration
+	// sleep waits between the attempts, time.Sleep if nil.
+	sleep func(time.Duration)
+}
+
+// NewClient returns a client of the public API authenticated with the token
+// and using the default retry settings.
+func NewClient(token string) *Client {
+	return &Client{
+		Token:   token,
+		Retries: DefaultRetries,
+		Backoff: DefaultBackoff,
+	}
+}
+
+// Error is a response of the API with a non successful status.
+type Error struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// Message is the message of the error returned by the API, if any.
+	Message string
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, msg)
+}
+
+// Do sends a request with the body, if not nil, to the path of the API and
+// returns the response of the first successful attempt, its body left to be
+// closed by the caller. Responses with a status other than 2xx are returned
+// as an *Error.
+func (c *Client) Do(method, path string, body []byte) (*http.Response, error) {
+	return c.do(method, path, body, idempotent(method))
+}
+
+// do sends the request as Do, retrying it only if retry is true.
+func (c *Client) do(method, path string, body []byte, retry bool) (*http.Response, error) {
+	wait := c.Backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(method, path, body)
+		last := attempt >= c.Retries || !retry
+		if err != nil {
+			if last {
+				return nil, err
+			}
+
+			c.wait(wait)
+			wait *= 2
+			continue
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		apiErr := readError(method, c.url(path), resp)
+		if last || !retryable(resp) {
+			return nil, apiErr
+		}
+
+		if after, ok := retryAfter(resp); ok {
+			c.wait(after)
+		} else {
+			c.wait(wait)
+		}
+
+		wait *= 2
+	}
+}
+
+func (c *Client) url(path string) string {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+
+	return strings.TrimSuffix(base, "/") + path
+}
+
+func (c *Client) send(method, path string, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, c.url(path), r)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.Token != "" {
+		req.Header.Set("Authorization", "token "+c.Token)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	return hc.Do(req)
+}
+
+func (c *Client) wait(d time.Duration) {
+	if d > maxWait {
+		d = maxWait
+	}
+
+	if c.sleep != nil {
+		c.sleep(d)
+		return
+	}
+
+	time.Sleep(d)
+}
+
+// idempotent returns true for the methods that can be sent again without
+// side effects. POST and PATCH requests are never retried.
+func idempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable returns true for the responses to transient errors: server
+// errors and rate limits. GitHub answers 403 to rate limited requests, other
+// 4xx responses fail for good.
+func retryable(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode >= 500:
+		return true
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusForbidden:
+		_, ok := retryAfter(resp)
+		return ok || resp.Header.Get("X-RateLimit-Remaining") == "0"
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the wait asked by the Retry-After header of the
+// response, given in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(time.Now())
+		if d < 0 {
+			d = 0
+		}
+
+		return d, true
+	}
+
+	return 0, false
+}
+
+// readError reads and closes the body of an unsuccessful response.
+func readError(method, url string, resp *http.Response) *Error {
+	defer resp.Body.Close()
+	e := &Error{
+		Method:     method,
+		URL:        url,
+		StatusCode: resp.StatusCode,
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return e
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+
+	if json.Unmarshal(data, &body) == nil {
+		e.Message = body.Message
+	}
+
+	return e
+}
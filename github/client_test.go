@@ -0,0 +1,152 @@
+package github
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testServer answers the requests with the statuses, in order, the last one
+// to the requests after them, setting the header of each response.
+type testServer struct {
+	*httptest.Server
+	statuses []int
+	header   http.Header
+	// requests counts the requests received.
+	requests int
+	bodies   []string
+}
+
+func newTestServer(header http.Header, statuses ...int) *testServer {
+	s := &testServer{statuses: statuses, header: header}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		s.bodies = append(s.bodies, string(data))
+		status := s.statuses[len(s.statuses)-1]
+		if s.requests < len(s.statuses) {
+			status = s.statuses[s.requests]
+		}
+
+		s.requests++
+		for k, v := range s.header {
+			w.Header()[k] = v
+		}
+
+		w.WriteHeader(status)
+		if status >= 300 {
+			w.Write([]byte(`{"message": "failed"}`))
+		}
+	}))
+
+	return s
+}
+
+// testClient returns a client of the server recording its waits instead of
+// sleeping.
+func testClient(s *testServer, waits *[]time.Duration) *Client {
+	c := NewClient("secret")
+	c.BaseURL = s.URL
+	c.Backoff = 10 * time.Millisecond
+	c.sleep = func(d time.Duration) {
+		*waits = append(*waits, d)
+	}
+
+	return c
+}
+
+func TestDoRetries(t *testing.T) {
+	ms := time.Millisecond
+	for _, tc := range []struct {
+		name     string
+		method   string
+		header   http.Header
+		statuses []int
+		requests int
+		waits    []time.Duration
+		status   int
+	}{
+		{"success", "GET", nil, []int{200}, 1, nil, 0},
+		{"503 then 200", "GET", nil, []int{503, 200}, 2, []time.Duration{10 * ms}, 0},
+		{"backoff", "GET", nil, []int{502, 500, 503, 204}, 4, []time.Duration{10 * ms, 20 * ms, 40 * ms}, 0},
+		{"retry cap", "GET", nil, []int{503}, 4, []time.Duration{10 * ms, 20 * ms, 40 * ms}, 503},
+		{"not found", "GET", nil, []int{404, 200}, 1, nil, 404},
+		{"forbidden", "GET", nil, []int{403, 200}, 1, nil, 403},
+		{"rate limit", "GET", http.Header{"X-Ratelimit-Remaining": {"0"}}, []int{403, 200}, 2, []time.Duration{10 * ms}, 0},
+		{"retry after", "PUT", http.Header{"Retry-After": {"3"}}, []int{429, 403, 200}, 3, []time.Duration{3 * time.Second, 3 * time.Second}, 0},
+		{"retry after capped", "GET", http.Header{"Retry-After": {"3600"}}, []int{429, 200}, 2, []time.Duration{maxWait}, 0},
+		{"post", "POST", nil, []int{503, 200}, 1, nil, 503},
+	} {
+		s := newTestServer(tc.header, tc.statuses...)
+		var waits []time.Duration
+		resp, err := testClient(s, &waits).Do(tc.method, "/repos/o/r", []byte("{}"))
+		s.Close()
+		if tc.status == 0 && err != nil {
+			t.Errorf("%s: Do = %v", tc.name, err)
+		}
+
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		if apiErr, ok := err.(*Error); tc.status != 0 && (!ok || apiErr.StatusCode != tc.status || apiErr.Message != "failed") {
+			t.Errorf("%s: Do = %v, want a %d error", tc.name, err, tc.status)
+		}
+
+		if s.requests != tc.requests {
+			t.Errorf("%s: %d requests, want %d", tc.name, s.requests, tc.requests)
+		}
+
+		if !reflect.DeepEqual(waits, tc.waits) {
+			t.Errorf("%s: waited %v, want %v", tc.name, waits, tc.waits)
+		}
+	}
+}
+
+func TestDoNetworkError(t *testing.T) {
+	s := newTestServer(nil, 200)
+	s.Close()
+	var waits []time.Duration
+	c := testClient(s, &waits)
+	c.Retries = 2
+	if _, err := c.Do("GET", "/", nil); err == nil {
+		t.Error("Do of a closed server succeeded")
+	}
+
+	if want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}; !reflect.DeepEqual(waits, want) {
+		t.Errorf("waited %v, want %v", waits, want)
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	var auth, path string
+	s := newTestServer(nil, 503, 201)
+	handler := s.Config.Handler
+	s.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, path = r.Header.Get("Authorization"), r.Method+" "+r.URL.Path
+		handler.ServeHTTP(w, r)
+	})
+	defer s.Close()
+
+	var waits []time.Duration
+	err := testClient(s, &waits).SetStatus("o/r", "abc", Status{State: StatusFailure, Description: "2 errors", Context: "bot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.requests != 2 {
+		t.Errorf("%d requests, want the POST retried once", s.requests)
+	}
+
+	if auth != "token secret" || path != "POST /repos/o/r/statuses/abc" {
+		t.Errorf("request %s authorized by %q", path, auth)
+	}
+
+	want := `{"state":"failure","description":"2 errors","context":"bot"}`
+	if len(s.bodies) != 2 || strings.TrimSpace(s.bodies[1]) != want {
+		t.Errorf("bodies %q, want %s", s.bodies, want)
+	}
+}
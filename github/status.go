@@ -0,0 +1,38 @@
+package github
+
+import "encoding/json"
+
+// States of a commit status.
+const (
+	StatusPending = "pending"
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+	StatusError   = "error"
+)
+
+// Status is the status of a commit, shown next to it and in the pull
+// requests whose head it is. A status replaces the one of the same Context.
+type Status struct {
+	State string `json:"state"`
+	// TargetURL links to the details of the status, if not empty.
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context,omitempty"`
+}
+
+// SetStatus sets the status of the commit sha of the repository, named
+// OWNER/NAME. Since the status replaces the previous one of its context, the
+// request is retried as idempotent ones are even though it's a POST.
+func (c *Client) SetStatus(repo, sha string, s Status) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do("POST", "/repos/"+repo+"/statuses/"+sha, body, true)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/github"
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+// statusContext is the context of the commit statuses set by -github-status.
+const statusContext = "code-review-bot"
+
+// setStatus sets the status of the reviewed commit on the GitHub repository,
+// OWNER/NAME: failure when the violations fail the review, success otherwise,
+// described by the number of violations.
+func setStatus(c *github.Client, repo, commit string, vs []report.Violation) error {
+	if commit == "" {
+		return errors.New("no reviewed commit to set the status of")
+	}
+
+	s := github.Status{State: github.StatusSuccess, Context: statusContext}
+	if report.Failing(vs) {
+		s.State = github.StatusFailure
+	}
+
+	errs := 0
+	for _, v := range vs {
+		if v.Severity >= report.Error {
+			errs++
+		}
+	}
+
+	switch {
+	case len(vs) == 0:
+		s.Description = "no violations"
+	case errs == 0:
+		s.Description = fmt.Sprintf("%s violations, none failing", thousands(len(vs)))
+	default:
+		s.Description = fmt.Sprintf("%s violations, %s errors", thousands(len(vs)), thousands(errs))
+	}
+
+	return c.SetStatus(repo, commit, s)
+}
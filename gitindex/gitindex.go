@@ -0,0 +1,249 @@
+// Package gitindex reads the git index, the staging area holding the content
+// of the next commit, as described in gitformat-index(5).
+package gitindex
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// signature starts every index file.
+var signature = []byte("DIRC")
+
+// headerSize is the size of the header of the index, its signature, version
+// and number of entries.
+const headerSize = 12
+
+// Flags of the index entries.
+const (
+	nameMask     = 0x0fff
+	stageShift   = 12
+	extendedFlag = 0x4000
+	intentToAdd  = 0x2000
+)
+
+// Modes of the index entries that aren't regular files.
+const (
+	symlinkMode = 0120000
+	gitlinkMode = 0160000
+)
+
+// ErrInvalid is returned for files that aren't a git index.
+var ErrInvalid = errors.New("not a git index")
+
+// Entry is a path of the index. Entries with a Stage above 0 are the sides
+// of an unresolved merge conflict.
+type Entry struct {
+	Name string
+	// Mode is the mode of the entry as decoded from trees: the octal git
+	// mode, with os.ModeSymlink added for symbolic links.
+	Mode os.FileMode
+	Hash core.Hash
+	// Stage is 0 for merged entries, 1 to 3 for the base, ours and theirs
+	// versions of a conflicted path.
+	Stage int
+	// IntentToAdd is set for the paths added with git add -N, whose content
+	// isn't staged yet.
+	IntentToAdd bool
+}
+
+// IsSubmodule returns true if the entry is a submodule, its Hash being a
+// commit of another repository.
+func (e *Entry) IsSubmodule() bool {
+	return uint32(e.Mode)&0170000 == gitlinkMode
+}
+
+// Index is a parsed git index, its entries sorted by name then stage.
+type Index struct {
+	Version int
+	Entries []Entry
+}
+
+// Load reads the index file at path.
+func Load(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads an index of version 2, 3 or 4, verifying the SHA-1 checksum
+// ending it unless it is zero, as written with index.skipHash. The
+// extensions following the entries are ignored.
+func Parse(r io.Reader) (*Index, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		Signature [4]byte
+		Version   uint32
+		Count     uint32
+	}
+
+	if err := binary.Read(bytes.NewReader(data), binary.BigEndian, &header); err != nil {
+		return nil, ErrInvalid
+	}
+
+	if !bytes.Equal(header.Signature[:], signature) {
+		return nil, ErrInvalid
+	}
+
+	if header.Version < 2 || header.Version > 4 {
+		return nil, fmt.Errorf("unsupported index version %d", header.Version)
+	}
+
+	if len(data) < headerSize+sha1.Size {
+		return nil, errors.New("index truncated before its checksum")
+	}
+
+	body, sum := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	if !bytes.Equal(sum, make([]byte, sha1.Size)) {
+		if computed := sha1.Sum(body); !bytes.Equal(sum, computed[:]) {
+			return nil, fmt.Errorf("index checksum mismatch: %x, computed %x", sum, computed)
+		}
+	}
+
+	// Every entry takes at least entrySize bytes, the count can't be
+	// trusted further.
+	entries := body[headerSize:]
+	if uint64(header.Count) > uint64(len(entries))/entrySize {
+		return nil, fmt.Errorf("index of %d bytes can't hold %d entries", len(data), header.Count)
+	}
+
+	br := bufio.NewReader(bytes.NewReader(entries))
+	idx := &Index{Version: int(header.Version)}
+	var prev string
+	for i := uint32(0); i < header.Count; i++ {
+		e, err := readEntry(br, idx.Version, prev)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("index truncated at entry %d of %d", i+1, header.Count)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		idx.Entries = append(idx.Entries, e)
+		prev = e.Name
+	}
+
+	return idx, nil
+}
+
+// entryFields are the fixed size fields of an entry, the stat data used by
+// git to detect changes being ignored.
+type entryFields struct {
+	Stat  [6]uint32
+	Mode  uint32
+	Stat2 [3]uint32
+	Hash  core.Hash
+	Flags uint16
+}
+
+// entrySize is the size of entryFields.
+const entrySize = 62
+
+// readEntry reads an entry. In version 4, names are compressed by removing
+// a prefix from the previous name, prev, and entries aren't padded.
+func readEntry(r *bufio.Reader, version int, prev string) (Entry, error) {
+	var f entryFields
+	if err := binary.Read(r, binary.BigEndian, &f); err != nil {
+		return Entry{}, err
+	}
+
+	e := Entry{Hash: f.Hash, Stage: int(f.Flags>>stageShift) & 3}
+	size := entrySize
+	if f.Flags&extendedFlag != 0 {
+		if version < 3 {
+			return Entry{}, errors.New("extended entry flags in an index of version 2")
+		}
+
+		var extended uint16
+		if err := binary.Read(r, binary.BigEndian, &extended); err != nil {
+			return Entry{}, err
+		}
+
+		e.IntentToAdd = extended&intentToAdd != 0
+		size += 2
+	}
+
+	e.Mode = os.FileMode(f.Mode)
+	if f.Mode&0170000 == symlinkMode {
+		e.Mode |= os.ModeSymlink
+	}
+
+	if version == 4 {
+		strip, err := readOffset(r)
+		if err != nil {
+			return Entry{}, err
+		}
+
+		if strip > len(prev) {
+			return Entry{}, fmt.Errorf("entry after %q strips %d bytes of its name", prev, strip)
+		}
+
+		suffix, err := r.ReadString(0)
+		if err != nil {
+			return Entry{}, err
+		}
+
+		e.Name = prev[:len(prev)-strip] + suffix[:len(suffix)-1]
+		return e, nil
+	}
+
+	name, err := r.ReadString(0)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e.Name = name[:len(name)-1]
+	if n := int(f.Flags & nameMask); n < nameMask && n != len(e.Name) {
+		return Entry{}, fmt.Errorf("entry %q has a name length of %d", e.Name, n)
+	}
+
+	// The entry is padded with 1 to 8 NULs to a multiple of 8 bytes, the
+	// NUL ending the name included.
+	size += len(name)
+	if pad := (8 - size%8) % 8; pad > 0 {
+		if _, err := r.Discard(pad); err != nil {
+			return Entry{}, err
+		}
+	}
+
+	return e, nil
+}
+
+// readOffset reads the variable length integer of version 4 names, each
+// byte holding 7 bits, the high bit set if another byte follows, with 1
+// added to the value before each continuation.
+func readOffset(r *bufio.Reader) (int, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	n := int(c & 0x7f)
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+
+		n = ((n + 1) << 7) | int(c&0x7f)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,193 @@
+package gitindex
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// encode returns an index of the version holding the entries, followed by
+// the extension data, and ending with its checksum, zero if skipHash.
+func encode(version int, entries []Entry, ext []byte, skipHash bool) []byte {
+	var buf bytes.Buffer
+	buf.Write(signature)
+	binary.Write(&buf, binary.BigEndian, uint32(version))
+	binary.Write(&buf, binary.BigEndian, uint32(len(entries)))
+	prev := ""
+	for _, e := range entries {
+		f := entryFields{Mode: uint32(e.Mode & 0777777), Hash: e.Hash}
+		if e.Mode&os.ModeSymlink != 0 {
+			f.Mode = symlinkMode
+		}
+
+		f.Flags = uint16(e.Stage<<stageShift) | uint16(len(e.Name))
+		if e.IntentToAdd {
+			f.Flags |= extendedFlag
+		}
+
+		start := buf.Len()
+		binary.Write(&buf, binary.BigEndian, f)
+		if e.IntentToAdd {
+			binary.Write(&buf, binary.BigEndian, uint16(intentToAdd))
+		}
+
+		if version == 4 {
+			common := 0
+			for common < len(prev) && common < len(e.Name) && prev[common] == e.Name[common] {
+				common++
+			}
+
+			buf.Write(encodeOffset(len(prev) - common))
+			buf.WriteString(e.Name[common:])
+			buf.WriteByte(0)
+			prev = e.Name
+			continue
+		}
+
+		buf.WriteString(e.Name)
+		buf.WriteByte(0)
+		for (buf.Len()-start)%8 != 0 {
+			buf.WriteByte(0)
+		}
+	}
+
+	buf.Write(ext)
+	sum := sha1.Sum(buf.Bytes())
+	if skipHash {
+		sum = [sha1.Size]byte{}
+	}
+
+	buf.Write(sum[:])
+	return buf.Bytes()
+}
+
+// encodeOffset encodes n as read by readOffset.
+func encodeOffset(n int) []byte {
+	b := []byte{byte(n & 0x7f)}
+	for n >>= 7; n > 0; n >>= 7 {
+		n--
+		b = append([]byte{0x80 | byte(n&0x7f)}, b...)
+	}
+
+	return b
+}
+
+func hash(s string) core.Hash {
+	return core.ComputeHash(core.BlobObject, []byte(s))
+}
+
+var testEntries = []Entry{
+	{Name: ".gitignore", Mode: 0100644, Hash: hash("a")},
+	{Name: "cmd/bot/main.go", Mode: 0100755, Hash: hash("b")},
+	{Name: "cmd/bot/main_test.go", Mode: 0100644, Hash: hash("c")},
+	{Name: "conflict.go", Mode: 0100644, Hash: hash("d"), Stage: 1},
+	{Name: "conflict.go", Mode: 0100644, Hash: hash("e"), Stage: 2},
+	{Name: "conflict.go", Mode: 0100644, Hash: hash("f"), Stage: 3},
+	{Name: "link", Mode: 0120000 | os.ModeSymlink, Hash: hash("g")},
+	{Name: strings.Repeat("long/", 40) + "name", Mode: 0100644, Hash: hash("h")},
+	{Name: "vendor/lib", Mode: 0160000, Hash: hash("i")},
+}
+
+func TestParse(t *testing.T) {
+	added := Entry{Name: "new.go", Mode: 0100644, Hash: hash(""), IntentToAdd: true}
+	for _, tc := range []struct {
+		version  int
+		entries  []Entry
+		ext      []byte
+		skipHash bool
+	}{
+		{version: 2, entries: testEntries},
+		{version: 3, entries: append(append([]Entry{}, testEntries...), added)},
+		{version: 4, entries: append(append([]Entry{}, testEntries...), added)},
+		{version: 2, entries: testEntries, ext: []byte("TREE\x00\x00\x00\x02ab")},
+		{version: 4, entries: testEntries, skipHash: true},
+		{version: 2},
+	} {
+		idx, err := Parse(bytes.NewReader(encode(tc.version, tc.entries, tc.ext, tc.skipHash)))
+		if err != nil {
+			t.Errorf("version %d: %s", tc.version, err)
+			continue
+		}
+
+		if idx.Version != tc.version {
+			t.Errorf("Version = %d, want %d", idx.Version, tc.version)
+		}
+
+		if !reflect.DeepEqual(idx.Entries, tc.entries) {
+			t.Errorf("version %d: Entries = %+v, want %+v", tc.version, idx.Entries, tc.entries)
+		}
+	}
+
+	idx, err := Parse(bytes.NewReader(encode(2, testEntries, nil, false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := idx.Entries[len(idx.Entries)-1]; !e.IsSubmodule() {
+		t.Errorf("%s isn't a submodule", e.Name)
+	}
+
+	if e := idx.Entries[0]; e.IsSubmodule() {
+		t.Errorf("%s is a submodule", e.Name)
+	}
+}
+
+func TestReadOffset(t *testing.T) {
+	for _, n := range []int{0, 1, 127, 128, 255, 16511, 16512, 1 << 20} {
+		b := encodeOffset(n)
+		got, err := readOffset(bufio.NewReader(bytes.NewReader(b)))
+		if err != nil || got != n {
+			t.Errorf("readOffset(%x) = %d, %v, want %d", b, got, err, n)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	valid := encode(2, testEntries, nil, false)
+	badSum := append([]byte{}, valid...)
+	badSum[len(badSum)-1] ^= 1
+	corrupt := append([]byte{}, valid...)
+	corrupt[headerSize+entrySize] ^= 1
+	huge := encode(2, testEntries, nil, true)
+	binary.BigEndian.PutUint32(huge[8:], 1<<31)
+	more := encode(2, testEntries, nil, true)
+	binary.BigEndian.PutUint32(more[8:], uint32(len(testEntries)+1))
+	extended := encode(3, []Entry{{Name: "a", Mode: 0100644, IntentToAdd: true}}, nil, true)
+	binary.BigEndian.PutUint32(extended[4:], 2)
+	length := encode(2, []Entry{{Name: "a", Mode: 0100644}}, nil, true)
+	length[headerSize+entrySize-1] = 2
+	strip := encode(4, []Entry{{Name: "a", Mode: 0100644}}, nil, true)
+	strip[headerSize+entrySize] = 5
+	for _, tc := range []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"empty", nil, ErrInvalid.Error()},
+		{"short header", valid[:8], ErrInvalid.Error()},
+		{"signature", append([]byte("DIRX"), valid[4:]...), ErrInvalid.Error()},
+		{"version 1", encode(1, nil, nil, false), "unsupported index version 1"},
+		{"version 5", encode(5, nil, nil, false), "unsupported index version 5"},
+		{"no checksum", valid[:headerSize+sha1.Size-1], "index truncated before its checksum"},
+		{"checksum", badSum, "index checksum mismatch"},
+		{"corrupt entry", corrupt, "index checksum mismatch"},
+		{"truncated", valid[:len(valid)-10], "index checksum mismatch"},
+		{"huge count", huge, "can't hold 2147483648 entries"},
+		{"missing entry", more, "index truncated at entry 10 of 10"},
+		{"extended version 2", extended, "extended entry flags in an index of version 2"},
+		{"name length", length, "has a name length of 2"},
+		{"strip", strip, "strips 5 bytes"},
+	} {
+		_, err := Parse(bytes.NewReader(tc.data))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: Parse = %v, want an error containing %q", tc.name, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Package gitserver serves the repositories of a local directory over the
+// smart HTTP protocol, with git http-backend, for the tests fetching and
+// cloning remote repositories.
+package gitserver
+
+import (
+	"io/ioutil"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Serve serves the repositories under root, each one at the URL of the
+// server followed by its path relative to root. The test is skipped if git
+// isn't installed.
+func Serve(t *testing.T, root string) *httptest.Server {
+	out, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	backend := filepath.Join(strings.TrimSpace(string(out)), "git-http-backend")
+	if _, err := os.Stat(backend); err != nil {
+		t.Skip("git http-backend not installed")
+	}
+
+	return httptest.NewServer(&cgi.Handler{
+		Path:   backend,
+		Env:    []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+		Stderr: ioutil.Discard,
+	})
+}
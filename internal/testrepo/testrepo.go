@@ -0,0 +1,186 @@
+// Package testrepo writes the objects of repositories in memory for the
+// tests of the other packages, failing the test on any error.
+package testrepo
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// DefaultAuthor is the author and committer of the commits of a Repo
+// without Author.
+const DefaultAuthor = "A <a@example.com>"
+
+// Repo is a repository in memory whose objects are written by a test.
+type Repo struct {
+	t       *testing.T
+	Storage *memory.Storage
+	*git.Repository
+	// Author is the "Name <email>" of the author and committer of the
+	// commits written, DefaultAuthor if empty.
+	Author string
+	// commits counts the commits written, dating each one a minute after
+	// the previous one.
+	commits int
+}
+
+// New returns an empty repository.
+func New(t *testing.T) *Repo {
+	s := memory.NewStorage()
+	r, err := git.NewRepository(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Repo{t: t, Storage: s, Repository: r}
+}
+
+// Write writes an object of the type and returns its hash.
+func (r *Repo) Write(t core.ObjectType, data []byte) core.Hash {
+	obj := &core.MemoryObject{}
+	obj.SetType(t)
+	obj.SetSize(int64(len(data)))
+	obj.Write(data)
+	h, err := r.Storage.ObjectStorage().Set(obj)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return h
+}
+
+// Blob writes a blob of the content and returns its hash.
+func (r *Repo) Blob(content string) core.Hash {
+	return r.Write(core.BlobObject, []byte(content))
+}
+
+// Entry is an entry of a tree written by RawTree.
+type Entry struct {
+	Mode, Name string
+	Hash       core.Hash
+}
+
+// RawTree writes a tree of the entries in the order given, which lets the
+// tests write trees git wouldn't, unsorted or with duplicate names.
+func (r *Repo) RawTree(entries ...Entry) core.Hash {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "%s %s\x00", e.Mode, e.Name)
+		buf.Write(e.Hash[:])
+	}
+
+	return r.Write(core.TreeObject, buf.Bytes())
+}
+
+// WriteTree writes the tree of the files, mapping slash separated paths to
+// contents, and returns its hash. A path may be prefixed with its octal
+// mode and a space, such as "100755 run.sh", 100644 being the default. The
+// content of a symbolic link, mode 120000, is its target and the one of a
+// submodule, mode 160000, the hash of its commit.
+func (r *Repo) WriteTree(files map[string]string) core.Hash {
+	// byKey maps the sort keys of the entries, git sorting directories as
+	// if their name ended with a slash, to their mode and name.
+	byKey := make(map[string]string)
+	hashes := make(map[string]core.Hash)
+	subdirs := make(map[string]map[string]string)
+	for name, content := range files {
+		mode := "100644"
+		if i := strings.IndexByte(name, ' '); i > 0 && strings.Trim(name[:i], "01234567") == "" {
+			mode, name = name[:i], name[i+1:]
+		}
+
+		if i := strings.IndexByte(name, '/'); i >= 0 {
+			if subdirs[name[:i]] == nil {
+				subdirs[name[:i]] = make(map[string]string)
+			}
+
+			sub := name[i+1:]
+			if mode != "100644" {
+				sub = mode + " " + sub
+			}
+
+			subdirs[name[:i]][sub] = content
+			continue
+		}
+
+		byKey[name] = mode + " " + name
+		hashes[name] = core.NewHash(content)
+		if mode != "160000" {
+			hashes[name] = r.Blob(content)
+		}
+	}
+
+	for name, sub := range subdirs {
+		byKey[name+"/"] = "40000 " + name
+		hashes[name+"/"] = r.WriteTree(sub)
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for _, key := range keys {
+		h := hashes[key]
+		fmt.Fprintf(&buf, "%s\x00", byKey[key])
+		buf.Write(h[:])
+	}
+
+	return r.Write(core.TreeObject, buf.Bytes())
+}
+
+// WriteCommit writes a commit of the tree with the message, taken as is,
+// and the parents, and returns its hash.
+func (r *Repo) WriteCommit(tree core.Hash, msg string, parents ...core.Hash) core.Hash {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "tree %s\n", tree)
+	for _, p := range parents {
+		fmt.Fprintf(&buf, "parent %s\n", p)
+	}
+
+	r.commits++
+	when := 1500000000 + 60*r.commits
+	author := r.Author
+	if author == "" {
+		author = DefaultAuthor
+	}
+
+	fmt.Fprintf(&buf, "author %s %d +0000\n", author, when)
+	fmt.Fprintf(&buf, "committer %s %d +0000\n\n%s", author, when, msg)
+	return r.Write(core.CommitObject, buf.Bytes())
+}
+
+// CommitFiles writes a commit of the tree of the files, as WriteTree, and
+// returns it.
+func (r *Repo) CommitFiles(files map[string]string, msg string, parents ...core.Hash) *git.Commit {
+	c, err := r.Commit(r.WriteCommit(r.WriteTree(files), msg, parents...))
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return c
+}
+
+// SetRef points the reference name to the hash.
+func (r *Repo) SetRef(name string, h core.Hash) {
+	if err := r.Storage.ReferenceStorage().Set(core.NewHashReference(core.ReferenceName(name), h)); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// SetHead points HEAD to the branch.
+func (r *Repo) SetHead(branch string) {
+	ref := core.NewSymbolicReference(core.HEAD, core.ReferenceName("refs/heads/"+branch))
+	if err := r.Storage.ReferenceStorage().Set(ref); err != nil {
+		r.t.Fatal(err)
+	}
+}
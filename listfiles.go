@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gunjan5/code-review-bot/review"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// listFiles writes the sorted paths of the files of the reviewed tree, one
+// per line.
+func listFiles(w io.Writer, repo *git.Repository, o *review.Options) error {
+	c, err := review.ResolveCommit(repo, o.Ref)
+	if err != nil {
+		return err
+	}
+
+	t, err := c.Tree()
+	if err != nil {
+		return err
+	}
+
+	paths, err := t.Paths()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		if _, err := fmt.Fprintln(w, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
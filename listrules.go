@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/gunjan5/code-review-bot/rules"
+)
+
+// listRules writes the id, default severity and description of every
+// registered rule.
+func listRules(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "RULE\tSEVERITY\tDESCRIPTION")
+	for _, r := range rules.All() {
+		m := r.Meta()
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", m.ID, m.Severity, m.Description)
+	}
+
+	return tw.Flush()
+}
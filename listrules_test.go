@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/rules"
+)
+
+func TestListRules(t *testing.T) {
+	var buf bytes.Buffer
+	if err := listRules(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	all := rules.All()
+	if len(lines) != len(all)+1 {
+		t.Fatalf("listRules printed %d lines, want a header and %d rules:\n%s", len(lines), len(all), buf.String())
+	}
+
+	if f := strings.Fields(lines[0]); strings.Join(f, " ") != "RULE SEVERITY DESCRIPTION" {
+		t.Errorf("header = %q", lines[0])
+	}
+
+	for i, r := range all {
+		m := r.Meta()
+		if m.Description == "" {
+			t.Errorf("rule %s has no description", m.ID)
+		}
+
+		f := strings.Fields(lines[i+1])
+		if len(f) < 3 || f[0] != m.ID || f[1] != m.Severity.String() ||
+			strings.Join(f[2:], " ") != strings.Join(strings.Fields(m.Description), " ") {
+			t.Errorf("line %d = %q, want rule %s, %s, %q", i+1, lines[i+1], m.ID, m.Severity, m.Description)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package mailmap maps the names and emails used in commits to canonical
+// identities, as described in gitmailmap(5).
+package mailmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// entry is a line of a mailmap file. Empty proper fields are left unchanged
+// and an empty commit name matches any name.
+type entry struct {
+	properName, properEmail string
+	commitName, commitEmail string
+}
+
+// Mailmap is a parsed mailmap file. The zero value maps nothing.
+type Mailmap struct {
+	entries []entry
+}
+
+// Parse reads a mailmap file. Supported lines are:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+//
+// Blank lines and everything after a # are ignored.
+func Parse(r io.Reader) (*Mailmap, error) {
+	m := &Mailmap{}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		e, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("mailmap line %d: %s", n, err)
+		}
+
+		m.entries = append(m.entries, e)
+	}
+
+	return m, s.Err()
+}
+
+func parseLine(line string) (entry, error) {
+	var names, emails []string
+	for {
+		open := strings.IndexByte(line, '<')
+		if open < 0 {
+			break
+		}
+
+		close := strings.IndexByte(line[open:], '>')
+		if close < 0 {
+			return entry{}, fmt.Errorf("unterminated email in %q", line)
+		}
+
+		names = append(names, strings.TrimSpace(line[:open]))
+		emails = append(emails, strings.TrimSpace(line[open+1:open+close]))
+		line = line[open+close+1:]
+	}
+
+	if strings.TrimSpace(line) != "" {
+		return entry{}, fmt.Errorf("unexpected text %q after the last email", strings.TrimSpace(line))
+	}
+
+	switch len(emails) {
+	case 1:
+		if names[0] == "" {
+			return entry{}, fmt.Errorf("a single email needs a proper name")
+		}
+
+		return entry{properName: names[0], commitEmail: emails[0]}, nil
+	case 2:
+		return entry{
+			properName:  names[0],
+			properEmail: emails[0],
+			commitName:  names[1],
+			commitEmail: emails[1],
+		}, nil
+	default:
+		return entry{}, fmt.Errorf("expected one or two emails, found %d", len(emails))
+	}
+}
+
+// Map returns the canonical name and email of an identity. Entries matching
+// both the commit name and email take precedence over those matching only
+// the email. Emails and names are compared without case.
+func (m *Mailmap) Map(name, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+
+	var match *entry
+	for i := range m.entries {
+		e := &m.entries[i]
+		if !strings.EqualFold(e.commitEmail, email) {
+			continue
+		}
+
+		if e.commitName != "" {
+			if strings.EqualFold(e.commitName, name) {
+				match = e
+				break
+			}
+
+			continue
+		}
+
+		if match == nil {
+			match = e
+		}
+	}
+
+	if match == nil {
+		return name, email
+	}
+
+	if match.properName != "" {
+		name = match.properName
+	}
+
+	if match.properEmail != "" {
+		email = match.properEmail
+	}
+
+	return name, email
+}
+
+// Normalize replaces the author and committer of the commit by their
+// canonical identities.
+func (m *Mailmap) Normalize(c *git.Commit) {
+	c.Author.Name, c.Author.Email = m.Map(c.Author.Name, c.Author.Email)
+	c.Committer.Name, c.Committer.Email = m.Map(c.Committer.Name, c.Committer.Email)
+}
+
+// FromTree reads the .mailmap file at the root of the tree. An empty Mailmap
+// is returned if there is none.
+func FromTree(t *git.Tree) (*Mailmap, error) {
+	f, err := t.File(".mailmap")
+	if err == git.ErrFileNotFound {
+		return &Mailmap{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return Parse(r)
+}
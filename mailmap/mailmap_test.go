@@ -0,0 +1,62 @@
+package mailmap
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMailmap = `# Jane changed her email and used a nickname
+Jane Doe <jane@example.com> <jane@old.example>
+Jane Doe <jane@example.com> jdoe <jane@example.com>
+
+<ops@example.com> <root@build.example>
+Build Bot <bot@example.com>
+Other <other@example.com> Someone <shared@example.com>
+`
+
+func TestMap(t *testing.T) {
+	m, err := Parse(strings.NewReader(testMailmap))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name, email         string
+		wantName, wantEmail string
+	}{
+		{"Jane", "jane@old.example", "Jane Doe", "jane@example.com"},
+		{"jdoe", "jane@example.com", "Jane Doe", "jane@example.com"},
+		{"JDOE", "Jane@Example.com", "Jane Doe", "jane@example.com"},
+		{"Jane Doe", "jane@example.com", "Jane Doe", "jane@example.com"},
+		{"root", "root@build.example", "root", "ops@example.com"},
+		{"bot", "bot@example.com", "Build Bot", "bot@example.com"},
+		{"Someone", "shared@example.com", "Other", "other@example.com"},
+		{"Someone Else", "shared@example.com", "Someone Else", "shared@example.com"},
+		{"Bob", "bob@example.com", "Bob", "bob@example.com"},
+	} {
+		name, email := m.Map(tc.name, tc.email)
+		if name != tc.wantName || email != tc.wantEmail {
+			t.Errorf("Map(%q, %q) = %q, %q, want %q, %q", tc.name, tc.email, name, email, tc.wantName, tc.wantEmail)
+		}
+	}
+
+	var zero *Mailmap
+	if name, email := zero.Map("Bob", "bob@example.com"); name != "Bob" || email != "bob@example.com" {
+		t.Errorf("Map of a nil mailmap = %q, %q", name, email)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		content, want string
+	}{
+		{"Jane <jane@example.com", `mailmap line 1: unterminated email in "Jane <jane@example.com"`},
+		{"\n<jane@example.com>", "mailmap line 2: a single email needs a proper name"},
+		{"Jane <a@example.com> <b@example.com> <c@example.com>", "mailmap line 1: expected one or two emails, found 3"},
+		{"Jane <jane@example.com> trailing", `mailmap line 1: unexpected text "trailing" after the last email`},
+	} {
+		if _, err := Parse(strings.NewReader(tc.content)); err == nil || err.Error() != tc.want {
+			t.Errorf("Parse(%q) = %v, want %s", tc.content, err, tc.want)
+		}
+	}
+}
@@ -1,37 +1,592 @@
 package main
 
 import (
-    "fmt"
-    "io"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
-    "gopkg.in/src-d/go-git.v4"
-    "gopkg.in/src-d/go-git.v4/utils/fs"
+	"github.com/gunjan5/code-review-bot/config"
+	"github.com/gunjan5/code-review-bot/github"
+	"github.com/gunjan5/code-review-bot/gitindex"
+	"github.com/gunjan5/code-review-bot/objcache"
+	"github.com/gunjan5/code-review-bot/patch"
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/review"
+	"github.com/gunjan5/code-review-bot/rules"
+	"github.com/gunjan5/code-review-bot/rules/commitmsg"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// Exit codes returned by the bot.
+const (
+	exitOK         = 0
+	exitViolations = 1
+	exitError      = 2
+	// exitEmpty is returned when every reviewed repository has no commits.
+	exitEmpty = 3
+	// exitEmptyRange is returned with -fail-on-empty-range when no commit
+	// was reviewed.
+	exitEmptyRange = 4
+)
+
+var (
+	repoPaths       commaList
+	serveAllow      stringList
+	serveAddr       = flag.String("serve", "", "serve the review API on this address instead of reviewing -repo")
+	listRulesFlag   = flag.Bool("list-rules", false, "list the available rules and exit")
+	checkConfigFlag = flag.Bool("check-config", false, "check the options, their patterns and the revisions to review, list the problems found and exit")
+	manifest        = flag.String("manifest", "", "write the files of the reviewed tree in this format, json, and exit")
+	listFilesFlag   = flag.Bool("list-files", false, "list the paths of the files of the reviewed tree and exit")
+	explainPath     = flag.String("explain", "", "explain why the file at this path is checked or skipped and exit")
+	topFiles        = flag.Int("top-files", 0, "list the N largest files of the reviewed tree and exit")
+	authorStats     = flag.Bool("authorstats", false, "print commits, files touched and lines changed per author and exit")
+	format          = flag.String("format", "text", "output format of the violations, text, json or junit")
+	rangeFlag       = flag.String("range", "", "review only the commits of this range, BASE..HEAD for those reachable from HEAD but not from BASE, BASE...HEAD for the commits and files changed since their merge base")
+	fromFlag        = flag.String("from", "", "review the commits of a release since this tag or revision, already released, as -range FROM..TO")
+	toFlag          = flag.String("to", "", "with -from, tag or revision of the reviewed release (default HEAD)")
+	failOnEmpty     = flag.Bool("fail-on-empty-range", false, "exit with 4 when no commit is reviewed, e.g. when -since-commit is the reviewed commit")
+	summaryJSON     = flag.String("summary-json", "", "write a JSON summary of the run to this file, - for stderr")
+	changedFiles    = flag.String("changed-files", "", "file listing the paths to check, one per line, instead of every file of the tree")
+	colorFlag       = flag.String("color", "auto", "color the text output, auto for terminals unless NO_COLOR is set, always or never")
+	noColor         = flag.Bool("no-color", false, "never color the text output, as -color never")
+	abbrev          = flag.Int("abbrev", report.DefaultAbbrev, "number of characters of the commit hashes shown in the text output, 0 for the fewest keeping them unique")
+	indexFlag       = flag.Bool("index", false, "review the files staged in the index that differ from -ref instead of the tree, as a pre-commit hook")
+	patchFlag       = flag.String("patch", "", "review the commits of this mailbox or git format-patch file, - for stdin, instead of a repository")
+	worktreeFlag    = flag.Bool("worktree", false, "review the tracked files of the working tree that differ from -ref, staged or not, instead of the tree")
+	maxViolations   = flag.Int("max-violations", 0, "maximum number of violations shown in the text output, 0 for all, the exit status still accounting for all")
+	groupByFile     = flag.Bool("group-by-file", false, "group the violations by file in the output")
+	baselinePath    = flag.String("baseline", "", "file with the fingerprints of accepted violations")
+	writeBaseline   = flag.String("write-baseline", "", "write the violations found to this baseline file and exit")
+	githubToken     = flag.String("github-token", "", "token used to authenticate to the GitHub API and to clone from github.com")
+	githubStatus    = flag.String("github-status", "", "OWNER/NAME, set the "+statusContext+" status of the reviewed commit on this GitHub repository, failure when the review fails")
+	githubAPI       = flag.String("github-api-url", github.DefaultBaseURL, "URL of the GitHub API, such as https://github.example.com/api/v3 for GitHub Enterprise")
+	cloneDepth      = flag.Int("clone-depth", 0, "when -repo is a URL, fetch only this number of commits of each branch, 0 for the whole history; with -base-branch only the base branch and -ref are fetched")
+	objectCache     = flag.Int64("object-cache-size", objcache.DefaultSize, "size in bytes of the cache of the objects read from -repo paths, 0 disables it")
+	configPath      = flag.String("config", "", "configuration file, its keys are the names of these flags")
+	cacheDir        = flag.String("cache-dir", "", "directory caching the violations of the files between runs")
 )
 
 func main() {
-    fs := fs.NewOS()
-    path := ".git"
-
-    repo, err := git.NewRepositoryFromFS(fs, path)
-    if err != nil {
-        panic(err)
-    }
-
-    iter, err := repo.Commits()
-    if err != nil {
-        panic(err)
-    }
-    defer iter.Close()
-
-    for {
-        commit, err := iter.Next()
-        if err != nil {
-            if err == io.EOF {
-                break
-            }
-            panic(err)
-        }
-
-        fmt.Println(commit)
-    }
+	o := &review.Options{}
+	flag.Var(&serveAllow, "serve-allow", "glob of the repository paths and URLs reviewed by -serve, such as /srv/checkouts/* or https://github.com/org/**, can be repeated (default any path or URL)")
+	flag.Var(&repoPaths, "repo", "path or http, https or ssh URL of a repository to review, can be repeated or comma separated, URLs being cloned in memory (default .)")
+	flag.StringVar(&o.Ref, "ref", "", "reference or commit to review (default HEAD)")
+	flag.StringVar(&o.Commit, "commit", "", "review only this commit, a hash or a revision such as HEAD~2")
+	flag.StringVar(&o.SinceCommit, "since-commit", "", "review only the commits after this revision, already reviewed")
+	flag.StringVar(&o.DiffAgainst, "diff-against", rules.FirstParent, "trees the reviewed commits are compared to for their changed files: first-parent, all-parents or HEAD~N")
+	flag.StringVar(&o.BaseBranch, "base-branch", "", "review only the commits and files changed since the merge base with this branch")
+	flag.StringVar(&o.Merges, "merges", review.MergesAll, "how merge commits of the reviewed history are handled: all, skip to skip them or first-parent to follow only their first parent")
+	flag.StringVar(&o.Order, "order", review.OrderDate, "order of the reviewed commits and of their violations: date for the latest committed first, author-date for the latest authored first, or topo to keep the commits of a branch together, children always coming before their parents")
+	flag.StringVar(&o.Author, "author", "", "review only the commits whose author, normalized with .mailmap, matches this regular expression")
+	flag.StringVar(&o.Committer, "committer", "", "review only the commits whose committer, normalized with .mailmap, matches this regular expression")
+	flag.Var((*stringList)(&o.ExcludedAuthors), "exclude-author", "skip the commits whose author, normalized with .mailmap, has this name, email or \"Name <email>\", ignoring case, such as dependabot[bot], can be repeated")
+	flag.Var((*stringList)(&o.Paths), "path", "glob of the files to review, or of a directory whose files are reviewed, can be repeated, the commits being reviewed only if they change such a file (default all files)")
+	flag.Var((*stringList)(&o.ExcludedPaths), "exclude", "glob of the files to leave out of the review, or of a directory whose files are left out, can be repeated, commits changing only such files not being reviewed")
+	flag.Int64Var(&o.MaxFileSize, "max-file-size", 0, "maximum file size in bytes, 0 disables the check")
+	flag.BoolVar(&o.FlagEmptyFiles, "flag-empty-files", false, "report empty files not matching -empty-file-allow")
+	flag.Var((*stringList)(&o.EmptyFilesAllowed), "empty-file-allow", "glob of the files allowed to be empty with -flag-empty-files, can be repeated (default "+strings.Join(rules.DefaultEmptyFilesAllowed, ",")+")")
+	flag.IntVar(&o.MaxSubjectLength, "max-subject-length", 72, "maximum commit subject length, 0 disables the check")
+	flag.BoolVar(&o.ImperativeSubject, "imperative-subject", false, "report commit subjects not starting with a verb in the imperative mood")
+	flag.BoolVar(&o.ConventionalCommits, "conventional-commits", false, "report commit subjects not following Conventional Commits")
+	flag.Var((*commaList)(&o.ConventionalTypes), "conventional-types", "type accepted by -conventional-commits, can be repeated or comma separated (default "+strings.Join(commitmsg.DefaultTypes, ",")+")")
+	flag.BoolVar(&o.ConventionalRequireScope, "conventional-require-scope", false, "require a scope in the Conventional Commit prefixes, as in fix(parser): ")
+	flag.IntVar(&o.MaxBodyLineLength, "max-body-line-length", 0, "maximum length of the lines of the commit message bodies, 0 disables the check")
+	flag.BoolVar(&o.RequireTicket, "require-ticket", false, "report commit messages without a ticket reference matching -ticket-pattern")
+	flag.StringVar(&o.TicketPattern, "ticket-pattern", "", "regular expression of the ticket references (default "+rules.DefaultTicketPattern+")")
+	flag.BoolVar(&o.TicketExemptMerges, "ticket-exempt-merges", false, "don't require a ticket reference in merge commits")
+	flag.Var((*stringList)(&o.SensitivePaths), "sensitive-path", "glob of the files whose changes by the reviewed commits are pointed out, can be repeated")
+	flag.BoolVar(&o.FlagSubmodules, "flag-submodule-changes", false, "report the submodules added, removed or moved to another commit by the reviewed commits")
+	flag.IntVar(&o.MaxPathLength, "max-path-length", 255, "maximum file path length in bytes, 0 disables the check")
+	flag.IntVar(&o.MaxPathComponentLength, "max-path-component-length", 100, "maximum file or directory name length in bytes, 0 disables the check")
+	flag.BoolVar(&o.RequireUTF8, "require-utf8", false, "report text files that aren't valid UTF-8")
+	flag.BoolVar(&o.ASCIIFilenames, "ascii-filenames", false, "report file and directory names with characters outside printable ASCII")
+	flag.BoolVar(&o.VerifyObjects, "verify-objects", false, "report malformed objects of the reviewed tree, such as trees with duplicate entry names")
+	flag.BoolVar(&o.CaseCollisions, "case-collisions", false, "report paths of a directory differing only by case")
+	flag.Var((*stringList)(&o.Plugins), "plugin", "path of an external check run on every file, can be repeated")
+	flag.BoolVar(&o.IgnoreRepoConfig, "ignore-repo-config", false, "ignore the "+config.RepoFile+" of the reviewed repositories")
+	flag.Var((*commaList)(&o.DisabledRules), "disable-rule", "id of a rule not run, can be repeated or comma separated")
+	flag.Var((*pathExcludes)(&o.Exclude), "exclude-path", "RULE=GLOB, skip the paths matching GLOB for the file rule RULE, can be repeated")
+	flag.IntVar(&o.MaxBlankLines, "max-consecutive-blank-lines", 0, "report the runs of this number of consecutive blank lines or more in text files, 0 disables the check")
+	flag.Var((*stringList)(&o.BlankLinesPaths), "blank-lines-path", "glob of the files checked by -max-consecutive-blank-lines, can be repeated (default all text files)")
+	flag.BoolVar(&o.NoTrailingWhitespace, "no-trailing-whitespace", false, "report lines of text files ending with spaces or tabs")
+	flag.Var((*stringList)(&o.TrailingWhitespacePaths), "trailing-whitespace-path", "glob of the files checked by -no-trailing-whitespace, can be repeated (default all text files)")
+	flag.BoolVar(&o.ChangedLineWhitespace, "changed-line-whitespace", false, "report trailing whitespace, spaces before tabs and missing newlines at the end of the files in the lines added by the reviewed commits")
+	flag.StringVar(&o.LicenseHeader, "require-license-header", "", "report text files without this header in their first lines")
+	flag.BoolVar(&o.LicenseHeaderRegexp, "license-header-regexp", false, "match -require-license-header as a regular expression")
+	flag.IntVar(&o.LicenseHeaderLines, "license-header-lines", rules.DefaultLicenseHeaderLines, "number of lines searched for -require-license-header")
+	flag.Var((*stringList)(&o.LicenseHeaderPaths), "license-header-path", "glob of the files checked by -require-license-header, can be repeated (default all text files)")
+	flag.StringVar(&o.LicenseTemplate, "license-template", "", "report text files added by the reviewed commits not starting with this header, "+rules.LicenseYear+" standing for the year of the commit")
+	flag.BoolVar(&o.CheckBOM, "check-bom", false, "report files starting with a UTF-8 or UTF-16 byte order mark")
+	flag.BoolVar(&o.CheckExecBit, "check-exec-bit", false, "report executable files matching -non-executable")
+	flag.Var((*stringList)(&o.NonExecutable), "non-executable", "glob of the files that must not be executable with -check-exec-bit, can be repeated (default "+strings.Join(rules.DefaultNonExecutable, ",")+")")
+	flag.Int64Var(&o.MaxReadSize, "max-read-size", 0, "number of bytes of a file read by the rules scanning its lines or checking its encoding, the rest of larger files being ignored, 0 reads whole files")
+	flag.BoolVar(&o.CheckSymlinks, "check-symlinks", false, "report symbolic links pointing outside the repository or to a file matching -sensitive-path")
+	flag.BoolVar(&o.DetectSecrets, "detect-secrets", false, "report API keys, private keys and other credentials in the lines added by the reviewed commits")
+	flag.Var((*stringList)(&o.SecretsIgnored), "secrets-ignore", "glob of the files not scanned by -detect-secrets, can be repeated (default "+strings.Join(rules.DefaultSecretsIgnored, ",")+")")
+	flag.BoolVar(&o.ForbidFiles, "forbid-files", false, "report the files matching -forbidden-file added by the reviewed commits")
+	flag.Var((*stringList)(&o.ForbiddenFiles), "forbidden-file", "glob of the files the reviewed commits can't add with -forbid-files, can be repeated (default "+strings.Join(rules.DefaultForbiddenFiles, ",")+")")
+	flag.BoolVar(&o.CheckGofmt, "check-gofmt", false, "report the Go files changed by the reviewed commits that gofmt would change, with the diff")
+	flag.BoolVar(&o.GofmtFix, "gofmt-fix", false, "add the formatted content of the files to the -check-gofmt violations, in the fix field of the JSON output")
+	flag.BoolVar(&o.GoVet, "go-vet", false, "run go vet on the Go packages changed by the reviewed commits and report its diagnostics on the added lines")
+	flag.Var((*commaList)(&o.GoVetAnalyzers), "go-vet-analyzer", "go vet analyzer run by -go-vet, such as printf, can be repeated or comma separated (default the go vet suite)")
+	flag.IntVar(&o.MaxFileLines, "max-file-lines", 0, "maximum number of lines of the files grown by the reviewed commits, 0 disables the check")
+	flag.IntVar(&o.MaxFuncLines, "max-func-lines", 0, "maximum number of lines of the Go functions changed by the reviewed commits, 0 disables the check")
+	flag.IntVar(&o.MaxFuncStatements, "max-func-statements", 0, "maximum number of statements of the Go functions changed by the reviewed commits, 0 disables the check")
+	flag.IntVar(&o.MaxComplexity, "max-complexity", 0, "maximum cyclomatic complexity of the Go functions changed by the reviewed commits, 0 disables the check")
+	flag.Int64Var(&o.MaxAddedFileSize, "max-added-file-size", 0, "maximum size in bytes of the files added by the reviewed commits, 0 disables the check")
+	flag.BoolVar(&o.FlagBinaryAdditions, "flag-binary-additions", false, "report the binary files added by the reviewed commits")
+	flag.BoolVar(&o.FlagTodos, "flag-todos", false, "report the TODO, FIXME and HACK comments added by the reviewed commits")
+	flag.BoolVar(&o.TodoRequireIssue, "todo-require-issue", false, "report the TODO, FIXME and HACK comments added by the reviewed commits without an issue reference like TODO(#123)")
+	flag.StringVar(&o.SigningKeyring, "signing-keyring", "", "report commits not signed by a key of this keyring, as exported by gpg --export, verified with gpgv")
+	flag.BoolVar(&o.ShebangExec, "shebang-exec", false, "with -check-exec-bit, also report files starting with a shebang that aren't executable")
+	flag.Var((*severities)(&o.Severity), "severity", "RULE=SEVERITY, report the violations of RULE as error, warning or info, can be repeated")
+	flag.Var((*docURLs)(&o.DocURLs), "doc-url", "RULE=URL, documentation page shown with the violations of RULE, a built-in or plugin rule, can be repeated")
+	flag.BoolVar(&o.IncludeGenerated, "include-generated", false, "review the files marked linguist-generated or linguist-vendored in .gitattributes")
+	flag.Var(dirEntryLimits{&o.Options}, "max-dir-entries", "maximum number of direct entries of a directory, or GLOB=N for the directories matching GLOB, can be repeated")
+	flag.Var((*indentStyles)(&o.Indent), "indent", "GLOB=spaces or GLOB=tabs, indentation expected in the files matching GLOB, can be repeated")
+	flag.Var((*sortedFiles)(&o.Sorted), "enforce-sorted", "GLOB or GLOB=case-insensitive, report the files matching GLOB whose lines aren't sorted, can be repeated")
+	flag.IntVar(&o.Jobs, "jobs", runtime.NumCPU(), "number of files checked concurrently")
+	flag.BoolVar(&o.Snippets, "snippets", false, "show the lines around each violation")
+	flag.IntVar(&o.ContextLines, "context-lines", review.DefaultContextLines, "number of lines shown before and after a violation with -snippets")
+	flag.Parse()
+	if err := resolveOptions(flag.CommandLine, os.Getenv); err != nil {
+		os.Exit(fail(err))
+	}
+
+	if err := applyRelease(o); err != nil {
+		os.Exit(fail(err))
+	}
+
+	if err := applyRange(o); err != nil {
+		os.Exit(fail(err))
+	}
+
+	os.Exit(run(o))
+}
+
+func run(o *review.Options) int {
+	if *listRulesFlag {
+		if err := listRules(os.Stdout); err != nil {
+			return fail(err)
+		}
+
+		return exitOK
+	}
+
+	if *checkConfigFlag {
+		return checkConfig(os.Stdout, o)
+	}
+
+	if errs := checkFlags(o); len(errs) > 0 {
+		return fail(errs)
+	}
+
+	baseline, err := loadBaseline()
+	if err != nil {
+		return fail(err)
+	}
+
+	if *serveAddr != "" {
+		if err := serve(*serveAddr, o, baseline); err != nil {
+			return fail(err)
+		}
+
+		return exitOK
+	}
+
+	if *changedFiles != "" {
+		if o.ChangedFiles, err = loadChangedFiles(*changedFiles); err != nil {
+			return fail(err)
+		}
+	}
+
+	if len(repoPaths) == 0 && *patchFlag == "" {
+		repoPaths = commaList{"."}
+	}
+
+	// Nothing is checked until every repository and every revision of the
+	// options are known to be fine. Repositories without commits, as
+	// before the first commit in CI, are skipped, unless their index or
+	// working tree is reviewed.
+	var repos []*git.Repository
+	var paths []string
+	var series *patch.Series
+	if *patchFlag != "" {
+		if series, err = openPatch(*patchFlag, o); err != nil {
+			return fail(err)
+		}
+
+		repos, paths = append(repos, series.Repo), append(paths, *patchFlag)
+	}
+
+	for _, path := range repoPaths {
+		repo, err := openRepo(path, *cloneDepth, o)
+		if err == review.ErrEmptyRepository && !*indexFlag && !*worktreeFlag {
+			fmt.Fprintf(os.Stderr, "code-review-bot: %s: %s\n", path, err)
+			continue
+		}
+
+		if err != nil && err != review.ErrEmptyRepository {
+			return fail(err)
+		}
+
+		repos = append(repos, repo)
+		paths = append(paths, path)
+	}
+
+	if len(repos) == 0 {
+		return exitEmpty
+	}
+
+	if *manifest != "" {
+		if err := writeManifest(os.Stdout, repos, paths, o); err != nil {
+			return fail(err)
+		}
+
+		return exitOK
+	}
+
+	if *listFilesFlag || *topFiles > 0 || *authorStats || *explainPath != "" {
+		for i, repo := range repos {
+			if err := listRepo(os.Stdout, paths[i], repo, o); err != nil {
+				return fail(err)
+			}
+		}
+
+		return exitOK
+	}
+
+	if *cacheDir != "" {
+		if o.Cache, err = review.OpenCache(*cacheDir, o); err != nil {
+			return fail(fmt.Errorf("cache: %s", err))
+		}
+	}
+
+	start := time.Now()
+	total := &review.Result{}
+	collected := &report.Collector{}
+	for i, repo := range repos {
+		var res *review.Result
+		if *indexFlag || *worktreeFlag {
+			res, err = reviewIndex(paths[i], repo, o)
+		} else {
+			res, err = review.Run(repo, o)
+		}
+
+		if err != nil {
+			return fail(fmt.Errorf("%s: %s", paths[i], err))
+		}
+
+		if series != nil {
+			patchResult(series, res)
+		}
+
+		addResult(total, collected, res, paths[i], len(repoPaths) > 1)
+	}
+
+	total.Violations = collected.Violations()
+
+	if o.Cache != nil {
+		if err := o.Cache.Save(); err != nil {
+			return fail(fmt.Errorf("cache: %s", err))
+		}
+	}
+
+	if *writeBaseline != "" {
+		if err := report.WriteBaseline(*writeBaseline, total.Violations); err != nil {
+			return fail(err)
+		}
+
+		fmt.Fprintf(os.Stderr, "baseline with %d violations written to %s\n", len(total.Violations), *writeBaseline)
+		return exitOK
+	}
+
+	for _, w := range total.Warnings {
+		fmt.Fprintln(os.Stderr, "code-review-bot: warning:", w)
+	}
+
+	vs := baseline.Filter(total.Violations)
+	abbrev, err := abbrevWidth(repos, paths, vs)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := writeReport(os.Stdout, total, vs, abbrev); err != nil {
+		return fail(err)
+	}
+
+	if *summaryJSON != "" {
+		s := newSummary(o, total, vs, time.Since(start))
+		if err := writeSummary(*summaryJSON, s); err != nil {
+			return fail(err)
+		}
+	}
+
+	if *githubStatus != "" {
+		c := github.NewClient(*githubToken)
+		c.BaseURL = *githubAPI
+		if err := setStatus(c, *githubStatus, total.Commit, vs); err != nil {
+			return fail(fmt.Errorf("-github-status: %s", err))
+		}
+	}
+
+	if *failOnEmpty && total.Commits == 0 {
+		fmt.Fprintln(os.Stderr, "code-review-bot: no commit reviewed, the range is empty")
+		return exitEmptyRange
+	}
+
+	if report.Failing(vs) {
+		return exitViolations
+	}
+
+	return exitOK
+}
+
+// addResult adds the result of the review of the repository at path to the
+// total, its violations to the collector. When several repositories are
+// reviewed, the violations and warnings are attributed to their repository
+// and the reviewed commit isn't kept.
+func addResult(total *review.Result, c *report.Collector, res *review.Result, path string, several bool) {
+	if several {
+		for i := range res.Violations {
+			res.Violations[i].Repo = path
+		}
+
+		for i, w := range res.Warnings {
+			res.Warnings[i] = path + ": " + w
+		}
+
+		for i, p := range res.Paths {
+			res.Paths[i] = filepath.ToSlash(filepath.Join(path, p))
+		}
+	} else {
+		total.Commit = res.Commit
+	}
+
+	c.Add(res.Violations...)
+	total.Warnings = append(total.Warnings, res.Warnings...)
+	total.Commits += res.Commits
+	total.Paths = append(total.Paths, res.Paths...)
+}
+
+// listRepo writes the files, the largest files, the author statistics or the
+// explanation of -explain for the repository at path, headed by the path
+// when several repositories are reviewed.
+func listRepo(w io.Writer, path string, repo *git.Repository, o *review.Options) error {
+	if len(repoPaths) > 1 {
+		fmt.Fprintf(w, "%s:\n", path)
+	}
+
+	if *listFilesFlag {
+		return listFiles(w, repo, o)
+	}
+
+	if *topFiles > 0 {
+		return writeTopFiles(w, repo, *topFiles, o)
+	}
+
+	if *explainPath != "" {
+		return review.Explain(w, repo, *explainPath, o)
+	}
+
+	return writeAuthorStats(w, repo, o)
+}
+
+// writeReport writes the violations in the configured format, the JUnit
+// report listing also the files of the result checked without violations.
+// The text output abbreviates the commit hashes to abbrev characters and
+// shows at most -max-violations violations, followed by the number of those
+// left out.
+func writeReport(w io.Writer, res *review.Result, vs []report.Violation, abbrev int) error {
+	switch *format {
+	case "json":
+		return report.WriteJSON(w, vs)
+	case "junit":
+		return report.WriteJUnit(w, res.Paths, vs)
+	}
+
+	shown := vs
+	if *maxViolations > 0 && len(vs) > *maxViolations {
+		shown = vs[:*maxViolations]
+	}
+
+	style := report.TextStyle{Color: useColor(w), Abbrev: abbrev}
+	var err error
+	if *groupByFile {
+		err = report.WriteGrouped(w, shown, style)
+	} else {
+		err = report.WriteText(w, shown, style)
+	}
+
+	if err != nil || len(shown) == len(vs) {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "... and %s more violations (increase -max-violations to see them)\n", thousands(len(vs)-len(shown)))
+	return err
+}
+
+// thousands formats n with commas separating the groups of three digits.
+func thousands(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return s
+}
+
+// abbrevWidth returns the number of characters of the commit hashes shown in
+// the text output, -abbrev or, if 0, the smallest number keeping the hashes
+// of the violations unique in their repository.
+func abbrevWidth(repos []*git.Repository, paths []string, vs []report.Violation) (int, error) {
+	if *abbrev > 0 || *format != "text" {
+		return *abbrev, nil
+	}
+
+	width := 0
+	for i, repo := range repos {
+		var hashes []string
+		for _, v := range vs {
+			if v.Commit != "" && (len(repos) == 1 || v.Repo == paths[i]) {
+				hashes = append(hashes, v.Commit)
+			}
+		}
+
+		if len(hashes) == 0 {
+			continue
+		}
+
+		n, err := review.UniqueAbbrev(repo, hashes)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %s", paths[i], err)
+		}
+
+		if n > width {
+			width = n
+		}
+	}
+
+	return width, nil
+}
+
+// useColor returns true if the text output written to w must be colored:
+// with -color always, or with -color auto when w is a terminal and colors
+// weren't disabled by -no-color or the NO_COLOR environment variable.
+func useColor(w io.Writer) bool {
+	if *noColor || *colorFlag == "never" {
+		return false
+	}
+
+	if *colorFlag == "always" {
+		return true
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// reviewIndex reviews the files staged in the index of the repository at
+// path, or with -worktree its tracked files as they are on disk.
+func reviewIndex(path string, repo *git.Repository, o *review.Options) (*review.Result, error) {
+	dir, err := gitDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if *worktreeFlag && filepath.Clean(dir) == filepath.Clean(path) {
+		return nil, errors.New("-worktree can't review a bare repository, which has no working tree")
+	}
+
+	idx, err := gitindex.Load(filepath.Join(dir, "index"))
+	if os.IsNotExist(err) {
+		return &review.Result{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("index: %s", err)
+	}
+
+	if *worktreeFlag {
+		return review.RunWorktree(repo, idx, path, o)
+	}
+
+	return review.RunIndex(repo, idx, o)
+}
+
+// openRepo opens the repository at path, or fetches it if it is a URL, see
+// openPath, and validates the options against it. The repository is
+// returned along with review.ErrEmptyRepository if it has no commits.
+func openRepo(path string, depth int, o *review.Options) (*git.Repository, error) {
+	repo, err := openPath(path, depth, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := review.Validate(repo, o); err == review.ErrEmptyRepository {
+		return repo, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// loadChangedFiles reads the list of paths of -changed-files, ignoring blank
+// lines and leading ./ as printed by some tools.
+func loadChangedFiles(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, line := range strings.Split(string(data), "\n") {
+		name := strings.TrimPrefix(strings.TrimSpace(line), "./")
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// loadBaseline loads the -baseline file, an empty baseline accepting nothing
+// is returned when it isn't set.
+func loadBaseline() (report.Baseline, error) {
+	if *baselinePath == "" || *writeBaseline != "" {
+		return report.Baseline{}, nil
+	}
+
+	return report.LoadBaseline(*baselinePath)
+}
+
+func fail(err error) int {
+	if errs, ok := err.(optionErrors); ok {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, "code-review-bot:", err)
+		}
+
+		return exitError
+	}
+
+	fmt.Fprintln(os.Stderr, "code-review-bot:", err)
+	return exitError
 }
@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/review"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// reviewStaged returns the paths of the files reviewed by reviewIndex in
+// the repository, and those with trailing whitespace.
+func reviewStaged(t *testing.T, r *gitRepo, worktree bool) (paths, violations []string) {
+	defer func(saved bool) { *worktreeFlag = saved }(*worktreeFlag)
+	*worktreeFlag = worktree
+
+	o := &review.Options{}
+	o.NoTrailingWhitespace = true
+	repo, err := openRepo(r.dir, 0, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := reviewIndex(r.dir, repo, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations = []string{}
+	for _, v := range res.Violations {
+		if v.Rule == "trailing-whitespace" {
+			violations = append(violations, v.Path)
+		}
+	}
+
+	sort.Strings(res.Paths)
+	sort.Strings(violations)
+	return res.Paths, violations
+}
+
+func TestReviewIndexStagedContent(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	r.commit(map[string]string{"clean": "a\n", "dirty": "a\n", "old": "a \n"}, "Add the files")
+
+	// The staged clean is fixed in the working tree, the staged dirty
+	// broken there, and old, unchanged, keeps its trailing space.
+	r.write(map[string]string{"clean": "b \n", "dirty": "b\n"})
+	r.git("add", "clean", "dirty")
+	r.write(map[string]string{"clean": "b\n", "dirty": "b \n"})
+
+	paths, violations := reviewStaged(t, r, false)
+	if want := []string{"clean", "dirty"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("index: reviewed %q, want %q", paths, want)
+	}
+
+	if want := []string{"clean"}; !reflect.DeepEqual(violations, want) {
+		t.Errorf("index: trailing whitespace in %q, want the staged %q", violations, want)
+	}
+
+	paths, violations = reviewStaged(t, r, true)
+	if want := []string{"clean", "dirty"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("worktree: reviewed %q, want %q", paths, want)
+	}
+
+	if want := []string{"dirty"}; !reflect.DeepEqual(violations, want) {
+		t.Errorf("worktree: trailing whitespace in %q, want the working tree %q", violations, want)
+	}
+}
+
+func TestLoadChangedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "changed.txt")
+	if err := ioutil.WriteFile(path, []byte("main.go\n\n./cmd/run.go\r\n  docs/a b.md  \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := loadChangedFiles(path)
+	if want := []string{"main.go", "cmd/run.go", "docs/a b.md"}; err != nil || !reflect.DeepEqual(names, want) {
+		t.Errorf("loadChangedFiles() = %q, %v, want %q", names, err, want)
+	}
+
+	if _, err := loadChangedFiles(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Errorf("loadChangedFiles of a missing file succeeded")
+	}
+}
+
+func TestAddResult(t *testing.T) {
+	results := func() []*review.Result {
+		return []*review.Result{
+			{
+				Commit:     "0be77e079c17b4c95ddf68762338071427f66360",
+				Violations: []report.Violation{{Rule: "r", Path: "a.go", Line: 1, Message: "m"}},
+				Warnings:   []string{"changed file x is not in the reviewed tree"},
+				Commits:    2,
+				Paths:      []string{"a.go", "cmd/b.go"},
+			},
+			{
+				Commit:     "1ce3c1b9f84f2e4c9f4bf9a48b9d7e2bcac5a3fb",
+				Violations: []report.Violation{{Rule: "r", Commit: "1ce3c1b9f84f2e4c9f4bf9a48b9d7e2bcac5a3fb", Message: "m"}},
+				Commits:    1,
+				Paths:      []string{"c.go"},
+			},
+		}
+	}
+
+	total, c := &review.Result{}, &report.Collector{}
+	addResult(total, c, results()[0], ".", false)
+	if total.Commit != "0be77e079c17b4c95ddf68762338071427f66360" || total.Commits != 2 ||
+		!reflect.DeepEqual(total.Paths, []string{"a.go", "cmd/b.go"}) || c.Violations()[0].Repo != "" {
+		t.Errorf("single repository: %+v, %+v", total, c.Violations())
+	}
+
+	total, c = &review.Result{}, &report.Collector{}
+	for i, res := range results() {
+		addResult(total, c, res, []string{"api", "../web"}[i], true)
+	}
+
+	if total.Commit != "" || total.Commits != 3 {
+		t.Errorf("several repositories: commit %q, %d commits, want none and 3", total.Commit, total.Commits)
+	}
+
+	if want := []string{"api/a.go", "api/cmd/b.go", "../web/c.go"}; !reflect.DeepEqual(total.Paths, want) {
+		t.Errorf("several repositories: paths %q, want %q", total.Paths, want)
+	}
+
+	if want := []string{"api: changed file x is not in the reviewed tree"}; !reflect.DeepEqual(total.Warnings, want) {
+		t.Errorf("several repositories: warnings %q, want %q", total.Warnings, want)
+	}
+
+	var where []string
+	for _, v := range c.Violations() {
+		where = append(where, v.Location())
+	}
+
+	if want := []string{"api/a.go:1", "../web@1ce3c1b"}; !reflect.DeepEqual(where, want) {
+		t.Errorf("several repositories: violations at %q, want %q", where, want)
+	}
+}
+
+func TestEmptyRepository(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+
+	repo, err := openRepo(r.dir, 0, &review.Options{})
+	if repo == nil || err != review.ErrEmptyRepository {
+		t.Fatalf("openRepo of an empty repository = %v, %v, want the repository and %s", repo, err, review.ErrEmptyRepository)
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+
+	defer func(saved commaList, stderr *os.File) {
+		repoPaths, os.Stderr = saved, stderr
+	}(repoPaths, os.Stderr)
+	os.Stderr = devNull
+
+	repoPaths = commaList{r.dir}
+	if code := run(&review.Options{}); code != exitEmpty {
+		t.Errorf("run() of an empty repository = %d, want %d", code, exitEmpty)
+	}
+
+	r.commit(map[string]string{"a": "a\n"}, "Add a")
+	if _, err := openRepo(r.dir, 0, &review.Options{}); err != nil {
+		t.Errorf("openRepo after the first commit: %s", err)
+	}
+}
+
+func TestWriteReportAbbrev(t *testing.T) {
+	defer func(f string, a int) { *format, *abbrev = f, a }(*format, *abbrev)
+	const hash = "0be77e079c17b4c95ddf68762338071427f66360"
+	vs := []report.Violation{{Rule: "subject-length", Severity: report.Warning, Commit: hash, Message: "subject too long"}}
+	for _, tc := range []struct {
+		format string
+		abbrev int
+		want   string
+	}{
+		{"text", 7, "0be77e0: warning: subject too long [subject-length]\n"},
+		{"text", 12, "0be77e079c17: warning: subject too long [subject-length]\n"},
+		{"text", 0, "0be77e0: warning: subject too long [subject-length]\n"},
+		{"json", 7, `"commit": "` + hash + `"`},
+	} {
+		*format = tc.format
+		var buf bytes.Buffer
+		if err := writeReport(&buf, &review.Result{}, vs, tc.abbrev); err != nil {
+			t.Fatal(err)
+		}
+
+		if out := buf.String(); !strings.Contains(out, tc.want) {
+			t.Errorf("writeReport in %s abbreviated to %d = %q, want %q", tc.format, tc.abbrev, out, tc.want)
+		}
+	}
+
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	head := r.commit(map[string]string{"a": "a\n"}, "Add a")
+	repo, err := openRepo(r.dir, 0, &review.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs = []report.Violation{{Commit: head, Message: "m"}}
+	for _, tc := range []struct {
+		format string
+		abbrev int
+		want   int
+	}{
+		{"text", 0, 4},
+		{"text", 9, 9},
+		{"json", 0, 0},
+	} {
+		*format, *abbrev = tc.format, tc.abbrev
+		if n, err := abbrevWidth([]*git.Repository{repo}, []string{r.dir}, vs); err != nil || n != tc.want {
+			t.Errorf("abbrevWidth in %s with -abbrev %d = %d, %v, want %d", tc.format, tc.abbrev, n, err, tc.want)
+		}
+	}
+}
+
+func TestThousands(t *testing.T) {
+	for _, tc := range []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{7, "7"},
+		{999, "999"},
+		{1000, "1,000"},
+		{12345, "12,345"},
+		{999999, "999,999"},
+		{1234567, "1,234,567"},
+	} {
+		if got := thousands(tc.n); got != tc.want {
+			t.Errorf("thousands(%d) = %q, want %q", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestWriteReportTruncated(t *testing.T) {
+	defer func(f string, max int) { *format, *maxViolations = f, max }(*format, *maxViolations)
+	var vs []report.Violation
+	for i := 0; i < 1234; i++ {
+		vs = append(vs, report.Violation{Rule: "r", Severity: report.Error, Path: "a", Line: i + 1, Message: "m"})
+	}
+
+	res := &review.Result{Paths: []string{"a"}}
+	for _, tc := range []struct {
+		format string
+		max    int
+		// lines is the number of lines of the violations written, notice
+		// the one following them.
+		lines  int
+		notice string
+	}{
+		{"text", 0, 1234, ""},
+		{"text", 1234, 1234, ""},
+		{"text", 2000, 1234, ""},
+		{"text", 1233, 1233, "... and 1 more violations (increase -max-violations to see them)"},
+		{"text", 10, 10, "... and 1,224 more violations (increase -max-violations to see them)"},
+		{"json", 10, 1234, ""},
+		{"junit", 10, 1234, ""},
+	} {
+		*format, *maxViolations = tc.format, tc.max
+		var buf bytes.Buffer
+		if err := writeReport(&buf, res, vs, 7); err != nil {
+			t.Fatal(err)
+		}
+
+		out := buf.String()
+		var count int
+		switch tc.format {
+		case "json":
+			count = strings.Count(out, `"rule": "r"`)
+		case "junit":
+			count = strings.Count(out, "<failure ")
+		default:
+			count = strings.Count(out, "error: m [r]")
+		}
+
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		notice := lines[len(lines)-1]
+		if !strings.HasPrefix(notice, "... and ") {
+			notice = ""
+		}
+
+		if count != tc.lines || notice != tc.notice {
+			t.Errorf("writeReport in %s with -max-violations %d wrote %d violations and notice %q, want %d and %q", tc.format, tc.max, count, notice, tc.lines, tc.notice)
+		}
+	}
+}
+
+func TestRunTruncated(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	r.commit(map[string]string{"a.txt": "a \nb \nc \n"}, "Add a")
+
+	out, err := ioutil.TempFile("", "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	defer func(saved commaList, stdout *os.File, max int) {
+		repoPaths, os.Stdout, *maxViolations = saved, stdout, max
+	}(repoPaths, os.Stdout, *maxViolations)
+	repoPaths, os.Stdout, *maxViolations = commaList{r.dir}, out, 1
+
+	// The violations not shown still fail the review.
+	o := &review.Options{}
+	o.NoTrailingWhitespace = true
+	if code := run(o); code != exitViolations {
+		t.Errorf("run() with -max-violations 1 = %d, want %d", code, exitViolations)
+	}
+
+	data, err := ioutil.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(data); strings.Count(got, "[trailing-whitespace]") != 1 || !strings.Contains(got, "... and 2 more violations") {
+		t.Errorf("run() with -max-violations 1 wrote %q, want 1 violation and 2 more", got)
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	// The null device is a character device, as a terminal.
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+
+	file, err := ioutil.TempFile("", "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	defer func(color string, no bool, env string) {
+		*colorFlag, *noColor = color, no
+		os.Setenv("NO_COLOR", env)
+	}(*colorFlag, *noColor, os.Getenv("NO_COLOR"))
+
+	for _, tc := range []struct {
+		name    string
+		w       io.Writer
+		color   string
+		noColor bool
+		env     string
+		want    bool
+	}{
+		{"terminal", devNull, "auto", false, "", true},
+		{"file", file, "auto", false, "", false},
+		{"buffer", &bytes.Buffer{}, "auto", false, "", false},
+		{"NO_COLOR", devNull, "auto", false, "1", false},
+		{"-no-color", devNull, "auto", true, "", false},
+		{"-color never", devNull, "never", false, "", false},
+		{"-color always", &bytes.Buffer{}, "always", false, "", true},
+		{"-color always and NO_COLOR", file, "always", false, "1", true},
+		{"-color always and -no-color", devNull, "always", true, "", false},
+	} {
+		*colorFlag, *noColor = tc.color, tc.noColor
+		os.Setenv("NO_COLOR", tc.env)
+		if got := useColor(tc.w); got != tc.want {
+			t.Errorf("%s: useColor = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// The text report has escape codes only when colored.
+	defer func(saved string) { *format = saved }(*format)
+	*format = "text"
+	vs := []report.Violation{{Rule: "r", Severity: report.Error, Path: "a.go", Line: 1, Message: "m"}}
+	for _, color := range []string{"never", "auto", "always"} {
+		*colorFlag, *noColor = color, false
+		os.Setenv("NO_COLOR", "")
+		var buf bytes.Buffer
+		if err := writeReport(&buf, &review.Result{}, vs, 7); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := strings.Contains(buf.String(), "\x1b["), color == "always"; got != want {
+			t.Errorf("writeReport with -color %s = %q, want escape codes %v", color, buf.String(), want)
+		}
+	}
+}
+
+func TestFailOnEmptyRange(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	first := r.commit(map[string]string{"a": "a\n"}, "Add a")
+	head := r.commit(map[string]string{"a": "a\n", "b": "b\n"}, "Add b")
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+
+	defer func(saved commaList, stdout, stderr *os.File, fail bool) {
+		repoPaths, os.Stdout, os.Stderr, *failOnEmpty = saved, stdout, stderr, fail
+	}(repoPaths, os.Stdout, os.Stderr, *failOnEmpty)
+	repoPaths, os.Stdout, os.Stderr = commaList{r.dir}, devNull, devNull
+
+	for _, tc := range []struct {
+		name  string
+		since string
+		fail  bool
+		want  int
+	}{
+		{"empty range", head, true, exitEmptyRange},
+		{"empty range without the flag", head, false, exitOK},
+		{"non-empty range", first, true, exitOK},
+	} {
+		*failOnEmpty = tc.fail
+		o := &review.Options{}
+		o.SinceCommit = tc.since
+		if code := run(o); code != tc.want {
+			t.Errorf("%s: run() = %d, want %d", tc.name, code, tc.want)
+		}
+	}
+}
+
+func TestWriteReportDocURL(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	r.commit(map[string]string{"a.txt": "\xef\xbb\xbfa\n"}, "Add a")
+
+	const url = "https://example.com/rules/bom"
+	o := &review.Options{}
+	o.CheckBOM = true
+	o.DocURLs = map[string]string{"bom": url}
+	repo, err := openRepo(r.dir, 0, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := review.Run(repo, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vs []report.Violation
+	for _, v := range res.Violations {
+		if v.Rule == "bom" {
+			vs = append(vs, v)
+		}
+	}
+
+	if len(vs) != 1 || vs[0].DocURL != url {
+		t.Fatalf("bom violations %+v, want one with the documentation URL %s", vs, url)
+	}
+
+	defer func(saved string) { *format = saved }(*format)
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{"text", "a.txt:1: error: file starts with a UTF-8 byte order mark [bom] (see " + url + ")\n"},
+		{"json", `"doc_url": "` + url + `"`},
+		{"junit", "[bom] (see " + url + ")</failure>"},
+	} {
+		*format = tc.format
+		var buf bytes.Buffer
+		if err := writeReport(&buf, res, vs, 7); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(buf.String(), tc.want) {
+			t.Errorf("writeReport in %s = %q, want %q", tc.format, buf.String(), tc.want)
+		}
+	}
+}
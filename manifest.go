@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/gunjan5/code-review-bot/review"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// Git modes of the tree entries, decoded as the low bits of their
+// os.FileMode.
+const (
+	gitModeMask = 0777777
+	gitlinkMode = 0160000
+)
+
+// manifestEntry describes a file of the reviewed tree. Submodules are
+// entries of type commit, their hash being the referenced commit.
+type manifestEntry struct {
+	Repo string `json:"repo,omitempty"`
+	Path string `json:"path"`
+	// Mode is the octal mode of the tree entry, as in git ls-tree.
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// writeManifest writes the files of the reviewed trees of the repositories
+// as a JSON array, their entries attributed to their repository when there
+// are several.
+func writeManifest(w io.Writer, repos []*git.Repository, paths []string, o *review.Options) error {
+	entries := []manifestEntry{}
+	for i, repo := range repos {
+		c, err := review.ResolveCommit(repo, o.Ref)
+		if err != nil {
+			return err
+		}
+
+		t, err := c.Tree()
+		if err != nil {
+			return err
+		}
+
+		repoPath := ""
+		if len(repoPaths) > 1 {
+			repoPath = paths[i]
+		}
+
+		if entries, err = appendManifest(entries, repo, t, "", repoPath); err != nil {
+			return fmt.Errorf("%s: %s", paths[i], err)
+		}
+	}
+
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
+
+// appendManifest appends the entries of the tree and of its subtrees, in
+// tree order, with their path under dir.
+func appendManifest(entries []manifestEntry, repo *git.Repository, t *git.Tree, dir, repoPath string) ([]manifestEntry, error) {
+	for _, e := range t.Entries {
+		name := path.Join(dir, e.Name)
+		entry := manifestEntry{
+			Repo: repoPath,
+			Path: name,
+			Mode: fmt.Sprintf("%06o", e.Mode&gitModeMask),
+			Hash: e.Hash.String(),
+		}
+
+		switch {
+		case e.Mode == gitlinkMode:
+			entry.Type = "commit"
+		case e.Mode.IsDir():
+			sub, err := repo.Tree(e.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+
+			if entries, err = appendManifest(entries, repo, sub, name, repoPath); err != nil {
+				return nil, err
+			}
+
+			continue
+		default:
+			b, err := repo.Blob(e.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+
+			entry.Type = "blob"
+			entry.Size = b.Size
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
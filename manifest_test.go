@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/review"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func TestWriteManifest(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+
+	r.write(map[string]string{"a.txt": "a\n", "dir/sub/b.go": "package sub\n", "run.sh": "#!/bin/sh\n"})
+	if err := os.Chmod(filepath.Join(r.dir, "run.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink("a.txt", filepath.Join(r.dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	r.git("add", "-A")
+	r.git("update-index", "--add", "--cacheinfo", "160000,0be77e079c17b4c95ddf68762338071427f66360,vendor/lib")
+	r.commits++
+	r.git("commit", "-q", "-m", "Add the files")
+
+	repo, err := openRepo(r.dir, 0, &review.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeManifest(&buf, []*git.Repository{repo}, []string{r.dir}, &review.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []manifestEntry
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	// git ls-tree -r -l prints "mode type hash size\tpath", the size being
+	// - for submodules.
+	var want []manifestEntry
+	for _, line := range strings.Split(strings.TrimSpace(r.git("ls-tree", "-r", "-l", "HEAD")), "\n") {
+		tab := strings.IndexByte(line, '\t')
+		f := strings.Fields(line[:tab])
+		size, _ := strconv.ParseInt(f[3], 10, 64)
+		want = append(want, manifestEntry{Path: line[tab+1:], Mode: f[0], Type: f[1], Hash: f[2], Size: size})
+	}
+
+	if len(want) != 5 {
+		t.Fatalf("git ls-tree listed %d entries, want 5", len(want))
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("manifest:\n%s\nwant git ls-tree:\n%+v", buf.String(), want)
+	}
+}
@@ -0,0 +1,123 @@
+// Package objcache keeps the objects recently read from a repository in
+// memory, so the trees and blobs read again and again when walking or
+// diffing many commits aren't decompressed from their packfile each time.
+package objcache
+
+import (
+	"container/list"
+	"sync"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// DefaultSize is the default total size of the cached objects, in bytes.
+const DefaultSize = 64 << 20
+
+// Storage is a repository storage whose objects are cached.
+type Storage struct {
+	git.Storage
+	objects *ObjectStorage
+}
+
+// New returns the storage s with a cache of its objects of up to size
+// bytes.
+func New(s git.Storage, size int64) *Storage {
+	return &Storage{Storage: s, objects: NewObjectStorage(s.ObjectStorage(), size)}
+}
+
+// ObjectStorage returns the cached object storage.
+func (s *Storage) ObjectStorage() core.ObjectStorage {
+	return s.objects
+}
+
+// ObjectStorage is an object storage keeping the objects it returns in a
+// least recently used cache, bounded by the total size of their content.
+// It is safe for concurrent use if the storage it wraps is.
+type ObjectStorage struct {
+	core.ObjectStorage
+
+	mu   sync.Mutex
+	max  int64
+	size int64
+	// lru holds the cached entries, the most recently used first.
+	lru   *list.List
+	items map[core.Hash]*list.Element
+}
+
+type entry struct {
+	hash core.Hash
+	obj  core.Object
+}
+
+// NewObjectStorage returns the storage s with a cache of its objects of up
+// to size bytes. Objects larger than size aren't cached.
+func NewObjectStorage(s core.ObjectStorage, size int64) *ObjectStorage {
+	return &ObjectStorage{
+		ObjectStorage: s,
+		max:           size,
+		lru:           list.New(),
+		items:         make(map[core.Hash]*list.Element),
+	}
+}
+
+// Get returns the object with the hash and type from the cache, or reads it
+// from the storage and caches it.
+func (s *ObjectStorage) Get(t core.ObjectType, h core.Hash) (core.Object, error) {
+	if obj, ok := s.get(h); ok {
+		if t != core.AnyObject && obj.Type() != t {
+			return nil, core.ErrObjectNotFound
+		}
+
+		return obj, nil
+	}
+
+	obj, err := s.ObjectStorage.Get(t, h)
+	if err != nil {
+		return nil, err
+	}
+
+	s.add(h, obj)
+	return obj, nil
+}
+
+func (s *ObjectStorage) get(h core.Hash) (core.Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[h]
+	if !ok {
+		return nil, false
+	}
+
+	s.lru.MoveToFront(e)
+	return e.Value.(*entry).obj, true
+}
+
+// add caches the object, evicting the least recently used objects to make
+// room for it.
+func (s *ObjectStorage) add(h core.Hash, obj core.Object) {
+	if obj.Size() > s.max {
+		return
+	}
+
+	// The hash of memory objects is computed on first use, it is done
+	// before they are shared.
+	obj.Hash()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[h]; ok {
+		return
+	}
+
+	for s.size+obj.Size() > s.max {
+		old := s.lru.Remove(s.lru.Back()).(*entry)
+		delete(s.items, old.hash)
+		s.size -= old.obj.Size()
+	}
+
+	s.items[h] = s.lru.PushFront(&entry{h, obj})
+	s.size += obj.Size()
+}
@@ -0,0 +1,148 @@
+package objcache
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4/core"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// countingStorage counts the objects read from the storage it wraps.
+type countingStorage struct {
+	core.ObjectStorage
+	reads map[core.Hash]int
+}
+
+func (s *countingStorage) Get(t core.ObjectType, h core.Hash) (core.Object, error) {
+	s.reads[h]++
+	return s.ObjectStorage.Get(t, h)
+}
+
+// newTestStorage returns a cache of size bytes over a storage of blobs of
+// the contents, and their hashes.
+func newTestStorage(t *testing.T, size int64, contents ...string) (*ObjectStorage, *countingStorage, []core.Hash) {
+	cs := &countingStorage{ObjectStorage: memory.NewStorage().ObjectStorage(), reads: make(map[core.Hash]int)}
+	hashes := make([]core.Hash, len(contents))
+	for i, content := range contents {
+		obj := &core.MemoryObject{}
+		obj.SetType(core.BlobObject)
+		obj.SetSize(int64(len(content)))
+		obj.Write([]byte(content))
+		h, err := cs.Set(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hashes[i] = h
+	}
+
+	return NewObjectStorage(cs, size), cs, hashes
+}
+
+// cached returns the indexes in hashes of the cached objects, the most
+// recently used first.
+func cached(s *ObjectStorage, hashes []core.Hash) []int {
+	var indexes []int
+	for e := s.lru.Front(); e != nil; e = e.Next() {
+		for i, h := range hashes {
+			if e.Value.(*entry).hash == h {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+
+	return indexes
+}
+
+func TestObjectStorage(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		size int64
+		gets []int
+		// cached are the objects left in the cache, the most recently used
+		// first, and reads the number of times each object was read from
+		// the storage.
+		cached []int
+		reads  []int
+	}{
+		{"cached", 12, []int{0, 1, 0, 1}, []int{1, 0}, []int{1, 1, 0}},
+		{"least recently used evicted", 8, []int{0, 1, 2}, []int{2, 1}, []int{1, 1, 1}},
+		{"recency on get", 8, []int{0, 1, 0, 2}, []int{2, 0}, []int{1, 1, 1}},
+		{"evicted read again", 8, []int{0, 1, 2, 0}, []int{0, 2}, []int{2, 1, 1}},
+		{"several evicted", 9, []int{0, 1, 3}, []int{3}, []int{1, 1, 0, 1}},
+		{"larger than the limit", 3, []int{0, 0, 3}, nil, []int{2, 0, 0, 1}},
+		{"exact limit", 4, []int{0, 0, 1}, []int{1}, []int{1, 1, 0, 0}},
+	} {
+		contents := []string{"aaaa", "bbbb", "cccc", "dddddddd"}
+		s, cs, hashes := newTestStorage(t, tc.size, contents...)
+		for _, i := range tc.gets {
+			obj, err := s.Get(core.AnyObject, hashes[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if obj.Hash() != hashes[i] {
+				t.Errorf("%s: Get(%s) = %s", tc.name, hashes[i], obj.Hash())
+			}
+		}
+
+		if got := cached(s, hashes); !reflect.DeepEqual(got, tc.cached) {
+			t.Errorf("%s: cached %v, want %v", tc.name, got, tc.cached)
+		}
+
+		for i, want := range tc.reads {
+			if got := cs.reads[hashes[i]]; got != want {
+				t.Errorf("%s: object %d read %d times, want %d", tc.name, i, got, want)
+			}
+		}
+
+		var size int64
+		for _, i := range tc.cached {
+			size += int64(len(contents[i]))
+		}
+
+		if s.size != size || len(s.items) != len(tc.cached) {
+			t.Errorf("%s: %d bytes and %d objects cached, want %d and %d", tc.name, s.size, len(s.items), size, len(tc.cached))
+		}
+	}
+}
+
+func TestObjectStorageAddCached(t *testing.T) {
+	s, cs, hashes := newTestStorage(t, 8, "aaaa")
+	first, err := s.Get(core.BlobObject, hashes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Adding an object already cached, as two concurrent reads of it do,
+	// keeps the first one without counting its size twice.
+	second, err := cs.ObjectStorage.Get(core.BlobObject, hashes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.add(hashes[0], second)
+	if got := cached(s, hashes); !reflect.DeepEqual(got, []int{0}) || s.size != 4 {
+		t.Errorf("cached %v of %d bytes, want [0] of 4", got, s.size)
+	}
+
+	if obj, err := s.Get(core.BlobObject, hashes[0]); err != nil || obj != first {
+		t.Errorf("Get() = %p, %v, want the first object %p", obj, err, first)
+	}
+}
+
+func TestObjectStorageType(t *testing.T) {
+	s, _, hashes := newTestStorage(t, 8, "aaaa")
+	if _, err := s.Get(core.BlobObject, hashes[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if obj, err := s.Get(core.TreeObject, hashes[0]); err != core.ErrObjectNotFound {
+		t.Errorf("Get(tree) of a cached blob = %v, %v, want %v", obj, err, core.ErrObjectNotFound)
+	}
+
+	if obj, err := s.Get(core.AnyObject, hashes[0]); err != nil || obj.Type() != core.BlobObject {
+		t.Errorf("Get(any) of a cached blob = %v, %v, want the blob", obj, err)
+	}
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/config"
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/review"
+	"github.com/gunjan5/code-review-bot/rules"
+)
+
+// envPrefix is prepended to the upper-cased name of a flag, dashes replaced
+// by underscores, to get the environment variable of an option.
+const envPrefix = "CRB_"
+
+// envNames maps the options whose environment variable doesn't follow the
+// CRB_ naming, usually because other tools already define it.
+var envNames = map[string]string{
+	"github-token": "GITHUB_TOKEN",
+}
+
+// envName returns the environment variable bound to the flag name.
+func envName(name string) string {
+	if env, ok := envNames[name]; ok {
+		return env
+	}
+
+	return envPrefix + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// resolveOptions fills in the flags not given on the command line, so every
+// option is taken from, in order of precedence: the command line flag, its
+// environment variable, the configuration file and the flag default.
+//
+// The configuration file is named by the -config flag or its environment
+// variable and uses the flag names as keys.
+func resolveOptions(fs *flag.FlagSet, getenv func(string) string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	if err := setFromEnv(fs, "config", set, getenv); err != nil {
+		return err
+	}
+
+	var errs optionErrors
+	var cfg *config.Config
+	if path := fs.Lookup("config").Value.String(); path != "" {
+		var err error
+		if cfg, err = config.Load(path); err != nil {
+			return err
+		}
+
+		errs = checkConfigKeys(fs, cfg)
+	}
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || f.Name == "config" {
+			return
+		}
+
+		if err := setFromEnv(fs, f.Name, set, getenv); err != nil || set[f.Name] {
+			errs.add(err)
+			return
+		}
+
+		if cfg != nil {
+			errs.add(setFromConfig(fs, f.Name, cfg))
+		}
+	})
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// applyRange sets the Ref and SinceCommit of the options to the sides of
+// -range, or its Ref and BaseBranch for BASE...HEAD. It can't be combined
+// with the other options selecting the commits.
+func applyRange(o *review.Options) error {
+	if *rangeFlag == "" {
+		return nil
+	}
+
+	if o.Ref != "" || o.SinceCommit != "" || o.BaseBranch != "" || o.Commit != "" || *indexFlag || *worktreeFlag {
+		return errors.New("-range can't be combined with -ref, -since-commit, -base-branch, -commit, -index or -worktree")
+	}
+
+	rg, err := review.ParseRange(*rangeFlag)
+	if err != nil {
+		return err
+	}
+
+	o.Ref = rg.Head
+	if rg.MergeBase {
+		o.BaseBranch = rg.Base
+	} else {
+		o.SinceCommit = rg.Base
+	}
+
+	return nil
+}
+
+// applyRelease sets the Ref and SinceCommit of the options to -to and -from,
+// to review the commits of a release since the previous one. It can't be
+// combined with the other options selecting the commits.
+func applyRelease(o *review.Options) error {
+	if *fromFlag == "" && *toFlag == "" {
+		return nil
+	}
+
+	if *fromFlag == "" {
+		return errors.New("-to requires -from")
+	}
+
+	if *rangeFlag != "" || o.Ref != "" || o.SinceCommit != "" || o.BaseBranch != "" || o.Commit != "" || *indexFlag || *worktreeFlag {
+		return errors.New("-from and -to can't be combined with -range, -ref, -since-commit, -base-branch, -commit, -index or -worktree")
+	}
+
+	o.Ref = *toFlag
+	o.SinceCommit = *fromFlag
+	return nil
+}
+
+// optionErrors lists every invalid option found, so they can all be fixed at
+// once.
+type optionErrors []error
+
+func (e *optionErrors) add(err error) {
+	if err != nil {
+		*e = append(*e, err)
+	}
+}
+
+func (e optionErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+func setFromEnv(fs *flag.FlagSet, name string, set map[string]bool, getenv func(string) string) error {
+	if set[name] {
+		return nil
+	}
+
+	env := envName(name)
+	value := getenv(env)
+	if value == "" {
+		return nil
+	}
+
+	if err := fs.Set(name, value); err != nil {
+		return fmt.Errorf("invalid value %q for %s: %s", value, env, err)
+	}
+
+	set[name] = true
+	return nil
+}
+
+// listValue is implemented by the flags that can be repeated. They are set
+// once for each item of their list in the configuration file.
+type listValue interface {
+	flag.Value
+	list()
+}
+
+// stringList is a flag that can be repeated, its values are appended.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *stringList) list() {}
+
+// commaList is a repeatable flag whose values can also be comma separated.
+type commaList []string
+
+func (l *commaList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *commaList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+
+	return nil
+}
+
+func (l *commaList) list() {}
+
+// pathExcludes is the repeatable RULE=GLOB flag filling rules.Options.Exclude.
+type pathExcludes map[string][]string
+
+func (e *pathExcludes) String() string {
+	var pairs []string
+	for id, globs := range *e {
+		for _, glob := range globs {
+			pairs = append(pairs, id+"="+glob)
+		}
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (e *pathExcludes) Set(value string) error {
+	i := strings.IndexByte(value, '=')
+	if i <= 0 || i == len(value)-1 {
+		return fmt.Errorf("expected RULE=GLOB")
+	}
+
+	id, glob := value[:i], value[i+1:]
+	if r := rules.Lookup(id); r == nil || !rules.Checks(r, rules.FileKind) {
+		return fmt.Errorf("unknown file rule %q", id)
+	}
+
+	if _, err := path.Match(glob, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %s", glob, err)
+	}
+
+	if *e == nil {
+		*e = make(pathExcludes)
+	}
+
+	(*e)[id] = append((*e)[id], glob)
+	return nil
+}
+
+func (e *pathExcludes) list() {}
+
+// severities is the repeatable RULE=SEVERITY flag filling
+// rules.Options.Severity.
+type severities map[string]report.Severity
+
+func (s *severities) String() string {
+	var pairs []string
+	for id, severity := range *s {
+		pairs = append(pairs, id+"="+severity.String())
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (s *severities) Set(value string) error {
+	i := strings.LastIndex(value, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected RULE=SEVERITY")
+	}
+
+	id := value[:i]
+	if rules.Lookup(id) == nil {
+		return fmt.Errorf("unknown rule %q", id)
+	}
+
+	severity, err := report.ParseSeverity(value[i+1:])
+	if err != nil {
+		return err
+	}
+
+	if *s == nil {
+		*s = make(severities)
+	}
+
+	(*s)[id] = severity
+	return nil
+}
+
+func (s *severities) list() {}
+
+// docURLs is the repeatable RULE=URL flag filling rules.Options.DocURLs. The
+// rule can be a plugin rule, its id isn't checked.
+type docURLs map[string]string
+
+func (d *docURLs) String() string {
+	var pairs []string
+	for id, url := range *d {
+		pairs = append(pairs, id+"="+url)
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (d *docURLs) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected RULE=URL")
+	}
+
+	if *d == nil {
+		*d = make(docURLs)
+	}
+
+	(*d)[value[:i]] = value[i+1:]
+	return nil
+}
+
+func (d *docURLs) list() {}
+
+// dirEntryLimits is the repeatable flag setting the MaxDirEntries of the
+// rules options with a number, or one of their DirEntryLimits with GLOB=N.
+type dirEntryLimits struct {
+	o *rules.Options
+}
+
+func (d dirEntryLimits) String() string {
+	if d.o == nil {
+		return "0"
+	}
+
+	values := []string{strconv.Itoa(d.o.MaxDirEntries)}
+	for _, l := range d.o.DirEntryLimits {
+		values = append(values, fmt.Sprintf("%s=%d", l.Glob, l.Max))
+	}
+
+	return strings.Join(values, ",")
+}
+
+func (d dirEntryLimits) Set(value string) error {
+	i := strings.LastIndex(value, "=")
+	max, err := strconv.Atoi(value[i+1:])
+	if err != nil || max < 0 {
+		return fmt.Errorf("expected N or GLOB=N with N a positive number")
+	}
+
+	if i < 0 {
+		d.o.MaxDirEntries = max
+		return nil
+	}
+
+	glob := value[:i]
+	if _, err := path.Match(glob, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %s", glob, err)
+	}
+
+	d.o.DirEntryLimits = append(d.o.DirEntryLimits, rules.PathLimit{Glob: glob, Max: max})
+	return nil
+}
+
+func (d dirEntryLimits) list() {}
+
+// indentStyles is the repeatable GLOB=STYLE flag filling rules.Options.Indent.
+type indentStyles []rules.IndentStyle
+
+func (s *indentStyles) String() string {
+	var pairs []string
+	for _, style := range *s {
+		pairs = append(pairs, style.Glob+"="+style.Style)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (s *indentStyles) Set(value string) error {
+	i := strings.LastIndex(value, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected GLOB=%s or GLOB=%s", rules.Spaces, rules.Tabs)
+	}
+
+	glob, style := value[:i], value[i+1:]
+	if style != rules.Spaces && style != rules.Tabs {
+		return fmt.Errorf("unknown indentation style %q, expected %s or %s", style, rules.Spaces, rules.Tabs)
+	}
+
+	if _, err := path.Match(glob, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %s", glob, err)
+	}
+
+	*s = append(*s, rules.IndentStyle{Glob: glob, Style: style})
+	return nil
+}
+
+func (s *indentStyles) list() {}
+
+// Values of the sortedFiles flag after the glob.
+const (
+	caseSensitive   = "case-sensitive"
+	caseInsensitive = "case-insensitive"
+)
+
+// sortedFiles is the repeatable GLOB[=case-insensitive] flag filling
+// rules.Options.Sorted.
+type sortedFiles []rules.SortedFiles
+
+func (s *sortedFiles) String() string {
+	var values []string
+	for _, f := range *s {
+		if f.IgnoreCase {
+			values = append(values, f.Glob+"="+caseInsensitive)
+		} else {
+			values = append(values, f.Glob)
+		}
+	}
+
+	return strings.Join(values, ",")
+}
+
+func (s *sortedFiles) Set(value string) error {
+	f := rules.SortedFiles{Glob: value}
+	if i := strings.LastIndex(value, "="); i >= 0 {
+		switch value[i+1:] {
+		case caseSensitive:
+		case caseInsensitive:
+			f.IgnoreCase = true
+		default:
+			return fmt.Errorf("unknown ordering %q, expected %s or %s", value[i+1:], caseSensitive, caseInsensitive)
+		}
+
+		f.Glob = value[:i]
+	}
+
+	if _, err := path.Match(f.Glob, ""); err != nil || f.Glob == "" {
+		return fmt.Errorf("invalid glob %q", f.Glob)
+	}
+
+	*s = append(*s, f)
+	return nil
+}
+
+func (s *sortedFiles) list() {}
+
+func setFromConfig(fs *flag.FlagSet, name string, cfg *config.Config) error {
+	if _, ok := fs.Lookup(name).Value.(listValue); ok {
+		return setListFromConfig(fs, name, cfg)
+	}
+
+	value, ok, err := cfg.String(name)
+	if err != nil {
+		return fmt.Errorf("%s: %s", cfg.Path, err)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	if err := fs.Set(name, value); err != nil {
+		return fmt.Errorf("%s: invalid value %q for %s: %s", cfg.Path, value, name, err)
+	}
+
+	return nil
+}
+
+// checkConfigKeys returns an error for each key of the configuration file
+// that doesn't name an option.
+func checkConfigKeys(fs *flag.FlagSet, cfg *config.Config) optionErrors {
+	var errs optionErrors
+	for _, key := range cfg.Keys() {
+		if key == "config" || fs.Lookup(key) == nil {
+			errs.add(fmt.Errorf("%s: line %d: unknown option %q",
+				cfg.Path, cfg.Root.Get(key).Line, key))
+		}
+	}
+
+	return errs
+}
+
+// setListFromConfig sets a repeatable flag once for each value of its list in
+// the configuration file. The entries of a map are given as KEY=VALUE, in
+// file order.
+func setListFromConfig(fs *flag.FlagSet, name string, cfg *config.Config) error {
+	values, _, err := cfg.Strings(name)
+	if n := cfg.Root.Get(name); n != nil && n.Kind == config.Map {
+		values, err = cfg.Pairs(name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %s", cfg.Path, err)
+	}
+
+	for _, value := range values {
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("%s: invalid value %q for %s: %s", cfg.Path, value, name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/rules"
+)
+
+// testFlags returns a flag set of a few options parsed from the arguments.
+func testFlags(t *testing.T, args ...string) *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.String("config", "", "")
+	fs.String("format", "text", "")
+	fs.Int("jobs", 4, "")
+	fs.Bool("flag-todos", false, "")
+	fs.String("github-token", "", "")
+	fs.Var(&stringList{}, "path", "")
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	return fs
+}
+
+// testEnv returns a getenv of the variables.
+func testEnv(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
+
+func TestResolveOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "options")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := filepath.Join(dir, "config.yml")
+	data := "format: junit\njobs: 2\nflag-todos: true\npath:\n  - cmd/**\n  - '*.go'\n"
+	if err := ioutil.WriteFile(cfg, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{"format": "text", "jobs": "4", "flag-todos": "false", "path": ""},
+		},
+		{
+			name: "config file",
+			args: []string{"-config", cfg},
+			want: map[string]string{"format": "junit", "jobs": "2", "flag-todos": "true", "path": "cmd/**,*.go"},
+		},
+		{
+			name: "config from the environment",
+			env:  map[string]string{"CRB_CONFIG": cfg},
+			want: map[string]string{"format": "junit", "jobs": "2"},
+		},
+		{
+			name: "environment over config file",
+			args: []string{"-config", cfg},
+			env:  map[string]string{"CRB_FORMAT": "json", "CRB_PATH": "docs/**", "GITHUB_TOKEN": "secret"},
+			want: map[string]string{"format": "json", "jobs": "2", "path": "docs/**", "github-token": "secret"},
+		},
+		{
+			name: "flags over environment and config file",
+			args: []string{"-config", cfg, "-format", "text", "-jobs", "8", "-path", "a"},
+			env:  map[string]string{"CRB_FORMAT": "json", "CRB_JOBS": "6"},
+			want: map[string]string{"format": "text", "jobs": "8", "flag-todos": "true", "path": "a"},
+		},
+		{
+			name: "flag set to its default",
+			args: []string{"-config", cfg, "-flag-todos=false"},
+			env:  map[string]string{"CRB_FLAG_TODOS": "true"},
+			want: map[string]string{"flag-todos": "false"},
+		},
+		{
+			name: "CRB_ variable of the token",
+			env:  map[string]string{"CRB_GITHUB_TOKEN": "ignored"},
+			want: map[string]string{"github-token": ""},
+		},
+	} {
+		fs := testFlags(t, tc.args...)
+		if err := resolveOptions(fs, testEnv(tc.env)); err != nil {
+			t.Errorf("%s: resolveOptions = %v", tc.name, err)
+			continue
+		}
+
+		for name, want := range tc.want {
+			if got := fs.Lookup(name).Value.String(); got != want {
+				t.Errorf("%s: -%s = %q, want %q", tc.name, name, got, want)
+			}
+		}
+	}
+}
+
+func TestResolveOptionsErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "options")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+
+	unknown := write("unknown.yml", "format: json\nmax-frobs: 3\nconfig: other.yml\n")
+	invalid := write("invalid.yml", "jobs: many\n")
+	for _, tc := range []struct {
+		name string
+		args []string
+		env  map[string]string
+		errs []string
+	}{
+		{
+			name: "unknown keys",
+			args: []string{"-config", unknown},
+			errs: []string{`line 2: unknown option "max-frobs"`, `line 3: unknown option "config"`},
+		},
+		{
+			name: "invalid config value",
+			args: []string{"-config", invalid},
+			errs: []string{`invalid value "many" for jobs`},
+		},
+		{
+			name: "invalid environment value",
+			env:  map[string]string{"CRB_JOBS": "many", "CRB_FLAG_TODOS": "maybe"},
+			errs: []string{`invalid value "many" for CRB_JOBS`, `invalid value "maybe" for CRB_FLAG_TODOS`},
+		},
+		{
+			name: "missing config file",
+			env:  map[string]string{"CRB_CONFIG": filepath.Join(dir, "missing.yml")},
+			errs: []string{"missing.yml"},
+		},
+	} {
+		fs := testFlags(t, tc.args...)
+		err := resolveOptions(fs, testEnv(tc.env))
+		if err == nil {
+			t.Errorf("%s: resolveOptions succeeded", tc.name)
+			continue
+		}
+
+		for _, want := range tc.errs {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: resolveOptions = %q, want an error containing %q", tc.name, err, want)
+			}
+		}
+	}
+
+	// The valid options are still set.
+	fs := testFlags(t, "-config", unknown)
+	resolveOptions(fs, testEnv(nil))
+	if got := fs.Lookup("format").Value.String(); got != "json" {
+		t.Errorf("-format = %q, want json despite the unknown keys", got)
+	}
+}
+
+func TestDirEntryLimits(t *testing.T) {
+	o := &rules.Options{}
+	d := dirEntryLimits{o}
+	for _, value := range []string{"100", "vendor/*=0", "docs=20"} {
+		if err := d.Set(value); err != nil {
+			t.Fatalf("Set(%q): %s", value, err)
+		}
+	}
+
+	want := []rules.PathLimit{{Glob: "vendor/*", Max: 0}, {Glob: "docs", Max: 20}}
+	if o.MaxDirEntries != 100 || !reflect.DeepEqual(o.DirEntryLimits, want) {
+		t.Errorf("-max-dir-entries = %d, %+v, want 100, %+v", o.MaxDirEntries, o.DirEntryLimits, want)
+	}
+
+	if got := d.String(); got != "100,vendor/*=0,docs=20" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, value := range []string{"", "many", "-1", "docs=", "docs=-2", "[a=3"} {
+		if err := d.Set(value); err == nil {
+			t.Errorf("Set(%q) succeeded", value)
+		}
+	}
+}
+
+func TestCommaList(t *testing.T) {
+	var l commaList
+	for _, value := range []string{".", "../api, ../web", ",", " ../docs "} {
+		if err := l.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want := (commaList{".", "../api", "../web", "../docs"}); !reflect.DeepEqual(l, want) {
+		t.Errorf("-repo = %q, want %q", l, want)
+	}
+
+	if got := l.String(); got != ".,../api,../web,../docs" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestSeverities(t *testing.T) {
+	var s severities
+	for _, value := range []string{"bom=warning", "utf8=INFO", "bom=error"} {
+		if err := s.Set(value); err != nil {
+			t.Fatalf("Set(%q): %s", value, err)
+		}
+	}
+
+	if want := (severities{"bom": report.Error, "utf8": report.Info}); !reflect.DeepEqual(s, want) {
+		t.Errorf("-severity = %v, want %v", s, want)
+	}
+
+	if got := s.String(); got != "bom=error,utf8=info" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for value, want := range map[string]string{
+		"bom":           "expected RULE=SEVERITY",
+		"=error":        "expected RULE=SEVERITY",
+		"nope=error":    `unknown rule "nope"`,
+		"bom=important": `unknown severity "important"`,
+	} {
+		if err := s.Set(value); err == nil || err.Error() != want {
+			t.Errorf("Set(%q) = %v, want %s", value, err, want)
+		}
+	}
+}
@@ -0,0 +1,471 @@
+package patch
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// Modes of the tree entries written by Build.
+const (
+	regularMode   = 0100644
+	treeMode      = 0040000
+	submoduleMode = 0160000
+)
+
+// subprojectPrefix starts the lines of the hunks of submodules, followed by
+// the commit they point to.
+const subprojectPrefix = "Subproject commit "
+
+// Series is a series of patches rebuilt as commits in a memory repository.
+type Series struct {
+	Repo *git.Repository
+	// Base is the commit the first patch applies to, holding what the
+	// patches tell of the files they change, and Head the commit of the
+	// last patch, HEAD being detached at it.
+	Base, Head core.Hash
+	// Complete lists the files of Head whose whole content is known, those
+	// added by the patches. Partial lists the other files changed by the
+	// patches, only holding the lines of their hunks.
+	Complete, Partial []string
+	// Hashes maps the rebuilt commits to the hashes of the commits the
+	// patches were made from, when known.
+	Hashes map[core.Hash]string
+}
+
+// version is a version of a file. Only the lines of its hunks are known
+// when it isn't complete.
+type version struct {
+	mode     os.FileMode
+	content  string
+	complete bool
+	// commit is the commit of a submodule.
+	commit core.Hash
+}
+
+// change sets a path to a version, or deletes it if nil.
+type change struct {
+	path string
+	v    *version
+}
+
+// Build rebuilds the patches as a linear history on top of a base commit.
+// The files the patches change without adding them are put in the base as
+// far as the hunks tell, so every patch changes the same files as the
+// original commit. It fails if a patch doesn't apply to the files known.
+func Build(patches []*Patch) (*Series, error) {
+	base := make(map[string]*version)
+	files := make(map[string]*version)
+	// deleted holds the paths deleted by the patches, known to be absent.
+	deleted := make(map[string]bool)
+	changes := make([][]change, len(patches))
+	for i, p := range patches {
+		for _, f := range p.Files {
+			cs, err := apply(f, base, files, deleted)
+			if err != nil {
+				return nil, fmt.Errorf("patch %d: %s: %s", i+1, f.path(), err)
+			}
+
+			for _, c := range cs {
+				if c.v == nil {
+					delete(files, c.path)
+					deleted[c.path] = true
+				} else {
+					files[c.path] = c.v
+					delete(deleted, c.path)
+				}
+			}
+
+			changes[i] = append(changes[i], cs...)
+		}
+	}
+
+	s := memory.NewStorage()
+	r, err := git.NewRepository(s)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &builder{s: s.ObjectStorage()}
+	series := &Series{Repo: r, Hashes: make(map[core.Hash]string)}
+	tree := make(map[string]*version)
+	for name, v := range base {
+		tree[name] = v
+	}
+
+	first := patches[0].Author
+	sig := git.Signature{Name: "base", When: first.When}
+	if series.Base, err = b.commit(tree, nil, sig, sig, "base\n"); err != nil {
+		return nil, err
+	}
+
+	parent := series.Base
+	when := first.When
+	for i, p := range patches {
+		for _, c := range changes[i] {
+			if c.v == nil {
+				delete(tree, c.path)
+			} else {
+				tree[c.path] = c.v
+			}
+		}
+
+		// The committer dates never decrease, as the history is walked
+		// by committer date.
+		committer := p.Author
+		if committer.When.Before(when) {
+			committer.When = when
+		}
+
+		when = committer.When
+		h, err := b.commit(tree, &parent, p.Author, committer, p.Message)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Hash != "" {
+			series.Hashes[h] = p.Hash
+		}
+
+		parent = h
+	}
+
+	series.Head = parent
+	if err := s.ReferenceStorage().Set(core.NewHashReference(core.HEAD, parent)); err != nil {
+		return nil, err
+	}
+
+	for name, v := range files {
+		if v.mode == submoduleMode {
+			continue
+		}
+
+		if v.complete {
+			series.Complete = append(series.Complete, name)
+		} else {
+			series.Partial = append(series.Partial, name)
+		}
+	}
+
+	sort.Strings(series.Complete)
+	sort.Strings(series.Partial)
+	return series, nil
+}
+
+// apply returns the changes of the paths made by the change of the file,
+// adding to base the version the change applies to when it isn't known.
+func apply(f *File, base, files map[string]*version, deleted map[string]bool) ([]change, error) {
+	paths := []string{f.OldPath, f.NewPath}
+	switch f.Action {
+	case diff.Added:
+		paths = paths[1:]
+	case diff.Deleted:
+		paths = paths[:1]
+	}
+
+	for _, p := range paths {
+		if !validPath(p) {
+			return nil, fmt.Errorf("invalid path %q", p)
+		}
+	}
+
+	if f.Action == diff.Added {
+		if files[f.NewPath] != nil {
+			return nil, fmt.Errorf("added but already exists")
+		}
+
+		v, err := newVersion(f, &version{})
+		return []change{{f.NewPath, v}}, err
+	}
+
+	old := files[f.OldPath]
+	if old == nil {
+		if deleted[f.OldPath] {
+			return nil, fmt.Errorf("%s was deleted by a previous patch", f.OldPath)
+		}
+
+		old = oldVersion(f)
+		base[f.OldPath] = old
+	}
+
+	if f.Action == diff.Deleted {
+		return []change{{f.OldPath, nil}}, nil
+	}
+
+	if (f.Action == diff.Renamed || f.Action == diff.Copied) && files[f.NewPath] != nil {
+		return nil, fmt.Errorf("%s already exists", f.NewPath)
+	}
+
+	v, err := newVersion(f, old)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := []change{{f.NewPath, v}}
+	if f.Action == diff.Renamed {
+		cs = append(cs, change{f.OldPath, nil})
+	}
+
+	return cs, nil
+}
+
+// oldVersion returns what the change tells of the version of the file it
+// applies to: the unchanged and deleted lines of its hunks.
+func oldVersion(f *File) *version {
+	v := &version{mode: f.OldMode}
+	if v.mode == 0 {
+		v.mode = regularMode
+	}
+
+	v.content, v.commit = fragment(f, diff.Delete)
+	return v
+}
+
+// newVersion returns the version of the file after the change, the hunks
+// applied to the old version when it is complete, only the unchanged and
+// inserted lines of the hunks otherwise.
+func newVersion(f *File, old *version) (*version, error) {
+	v := &version{mode: f.NewMode, complete: old.complete || f.Action == diff.Added}
+	if v.mode == 0 {
+		v.mode = old.mode
+	}
+
+	if f.Binary || f.Submodule {
+		v.complete = false
+	}
+
+	if !v.complete {
+		v.content, v.commit = fragment(f, diff.Insert)
+		return v, nil
+	}
+
+	var err error
+	v.content, err = applyHunks(old.content, f.Hunks)
+	return v, err
+}
+
+// fragment returns the lines of the hunks of the change on one side, the
+// unchanged lines and those of the kind, with the commit of a submodule.
+// The abbreviated hash of the index line stands for the content of binary
+// files.
+func fragment(f *File, kind diff.LineKind) (string, core.Hash) {
+	if f.Binary {
+		if kind == diff.Delete {
+			return f.OldHash, core.ZeroHash
+		}
+
+		return f.NewHash, core.ZeroHash
+	}
+
+	var buf bytes.Buffer
+	var commit core.Hash
+	for _, h := range f.Hunks {
+		for _, l := range h.Lines {
+			if l.Kind != diff.Context && l.Kind != kind {
+				continue
+			}
+
+			if f.Submodule && strings.HasPrefix(l.Text, subprojectPrefix) {
+				commit = core.NewHash(strings.TrimSpace(l.Text[len(subprojectPrefix):]))
+			}
+
+			buf.WriteString(l.Text)
+			if !l.NoNewline {
+				buf.WriteByte('\n')
+			}
+		}
+	}
+
+	return buf.String(), commit
+}
+
+// line is a line of a file, nl being false for a last line without newline.
+type line struct {
+	text string
+	nl   bool
+}
+
+// applyHunks applies the hunks to the content, checking the unchanged and
+// deleted lines match.
+func applyHunks(content string, hunks []diff.Hunk) (string, error) {
+	var old []line
+	for _, l := range strings.SplitAfter(content, "\n") {
+		if l != "" {
+			old = append(old, line{strings.TrimSuffix(l, "\n"), strings.HasSuffix(l, "\n")})
+		}
+	}
+
+	var out []line
+	pos := 0
+	for _, h := range hunks {
+		start := h.OldStart - 1
+		if h.OldLines == 0 {
+			start = h.OldStart
+		}
+
+		if start < pos || start > len(old) {
+			return "", fmt.Errorf("hunk %s doesn't apply", h.Header())
+		}
+
+		out = append(out, old[pos:start]...)
+		pos = start
+		for _, l := range h.Lines {
+			if l.Kind != diff.Insert {
+				if pos >= len(old) || old[pos].text != l.Text {
+					return "", fmt.Errorf("hunk %s doesn't apply at line %d", h.Header(), pos+1)
+				}
+
+				pos++
+			}
+
+			if l.Kind != diff.Delete {
+				out = append(out, line{l.Text, !l.NoNewline})
+			}
+		}
+	}
+
+	out = append(out, old[pos:]...)
+	var buf bytes.Buffer
+	for i, l := range out {
+		buf.WriteString(l.text)
+		if l.nl || i < len(out)-1 {
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// validPath returns true if the path can be a file of a tree: relative,
+// without empty, . or .. components.
+func validPath(p string) bool {
+	for _, c := range strings.Split(p, "/") {
+		if c == "" || c == "." || c == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// builder writes the objects of the rebuilt commits.
+type builder struct {
+	s core.ObjectStorage
+}
+
+// commit writes the tree of the files and a commit of it, returning its
+// hash.
+func (b *builder) commit(files map[string]*version, parent *core.Hash, author, committer git.Signature, msg string) (core.Hash, error) {
+	tree, err := b.tree(files, "")
+	if err != nil {
+		return core.ZeroHash, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "tree %s\n", tree)
+	if parent != nil {
+		fmt.Fprintf(&buf, "parent %s\n", *parent)
+	}
+
+	buf.WriteString("author ")
+	author.Encode(&buf)
+	buf.WriteString("\ncommitter ")
+	committer.Encode(&buf)
+	fmt.Fprintf(&buf, "\n\n%s", msg)
+	return b.write(core.CommitObject, buf.Bytes())
+}
+
+// entry is an entry of a tree being written.
+type entry struct {
+	name string
+	mode os.FileMode
+	hash core.Hash
+}
+
+// tree writes the tree of the files under the directory dir, "" for the
+// root, and its subtrees.
+func (b *builder) tree(files map[string]*version, dir string) (core.Hash, error) {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+
+	var entries []entry
+	subdirs := make(map[string]bool)
+	for name, v := range files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		rest := name[len(prefix):]
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			subdirs[rest[:i]] = true
+			continue
+		}
+
+		e := entry{name: rest, mode: v.mode, hash: v.commit}
+		if v.mode != submoduleMode {
+			h, err := b.write(core.BlobObject, []byte(v.content))
+			if err != nil {
+				return core.ZeroHash, err
+			}
+
+			e.hash = h
+		}
+
+		entries = append(entries, e)
+	}
+
+	for name := range subdirs {
+		h, err := b.tree(files, path.Join(dir, name))
+		if err != nil {
+			return core.ZeroHash, err
+		}
+
+		entries = append(entries, entry{name: name, mode: treeMode, hash: h})
+	}
+
+	sort.Sort(byTreeOrder(entries))
+	var buf bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "%o %s\x00", uint32(e.mode), e.name)
+		buf.Write(e.hash[:])
+	}
+
+	return b.write(core.TreeObject, buf.Bytes())
+}
+
+func (b *builder) write(t core.ObjectType, data []byte) (core.Hash, error) {
+	obj := &core.MemoryObject{}
+	obj.SetType(t)
+	obj.SetSize(int64(len(data)))
+	obj.Write(data)
+	return b.s.Set(obj)
+}
+
+// byTreeOrder sorts the entries of a tree as git does, the names of the
+// subtrees compared as if they ended with a slash.
+type byTreeOrder []entry
+
+func (s byTreeOrder) Len() int      { return len(s) }
+func (s byTreeOrder) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s byTreeOrder) Less(i, j int) bool {
+	return treeName(s[i]) < treeName(s[j])
+}
+
+func treeName(e entry) string {
+	if e.mode == treeMode {
+		return e.name + "/"
+	}
+
+	return e.name
+}
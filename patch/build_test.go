@@ -0,0 +1,163 @@
+package patch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// treeFiles returns the files of the tree of the commit as "mode path".
+func treeFiles(t *testing.T, c *git.Commit) []string {
+	tree, err := c.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	err = tree.Files().ForEach(func(f *git.File) error {
+		files = append(files, fmt.Sprintf("%o %s", uint32(f.Mode), f.Name))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(files)
+	return files
+}
+
+func TestBuild(t *testing.T) {
+	s, err := Build(parseSeries(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The commits from the last, walking the first parents down to the
+	// base.
+	want := []struct {
+		author, message string
+		files           []string
+	}{
+		{"Jane Doe <jane@example.com>", "Add the logo\n", []string{"100644 README", "100644 lib/b.go", "100644 logo.png", "100644 new.go", "100755 run.sh"}},
+		{"Bob <bob@example.com>", "[JIRA-12] Move A and B, drop old.txt\n", []string{"100644 README", "100644 lib/b.go", "100644 new.go", "100755 run.sh"}},
+		{"Jane Doe <jane@example.com>", "Update the README and add main\n\nThe README gets two fixes far apart, making two hunks.\n",
+			[]string{"100644 README", "100644 lib/a.go", "100644 new.go", "100644 old.txt", "100644 run.sh"}},
+		// The base holds the files changed by the patches without being
+		// added, as far as their hunks tell.
+		{"base <>", "base\n", []string{"100644 README", "100644 lib/a.go", "100644 old.txt", "100644 run.sh"}},
+	}
+
+	c, err := s.Repo.Commit(s.Head)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, w := range want {
+		author := fmt.Sprintf("%s <%s>", c.Author.Name, c.Author.Email)
+		if author != w.author || c.Message != w.message {
+			t.Errorf("commit %d by %s: %q, want by %s: %q", i, author, c.Message, w.author, w.message)
+		}
+
+		if files := treeFiles(t, c); !reflect.DeepEqual(files, w.files) {
+			t.Errorf("commit %d files %q, want %q", i, files, w.files)
+		}
+
+		if i == len(want)-1 {
+			if c.Hash != s.Base || c.NumParents() != 0 {
+				t.Errorf("last commit %s with %d parents, want the base %s", c.Hash, c.NumParents(), s.Base)
+			}
+
+			break
+		}
+
+		if s.Hashes[c.Hash] == "" {
+			t.Errorf("commit %d has no original hash", i)
+		}
+
+		p, err := c.Parent(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.Committer.When.Before(p.Committer.When) {
+			t.Errorf("commit %d committed at %s before its parent at %s", i, c.Committer.When, p.Committer.When)
+		}
+
+		c = p
+	}
+
+	if s.Hashes[s.Head] != "757a7b8c1441a9e6b49b839ba598c878bb6b1f14" {
+		t.Errorf("head made from %s, want the commit of the last patch", s.Hashes[s.Head])
+	}
+
+	if want := []string{"new.go"}; !reflect.DeepEqual(s.Complete, want) {
+		t.Errorf("complete files %q, want %q", s.Complete, want)
+	}
+
+	if want := []string{"README", "lib/b.go", "logo.png", "run.sh"}; !reflect.DeepEqual(s.Partial, want) {
+		t.Errorf("partial files %q, want %q", s.Partial, want)
+	}
+
+	head, err := s.Repo.Commit(s.Head)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path, content string
+	}{
+		// The added file is complete.
+		{"new.go", "package main\n\nfunc main() {}\n"},
+		// The other files only hold the lines of their hunks.
+		{"README", "line 1\nline two\nline 3\nline 4\nline 5\nline 15\nline 16\nline 17\nline eighteen\nline 19\nline 20\n"},
+		{"lib/b.go", "\nfunc A() {}\n\nfunc B() int { return 1 }\n"},
+		// A binary file holds the hash of its content.
+		{"logo.png", "029ace0fcbb58feb758971feed0457fd34dbb60b"},
+		{"run.sh", ""},
+	} {
+		f, err := head.File(tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := f.Contents()
+		if err != nil || content != tc.content {
+			t.Errorf("content of %s = %q, %v, want %q", tc.path, content, err, tc.content)
+		}
+	}
+}
+
+func TestBuildConflicts(t *testing.T) {
+	const header = "From: A <a@example.com>\nDate: Mon, 1 Jan 2018 00:00:00 +0000\nSubject: [PATCH] x\n\n---\n"
+	const addA = "diff --git a/a b/a\nnew file mode 100644\nindex 0000000..7898192\n--- /dev/null\n+++ b/a\n@@ -0,0 +1 @@\n+a\n"
+	for _, tc := range []struct {
+		name    string
+		patches []string
+		want    string
+	}{
+		{"added twice", []string{addA, addA}, "patch 2: a: added but already exists"},
+		{"hunk not applying", []string{addA, "diff --git a/a b/a\nindex 7898192..6178079 100644\n--- a/a\n+++ b/a\n@@ -1 +1 @@\n-b\n+c\n"}, "patch 2: a: hunk @@ -1 +1 @@ doesn't apply at line 1"},
+		{"changed after deleted", []string{
+			"diff --git a/a b/a\ndeleted file mode 100644\nindex 7898192..0000000\n--- a/a\n+++ /dev/null\n@@ -1 +0,0 @@\n-a\n",
+			"diff --git a/a b/a\nindex 7898192..6178079 100644\n--- a/a\n+++ b/a\n@@ -1 +1 @@\n-a\n+b\n",
+		}, "patch 2: a: a was deleted by a previous patch"},
+	} {
+		var in strings.Builder
+		for i, p := range tc.patches {
+			fmt.Fprintf(&in, "From %040d Mon Sep 17 00:00:00 2001\n%s%s", i, header, p)
+		}
+
+		patches, err := Parse(strings.NewReader(in.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Build(patches); err == nil || err.Error() != tc.want {
+			t.Errorf("%s: Build = %v, want %s", tc.name, err, tc.want)
+		}
+	}
+}
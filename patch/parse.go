@@ -0,0 +1,580 @@
+// Package patch reads the patches made by git format-patch, alone or in a
+// mailbox as received by email, and rebuilds the commits they hold in a
+// memory repository, so they are reviewed without the repository they were
+// made from.
+package patch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// ErrNoPatch is returned by Parse for an input without any message.
+var ErrNoPatch = errors.New("no patch found")
+
+var (
+	// fromLine separates the messages of a mailbox, a sender and a date,
+	// git format-patch giving the hash of the commit as the sender, as in
+	// From 1b85...a5f Mon Sep 17 00:00:00 2001.
+	fromLine = regexp.MustCompile(`^From (\S+) +[A-Z][a-z]{2} [A-Z][a-z]{2} +\d{1,2} \d{2}:\d{2}:\d{2} `)
+	hashLine = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// subjectPrefix is the [PATCH v2 3/5] prefix of the subjects, and the
+	// Re: of replies, removed as git am -b does. Other brackets, such as
+	// [JIRA-12], are kept.
+	subjectPrefix = regexp.MustCompile(`^\s*((\[[^\]]*PATCH[^\]]*\]|[Rr][Ee]:)\s*)+`)
+	// coverLetter is the subject prefix of the cover letter of a series,
+	// [PATCH 0/3], which holds no commit.
+	coverLetter = regexp.MustCompile(`^\s*\[[^\]]*PATCH[^\]]* 0+/\d+\]`)
+	hunkHeader  = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+)
+
+// Patch is a commit read from a patch.
+type Patch struct {
+	// Hash is the hash of the commit the patch was made from, given by
+	// the From line of git format-patch, empty if unknown.
+	Hash string
+	// Author is taken from the From and Date headers.
+	Author git.Signature
+	// Message is the subject without its [PATCH] prefix and the body up to
+	// the --- line, as in the commit.
+	Message string
+	Files   []*File
+}
+
+// File is the change of a file made by a patch. OldPath is empty for an
+// added file, NewPath for a deleted one, and the modes are 0 then.
+type File struct {
+	Action           diff.Action
+	OldPath, NewPath string
+	OldMode, NewMode os.FileMode
+	// Similarity is the percentage of the file kept by a rename or a copy.
+	Similarity int
+	// Binary is set for binary files, whose changes the patch doesn't
+	// hold as lines. Hunks is empty then.
+	Binary bool
+	Hunks  []diff.Hunk
+	// OldHash and NewHash are the abbreviated hashes of the versions of
+	// the file, from the index line.
+	OldHash, NewHash string
+	// Submodule is set for the changes of a submodule, whose commits are
+	// the hashes of the Subproject commit lines of the hunks.
+	Submodule bool
+}
+
+// Parse reads the patches of a mailbox, or the single message of a patch
+// made by git format-patch, in order. The cover letter of a series is
+// skipped.
+func Parse(r io.Reader) ([]*Patch, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var patches []*Patch
+	for i, msg := range splitMailbox(data) {
+		p, err := parseMessage(msg)
+		if err != nil {
+			return nil, fmt.Errorf("patch %d: %s", i+1, err)
+		}
+
+		if p != nil {
+			patches = append(patches, p)
+		}
+	}
+
+	if len(patches) == 0 {
+		return nil, ErrNoPatch
+	}
+
+	return patches, nil
+}
+
+// splitMailbox returns the messages of the mailbox, each starting after its
+// From line. Input without From line is a single message.
+func splitMailbox(data []byte) [][]byte {
+	var msgs [][]byte
+	var cur *bytes.Buffer
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if fromLine.Match(line) {
+			if cur != nil {
+				msgs = append(msgs, cur.Bytes())
+			}
+
+			cur = &bytes.Buffer{}
+			cur.Write(line)
+			continue
+		}
+
+		if cur == nil {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+
+			cur = &bytes.Buffer{}
+		}
+
+		cur.Write(line)
+	}
+
+	if cur != nil {
+		msgs = append(msgs, cur.Bytes())
+	}
+
+	return msgs
+}
+
+// parseMessage reads the commit and the changes of a message, nil for a
+// cover letter.
+func parseMessage(msg []byte) (*Patch, error) {
+	p := &Patch{}
+	if m := fromLine.FindSubmatch(msg); m != nil {
+		if hashLine.Match(m[1]) {
+			p.Hash = string(m[1])
+		}
+
+		i := bytes.IndexByte(msg, '\n')
+		if i < 0 {
+			i = len(msg) - 1
+		}
+
+		msg = msg[i+1:]
+	}
+
+	m, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	if coverLetter.MatchString(decodeHeader(m.Header.Get("Subject"))) {
+		return nil, nil
+	}
+
+	if err := p.readHeader(m.Header); err != nil {
+		return nil, err
+	}
+
+	body, err := readBody(m)
+	if err != nil {
+		return nil, err
+	}
+
+	text, diffs := splitBody(string(body))
+	subject := subjectPrefix.ReplaceAllString(decodeHeader(m.Header.Get("Subject")), "")
+	p.Message = strings.TrimSpace(subject) + "\n"
+	if text = strings.TrimSpace(text); text != "" {
+		p.Message += "\n" + text + "\n"
+	}
+
+	if p.Files, err = parseDiffs(diffs); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *Patch) readHeader(h mail.Header) error {
+	from := decodeHeader(h.Get("From"))
+	if from == "" {
+		return errors.New("missing From header")
+	}
+
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid From header %q: %s", from, err)
+	}
+
+	p.Author.Name, p.Author.Email = addr.Name, addr.Address
+	if p.Author.When, err = h.Date(); err != nil {
+		return fmt.Errorf("invalid Date header: %s", err)
+	}
+
+	return nil
+}
+
+// decodeHeader decodes the encoded words of a header, as in
+// =?UTF-8?q?J=C3=BCrgen?=, left as they are when invalid.
+func decodeHeader(v string) string {
+	dec := &mime.WordDecoder{}
+	if s, err := dec.DecodeHeader(v); err == nil {
+		return s
+	}
+
+	return v
+}
+
+// readBody returns the body of the message, decoded from quoted-printable or
+// base64 as some mailers send patches.
+func readBody(m *mail.Message) ([]byte, error) {
+	if ct := m.Header.Get("Content-Type"); strings.HasPrefix(strings.ToLower(ct), "multipart/") {
+		return nil, errors.New("multipart messages aren't supported, the patch must be inline")
+	}
+
+	var r io.Reader = m.Body
+	switch strings.ToLower(strings.TrimSpace(m.Header.Get("Content-Transfer-Encoding"))) {
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	}
+
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.Replace(body, []byte("\r\n"), []byte("\n"), -1), nil
+}
+
+// splitBody splits the body into the commit message, before the --- line,
+// and the diffs from the first diff --git line. The diffstat between them
+// is dropped.
+func splitBody(body string) (string, string) {
+	text, rest := body, ""
+	if i := strings.Index(body, "\n---\n"); i >= 0 || strings.HasPrefix(body, "---\n") {
+		if i < 0 {
+			text, rest = "", body[len("---\n"):]
+		} else {
+			text, rest = body[:i], body[i+len("\n---\n"):]
+		}
+	}
+
+	if i := strings.Index(rest, "diff --git "); i >= 0 && (i == 0 || rest[i-1] == '\n') {
+		return text, rest[i:]
+	}
+
+	// A message without --- line may still hold diffs.
+	if i := strings.Index(text, "\ndiff --git "); i >= 0 {
+		return text[:i], text[i+1:]
+	}
+
+	return text, ""
+}
+
+// parser reads the diffs of a message line by line.
+type parser struct {
+	s    *bufio.Scanner
+	line string
+	ok   bool
+}
+
+func (ps *parser) next() {
+	ps.ok = ps.s.Scan()
+	ps.line = ps.s.Text()
+}
+
+// parseDiffs reads the diffs of the files, each starting with a diff --git
+// line, up to the -- line of the signature added by git format-patch.
+func parseDiffs(diffs string) ([]*File, error) {
+	ps := &parser{s: bufio.NewScanner(strings.NewReader(diffs))}
+	ps.s.Buffer(nil, 16<<20)
+	ps.next()
+	var files []*File
+	for ps.ok && strings.HasPrefix(ps.line, "diff --git ") {
+		f, err := ps.file()
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, f)
+	}
+
+	if err := ps.s.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// file reads the diff of a file, from its diff --git line.
+func (ps *parser) file() (*File, error) {
+	f := &File{Action: diff.Modified}
+	f.OldPath, f.NewPath = gitPaths(strings.TrimPrefix(ps.line, "diff --git "))
+	ps.next()
+	for ps.ok && !strings.HasPrefix(ps.line, "diff --git ") && !strings.HasPrefix(ps.line, "@@ ") && ps.line != "-- " {
+		if err := f.header(ps.line); err != nil {
+			return nil, fmt.Errorf("%s: %s", f.path(), err)
+		}
+
+		ps.next()
+	}
+
+	if f.NewMode == 0 && f.Action != diff.Deleted {
+		f.NewMode = f.OldMode
+	}
+
+	for ps.ok && strings.HasPrefix(ps.line, "@@ ") {
+		h, err := ps.hunk()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", f.path(), err)
+		}
+
+		f.Hunks = append(f.Hunks, h)
+	}
+
+	for ps.ok && !strings.HasPrefix(ps.line, "diff --git ") {
+		ps.next()
+	}
+
+	f.Submodule = f.OldMode == submoduleMode || f.NewMode == submoduleMode
+	return f, nil
+}
+
+func (f *File) path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+
+	return f.OldPath
+}
+
+// header reads an extended header line of a diff, or its ---, +++ or binary
+// lines.
+func (f *File) header(line string) error {
+	var err error
+	switch {
+	case strings.HasPrefix(line, "old mode "):
+		f.OldMode, err = parseMode(line[len("old mode "):])
+	case strings.HasPrefix(line, "new mode "):
+		f.NewMode, err = parseMode(line[len("new mode "):])
+	case strings.HasPrefix(line, "new file mode "):
+		f.Action, f.OldPath = diff.Added, ""
+		f.NewMode, err = parseMode(line[len("new file mode "):])
+	case strings.HasPrefix(line, "deleted file mode "):
+		f.Action, f.NewPath = diff.Deleted, ""
+		f.OldMode, err = parseMode(line[len("deleted file mode "):])
+	case strings.HasPrefix(line, "rename from "):
+		f.Action, f.OldPath = diff.Renamed, unquote(line[len("rename from "):])
+	case strings.HasPrefix(line, "rename to "):
+		f.Action, f.NewPath = diff.Renamed, unquote(line[len("rename to "):])
+	case strings.HasPrefix(line, "copy from "):
+		f.Action, f.OldPath = diff.Copied, unquote(line[len("copy from "):])
+	case strings.HasPrefix(line, "copy to "):
+		f.Action, f.NewPath = diff.Copied, unquote(line[len("copy to "):])
+	case strings.HasPrefix(line, "similarity index "):
+		f.Similarity, err = strconv.Atoi(strings.TrimSuffix(line[len("similarity index "):], "%"))
+	case strings.HasPrefix(line, "index "):
+		err = f.index(line[len("index "):])
+	case strings.HasPrefix(line, "--- "):
+		if p := diffPath(line[len("--- "):]); p != "" {
+			f.OldPath = p
+		}
+	case strings.HasPrefix(line, "+++ "):
+		if p := diffPath(line[len("+++ "):]); p != "" {
+			f.NewPath = p
+		}
+	case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+		f.Binary = true
+	}
+
+	return err
+}
+
+// index reads the abbreviated hashes of the index line, and the mode of the
+// file when it didn't change.
+func (f *File) index(v string) error {
+	fields := strings.Fields(v)
+	if len(fields) == 0 {
+		return fmt.Errorf("invalid index line %q", v)
+	}
+
+	hashes := strings.SplitN(fields[0], "..", 2)
+	if len(hashes) != 2 {
+		return fmt.Errorf("invalid index line %q", v)
+	}
+
+	f.OldHash, f.NewHash = hashes[0], hashes[1]
+	if len(fields) > 1 {
+		mode, err := parseMode(fields[1])
+		if err != nil {
+			return err
+		}
+
+		f.OldMode, f.NewMode = mode, mode
+	}
+
+	return nil
+}
+
+// hunk reads a hunk, from its @@ line, and the lines it counts.
+func (ps *parser) hunk() (diff.Hunk, error) {
+	m := hunkHeader.FindStringSubmatch(ps.line)
+	if m == nil {
+		return diff.Hunk{}, fmt.Errorf("invalid hunk header %q", ps.line)
+	}
+
+	h := diff.Hunk{
+		OldStart: atoi(m[1]),
+		OldLines: count(m[2]),
+		NewStart: atoi(m[3]),
+		NewLines: count(m[4]),
+	}
+
+	old, new := h.OldStart, h.NewStart
+	if h.OldLines == 0 {
+		old++
+	}
+
+	if h.NewLines == 0 {
+		new++
+	}
+
+	oldLeft, newLeft := h.OldLines, h.NewLines
+	ps.next()
+	for ps.ok && (oldLeft > 0 || newLeft > 0 || strings.HasPrefix(ps.line, `\`)) {
+		line := ps.line
+		if strings.HasPrefix(line, `\`) {
+			if len(h.Lines) > 0 {
+				h.Lines[len(h.Lines)-1].NoNewline = true
+			}
+
+			ps.next()
+			continue
+		}
+
+		// Some mailers strip the trailing space of empty context lines.
+		if line == "" {
+			line = " "
+		}
+
+		l := diff.Line{Kind: diff.LineKind(line[0]), Text: line[1:]}
+		switch l.Kind {
+		case diff.Context:
+			l.OldLine, l.NewLine = old, new
+			old, new, oldLeft, newLeft = old+1, new+1, oldLeft-1, newLeft-1
+		case diff.Delete:
+			l.OldLine = old
+			old, oldLeft = old+1, oldLeft-1
+		case diff.Insert:
+			l.NewLine = new
+			new, newLeft = new+1, newLeft-1
+		default:
+			return diff.Hunk{}, fmt.Errorf("invalid line %q in hunk %s", ps.line, h.Header())
+		}
+
+		if oldLeft < 0 || newLeft < 0 {
+			return diff.Hunk{}, fmt.Errorf("hunk %s has more lines than counted", h.Header())
+		}
+
+		h.Lines = append(h.Lines, l)
+		ps.next()
+	}
+
+	if oldLeft > 0 || newLeft > 0 {
+		return diff.Hunk{}, fmt.Errorf("hunk %s is truncated", h.Header())
+	}
+
+	return h, nil
+}
+
+// gitPaths returns the paths of the a/ and b/ sides of a diff --git line.
+// Unquoted paths with spaces are split in the middle, the paths being the
+// same unless the file is renamed, which the rename lines give then.
+func gitPaths(v string) (string, string) {
+	if strings.HasPrefix(v, `"`) {
+		if end := closingQuote(v); end > 0 {
+			a := unquote(v[:end+1])
+			b := unquote(strings.TrimSpace(v[end+1:]))
+			return strings.TrimPrefix(a, "a/"), strings.TrimPrefix(b, "b/")
+		}
+	}
+
+	if (len(v)-1)%2 == 0 {
+		half := (len(v) - 1) / 2
+		a, b := v[:half], v[half+1:]
+		if strings.HasPrefix(a, "a/") && strings.HasPrefix(b, "b/") && a[2:] == b[2:] {
+			return a[2:], b[2:]
+		}
+	}
+
+	if i := strings.Index(v, " b/"); i >= 0 {
+		return strings.TrimPrefix(v[:i], "a/"), unquote(v[i+len(" b/"):])
+	}
+
+	return v, v
+}
+
+// diffPath returns the path of a --- or +++ line, without its a/ or b/
+// prefix, empty for /dev/null.
+func diffPath(v string) string {
+	if i := strings.IndexByte(v, '\t'); i >= 0 {
+		v = v[:i]
+	}
+
+	v = unquote(v)
+	if v == "/dev/null" {
+		return ""
+	}
+
+	if strings.HasPrefix(v, "a/") || strings.HasPrefix(v, "b/") {
+		return v[2:]
+	}
+
+	return v
+}
+
+// unquote removes the C-style quotes of the paths with special characters,
+// as git quotes them, \303\251 being the bytes of an é.
+func unquote(v string) string {
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return v
+	}
+
+	if s, err := strconv.Unquote(v); err == nil {
+		return s
+	}
+
+	return v
+}
+
+// closingQuote returns the index of the quote closing the string starting
+// with a quote, -1 if there is none.
+func closingQuote(v string) int {
+	for i := 1; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+func parseMode(v string) (os.FileMode, error) {
+	m, err := strconv.ParseUint(strings.TrimSpace(v), 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mode %q", v)
+	}
+
+	return os.FileMode(m), nil
+}
+
+func atoi(v string) int {
+	n, _ := strconv.Atoi(v)
+	return n
+}
+
+// count returns the number of lines of a hunk range, 1 when omitted.
+func count(v string) int {
+	if v == "" {
+		return 1
+	}
+
+	return atoi(v)
+}
@@ -0,0 +1,146 @@
+package patch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunjan5/code-review-bot/diff"
+)
+
+func TestParseEmptyIndexLine(t *testing.T) {
+	in := "From:<0@0>\nDAte:1 JAn 00 0:00 +00\n\n\ndiff --git \nindex "
+	if _, err := Parse(strings.NewReader(in)); err == nil {
+		t.Fatal("Parse succeeded on an index line without hashes")
+	}
+}
+
+func TestBuildInvalidPath(t *testing.T) {
+	in := "From 0 Mon Sep 17 00:00:00 2001\nFrom: A <a@b>\nDate: Mon, 1 Jan 2018 00:00:00 +0000\nSubject: [PATCH] x\n\n---\ndiff --git /a /a\nnew file mode 100644\n"
+	patches, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Build(patches); err == nil {
+		t.Fatal("Build succeeded on an absolute path")
+	}
+}
+
+// describeFile returns the change of the file as "action old -> new",
+// followed by the modes, the similarity, the number of hunks and whether
+// it is binary.
+func describeFile(f *File) string {
+	return fmt.Sprintf("%s %s -> %s %o -> %o %d%% hunks=%d binary=%v", f.Action, f.OldPath, f.NewPath, uint32(f.OldMode), uint32(f.NewMode), f.Similarity, len(f.Hunks), f.Binary)
+}
+
+func parseSeries(t *testing.T) []*Patch {
+	f, err := os.Open(filepath.Join("testdata", "series.mbox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	patches, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return patches
+}
+
+func TestParse(t *testing.T) {
+	patches := parseSeries(t)
+	cet := time.FixedZone("", 3600)
+	want := []struct {
+		hash, author string
+		when         time.Time
+		message      string
+		files        []string
+	}{
+		{
+			"25fc329f06edc668df2add4e3d0714d2430923e1", "Jane Doe <jane@example.com>", time.Date(2018, 1, 3, 10, 0, 0, 0, cet),
+			"Update the README and add main\n\nThe README gets two fixes far apart, making two hunks.\n",
+			[]string{
+				"modified README -> README 100644 -> 100644 0% hunks=2 binary=false",
+				"added  -> new.go 0 -> 100644 0% hunks=1 binary=false",
+			},
+		},
+		{
+			// The brackets other than [PATCH] are kept.
+			"38c7eaa999fb33cb175364dd26bfa211d03ea230", "Bob <bob@example.com>", time.Date(2018, 1, 4, 10, 0, 0, 0, cet),
+			"[JIRA-12] Move A and B, drop old.txt\n",
+			[]string{
+				"renamed lib/a.go -> lib/b.go 100644 -> 100644 50% hunks=1 binary=false",
+				"deleted old.txt ->  100644 -> 0 0% hunks=1 binary=false",
+				"modified run.sh -> run.sh 100644 -> 100755 0% hunks=0 binary=false",
+			},
+		},
+		{
+			"757a7b8c1441a9e6b49b839ba598c878bb6b1f14", "Jane Doe <jane@example.com>", time.Date(2018, 1, 5, 10, 0, 0, 0, cet),
+			"Add the logo\n",
+			[]string{"added  -> logo.png 0 -> 100644 0% hunks=0 binary=true"},
+		},
+	}
+
+	if len(patches) != len(want) {
+		t.Fatalf("Parse read %d patches, want %d without the cover letter", len(patches), len(want))
+	}
+
+	for i, p := range patches {
+		w := want[i]
+		author := fmt.Sprintf("%s <%s>", p.Author.Name, p.Author.Email)
+		if p.Hash != w.hash || author != w.author || !p.Author.When.Equal(w.when) || p.Message != w.message {
+			t.Errorf("patch %d = %s by %s at %s: %q, want %s by %s at %s: %q", i+1, p.Hash, author, p.Author.When, p.Message, w.hash, w.author, w.when, w.message)
+		}
+
+		var files []string
+		for _, f := range p.Files {
+			files = append(files, describeFile(f))
+		}
+
+		if !reflect.DeepEqual(files, w.files) {
+			t.Errorf("patch %d files =\n%s\nwant\n%s", i+1, strings.Join(files, "\n"), strings.Join(w.files, "\n"))
+		}
+	}
+
+	// The hunks keep their line numbers on both sides.
+	h := patches[0].Files[0].Hunks[1]
+	if h.Header() != "@@ -15,6 +15,6 @@" || len(h.Lines) != 7 || h.Lines[3] != (diff.Line{Kind: diff.Delete, Text: "line 18", OldLine: 18}) ||
+		h.Lines[4] != (diff.Line{Kind: diff.Insert, Text: "line eighteen", NewLine: 18}) {
+		t.Errorf("second hunk of README = %s %+v", h.Header(), h.Lines)
+	}
+}
+
+func TestParseSubjects(t *testing.T) {
+	for _, tc := range []struct {
+		subject, want string
+	}{
+		{"[PATCH] Fix it", "Fix it"},
+		{"[PATCH 2/3] Fix it", "Fix it"},
+		{"[PATCH v2 03/12] Fix it", "Fix it"},
+		{"[RFC PATCH] Fix it", "Fix it"},
+		{"Re: [PATCH 1/2] Fix it", "Fix it"},
+		{"[PATCH] [JIRA-12] Fix it", "[JIRA-12] Fix it"},
+		{"Fix [PATCH] handling", "Fix [PATCH] handling"},
+		{"=?UTF-8?q?[PATCH]_Fix_J=C3=BCrgen's_bug?=", "Fix Jürgen's bug"},
+	} {
+		in := "From: A <a@example.com>\nDate: Mon, 1 Jan 2018 00:00:00 +0000\nSubject: " + tc.subject + "\n\nBody\n---\ndiff --git a/a b/a\n"
+		patches, err := Parse(strings.NewReader(in))
+		if err != nil || len(patches) != 1 || patches[0].Message != tc.want+"\n\nBody\n" {
+			t.Errorf("Parse of the subject %q = %+v, %v, want %q", tc.subject, patches, err, tc.want)
+		}
+	}
+
+	// A cover letter alone holds no patch.
+	for _, subject := range []string{"[PATCH 0/3] Series", "[PATCH v2 00/12] Series"} {
+		in := "From 0123456789012345678901234567890123456789 Mon Sep 17 00:00:00 2001\nFrom: A <a@example.com>\nDate: Mon, 1 Jan 2018 00:00:00 +0000\nSubject: " + subject + "\n\nBlurb\n"
+		if patches, err := Parse(strings.NewReader(in)); err != ErrNoPatch {
+			t.Errorf("Parse of the cover letter %q = %+v, %v, want %v", subject, patches, err, ErrNoPatch)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/patch"
+	"github.com/gunjan5/code-review-bot/review"
+)
+
+// openPatch rebuilds the commits of the mailbox or git format-patch file
+// name, - for stdin, and sets the options to review them: the commits after
+// the base of the series, and only the files whose whole content the
+// patches hold, the others being skipped by the commit checks reading whole
+// files.
+func openPatch(name string, o *review.Options) (*patch.Series, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	patches, err := patch.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+
+	s, err := patch.Build(patches)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+
+	o.SinceCommit = s.Base.String()
+	o.ChangedFiles = append([]string{}, s.Complete...)
+	o.PartialFiles = append([]string{}, s.Partial...)
+	if err := review.Validate(s.Repo, o); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+
+	return s, nil
+}
+
+// patchResult attributes the violations of the rebuilt commits to the
+// commits the patches were made from, in their messages too, and warns about
+// the files that couldn't be checked.
+func patchResult(s *patch.Series, res *review.Result) {
+	orig := make(map[string]string)
+	var replace []string
+	for h, o := range s.Hashes {
+		orig[h.String()] = o
+		replace = append(replace, h.String()[:7], o[:7])
+	}
+
+	r := strings.NewReplacer(replace...)
+	for i, v := range res.Violations {
+		if o, ok := orig[v.Commit]; ok {
+			res.Violations[i].Commit = o
+		}
+
+		res.Violations[i].Message = r.Replace(v.Message)
+	}
+
+	if o, ok := orig[res.Commit]; ok {
+		res.Commit = o
+	}
+
+	for _, name := range s.Partial {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("%s not checked, the patches only hold the lines of its hunks", name))
+	}
+}
@@ -0,0 +1,155 @@
+// Package plugin runs external checks written in any language.
+//
+// A plugin is an executable run once for every reviewed file. It receives a
+// JSON encoded Spec as its only argument and the content of the file on its
+// standard input, and writes a JSON array of Results to its standard output,
+// an empty array if the file is fine. A plugin exiting with a non-zero status
+// or writing anything else is reported as a violation of the plugin rule.
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// ProtocolVersion is the version of the protocol sent in the Spec, bumped on
+// incompatible changes.
+const ProtocolVersion = 1
+
+// ErrorRule is the rule of the violations reporting a failing plugin.
+const ErrorRule = "plugin"
+
+// Spec describes the file given to a plugin.
+type Spec struct {
+	Version int    `json:"version"`
+	Path    string `json:"path"`
+	// Mode is the octal mode of the tree entry, e.g. 100755.
+	Mode string `json:"mode"`
+	Size int64  `json:"size"`
+}
+
+// Result is a violation found by a plugin. Only Message is required: Rule
+// defaults to the name of the plugin, Severity to warning and Path to the
+// path of the checked file. DocURL is the page explaining the violation.
+type Result struct {
+	Rule     string           `json:"rule"`
+	Severity *report.Severity `json:"severity"`
+	Path     string           `json:"path"`
+	Line     int              `json:"line"`
+	Message  string           `json:"message"`
+	DocURL   string           `json:"doc_url"`
+}
+
+// Plugin is an external check.
+type Plugin struct {
+	// Path is the path of the executable.
+	Path string
+}
+
+// Name returns the name of the plugin, the base name of its executable
+// without extension.
+func (p *Plugin) Name() string {
+	base := filepath.Base(p.Path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Check runs the plugin on f. Failures of the plugin are returned as a
+// violation, errors are only returned if the file can't be read.
+func (p *Plugin) Check(f *git.File) ([]report.Violation, error) {
+	r, err := f.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	spec, err := json.Marshal(Spec{
+		Version: ProtocolVersion,
+		Path:    f.Name,
+		Mode:    fmt.Sprintf("%o", uint32(f.Mode)),
+		Size:    f.Size,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.Path, string(spec))
+	cmd.Stdin = r
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return p.failure(f, "%s%s", err, diagnostic(&stderr)), nil
+	}
+
+	var results []Result
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		return p.failure(f, "invalid output: %s%s", err, diagnostic(&stderr)), nil
+	}
+
+	vs := make([]report.Violation, 0, len(results))
+	for _, res := range results {
+		if res.Message == "" {
+			return p.failure(f, "invalid output: result without message"), nil
+		}
+
+		vs = append(vs, p.violation(f, res))
+	}
+
+	return vs, nil
+}
+
+func (p *Plugin) violation(f *git.File, res Result) report.Violation {
+	v := report.Violation{
+		Rule:     res.Rule,
+		Severity: report.Warning,
+		Path:     res.Path,
+		Line:     res.Line,
+		Message:  res.Message,
+		DocURL:   res.DocURL,
+	}
+
+	if v.Rule == "" {
+		v.Rule = p.Name()
+	}
+
+	if res.Severity != nil {
+		v.Severity = *res.Severity
+	}
+
+	if v.Path == "" {
+		v.Path = f.Name
+	}
+
+	return v
+}
+
+func (p *Plugin) failure(f *git.File, format string, args ...interface{}) []report.Violation {
+	return []report.Violation{{
+		Rule:     ErrorRule,
+		Severity: report.Error,
+		Path:     f.Name,
+		Message:  fmt.Sprintf("plugin %s failed: ", p.Path) + fmt.Sprintf(format, args...),
+	}}
+}
+
+// diagnostic returns the first line written by the plugin to its standard
+// error, to be appended to a failure message.
+func diagnostic(stderr io.Reader) string {
+	var buf bytes.Buffer
+	buf.ReadFrom(stderr)
+	line := strings.TrimSpace(strings.SplitN(buf.String(), "\n", 2)[0])
+	if line == "" {
+		return ""
+	}
+
+	return ": " + line
+}
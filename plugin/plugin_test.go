@@ -0,0 +1,179 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// testFile returns a file of the content, out of any repository.
+func testFile(t *testing.T, name string, mode os.FileMode, content string) *git.File {
+	obj := &core.MemoryObject{}
+	obj.SetType(core.BlobObject)
+	obj.SetSize(int64(len(content)))
+	if _, err := obj.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &git.Blob{}
+	if err := b.Decode(obj); err != nil {
+		t.Fatal(err)
+	}
+
+	return git.NewFile(name, mode, b)
+}
+
+// testPlugins writes the shell scripts, mapping names to their body, to a
+// temporary directory and returns it with a function removing it.
+func testPlugins(t *testing.T, scripts map[string]string) (string, func()) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, body := range scripts {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestCheck(t *testing.T) {
+	dir, remove := testPlugins(t, map[string]string{
+		"todo.sh": `grep -n TODO | while IFS=: read n rest; do
+	printf '{"line": %d, "message": "TODO left"}\n' "$n"
+done | paste -sd, - | sed 's/^/[/; s/$/]/'
+`,
+		"full":  `echo '[{"rule": "no-foo", "severity": "error", "path": "other.txt", "line": 3, "message": "foo", "doc_url": "https://example.com/foo"}]'`,
+		"clean": `cat >/dev/null; echo '[]'`,
+	})
+	defer remove()
+
+	f := testFile(t, "a.txt", 0644, "one\nTODO two\nthree\nTODO four\n")
+	for _, tc := range []struct {
+		plugin string
+		want   []report.Violation
+	}{
+		{"todo.sh", []report.Violation{
+			{Rule: "todo", Severity: report.Warning, Path: "a.txt", Line: 2, Message: "TODO left"},
+			{Rule: "todo", Severity: report.Warning, Path: "a.txt", Line: 4, Message: "TODO left"},
+		}},
+		{"full", []report.Violation{
+			{Rule: "no-foo", Severity: report.Error, Path: "other.txt", Line: 3, Message: "foo", DocURL: "https://example.com/foo"},
+		}},
+		{"clean", []report.Violation{}},
+	} {
+		p := &Plugin{Path: filepath.Join(dir, tc.plugin)}
+		vs, err := p.Check(f)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("plugin %s = %+v, %v, want %+v", tc.plugin, vs, err, tc.want)
+		}
+	}
+}
+
+func TestCheckInput(t *testing.T) {
+	dir, remove := testPlugins(t, map[string]string{
+		"echo": `out=$(dirname "$0")/out
+printf '%s\n' "$#" "$1" >"$out"
+cat >>"$out"
+echo '[]'
+`,
+	})
+	defer remove()
+
+	p := &Plugin{Path: filepath.Join(dir, "echo")}
+	content := "#!/bin/sh\necho hi\n"
+	if _, err := p.Check(testFile(t, "bin/run", 0755, content)); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.SplitN(string(out), "\n", 3)
+	if parts[0] != "1" {
+		t.Errorf("plugin run with %s arguments, want 1", parts[0])
+	}
+
+	var spec Spec
+	if err := json.Unmarshal([]byte(parts[1]), &spec); err != nil {
+		t.Fatalf("spec %q: %s", parts[1], err)
+	}
+
+	want := Spec{Version: ProtocolVersion, Path: "bin/run", Mode: "755", Size: int64(len(content))}
+	if spec != want {
+		t.Errorf("spec = %+v, want %+v", spec, want)
+	}
+
+	if parts[2] != content {
+		t.Errorf("standard input = %q, want %q", parts[2], content)
+	}
+}
+
+func TestCheckFailures(t *testing.T) {
+	dir, remove := testPlugins(t, map[string]string{
+		"exit":    `echo 'cannot parse the file' >&2; echo 'more details' >&2; exit 3`,
+		"crash":   `kill -KILL $$`,
+		"garbage": `echo 'not json'`,
+		"object":  `echo '{"message": "not an array"}'`,
+		"empty":   `echo '[{"line": 1}]'`,
+		"silent":  `exit 0`,
+	})
+	defer remove()
+
+	f := testFile(t, "a.txt", 0644, "a\n")
+	for _, tc := range []struct {
+		plugin, want string
+	}{
+		{"exit", "exit status 3: cannot parse the file"},
+		{"crash", "signal: killed"},
+		{"garbage", "invalid output: invalid character 'o' in literal null (expecting 'u')"},
+		{"object", "invalid output: json: cannot unmarshal object into Go value of type []plugin.Result"},
+		{"empty", "invalid output: result without message"},
+		{"silent", "invalid output: unexpected end of JSON input"},
+		{"missing", "fork/exec " + filepath.Join(dir, "missing") + ": no such file or directory"},
+	} {
+		path := filepath.Join(dir, tc.plugin)
+		vs, err := (&Plugin{Path: path}).Check(f)
+		want := []report.Violation{{
+			Rule:     ErrorRule,
+			Severity: report.Error,
+			Path:     "a.txt",
+			Message:  "plugin " + path + " failed: " + tc.want,
+		}}
+
+		if err != nil || !reflect.DeepEqual(vs, want) {
+			t.Errorf("plugin %s = %+v, %v, want %+v", tc.plugin, vs, err, want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	for path, want := range map[string]string{
+		"/usr/lib/checks/todo.sh": "todo",
+		"checks/license":          "license",
+		"lint.v2.py":              "lint.v2",
+	} {
+		if got := (&Plugin{Path: path}).Name(); got != want {
+			t.Errorf("Name(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
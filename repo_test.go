@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// gitRepo is a repository created on disk by the git command for the tests.
+type gitRepo struct {
+	t   *testing.T
+	dir string
+	// commits counts the commits, dating each one a minute after the
+	// previous one.
+	commits int
+}
+
+// newGitRepo creates an empty repository in a temporary directory, removed
+// by the returned function. The test is skipped if git isn't installed.
+func newGitRepo(t *testing.T) (*gitRepo, func()) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &gitRepo{t: t, dir: dir}
+	r.git("init", "-q", "-b", "main")
+	return r, func() { os.RemoveAll(dir) }
+}
+
+// git runs git in the repository and returns its output.
+func (r *gitRepo) git(args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	when := fmt.Sprintf("%d +0000", 1500000000+60*r.commits)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=A", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_AUTHOR_DATE="+when,
+		"GIT_COMMITTER_NAME=A", "GIT_COMMITTER_EMAIL=a@example.com", "GIT_COMMITTER_DATE="+when,
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+r.dir)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %q: %s\n%s", args, err, out)
+	}
+
+	return string(out)
+}
+
+// write writes the files of the working tree, mapping their names to their
+// contents.
+func (r *gitRepo) write(files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(r.dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			r.t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+}
+
+// commit writes and commits the files with the message and returns the hash
+// of the commit.
+func (r *gitRepo) commit(files map[string]string, msg string) string {
+	r.write(files)
+	r.git("add", "-A")
+	r.commits++
+	r.git("commit", "-q", "--allow-empty", "-m", msg)
+	return r.head()
+}
+
+func (r *gitRepo) head() string {
+	out := r.git("rev-parse", "HEAD")
+	return out[:len(out)-1]
+}
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Baseline is the set of fingerprints of violations that have been accepted
+// and must not be reported again.
+type Baseline map[string]bool
+
+// LoadBaseline reads a baseline file. Each line starts with a fingerprint,
+// anything after it as well as blank lines and lines starting with '#' are
+// ignored.
+func LoadBaseline(path string) (Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := make(Baseline)
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		b[strings.Fields(line)[0]] = true
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("reading baseline %s: %s", path, err)
+	}
+
+	return b, nil
+}
+
+// WriteBaseline writes a baseline file accepting all the given violations.
+// The rule and location are written next to each fingerprint to make the
+// file reviewable.
+func WriteBaseline(path string, vs []Violation) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "# code-review-bot baseline, regenerate with -write-baseline")
+	seen := make(map[string]bool)
+	for i := range vs {
+		fp := vs[i].Fingerprint()
+		if seen[fp] {
+			continue
+		}
+
+		seen[fp] = true
+		fmt.Fprintf(w, "%s %s %s\n", fp, vs[i].Rule, vs[i].Location())
+	}
+
+	return w.Flush()
+}
+
+// Filter returns the violations whose fingerprint is not in the baseline.
+func (b Baseline) Filter(vs []Violation) []Violation {
+	var out []Violation
+	for _, v := range vs {
+		if b[v.Fingerprint()] {
+			continue
+		}
+
+		out = append(out, v)
+	}
+
+	return out
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBaselineRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "baseline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	accepted := []Violation{
+		{Rule: "trailing-whitespace", Path: "a.go", Line: 3, Message: "line ends with whitespace"},
+		{Rule: "trailing-whitespace", Path: "a.go", Line: 3, Message: "line ends with whitespace"},
+		{Rule: "todo-comment", Path: "b.go", Line: 10, Message: "TODO comment"},
+		{Rule: "subject-length", Commit: "abc123", Message: "subject is 80 characters long, the limit is 72"},
+	}
+
+	path := filepath.Join(dir, "baseline")
+	if err := WriteBaseline(path, accepted); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "#") || !strings.HasSuffix(lines[1], " trailing-whitespace a.go:3") {
+		t.Errorf("baseline of the duplicates written once with their location:\n%s", data)
+	}
+
+	b, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := []Violation{
+		// Moved down by an edit above it.
+		{Rule: "trailing-whitespace", Path: "a.go", Line: 8, Message: "line ends with whitespace"},
+		{Rule: "todo-comment", Path: "b.go", Line: 10, Message: "TODO comment"},
+		{Rule: "subject-length", Commit: "abc123", Message: "subject is 80 characters long, the limit is 72"},
+		// New findings.
+		{Rule: "trailing-whitespace", Path: "c.go", Line: 3, Message: "line ends with whitespace"},
+		{Rule: "todo-comment", Path: "b.go", Line: 12, Message: "FIXME comment"},
+		{Rule: "subject-length", Commit: "def456", Message: "subject is 80 characters long, the limit is 72"},
+	}
+
+	if got := b.Filter(next); !reflect.DeepEqual(got, next[3:]) {
+		t.Errorf("Filter = %+v, want the new findings %+v", got, next[3:])
+	}
+}
+
+func TestLoadBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "baseline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "baseline")
+	data := "# comment\n\n  abc rule a.go:1\ndef\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := LoadBaseline(path)
+	if want := (Baseline{"abc": true, "def": true}); err != nil || !reflect.DeepEqual(b, want) {
+		t.Errorf("LoadBaseline(%q) = %v, %v, want %v", data, b, err, want)
+	}
+
+	if _, err := LoadBaseline(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("LoadBaseline of a missing file = %v, want a not found error", err)
+	}
+}
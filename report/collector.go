@@ -0,0 +1,75 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Collector gathers the violations reported by checks, possibly running
+// concurrently, for the formatters. Its zero value is ready to use.
+type Collector struct {
+	mu sync.Mutex
+	vs []Violation
+}
+
+// Add adds the violations to the collector.
+func (c *Collector) Add(vs ...Violation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vs = append(c.vs, vs...)
+}
+
+// Len returns the number of violations collected.
+func (c *Collector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.vs)
+}
+
+// Violations returns the violations collected without duplicates, those
+// located in a file first, sorted by path, line, column and rule, then those
+// of commits in the order they were added. Violations are duplicates when
+// they have the same fingerprint at the same location, as when reported by
+// overlapping checks.
+func (c *Collector) Violations() []Violation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var vs []Violation
+	for i := range c.vs {
+		v := &c.vs[i]
+		key := fmt.Sprintf("%s\x00%s\x00%s\x00%d:%d", v.Fingerprint(), v.Repo, v.Commit, v.Line, v.Column)
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		vs = append(vs, *v)
+	}
+
+	sort.Stable(byLocation(vs))
+	return vs
+}
+
+type byLocation []Violation
+
+func (s byLocation) Len() int      { return len(s) }
+func (s byLocation) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s byLocation) Less(i, j int) bool {
+	a, b := &s[i], &s[j]
+	switch {
+	case a.Path == "" || b.Path == "":
+		return a.Path != "" && b.Path == ""
+	case a.Target() != b.Target():
+		return a.Target() < b.Target()
+	case a.Line != b.Line:
+		return a.Line < b.Line
+	case a.Column != b.Column:
+		return a.Column < b.Column
+	default:
+		return a.Rule < b.Rule
+	}
+}
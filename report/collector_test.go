@@ -0,0 +1,103 @@
+package report
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCollectorConcurrentAdd(t *testing.T) {
+	var c Collector
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Add(Violation{Rule: "r", Path: fmt.Sprintf("f%d", i), Line: j + 1, Message: "m"})
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	if n := c.Len(); n != 800 {
+		t.Errorf("Len() = %d, want 800", n)
+	}
+
+	if n := len(c.Violations()); n != 800 {
+		t.Errorf("len(Violations()) = %d, want 800", n)
+	}
+}
+
+func TestCollectorOrder(t *testing.T) {
+	var c Collector
+	c.Add(
+		Violation{Rule: "subject", Commit: "bbbbbbbbbb", Message: "m"},
+		Violation{Rule: "b", Path: "z.go", Line: 1, Message: "m"},
+		Violation{Rule: "a", Path: "a.go", Line: 2, Column: 5, Message: "m"},
+		Violation{Rule: "subject", Commit: "aaaaaaaaaa", Message: "m"},
+		Violation{Rule: "b", Path: "a.go", Line: 2, Column: 1, Message: "m"},
+		Violation{Rule: "a", Path: "a.go", Line: 10, Message: "m"},
+		Violation{Rule: "b", Path: "a.go", Line: 2, Column: 5, Message: "m"},
+		Violation{Rule: "size", Path: "a.go", Message: "m"},
+	)
+
+	want := []Violation{
+		{Rule: "size", Path: "a.go", Message: "m"},
+		{Rule: "b", Path: "a.go", Line: 2, Column: 1, Message: "m"},
+		{Rule: "a", Path: "a.go", Line: 2, Column: 5, Message: "m"},
+		{Rule: "b", Path: "a.go", Line: 2, Column: 5, Message: "m"},
+		{Rule: "a", Path: "a.go", Line: 10, Message: "m"},
+		{Rule: "b", Path: "z.go", Line: 1, Message: "m"},
+		{Rule: "subject", Commit: "bbbbbbbbbb", Message: "m"},
+		{Rule: "subject", Commit: "aaaaaaaaaa", Message: "m"},
+	}
+
+	if got := c.Violations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Violations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCollectorDuplicates(t *testing.T) {
+	var c Collector
+	v := Violation{Rule: "r", Path: "a.go", Line: 3, Column: 2, Message: "m", Commit: "abc"}
+	dup := v
+	dup.Diff = "diff of the second report"
+	dup.Severity = Error
+	c.Add(v, dup)
+
+	otherLine := v
+	otherLine.Line = 4
+	otherColumn := v
+	otherColumn.Column = 3
+	otherCommit := v
+	otherCommit.Commit = "def"
+	otherRepo := v
+	otherRepo.Repo = "other"
+	otherMessage := v
+	otherMessage.Message = "other"
+	c.Add(otherLine, otherColumn, otherCommit, otherRepo, otherMessage, otherLine)
+
+	commit := Violation{Rule: "subject", Commit: "abc", Message: "m"}
+	c.Add(commit, commit)
+
+	got := c.Violations()
+	if len(got) != 7 {
+		t.Fatalf("Violations() = %+v, want 7 violations", got)
+	}
+
+	if !reflect.DeepEqual(got[0], v) {
+		t.Errorf("Violations() = %+v, want the first report of the duplicates kept", got)
+	}
+
+	for _, w := range got {
+		if w.Diff != "" {
+			t.Errorf("Violations() kept the duplicate %+v", w)
+		}
+	}
+
+	if c.Len() != 10 {
+		t.Errorf("Len() = %d, want the 10 violations added", c.Len())
+	}
+}
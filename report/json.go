@@ -0,0 +1,22 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteJSON writes the violations as an indented JSON array.
+func WriteJSON(w io.Writer, vs []Violation) error {
+	if vs == nil {
+		vs = []Violation{}
+	}
+
+	b, err := json.MarshalIndent(vs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
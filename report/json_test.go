@@ -0,0 +1,47 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "[]\n" {
+		t.Errorf("WriteJSON(nil) = %q, want an empty array", buf.String())
+	}
+
+	vs := []Violation{
+		{Rule: "trailing-whitespace", Severity: Warning, Path: "main.go", Line: 3, Column: 14, Message: "trailing whitespace"},
+		{Rule: "subject-length", Severity: Error, Commit: "0be77e079c17b4c95ddf68762338071427f66360", Message: "subject too long", DocURL: "https://example.com/subject"},
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, vs); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Violation
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, vs) {
+		t.Errorf("WriteJSON round trip = %+v, want %+v", got, vs)
+	}
+
+	var raw []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	if raw[0]["severity"] != "warning" || raw[0]["commit"] != nil || raw[1]["path"] != nil {
+		t.Errorf("WriteJSON wrote %s, want severities by name and empty fields left out", buf.String())
+	}
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+)
+
+// junitSuite is the name of the test suite of the JUnit reports.
+const junitSuite = "code-review-bot"
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the violations as a JUnit XML report, with a test case
+// per file or commit failing once for each of its violations. The checked
+// paths without violations are reported as passing test cases.
+func WriteJUnit(w io.Writer, paths []string, vs []Violation) error {
+	failures := make(map[string][]junitFailure)
+	for _, path := range paths {
+		failures[path] = nil
+	}
+
+	for i := range vs {
+		v := &vs[i]
+		failures[v.Target()] = append(failures[v.Target()], junitFailure{
+			Type:    v.Severity.String(),
+			Message: v.Message,
+			Text:    v.String(),
+		})
+	}
+
+	targets := make([]string, 0, len(failures))
+	for t := range failures {
+		targets = append(targets, t)
+	}
+
+	sort.Strings(targets)
+	suite := junitTestSuite{Name: junitSuite, Tests: len(targets)}
+	for _, t := range targets {
+		if len(failures[t]) > 0 {
+			suite.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      t,
+			ClassName: junitSuite,
+			Failures:  failures[t],
+		})
+	}
+
+	b, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,51 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	paths := []string{"README.md", "main.go", "build.sh"}
+	vs := []Violation{
+		{Rule: "trailing-whitespace", Severity: Warning, Path: "main.go", Line: 12, Message: "trailing whitespace"},
+		{Rule: "line-length", Severity: Info, Path: "main.go", Line: 3, Message: `line is 130 characters long, "max" is 120 & <more>`},
+		{Rule: "script-crlf", Severity: Error, Path: "build.sh", Line: 1, Message: "script has CRLF line endings"},
+		{Rule: "subject-length", Severity: Warning, Commit: "0be77e079c17b4c95ddf68762338071427f66360", Message: "subject too long"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, paths, vs); err != nil {
+		t.Fatal(err)
+	}
+
+	golden(t, "junit.golden", buf.Bytes())
+
+	var suite junitTestSuite
+	if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+		t.Fatal(err)
+	}
+
+	if suite.Tests != 4 || suite.Failures != 3 || len(suite.TestCases) != 4 {
+		t.Errorf("suite of %d tests and %d failures, want 4 and 3", suite.Tests, suite.Failures)
+	}
+
+	for _, tc := range suite.TestCases {
+		if tc.Name == "main.go" && (len(tc.Failures) != 2 || tc.Failures[1].Message != vs[1].Message) {
+			t.Errorf("main.go failures read back as %+v, want the escaped message %q", tc.Failures, vs[1].Message)
+		}
+	}
+}
+
+func TestWriteJUnitEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := xml.Header + `<testsuite name="code-review-bot" tests="0" failures="0"></testsuite>` + "\n"
+	if buf.String() != want {
+		t.Errorf("WriteJUnit() = %q, want %q", buf.String(), want)
+	}
+}
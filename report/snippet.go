@@ -0,0 +1,63 @@
+package report
+
+import (
+	"fmt"
+	"io"
+)
+
+// Snippet is an excerpt of the file around the line of a violation.
+type Snippet struct {
+	// Start is the number of the first line of the excerpt.
+	Start int `json:"start"`
+	// Target is the number of the line the violation refers to.
+	Target int      `json:"target"`
+	Lines  []string `json:"lines"`
+}
+
+// NewSnippet returns the excerpt of lines made of the target line, counting
+// from one, and up to context lines before and after it. It returns nil if
+// target is out of range.
+func NewSnippet(lines []string, target, context int) *Snippet {
+	if target < 1 || target > len(lines) {
+		return nil
+	}
+
+	if context < 0 {
+		context = 0
+	}
+
+	start := target - context
+	if start < 1 {
+		start = 1
+	}
+
+	end := target + context
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	return &Snippet{
+		Start:  start,
+		Target: target,
+		Lines:  lines[start-1 : end],
+	}
+}
+
+// Write writes the excerpt with line numbers, marking the target line.
+func (s *Snippet) Write(w io.Writer, indent string) error {
+	last := s.Start + len(s.Lines) - 1
+	width := len(fmt.Sprint(last))
+	for i, l := range s.Lines {
+		n := s.Start + i
+		mark := " "
+		if n == s.Target {
+			mark = ">"
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%s %*d | %s\n", indent, mark, width, n, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
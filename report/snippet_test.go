@@ -0,0 +1,55 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestNewSnippet(t *testing.T) {
+	lines := []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+	for _, tc := range []struct {
+		target, context int
+		want            *Snippet
+	}{
+		{1, 2, &Snippet{Start: 1, Target: 1, Lines: lines[0:3]}},
+		{2, 2, &Snippet{Start: 1, Target: 2, Lines: lines[0:4]}},
+		{5, 2, &Snippet{Start: 3, Target: 5, Lines: lines[2:7]}},
+		{9, 2, &Snippet{Start: 7, Target: 9, Lines: lines[6:10]}},
+		{10, 2, &Snippet{Start: 8, Target: 10, Lines: lines[7:10]}},
+		{5, 0, &Snippet{Start: 5, Target: 5, Lines: lines[4:5]}},
+		{5, -1, &Snippet{Start: 5, Target: 5, Lines: lines[4:5]}},
+		{5, 20, &Snippet{Start: 1, Target: 5, Lines: lines}},
+		{0, 2, nil},
+		{11, 2, nil},
+	} {
+		if got := NewSnippet(lines, tc.target, tc.context); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("NewSnippet(%d, %d) = %+v, want %+v", tc.target, tc.context, got, tc.want)
+		}
+	}
+
+	if got := NewSnippet(nil, 1, 2); got != nil {
+		t.Errorf("NewSnippet of an empty file = %+v, want nil", got)
+	}
+}
+
+func TestSnippetWrite(t *testing.T) {
+	lines := []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten", "eleven"}
+	for _, tc := range []struct {
+		target int
+		want   string
+	}{
+		{1, "  > 1 | one\n    2 | two\n    3 | three\n"},
+		{6, "    4 | four\n    5 | five\n  > 6 | six\n    7 | seven\n    8 | eight\n"},
+		{11, "     9 | nine\n    10 | ten\n  > 11 | eleven\n"},
+	} {
+		var buf bytes.Buffer
+		if err := NewSnippet(lines, tc.target, 2).Write(&buf, "  "); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != tc.want {
+			t.Errorf("snippet of line %d:\n%s\nwant:\n%s", tc.target, buf.String(), tc.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// TextStyle controls how the violations are written as text.
+type TextStyle struct {
+	// Color colors the severities with ANSI escape sequences.
+	Color bool
+	// Abbrev is the number of hexadecimal characters of the commit hashes
+	// shown, DefaultAbbrev if 0.
+	Abbrev int
+}
+
+func (s TextStyle) abbrev() int {
+	if s.Abbrev <= 0 {
+		return DefaultAbbrev
+	}
+
+	return s.Abbrev
+}
+
+// WriteText writes one violation per line in the path:line: form understood
+// by most editors, followed by its snippet and its diff if any.
+func WriteText(w io.Writer, vs []Violation, style TextStyle) error {
+	for i := range vs {
+		if _, err := fmt.Fprintln(w, vs[i].format(style)); err != nil {
+			return err
+		}
+
+		if err := vs[i].writeDetails(w, "    "); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeDetails writes the snippet and the diff of the violation, if any,
+// each line prefixed by indent.
+func (v *Violation) writeDetails(w io.Writer, indent string) error {
+	if v.Snippet != nil {
+		if err := v.Snippet.Write(w, indent); err != nil {
+			return err
+		}
+	}
+
+	if v.Diff == "" {
+		return nil
+	}
+
+	for _, l := range strings.Split(strings.TrimSuffix(v.Diff, "\n"), "\n") {
+		if _, err := fmt.Fprintf(w, "%s%s\n", indent, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteGrouped writes the violations grouped by their Target, the file or the
+// commit, files sorted by path and their violations by line.
+func WriteGrouped(w io.Writer, vs []Violation, style TextStyle) error {
+	groups := make(map[string][]Violation)
+	var keys []string
+	for _, v := range vs {
+		key := v.target(style.abbrev())
+
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], v)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := fmt.Fprintln(w, key); err != nil {
+			return err
+		}
+
+		group := groups[key]
+		sort.Stable(byLine(group))
+		for i := range group {
+			if err := writeGroupEntry(w, &group[i], style.Color); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeGroupEntry(w io.Writer, v *Violation, color bool) error {
+	line := ""
+	if v.Line > 0 {
+		line = fmt.Sprintf("%d: ", v.Line)
+	}
+
+	_, err := fmt.Fprintf(w, "  %s%s: %s [%s]%s\n", line, v.Severity.format(color), v.Message, v.Rule, v.seeDoc())
+	if err != nil {
+		return err
+	}
+
+	return v.writeDetails(w, "      ")
+}
+
+type byLine []Violation
+
+func (s byLine) Len() int           { return len(s) }
+func (s byLine) Less(i, j int) bool { return s[i].Line < s[j].Line }
+func (s byLine) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+// golden compares got to the content of the file under testdata, rewriting
+// the file instead with -update.
+func golden(t *testing.T, name string, got []byte) {
+	file := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(file, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s\nwant:\n%s", file, got, want)
+	}
+}
+
+func TestWriteGrouped(t *testing.T) {
+	vs := []Violation{
+		{Rule: "trailing-whitespace", Severity: Warning, Path: "main.go", Line: 12, Message: "trailing whitespace"},
+		{Rule: "subject-length", Severity: Warning, Commit: "0be77e079c17b4c95ddf68762338071427f66360", Message: "subject is 81 characters long, at most 72 are allowed"},
+		{Rule: "line-length", Severity: Info, Path: "main.go", Line: 3, Message: "line is 130 characters long"},
+		{Rule: "script-crlf", Severity: Error, Path: "build.sh", Line: 1, Message: "script has CRLF line endings (2 of 2 lines), it won't run on Unix", DocURL: "https://example.com/rules/script-crlf"},
+		{Rule: "file-size", Severity: Error, Path: "assets/logo.png", Message: "file is 2.1 MB, at most 1 MB is allowed"},
+		{Rule: "trailing-whitespace", Severity: Warning, Path: "main.go", Line: 3, Message: "trailing whitespace", Snippet: &Snippet{Start: 2, Target: 3, Lines: []string{"", "func main() { ", "}"}}},
+		{Rule: "blank-lines", Severity: Warning, Path: "README.md", Line: 7, Message: "2 consecutive blank lines, at most 1 are allowed"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGrouped(&buf, vs, TextStyle{}); err != nil {
+		t.Fatal(err)
+	}
+
+	golden(t, "grouped.golden", buf.Bytes())
+}
+
+func TestWriteTextColor(t *testing.T) {
+	vs := []Violation{
+		{Rule: "script-crlf", Severity: Error, Path: "build.sh", Line: 1, Message: "script has CRLF line endings"},
+		{Rule: "subject-length", Severity: Warning, Commit: "0be77e079c17b4c95ddf68762338071427f66360", Message: "subject too long"},
+		{Rule: "line-length", Severity: Info, Path: "main.go", Line: 3, Message: "line too long"},
+	}
+
+	for _, tc := range []struct {
+		color bool
+		want  string
+	}{
+		{false, "build.sh:1: error: script has CRLF line endings [script-crlf]\n" +
+			"0be77e0: warning: subject too long [subject-length]\n" +
+			"main.go:3: info: line too long [line-length]\n"},
+		// Only the severities are colored, the paths stay clickable.
+		{true, "build.sh:1: \x1b[31merror\x1b[0m: script has CRLF line endings [script-crlf]\n" +
+			"0be77e0: \x1b[33mwarning\x1b[0m: subject too long [subject-length]\n" +
+			"main.go:3: \x1b[36minfo\x1b[0m: line too long [line-length]\n"},
+	} {
+		var buf bytes.Buffer
+		if err := WriteText(&buf, vs, TextStyle{Color: tc.color}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); got != tc.want {
+			t.Errorf("WriteText with Color %v =\n%q\nwant\n%q", tc.color, got, tc.want)
+		}
+	}
+}
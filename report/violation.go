@@ -0,0 +1,206 @@
+// Package report holds the violations produced by a review and the
+// formatters used to present them.
+package report
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Severity describes how serious a violation is.
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// severityColors are the ANSI escape sequences coloring the severities in the
+// text output.
+var severityColors = map[Severity]string{
+	Info:    "\x1b[36m",
+	Warning: "\x1b[33m",
+	Error:   "\x1b[31m",
+}
+
+const resetColor = "\x1b[0m"
+
+// format returns the name of the severity, colored if color is true.
+func (s Severity) format(color bool) string {
+	if !color {
+		return s.String()
+	}
+
+	return severityColors[s] + s.String() + resetColor
+}
+
+// MarshalText encodes the severity by its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	v, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = v
+	return nil
+}
+
+// ParseSeverity parses the textual representation of a Severity.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "info":
+		return Info, nil
+	case "warning", "warn":
+		return Warning, nil
+	case "error":
+		return Error, nil
+	default:
+		return Info, fmt.Errorf("unknown severity %q", s)
+	}
+}
+
+// Violation is a single finding reported by a rule.
+type Violation struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	// Column is the position in bytes in the line, from 1, if known.
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+	// Commit is the hash of the commit the violation was found in, it is
+	// the only location of violations about the commit itself.
+	Commit string `json:"commit,omitempty"`
+	// Snippet is the excerpt of the file around Line, if requested.
+	Snippet *Snippet `json:"snippet,omitempty"`
+	// Diff is the unified diff fixing the violation, if the rule gives one.
+	// It isn't part of the fingerprint.
+	Diff string `json:"diff,omitempty"`
+	// Fix is the content the rule suggests for the whole file, if any. It
+	// isn't part of the fingerprint.
+	Fix string `json:"fix,omitempty"`
+	// Repo is the path of the repository of the violation when several
+	// repositories are reviewed together. It isn't part of the fingerprint.
+	Repo string `json:"repo,omitempty"`
+	// DocURL is the page explaining the rule of the violation, if any. It
+	// isn't part of the fingerprint.
+	DocURL string `json:"doc_url,omitempty"`
+}
+
+// Fingerprint identifies the violation independently of its line number, so
+// it stays stable when unrelated edits move the offending code around.
+// Violations without a path are identified by their commit instead.
+func (v *Violation) Fingerprint() string {
+	where := v.Path
+	if where == "" {
+		where = v.Commit
+	}
+
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", v.Rule, where, v.Message)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DefaultAbbrev is the number of hexadecimal characters to which commit
+// hashes are abbreviated by default.
+const DefaultAbbrev = 7
+
+// Target returns the path of the file of the violation, or the abbreviated
+// hash of its commit if it has no path. Paths are joined to the repository
+// and hashes prefixed by repo@ when the repository is set.
+func (v *Violation) Target() string {
+	return v.target(DefaultAbbrev)
+}
+
+// target returns the Target with hashes abbreviated to abbrev characters.
+func (v *Violation) target(abbrev int) string {
+	switch {
+	case v.Path == "" && v.Repo != "":
+		return v.Repo + "@" + shortHash(v.Commit, abbrev)
+	case v.Path == "":
+		return shortHash(v.Commit, abbrev)
+	case v.Repo != "":
+		return path.Join(v.Repo, v.Path)
+	default:
+		return v.Path
+	}
+}
+
+// Location returns the path:line:column position of the violation, omitting
+// the line and column when they are unknown or the violation has no path,
+// see Target.
+func (v *Violation) Location() string {
+	return v.location(DefaultAbbrev)
+}
+
+func (v *Violation) location(abbrev int) string {
+	if v.Path != "" && v.Line > 0 && v.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", v.target(abbrev), v.Line, v.Column)
+	}
+
+	if v.Path != "" && v.Line > 0 {
+		return fmt.Sprintf("%s:%d", v.target(abbrev), v.Line)
+	}
+
+	return v.target(abbrev)
+}
+
+func shortHash(h string, abbrev int) string {
+	if abbrev > 0 && len(h) > abbrev {
+		return h[:abbrev]
+	}
+
+	return h
+}
+
+func (v *Violation) String() string {
+	return v.format(TextStyle{})
+}
+
+// format returns the violation as a line of the text output.
+func (v *Violation) format(style TextStyle) string {
+	return fmt.Sprintf("%s: %s: %s [%s]%s", v.location(style.abbrev()), v.Severity.format(style.Color), v.Message, v.Rule, v.seeDoc())
+}
+
+// seeDoc returns the reference to the documentation URL appended to the
+// violation in the text output, an empty string if it has none.
+func (v *Violation) seeDoc() string {
+	if v.DocURL == "" {
+		return ""
+	}
+
+	return " (see " + v.DocURL + ")"
+}
+
+// Failing returns true if any of the violations is an error.
+func Failing(vs []Violation) bool {
+	for _, v := range vs {
+		if v.Severity >= Error {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+package report
+
+import "testing"
+
+func TestLocation(t *testing.T) {
+	const hash = "0be77e079c17b4c95ddf68762338071427f66360"
+	for _, tc := range []struct {
+		v             Violation
+		target, where string
+	}{
+		{Violation{Path: "main.go", Line: 3}, "main.go", "main.go:3"},
+		{Violation{Path: "main.go", Line: 3, Column: 7}, "main.go", "main.go:3:7"},
+		{Violation{Path: "main.go", Column: 7}, "main.go", "main.go"},
+		{Violation{Commit: hash}, "0be77e0", "0be77e0"},
+		{Violation{Commit: hash, Line: 2}, "0be77e0", "0be77e0"},
+		{Violation{Path: "main.go", Line: 3, Repo: "../api"}, "../api/main.go", "../api/main.go:3"},
+		{Violation{Path: "cmd/run.go", Repo: "."}, "cmd/run.go", "cmd/run.go"},
+		{Violation{Commit: hash, Repo: "../api"}, "../api@0be77e0", "../api@0be77e0"},
+	} {
+		if got := tc.v.Target(); got != tc.target {
+			t.Errorf("Target(%+v) = %q, want %q", tc.v, got, tc.target)
+		}
+
+		if got := tc.v.Location(); got != tc.where {
+			t.Errorf("Location(%+v) = %q, want %q", tc.v, got, tc.where)
+		}
+	}
+}
+
+func TestFingerprintIgnoresRepo(t *testing.T) {
+	v := Violation{Rule: "trailing-whitespace", Path: "main.go", Line: 3, Message: "trailing whitespace"}
+	moved := v
+	moved.Line = 30
+	moved.Repo = "../api"
+	if v.Fingerprint() != moved.Fingerprint() {
+		t.Errorf("fingerprints differ with the line and the repository")
+	}
+
+	other := v
+	other.Path = "other.go"
+	if v.Fingerprint() == other.Fingerprint() {
+		t.Errorf("fingerprints of different paths are equal")
+	}
+}
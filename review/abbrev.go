@@ -0,0 +1,55 @@
+package review
+
+import (
+	"sort"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// UniqueAbbrev returns the smallest number of hexadecimal characters, at
+// least 4, to which each of the hashes can be abbreviated without matching
+// another commit of the repository, so the abbreviations can be resolved
+// back by CommitByPrefix. Every commit of the object storage is read.
+func UniqueAbbrev(r *git.Repository, hashes []string) (int, error) {
+	iter, err := r.Commits()
+	if err != nil {
+		return 0, err
+	}
+
+	var all []string
+	err = iter.ForEach(func(c *git.Commit) error {
+		all = append(all, c.Hash.String())
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	sort.Strings(all)
+	width := minAbbrev
+	for _, h := range hashes {
+		i := sort.SearchStrings(all, h)
+		// The closest hashes are the neighbors of h in sort order.
+		for _, j := range []int{i - 1, i, i + 1} {
+			if j < 0 || j >= len(all) || all[j] == h {
+				continue
+			}
+
+			if n := commonPrefix(h, all[j]) + 1; n > width {
+				width = n
+			}
+		}
+	}
+
+	return width, nil
+}
+
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+
+	return n
+}
@@ -0,0 +1,60 @@
+package review
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestUniqueAbbrev(t *testing.T) {
+	tr := newTestRepo(t)
+	first := tr.commit(map[string]string{"a": "a\n"}, "first").String()
+	if n, err := UniqueAbbrev(tr.Repository, []string{first}); err != nil || n != minAbbrev {
+		t.Errorf("UniqueAbbrev of the only commit = %d, %v, want %d", n, err, minAbbrev)
+	}
+
+	// Enough commits for two of them to share their first 5 characters.
+	var hashes []string
+	for i := 0; i < 3000; i++ {
+		hashes = append(hashes, tr.commit(nil, fmt.Sprintf("commit %d", i)).String())
+	}
+
+	sorted := append([]string{first}, hashes...)
+	sort.Strings(sorted)
+	longest, pair := 0, ""
+	for i := 1; i < len(sorted); i++ {
+		if n := commonPrefix(sorted[i-1], sorted[i]); n > longest {
+			longest, pair = n, sorted[i]
+		}
+	}
+
+	if longest < minAbbrev {
+		t.Fatalf("longest common prefix of %d characters, the test needs %d", longest, minAbbrev)
+	}
+
+	if n, err := UniqueAbbrev(tr.Repository, []string{pair}); err != nil || n != longest+1 {
+		t.Errorf("UniqueAbbrev(%s) = %d, %v, want %d", pair, n, err, longest+1)
+	}
+
+	// The width is the one of the hash needing the most characters.
+	if n, err := UniqueAbbrev(tr.Repository, []string{first, pair, hashes[0]}); err != nil || n != longest+1 {
+		t.Errorf("UniqueAbbrev of several hashes = %d, %v, want %d", n, err, longest+1)
+	}
+
+	for _, h := range hashes[:20] {
+		n, err := UniqueAbbrev(tr.Repository, []string{h})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n < minAbbrev || n > longest+1 {
+			t.Errorf("UniqueAbbrev(%s) = %d, want between %d and %d", h, n, minAbbrev, longest+1)
+			continue
+		}
+
+		c, err := CommitByPrefix(tr.Repository, h[:n])
+		if err != nil || c.Hash.String() != h {
+			t.Errorf("CommitByPrefix(%s) = %v, %v, want %s", h[:n], c, err, h)
+		}
+	}
+}
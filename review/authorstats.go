@@ -0,0 +1,91 @@
+package review
+
+import (
+	"sort"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// AuthorStats is the contribution of an author to the reviewed commits.
+type AuthorStats struct {
+	Name  string
+	Email string
+	// Commits is the number of commits of the author, Files the number of
+	// distinct paths they touched.
+	Commits int
+	Files   int
+	Added   int
+	Removed int
+
+	paths map[string]bool
+}
+
+// Authors walks the commits of the configured range that pass the author
+// filter and returns the statistics of their authors, after normalizing them
+// with the .mailmap, sorted by decreasing number of commits.
+func Authors(r *git.Repository, o *Options) ([]*AuthorStats, error) {
+	head, since, err := resolveRange(r, o)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := loadAuthorFilter(head, o)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*AuthorStats)
+	var stats []*AuthorStats
+	err = walkRange(r, head, since, o.Merges, func(c *git.Commit) error {
+		if !filter.Match(c) {
+			return nil
+		}
+
+		churn, err := CommitChurn(c)
+		if err != nil {
+			return err
+		}
+
+		id := c.Author.Name + " <" + c.Author.Email + ">"
+		s, ok := byID[id]
+		if !ok {
+			s = &AuthorStats{
+				Name:  c.Author.Name,
+				Email: c.Author.Email,
+				paths: make(map[string]bool),
+			}
+
+			byID[id] = s
+			stats = append(stats, s)
+		}
+
+		s.Commits++
+		for _, fc := range churn {
+			s.paths[fc.Path] = true
+			s.Added += fc.Added
+			s.Removed += fc.Removed
+		}
+
+		s.Files = len(s.paths)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(byCommits(stats))
+	return stats, nil
+}
+
+type byCommits []*AuthorStats
+
+func (s byCommits) Len() int      { return len(s) }
+func (s byCommits) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byCommits) Less(i, j int) bool {
+	if s[i].Commits != s[j].Commits {
+		return s[i].Commits > s[j].Commits
+	}
+
+	return s[i].Name < s[j].Name
+}
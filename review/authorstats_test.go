@@ -0,0 +1,66 @@
+package review
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAuthors(t *testing.T) {
+	tr := newTestRepo(t)
+	files := map[string]string{
+		".mailmap": "Jane Doe <jane@example.com> <jane@old.example>\nJane Doe <jane@example.com> jdoe <jane@example.com>\n",
+		"a.txt":    "one\ntwo\n",
+	}
+
+	tr.Author = "Bob <bob@example.com>"
+	h := tr.commit(files, "Add a")
+
+	tr.Author = "Jane <jane@old.example>"
+	files["a.txt"] = "one\n2\n"
+	h = tr.commit(files, "Change a", h)
+
+	tr.Author = "jdoe <jane@example.com>"
+	files["b.txt"] = "1\n2\n3\n"
+	h = tr.commit(files, "Add b", h)
+
+	tr.Author = "Carol <carol@example.com>"
+	delete(files, "b.txt")
+	h = tr.commit(files, "Remove b", h)
+
+	tr.Author = "Jane Doe <jane@example.com>"
+	files["a.txt"] = "one\n2\nthree\n"
+	h = tr.commit(files, "Extend a", h)
+
+	tr.SetRef("refs/heads/main", h)
+	tr.SetHead("main")
+
+	stats, err := Authors(tr.Repository, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []AuthorStats
+	for _, s := range stats {
+		s.paths = nil
+		got = append(got, *s)
+	}
+
+	want := []AuthorStats{
+		{Name: "Jane Doe", Email: "jane@example.com", Commits: 3, Files: 2, Added: 5, Removed: 1},
+		{Name: "Bob", Email: "bob@example.com", Commits: 1, Files: 2, Added: 4},
+		{Name: "Carol", Email: "carol@example.com", Commits: 1, Files: 1, Removed: 3},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Authors() = %+v, want %+v", got, want)
+	}
+
+	stats, err = Authors(tr.Repository, &Options{Author: "^Bob "})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stats) != 1 || stats[0].Name != "Bob" || stats[0].Commits != 1 {
+		t.Errorf("Authors() of -author ^Bob = %+v, want Bob only", stats)
+	}
+}
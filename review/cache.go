@@ -0,0 +1,170 @@
+package review
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gunjan5/code-review-bot/plugin"
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// cacheFile is the name of the file of the cache in its directory.
+const cacheFile = "results.json"
+
+// Cache stores on disk the violations found in files, so files unchanged
+// since a previous run aren't checked again. Files are identified by their
+// path, mode and blob hash, as rules depend on all of them. The cache is
+// discarded when the ruleset or the options of the rules change.
+//
+// Plugins are identified by their path only, the cache must be cleared when
+// they are updated. It is safe for concurrent use.
+type Cache struct {
+	path    string
+	version string
+
+	mu      sync.Mutex
+	entries map[string][]report.Violation
+	// used are the entries read or written during the run, the only ones
+	// saved.
+	used map[string][]report.Violation
+}
+
+type cacheData struct {
+	Version string                        `json:"version"`
+	Entries map[string][]report.Violation `json:"entries"`
+}
+
+// OpenCache loads the cache stored in dir for the options, an empty cache is
+// returned if there is none or if it was written with other options.
+func OpenCache(dir string, o *Options) (*Cache, error) {
+	version, err := cacheVersion(o)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache{
+		path:    filepath.Join(dir, cacheFile),
+		version: version,
+		entries: make(map[string][]report.Violation),
+		used:    make(map[string][]report.Violation),
+	}
+
+	data, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var d cacheData
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("%s: %s", c.path, err)
+	}
+
+	if d.Version == version && d.Entries != nil {
+		c.entries = d.Entries
+	}
+
+	return c, nil
+}
+
+// cacheVersion returns a hash of everything the violations of a file depend
+// on besides the file itself.
+func cacheVersion(o *Options) (string, error) {
+	opts, err := json.Marshal(o.Options)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha1.New()
+	fmt.Fprintf(h, "%d\x00%s\x00%q", rules.Version, opts, o.Plugins)
+	for _, r := range rules.All() {
+		fmt.Fprintf(h, "\x00%s", r.Meta().ID)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cacheKey identifies the file, and the repository configuration the rules
+// ran with if any, see Options.repoConfig.
+func cacheKey(f *git.File, repoConfig string) string {
+	key := fmt.Sprintf("%s %o %s", f.Hash, uint32(f.Mode), f.Name)
+	if repoConfig != "" {
+		key += " " + repoConfig
+	}
+
+	return key
+}
+
+// get returns a copy of the violations cached for the file checked with the
+// repository configuration. It can be called on a nil cache.
+func (c *Cache) get(f *git.File, repoConfig string) ([]report.Violation, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	key := cacheKey(f, repoConfig)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	vs, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.used[key] = vs
+	return append([]report.Violation(nil), vs...), true
+}
+
+// put caches the violations of the file checked with the repository
+// configuration, unless a plugin failed on it as the failure may be
+// transient. It can be called on a nil cache.
+func (c *Cache) put(f *git.File, repoConfig string, vs []report.Violation) {
+	if c == nil {
+		return
+	}
+
+	for _, v := range vs {
+		if v.Rule == plugin.ErrorRule {
+			return
+		}
+	}
+
+	vs = append([]report.Violation(nil), vs...)
+	key := cacheKey(f, repoConfig)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = vs
+	c.used[key] = vs
+}
+
+// Save writes the entries used since the cache was opened to its directory,
+// created if needed.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	data, err := json.Marshal(cacheData{Version: c.version, Entries: c.used})
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, c.path)
+}
@@ -0,0 +1,175 @@
+package review
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// checked counts the files checked by the test-checked rule by name.
+var checked = struct {
+	sync.Mutex
+	names map[string]int
+}{names: make(map[string]int)}
+
+func init() {
+	rules.Register(&rules.Func{
+		ID:          "test-checked",
+		Severity:    report.Info,
+		Description: "counts the files checked by the tests of the cache",
+		File: func(f *git.File, o *rules.Options) ([]report.Violation, error) {
+			checked.Lock()
+			checked.names[f.Name]++
+			checked.Unlock()
+			return []report.Violation{{Path: f.Name, Message: "checked"}}, nil
+		},
+	})
+}
+
+// checkedNames returns the names of the files checked since the last call,
+// sorted.
+func checkedNames() []string {
+	checked.Lock()
+	defer checked.Unlock()
+	names := []string{}
+	for name := range checked.names {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	checked.names = make(map[string]int)
+	return names
+}
+
+// runCached reviews the repository with the cache of dir, saving it, and
+// returns the names of the files checked and of those with a test-checked
+// violation.
+func runCached(t *testing.T, tr *testRepo, dir string, o *Options) (checked, violations []string) {
+	c, err := OpenCache(dir, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o.Cache = c
+	checkedNames()
+	res, err := Run(tr.Repository, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	violations = []string{}
+	for _, v := range res.Violations {
+		if v.Rule == "test-checked" {
+			violations = append(violations, v.Path)
+		}
+	}
+
+	sort.Strings(violations)
+	return checkedNames(), violations
+}
+
+func TestCacheSkipsUnchangedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tr := newTestRepo(t)
+	files := map[string]string{"a": "1\n", "b": "1\n", "c": "1\n"}
+	tr.SetRef("refs/heads/main", tr.commit(files, "first"))
+	tr.SetHead("main")
+	all := []string{"a", "b", "c"}
+	for _, tc := range []struct {
+		change  string
+		checked []string
+	}{
+		{"", all},
+		{"", []string{}},
+		{"b", []string{"b"}},
+		{"", []string{}},
+	} {
+		if tc.change != "" {
+			files[tc.change] += "2\n"
+			tr.SetRef("refs/heads/main", tr.commit(files, "change "+tc.change))
+		}
+
+		checked, violations := runCached(t, tr, dir, &Options{})
+		if !reflect.DeepEqual(checked, tc.checked) {
+			t.Errorf("after changing %q, checked %q, want %q", tc.change, checked, tc.checked)
+		}
+
+		if !reflect.DeepEqual(violations, all) {
+			t.Errorf("after changing %q, violations of %q, want %q", tc.change, violations, all)
+		}
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tr := newTestRepo(t)
+	tr.SetRef("refs/heads/main", tr.commit(map[string]string{"a": "1\n", "b": "1\n"}, "first"))
+	tr.SetHead("main")
+	all := []string{"a", "b"}
+	if checked, _ := runCached(t, tr, dir, &Options{}); !reflect.DeepEqual(checked, all) {
+		t.Fatalf("checked %q, want %q", checked, all)
+	}
+
+	// Other options of the rules invalidate the cache.
+	o := &Options{}
+	o.MaxFileLines = 100
+	if checked, _ := runCached(t, tr, dir, o); !reflect.DeepEqual(checked, all) {
+		t.Errorf("with other options, checked %q, want %q", checked, all)
+	}
+
+	if checked, _ := runCached(t, tr, dir, o); len(checked) != 0 {
+		t.Errorf("with the same options, checked %q, want none", checked)
+	}
+
+	// The cache written by another version of the rules is discarded.
+	path := filepath.Join(dir, cacheFile)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var d cacheData
+	if err := json.Unmarshal(data, &d); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d.Entries) != len(all) {
+		t.Errorf("cache of %d entries, want %d", len(d.Entries), len(all))
+	}
+
+	d.Version = "older"
+	if data, err = json.Marshal(d); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if checked, _ := runCached(t, tr, dir, o); !reflect.DeepEqual(checked, all) {
+		t.Errorf("with another version, checked %q, want %q", checked, all)
+	}
+}
@@ -0,0 +1,105 @@
+package review
+
+import (
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/rules"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/diff"
+)
+
+// FileChurn is the number of lines a commit added to and removed from a
+// file. Lines of binary files are not counted.
+type FileChurn struct {
+	Path    string
+	Added   int
+	Removed int
+	Binary  bool
+}
+
+// CommitChurn returns the churn of every file added, modified or deleted by
+// the commit against its first parent.
+func CommitChurn(c *git.Commit) ([]FileChurn, error) {
+	changes, err := rules.Changes(c)
+	if err != nil {
+		return nil, err
+	}
+
+	churn := make([]FileChurn, 0, len(changes))
+	for _, ch := range changes {
+		from, to, err := ch.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		fc := FileChurn{Path: ch.To.Name}
+		if ch.Action == git.Delete {
+			fc.Path = ch.From.Name
+		}
+
+		src, bin, err := textContents(from)
+		if err != nil {
+			return nil, err
+		}
+
+		dst, dstBin, err := textContents(to)
+		if err != nil {
+			return nil, err
+		}
+
+		fc.Binary = bin || dstBin
+		if !fc.Binary {
+			fc.Added, fc.Removed = countChanges(src, dst)
+		}
+
+		churn = append(churn, fc)
+	}
+
+	return churn, nil
+}
+
+// textContents returns the contents of f, or true if it is binary. A nil
+// file is empty.
+func textContents(f *git.File) (string, bool, error) {
+	if f == nil {
+		return "", false, nil
+	}
+
+	if binary, err := rules.IsBinary(f); err != nil || binary {
+		return "", binary, err
+	}
+
+	s, err := f.Contents()
+	return s, false, err
+}
+
+// countChanges returns the number of lines added and removed to turn src
+// into dst.
+func countChanges(src, dst string) (added, removed int) {
+	for _, d := range diff.Do(src, dst) {
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			added += countLines(d.Text)
+		case diffmatchpatch.DiffDelete:
+			removed += countLines(d.Text)
+		}
+	}
+
+	return added, removed
+}
+
+// countLines returns the number of lines of s, counting an unterminated
+// last line.
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+
+	n := strings.Count(s, "\n")
+	if !strings.HasSuffix(s, "\n") {
+		n++
+	}
+
+	return n
+}
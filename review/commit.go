@@ -0,0 +1,115 @@
+package review
+
+import (
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// RunCommit reviews a single commit, linting its message and checking the
+// files it added or modified against the trees chosen by DiffAgainst, its
+// first parent by default. The files marked as
+// generated in the .gitattributes of the commit are skipped.
+func RunCommit(r *git.Repository, c *git.Commit, o *Options) (*Result, error) {
+	vs, err := rules.CheckCommit(c, &o.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Violations: vs, Commit: c.Hash.String(), Commits: 1}
+
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := loadPathFilter(tree, o)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := changedFiles(c, o.DiffAgainst)
+	if err != nil {
+		return nil, err
+	}
+
+	q := newFileQueue(o)
+	for _, f := range files {
+		if !paths.Match(f.Name) {
+			continue
+		}
+
+		if err := q.Add(f); err != nil {
+			break
+		}
+	}
+
+	fvs, err := q.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range fvs {
+		v.Commit = c.Hash.String()
+		res.Violations = append(res.Violations, v)
+	}
+
+	res.Paths = append(res.Paths, q.Paths...)
+	return res, nil
+}
+
+// changedFiles returns the files added or modified by the commit compared to
+// the trees chosen by against, see rules.ChangesAgainst. A root commit adds
+// all of its files.
+func changedFiles(c *git.Commit, against string) ([]*git.File, error) {
+	changes, err := rules.ChangesAgainst(c, against)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*git.File
+	for _, ch := range changes {
+		if ch.Action == git.Delete {
+			continue
+		}
+
+		_, f, err := ch.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		// Change.Files names the file after its tree entry, not its path.
+		f.Name = ch.To.Name
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+// changedPaths returns the paths of the files added, modified or renamed
+// between the trees of the commits, an empty slice if there is none.
+func changedPaths(from, to *git.Commit) ([]string, error) {
+	ft, err := from.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	tt, err := to.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diff.TreeDiff(ft, tt)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, ch := range changes {
+		if ch.Action != diff.Deleted {
+			paths = append(paths, ch.To.Path)
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,178 @@
+package review
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/config"
+	"github.com/gunjan5/code-review-bot/gitattributes"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// Explain writes why the file at the path is checked or skipped by a review
+// with the options: whether it is in the reviewed tree, among the changed
+// files and selected by the pathspec, the .gitattributes lines marking it as generated, the verdict and
+// the rules and plugins run on it.
+func Explain(w io.Writer, r *git.Repository, name string, o *Options) error {
+	commit, since, err := resolveRange(r, o)
+	if err != nil {
+		return err
+	}
+
+	if o, err = loadRepoConfig(commit, since, o); err != nil {
+		return err
+	}
+
+	var changed []string
+	switch {
+	case o.Commit != "":
+		if commit, err = resolveSingleCommit(r, o); err != nil {
+			return err
+		}
+
+		files, err := changedFiles(commit, o.DiffAgainst)
+		if err != nil {
+			return err
+		}
+
+		changed = []string{}
+		for _, f := range files {
+			changed = append(changed, f.Name)
+		}
+	case o.BaseBranch != "":
+		if changed, err = changedPaths(since, commit); err != nil {
+			return err
+		}
+	default:
+		changed = o.ChangedFiles
+	}
+
+	fmt.Fprintf(w, "%s:\n", name)
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	f, err := tree.File(name)
+	if err == git.ErrFileNotFound {
+		fmt.Fprintf(w, "  not a file of the tree of commit %s\n  verdict: skipped\n", commit.Hash.String()[:7])
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "  file of the tree of commit %s, mode %o, %d bytes\n", commit.Hash.String()[:7], uint32(f.Mode), f.Size)
+	if changed != nil {
+		if !contains(changed, name) {
+			fmt.Fprintf(w, "  not among the %d changed files\n  verdict: skipped\n", len(changed))
+			return nil
+		}
+
+		fmt.Fprintf(w, "  among the %d changed files\n", len(changed))
+	}
+
+	if len(o.Paths) > 0 {
+		glob := matchingGlob(o.Paths, name)
+		if glob == "" {
+			fmt.Fprintln(w, "  not matched by any -path glob\n  verdict: skipped")
+			return nil
+		}
+
+		fmt.Fprintf(w, "  selected by -path %s\n", glob)
+	}
+
+	if glob := matchingGlob(o.ExcludedPaths, name); glob != "" {
+		fmt.Fprintf(w, "  excluded by -exclude %s\n  verdict: skipped\n", glob)
+		return nil
+	}
+
+	paths, err := loadPathFilter(tree, o)
+	if err != nil {
+		return err
+	}
+
+	explainAttributes(w, paths.attrs, name)
+	if !paths.Match(name) {
+		fmt.Fprintln(w, "  verdict: skipped as generated, -include-generated checks it")
+		return nil
+	}
+
+	if o.IncludeGenerated {
+		fmt.Fprintln(w, "  generated files included by -include-generated")
+	}
+
+	fmt.Fprintln(w, "  verdict: checked")
+	explainRules(w, name, o)
+	return nil
+}
+
+// explainAttributes writes the .gitattributes lines giving a value to the
+// attributes marking the file as generated.
+func explainAttributes(w io.Writer, attrs *gitattributes.Attributes, name string) {
+	for _, attr := range generatedAttrs {
+		ms := attrs.Matches(name, attr)
+		for i, m := range ms {
+			decides := ""
+			if i > 0 && i == len(ms)-1 {
+				decides = ", overriding the lines above as the last match"
+			}
+
+			fmt.Fprintf(w, "  .gitattributes:%d: %s sets %s to %s%s\n", m.Line, m.Pattern, attr, attrValue(m.Value), decides)
+		}
+	}
+}
+
+func attrValue(v string) string {
+	if v == "" {
+		return "unspecified"
+	}
+
+	return v
+}
+
+// explainRules writes the file rules run on the file and those skipped by
+// -disable-rule or -exclude-path, followed by the plugins. Rules run may still ignore the
+// file, e.g. when their own globs don't match it or when disabled.
+func explainRules(w io.Writer, name string, o *Options) {
+	var run []string
+	for _, r := range rules.All() {
+		id := r.Meta().ID
+		if !rules.Checks(r, rules.FileKind) {
+			continue
+		}
+
+		if o.DisabledAt(id, name) {
+			fmt.Fprintf(w, "  rule %s disabled by -disable-rule or %s\n", id, config.RepoFile)
+			continue
+		}
+
+		glob := excludingGlob(&o.Options, id, name)
+		if glob == "" {
+			run = append(run, id)
+			continue
+		}
+
+		fmt.Fprintf(w, "  rule %s skipped by -exclude-path %s=%s\n", id, id, glob)
+	}
+
+	fmt.Fprintf(w, "  file rules run: %s\n", strings.Join(run, ", "))
+	for _, p := range o.Plugins {
+		fmt.Fprintf(w, "  plugin run: %s\n", p)
+	}
+}
+
+// excludingGlob returns the first glob of -exclude-path making the rule id
+// skip the path, an empty string if none.
+func excludingGlob(o *rules.Options, id, name string) string {
+	for _, glob := range o.Exclude[id] {
+		if rules.MatchPath(glob, name) {
+			return glob
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,181 @@
+package review
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/rules"
+)
+
+// explain returns the lines written by Explain for the path.
+func explain(t *testing.T, tr *testRepo, name string, o *Options) []string {
+	var buf bytes.Buffer
+	if err := Explain(&buf, tr.Repository, name, o); err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// hasLine returns true if one of the lines starts with prefix.
+func hasLine(lines []string, prefix string) bool {
+	for _, l := range lines {
+		if strings.HasPrefix(l, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rulesRun returns the file rules listed as run in the lines.
+func rulesRun(lines []string) map[string]bool {
+	run := make(map[string]bool)
+	for _, l := range lines {
+		if strings.HasPrefix(l, "  file rules run: ") {
+			for _, id := range strings.Split(strings.TrimPrefix(l, "  file rules run: "), ", ") {
+				run[id] = true
+			}
+		}
+	}
+
+	return run
+}
+
+func TestExplainFile(t *testing.T) {
+	tr := newTestRepo(t)
+	h := tr.commit(map[string]string{
+		".gitattributes": "*.pb.go linguist-generated\nown.pb.go -linguist-generated\n",
+		"api.pb.go":      "package api\n",
+		"own.pb.go":      "package own\n",
+		"main.go":        "package main\n",
+	}, "Add the files")
+	tr.SetRef("refs/heads/main", h)
+	tr.SetHead("main")
+
+	for _, tc := range []struct {
+		name string
+		o    *Options
+		// want are the prefixes of lines of the trace, verdict the line
+		// giving its verdict.
+		want    []string
+		verdict string
+	}{
+		{"missing.go", &Options{}, []string{"  not a file of the tree of commit "}, "  verdict: skipped"},
+		{"main.go", &Options{}, []string{"  file of the tree of commit ", "  file rules run: "}, "  verdict: checked"},
+		{"main.go", &Options{Paths: []string{"cmd/**"}}, []string{"  not matched by any -path glob"}, "  verdict: skipped"},
+		{"main.go", &Options{Paths: []string{"*.txt", "*.go"}}, []string{"  selected by -path *.go", "  file rules run: "}, "  verdict: checked"},
+		{"main.go", &Options{ExcludedPaths: []string{"main.*"}}, []string{"  excluded by -exclude main.*"}, "  verdict: skipped"},
+		{"main.go", &Options{ChangedFiles: []string{"api.pb.go"}}, []string{"  not among the 1 changed files"}, "  verdict: skipped"},
+		{"api.pb.go", &Options{}, []string{"  .gitattributes:1: *.pb.go sets linguist-generated to true"}, "  verdict: skipped as generated, -include-generated checks it"},
+		{"api.pb.go", &Options{IncludeGenerated: true}, []string{"  generated files included by -include-generated"}, "  verdict: checked"},
+		// The later negation of the attribute overrides the first line.
+		{"own.pb.go", &Options{}, []string{
+			"  .gitattributes:1: *.pb.go sets linguist-generated to true",
+			"  .gitattributes:2: own.pb.go sets linguist-generated to false, overriding the lines above as the last match",
+		}, "  verdict: checked"},
+	} {
+		lines := explain(t, tr, tc.name, tc.o)
+		if lines[0] != tc.name+":" {
+			t.Errorf("Explain(%s) starts with %q", tc.name, lines[0])
+		}
+
+		for _, want := range tc.want {
+			if !hasLine(lines, want) {
+				t.Errorf("Explain(%s) =\n%s\nwant a line starting with %q", tc.name, strings.Join(lines, "\n"), want)
+			}
+		}
+
+		verdict := lines[len(lines)-1]
+		if strings.HasPrefix(verdict, "  file rules run: ") {
+			verdict = lines[len(lines)-2]
+		}
+
+		if verdict != tc.verdict {
+			t.Errorf("Explain(%s) =\n%s\nwant the verdict %q", tc.name, strings.Join(lines, "\n"), tc.verdict)
+		}
+	}
+}
+
+func TestExplainRules(t *testing.T) {
+	tr := newTestRepo(t)
+	h := tr.commit(map[string]string{"main.go": "package main\n", "notes.txt": "notes\n"}, "Add the files")
+	tr.SetRef("refs/heads/main", h)
+	tr.SetHead("main")
+
+	o := &Options{Plugins: []string{"/usr/local/bin/lint"}}
+	o.DisabledRules = []string{"bom"}
+	o.Exclude = map[string][]string{"utf8": {"*.go"}}
+	o.Overrides = []rules.Override{{Paths: []string{"*.txt"}, Disabled: map[string]bool{"trailing-whitespace": true, "bom": false}}}
+	for _, tc := range []struct {
+		name string
+		// want are the lines giving the reason of the skipped rules.
+		want []string
+		run  []string
+		skip []string
+	}{
+		{"main.go", []string{
+			"  rule bom disabled by -disable-rule or .codereview.yaml",
+			"  rule utf8 skipped by -exclude-path utf8=*.go",
+		}, []string{"trailing-whitespace", "script-crlf"}, []string{"bom", "utf8"}},
+		{"notes.txt", []string{
+			"  rule trailing-whitespace disabled by -disable-rule or .codereview.yaml",
+		}, []string{"bom", "utf8", "script-crlf"}, []string{"trailing-whitespace"}},
+	} {
+		lines := explain(t, tr, tc.name, o)
+		for _, want := range append(tc.want, "  plugin run: /usr/local/bin/lint") {
+			if !hasLine(lines, want) {
+				t.Errorf("Explain(%s) =\n%s\nwant the line %q", tc.name, strings.Join(lines, "\n"), want)
+			}
+		}
+
+		run := rulesRun(lines)
+		for _, id := range tc.run {
+			if !run[id] {
+				t.Errorf("Explain(%s) doesn't run rule %s", tc.name, id)
+			}
+		}
+
+		for _, id := range tc.skip {
+			if run[id] {
+				t.Errorf("Explain(%s) runs rule %s, want it skipped", tc.name, id)
+			}
+		}
+
+		// Every file rule is either run or skipped with a reason.
+		for _, r := range rules.All() {
+			id := r.Meta().ID
+			if rules.Checks(r, rules.FileKind) && !run[id] && !hasLine(lines, "  rule "+id+" ") {
+				t.Errorf("Explain(%s) doesn't explain the rule %s", tc.name, id)
+			}
+		}
+	}
+}
+
+func TestExplainCommit(t *testing.T) {
+	tr := newTestRepo(t)
+	first := tr.commit(map[string]string{"a.go": "package a\n", "b.go": "package b\n"}, "Add a and b")
+	second := tr.commit(map[string]string{"a.go": "package a\n\nfunc f() {}\n", "b.go": "package b\n", "c.go": "package c\n"}, "Change a, add c", first)
+	tr.SetRef("refs/heads/main", second)
+	tr.SetHead("main")
+
+	for _, tc := range []struct {
+		commit, name string
+		want         []string
+	}{
+		{second.String(), "a.go", []string{"  among the 2 changed files", "  verdict: checked"}},
+		{second.String()[:7], "c.go", []string{"  among the 2 changed files", "  verdict: checked"}},
+		{second.String(), "b.go", []string{"  not among the 2 changed files", "  verdict: skipped"}},
+		// The first commit adds every file of its tree.
+		{first.String(), "b.go", []string{"  file of the tree of commit " + first.String()[:7], "  among the 2 changed files", "  verdict: checked"}},
+		{first.String(), "c.go", []string{"  not a file of the tree of commit " + first.String()[:7], "  verdict: skipped"}},
+	} {
+		lines := explain(t, tr, tc.name, &Options{Commit: tc.commit})
+		for _, want := range tc.want {
+			if !hasLine(lines, want) {
+				t.Errorf("Explain(%s) of commit %s =\n%s\nwant a line starting with %q", tc.name, tc.commit, strings.Join(lines, "\n"), want)
+			}
+		}
+	}
+}
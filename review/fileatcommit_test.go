@@ -0,0 +1,40 @@
+package review
+
+import (
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+func TestFileAtCommit(t *testing.T) {
+	tr := newTestRepo(t)
+	root, second, side, _ := history(tr)
+	for _, tc := range []struct {
+		commit core.Hash
+		path   string
+		want   string
+	}{
+		{root, "a", "1\n"},
+		{second, "a", "2\n"},
+		{side, "b", "1\n"},
+	} {
+		f, err := tr.FileAtCommit(tc.commit, tc.path)
+		if err != nil {
+			t.Errorf("FileAtCommit(%s, %s): %s", tc.commit, tc.path, err)
+			continue
+		}
+
+		if got, err := f.Contents(); err != nil || got != tc.want || f.Name != tc.path {
+			t.Errorf("FileAtCommit(%s, %s) = %s %q, %v, want %q", tc.commit, tc.path, f.Name, got, err, tc.want)
+		}
+	}
+
+	if _, err := tr.FileAtCommit(root, "b"); err != git.ErrFileNotFound {
+		t.Errorf("FileAtCommit of a missing file = %v, want %s", err, git.ErrFileNotFound)
+	}
+
+	if _, err := tr.FileAtCommit(core.ComputeHash(core.CommitObject, []byte("missing")), "a"); err == nil {
+		t.Errorf("FileAtCommit of a missing commit succeeded")
+	}
+}
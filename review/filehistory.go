@@ -0,0 +1,168 @@
+package review
+
+import (
+	"io"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// FileHistoryIter iterates over the commits that changed a file, see
+// FileHistory.
+type FileHistoryIter struct {
+	r      *git.Repository
+	path   string
+	queue  []*git.Commit
+	queued map[core.Hash]bool
+}
+
+// FileHistory returns an iterator over the commits reachable from head that
+// changed the file at path, most recent first by committer date. As git log
+// does with a path, a merge is returned only when the file differs from all
+// of its parents, otherwise history is followed through the first parent
+// having the same file, skipping the side branches that didn't change it.
+// Commits adding or deleting the file are returned, the file being absent
+// before and after them. Parents missing from a shallow clone end the
+// history.
+func FileHistory(r *git.Repository, head *git.Commit, path string) *FileHistoryIter {
+	return &FileHistoryIter{
+		r:      r,
+		path:   path,
+		queue:  []*git.Commit{head},
+		queued: map[core.Hash]bool{head.Hash: true},
+	}
+}
+
+// Next returns the next commit that changed the file, io.EOF after the last
+// one.
+func (it *FileHistoryIter) Next() (*git.Commit, error) {
+	for len(it.queue) > 0 {
+		latest := 0
+		for i, c := range it.queue {
+			if c.Committer.When.After(it.queue[latest].Committer.When) {
+				latest = i
+			}
+		}
+
+		c := it.queue[latest]
+		it.queue = append(it.queue[:latest], it.queue[latest+1:]...)
+		changed, follow, err := it.compare(c)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range follow {
+			if !it.queued[p.Hash] {
+				it.queued[p.Hash] = true
+				it.queue = append(it.queue, p)
+			}
+		}
+
+		if changed {
+			return c, nil
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// ForEach calls cb for every commit that changed the file, stopping at the
+// first error returned by cb.
+func (it *FileHistoryIter) ForEach(cb func(*git.Commit) error) error {
+	for {
+		c, err := it.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := cb(c); err != nil {
+			return err
+		}
+	}
+}
+
+// compare returns whether c changed the file and the parents whose history
+// is followed: the first parent with the same file, or all of them.
+func (it *FileHistoryIter) compare(c *git.Commit) (bool, []*git.Commit, error) {
+	entry, err := commitEntry(c, it.path)
+	if err != nil {
+		return false, nil, err
+	}
+
+	parents, err := existingParents(it.r, c)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if len(parents) == 0 {
+		return entry != nil, nil, nil
+	}
+
+	for _, p := range parents {
+		pentry, err := commitEntry(p, it.path)
+		if err != nil {
+			return false, nil, err
+		}
+
+		if sameEntry(entry, pentry) {
+			return false, []*git.Commit{p}, nil
+		}
+	}
+
+	return true, parents, nil
+}
+
+// commitEntry returns the tree entry of the file at path in the commit, nil
+// if there is no such file. Only the trees along the path are read.
+func commitEntry(c *git.Commit, path string) (*git.TreeEntry, error) {
+	t, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	names := strings.Split(path, "/")
+	for i, name := range names {
+		var entry *git.TreeEntry
+		for j := range t.Entries {
+			if t.Entries[j].Name == name {
+				entry = &t.Entries[j]
+				break
+			}
+		}
+
+		if entry == nil {
+			return nil, nil
+		}
+
+		if i == len(names)-1 {
+			if entry.Mode.IsDir() {
+				return nil, nil
+			}
+
+			return entry, nil
+		}
+
+		if !entry.Mode.IsDir() {
+			return nil, nil
+		}
+
+		if t, err = t.Dir(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func sameEntry(a, b *git.TreeEntry) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Hash == b.Hash && a.Mode == b.Mode
+}
@@ -0,0 +1,83 @@
+package review
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// fileHistory returns the names of the commits returned by FileHistory from
+// head for the path.
+func fileHistory(t *testing.T, tr *testRepo, head core.Hash, path string, names map[core.Hash]string) []string {
+	c, err := tr.Commit(head)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	err = FileHistory(tr.Repository, c, path).ForEach(func(c *git.Commit) error {
+		got = append(got, names[c.Hash])
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return got
+}
+
+func TestFileHistoryPrunesTreesame(t *testing.T) {
+	tr := newTestRepo(t)
+	base := tr.commit(map[string]string{"f": "1\n", "g": "1\n"}, "base")
+	side1 := tr.commit(map[string]string{"f": "side\n", "g": "1\n"}, "side changes f", base)
+	side2 := tr.commit(map[string]string{"f": "1\n", "g": "2\n"}, "side reverts f", side1)
+	main := tr.commit(map[string]string{"f": "2\n", "g": "1\n"}, "main changes f", base)
+	merge := tr.commit(map[string]string{"f": "2\n", "g": "2\n"}, "merge", main, side2)
+	names := map[core.Hash]string{base: "base", side1: "side1", side2: "side2", main: "main", merge: "merge"}
+
+	// The merge has the f of main, so the side branch isn't followed.
+	if got, want := fileHistory(t, tr, merge, "f", names), []string{"main", "base"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FileHistory(f) = %q, want %q", got, want)
+	}
+
+	if got, want := fileHistory(t, tr, merge, "g", names), []string{"side2", "base"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FileHistory(g) = %q, want %q", got, want)
+	}
+}
+
+func TestFileHistoryAddedDeleted(t *testing.T) {
+	tr := newTestRepo(t)
+	root := tr.commit(map[string]string{"g": "1\n"}, "root")
+	added := tr.commit(map[string]string{"f": "1\n", "g": "1\n"}, "add f", root)
+	changed := tr.commit(map[string]string{"f": "2\n", "g": "1\n"}, "change f", added)
+	other := tr.commit(map[string]string{"f": "2\n", "g": "2\n"}, "change g", changed)
+	deleted := tr.commit(map[string]string{"g": "2\n"}, "delete f", other)
+	last := tr.commit(map[string]string{"g": "3\n"}, "change g", deleted)
+	names := map[core.Hash]string{root: "root", added: "added", changed: "changed", other: "other", deleted: "deleted", last: "last"}
+
+	if got, want := fileHistory(t, tr, last, "f", names), []string{"deleted", "changed", "added"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FileHistory(f) = %q, want %q", got, want)
+	}
+
+	if got := fileHistory(t, tr, last, "missing", names); got != nil {
+		t.Errorf("FileHistory(missing) = %q, want none", got)
+	}
+}
+
+func TestFileHistoryBothSides(t *testing.T) {
+	tr := newTestRepo(t)
+	base := tr.commit(map[string]string{"f": "1\n"}, "base")
+	left := tr.commit(map[string]string{"f": "left\n"}, "left", base)
+	right := tr.commit(map[string]string{"f": "right\n"}, "right", base)
+	unrelated := tr.commit(map[string]string{"f": "right\n", "g": "1\n"}, "add g", right)
+	merge := tr.commit(map[string]string{"f": "left\nright\n", "g": "1\n"}, "merge", left, unrelated)
+	names := map[core.Hash]string{base: "base", left: "left", right: "right", unrelated: "unrelated", merge: "merge"}
+
+	// The merge differs from both parents, so it is returned and both
+	// sides are followed, most recent first.
+	if got, want := fileHistory(t, tr, merge, "f", names), []string{"merge", "right", "left", "base"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FileHistory(f) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package review
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/mailmap"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// authorFilter selects the commits to review by author and committer.
+// Identities are normalized with the mailmap before being matched, so commits
+// made under a former name or email match the canonical identity.
+type authorFilter struct {
+	mailmap   *mailmap.Mailmap
+	author    *regexp.Regexp
+	committer *regexp.Regexp
+	excluded  []string
+}
+
+func newAuthorFilter(mm *mailmap.Mailmap, o *Options) (*authorFilter, error) {
+	f := &authorFilter{mailmap: mm, excluded: o.ExcludedAuthors}
+	var err error
+	if f.author, err = compileIdentity("author", o.Author); err != nil {
+		return nil, err
+	}
+
+	if f.committer, err = compileIdentity("committer", o.Committer); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// compileIdentity compiles the pattern of the role, nil if empty.
+func compileIdentity(role, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern %q: %s", role, pattern, err)
+	}
+
+	return re, nil
+}
+
+// Match normalizes the identities of c and returns true if its author and
+// committer match the patterns, given as regular expressions on "Name
+// <email>", and its author isn't excluded.
+func (f *authorFilter) Match(c *git.Commit) bool {
+	f.mailmap.Normalize(c)
+	for _, id := range f.excluded {
+		if isIdentity(c.Author, id) {
+			return false
+		}
+	}
+
+	return matchIdentity(f.author, c.Author) && matchIdentity(f.committer, c.Committer)
+}
+
+func matchIdentity(re *regexp.Regexp, s git.Signature) bool {
+	return re == nil || re.MatchString(fmt.Sprintf("%s <%s>", s.Name, s.Email))
+}
+
+// isIdentity returns true if id is the name, the email or "Name <email>" of
+// the signature, ignoring case, so bot accounts like dependabot[bot] are
+// named as they are without escaping.
+func isIdentity(s git.Signature, id string) bool {
+	return strings.EqualFold(id, s.Name) || strings.EqualFold(id, s.Email) ||
+		strings.EqualFold(id, fmt.Sprintf("%s <%s>", s.Name, s.Email))
+}
+
+// reviewHistory runs the commit rules on every commit of the range whose
+// author passes the filter and that changes a file of the pathspec of the
+// options, in their order, adding their violations to the result.
+func reviewHistory(res *Result, r *git.Repository, head, since *git.Commit, filter *authorFilter, o *Options) error {
+	spec := newPathspec(o)
+	return walkOrdered(r, head, since, o.Merges, o.Order, func(c *git.Commit) error {
+		ok, err := spec.touches(r, c)
+		if err != nil {
+			return err
+		}
+
+		if !ok || !filter.Match(c) {
+			return nil
+		}
+
+		vs, err := rules.CheckCommit(c, &o.Options)
+		res.Violations = append(res.Violations, vs...)
+		res.Commits++
+		return err
+	})
+}
+
+// Merge commit handling of the reviewed history, see Options.Merges.
+const (
+	MergesAll         = "all"
+	MergesSkip        = "skip"
+	MergesFirstParent = "first-parent"
+)
+
+// checkMerges returns an error if merges isn't a merge commit handling.
+func checkMerges(merges string) error {
+	switch merges {
+	case "", MergesAll, MergesSkip, MergesFirstParent:
+		return nil
+	}
+
+	return fmt.Errorf("invalid merge handling %q, expected %s, %s or %s", merges, MergesAll, MergesSkip, MergesFirstParent)
+}
+
+// walkRange calls cb for every commit reachable from head but not from
+// since, or for every commit reachable from head if since is nil, handling
+// merge commits as chosen by merges.
+func walkRange(r *git.Repository, head, since *git.Commit, merges string, cb func(*git.Commit) error) error {
+	seen := make(map[core.Hash]bool)
+	if since != nil {
+		var err error
+		if seen, err = excludedCommits(r, head, since); err != nil {
+			return err
+		}
+	}
+
+	switch merges {
+	case MergesFirstParent:
+		return walkFirstParents(r, head, seen, cb)
+	case MergesSkip:
+		return walkHistory(r, head, seen, func(c *git.Commit) error {
+			if c.NumParents() > 1 {
+				return nil
+			}
+
+			return cb(c)
+		})
+	}
+
+	return walkHistory(r, head, seen, cb)
+}
+
+// walkFirstParents calls cb for start and its first-parent ancestors, until
+// a commit already seen or missing from a shallow clone.
+func walkFirstParents(r *git.Repository, start *git.Commit, seen map[core.Hash]bool, cb func(*git.Commit) error) error {
+	for c := start; !seen[c.Hash]; {
+		seen[c.Hash] = true
+		if err := cb(c); err != nil {
+			return err
+		}
+
+		if c.NumParents() == 0 {
+			return nil
+		}
+
+		p, err := r.Commit(c.ParentHashes()[0])
+		if rules.IsMissing(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		c = p
+	}
+
+	return nil
+}
+
+// excludedCommits returns the commits reachable from since met walking back
+// from head and since together, by decreasing committer date, until only
+// commits reachable from since are left to walk. Walking the history of head
+// stops at these commits, near the merge base, so the history of since
+// isn't walked to its root. As in git, a commit dated before one of its
+// ancestors by a wrong clock may let the walk stop too early.
+func excludedCommits(r *git.Repository, head, since *git.Commit) (map[core.Hash]bool, error) {
+	excluded := map[core.Hash]bool{since.Hash: true}
+	queued := map[core.Hash]bool{head.Hash: true, since.Hash: true}
+	queue := []*git.Commit{head, since}
+	for len(queue) > 0 {
+		latest := 0
+		included := false
+		for i, c := range queue {
+			included = included || !excluded[c.Hash]
+			if c.Committer.When.After(queue[latest].Committer.When) {
+				latest = i
+			}
+		}
+
+		if !included {
+			break
+		}
+
+		c := queue[latest]
+		queue = append(queue[:latest], queue[latest+1:]...)
+		parents, err := existingParents(r, c)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range parents {
+			if excluded[c.Hash] {
+				excluded[p.Hash] = true
+			}
+
+			if !queued[p.Hash] {
+				queued[p.Hash] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+
+	return excluded, nil
+}
+
+// walkHistory calls cb for start and its ancestors, depth first, skipping the
+// commits already seen and their ancestors. Parents missing from the object
+// storage, as at the boundary of a shallow clone, end the history.
+func walkHistory(r *git.Repository, start *git.Commit, seen map[core.Hash]bool, cb func(*git.Commit) error) error {
+	stack := []*git.Commit{start}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[c.Hash] {
+			continue
+		}
+
+		seen[c.Hash] = true
+		if err := cb(c); err != nil {
+			return err
+		}
+
+		parents := c.ParentHashes()
+		for i := len(parents) - 1; i >= 0; i-- {
+			p, err := r.Commit(parents[i])
+			if rules.IsMissing(err) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			stack = append(stack, p)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package review
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+func TestAuthorFilterMailmap(t *testing.T) {
+	tr := newTestRepo(t)
+	files := map[string]string{".mailmap": "Jane Doe <jane@example.com> <jane@old.example>\nJane Doe <jane@example.com> jdoe <jane@example.com>\n"}
+	tr.Author = "Bob <bob@example.com>"
+	h := tr.commit(files, "Add the mailmap")
+	for _, author := range []string{"Jane <jane@old.example>", "jdoe <jane@example.com>", "Bob <bob@example.com>"} {
+		tr.Author = author
+		h = tr.commit(files, "Change nothing", h)
+	}
+
+	tr.SetRef("refs/heads/main", h)
+	tr.SetHead("main")
+
+	for _, tc := range []struct {
+		author, committer string
+		excluded          []string
+		want              int
+	}{
+		{"", "", nil, 4},
+		{"^Jane Doe <jane@example.com>$", "", nil, 2},
+		{"jane@old.example", "", nil, 0},
+		{"", "^Jane Doe ", nil, 2},
+		{"", "", []string{"jane@example.com"}, 2},
+		{"", "", []string{"jdoe"}, 4},
+	} {
+		o := &Options{Author: tc.author, Committer: tc.committer, ExcludedAuthors: tc.excluded}
+		res, err := Run(tr.Repository, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Commits != tc.want {
+			t.Errorf("-author %q -committer %q excluding %q: %d commits, want %d", tc.author, tc.committer, tc.excluded, res.Commits, tc.want)
+		}
+	}
+}
+
+func TestWalkRangeMerges(t *testing.T) {
+	tr := newTestRepo(t)
+
+	// root - a ------ m - h
+	//    \           /
+	//     s1 - s2 --
+	names := make(map[core.Hash]string)
+	commit := func(name string, parents ...core.Hash) core.Hash {
+		h := tr.commit(map[string]string{name: name}, name, parents...)
+		names[h] = name
+		return h
+	}
+
+	root := commit("root")
+	a := commit("a", root)
+	s1 := commit("s1", root)
+	s2 := commit("s2", s1)
+	m := commit("m", a, s2)
+	head := commit("h", m)
+
+	for _, tc := range []struct {
+		since  core.Hash
+		merges string
+		want   []string
+	}{
+		{core.ZeroHash, "", []string{"a", "h", "m", "root", "s1", "s2"}},
+		{core.ZeroHash, MergesAll, []string{"a", "h", "m", "root", "s1", "s2"}},
+		{core.ZeroHash, MergesSkip, []string{"a", "h", "root", "s1", "s2"}},
+		{core.ZeroHash, MergesFirstParent, []string{"a", "h", "m", "root"}},
+		{a, MergesAll, []string{"h", "m", "s1", "s2"}},
+		{a, MergesSkip, []string{"h", "s1", "s2"}},
+		{a, MergesFirstParent, []string{"h", "m"}},
+		{s2, MergesAll, []string{"a", "h", "m"}},
+		{s2, MergesSkip, []string{"a", "h"}},
+		{s2, MergesFirstParent, []string{"a", "h", "m"}},
+		{m, MergesAll, []string{"h"}},
+		{head, MergesFirstParent, nil},
+	} {
+		c, err := tr.Commit(head)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var since *git.Commit
+		if tc.since != core.ZeroHash {
+			if since, err = tr.Commit(tc.since); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var got []string
+		err = walkRange(tr.Repository, c, since, tc.merges, func(c *git.Commit) error {
+			got = append(got, names[c.Hash])
+			return nil
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("walkRange(since %s, merges %q) = %q, want %q", names[tc.since], tc.merges, got, tc.want)
+		}
+	}
+}
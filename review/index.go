@@ -0,0 +1,134 @@
+package review
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gunjan5/code-review-bot/gitattributes"
+	"github.com/gunjan5/code-review-bot/gitindex"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// RunIndex reviews the files staged in the index, what the next commit will
+// hold, skipping those identical in the tree of the commit pointed by Ref,
+// HEAD by default, if the repository has commits. Only the file rules and
+// the plugins are run, with the .gitattributes of the index. Unmerged paths
+// are added to the warnings, paths added with git add -N and submodules are
+// skipped.
+func RunIndex(r *git.Repository, idx *gitindex.Index, o *Options) (*Result, error) {
+	o, err := indexRepoConfig(r, idx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := indexBase(r, o)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := indexPathFilter(r, idx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	q := newFileQueue(o)
+	unmerged := make(map[string]bool)
+	for _, e := range idx.Entries {
+		switch {
+		case e.Stage > 0:
+			if !unmerged[e.Name] {
+				unmerged[e.Name] = true
+				res.Warnings = append(res.Warnings, fmt.Sprintf("unmerged path %s is not checked", e.Name))
+			}
+
+			continue
+		case e.IntentToAdd || e.IsSubmodule() || !paths.Match(e.Name):
+			continue
+		}
+
+		if committed, ok := head[e.Name]; ok && committed.Hash == e.Hash && committed.Mode == e.Mode {
+			continue
+		}
+
+		blob, err := r.Blob(e.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("staged %s: %s", e.Name, err)
+		}
+
+		if err := q.Add(git.NewFile(e.Name, e.Mode, blob)); err != nil {
+			break
+		}
+	}
+
+	vs, err := q.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	res.Violations = vs
+	res.Paths = q.Paths
+	return res, nil
+}
+
+// indexBase returns the entries of the tree the index is compared to, by
+// path, none if the repository has no commits.
+func indexBase(r *git.Repository, o *Options) (map[string]git.TreeEntry, error) {
+	entries := make(map[string]git.TreeEntry)
+	c, err := ResolveCommit(r, o.Ref)
+	if err != nil {
+		if empty, _ := r.IsEmpty(); empty {
+			return entries, nil
+		}
+
+		return nil, err
+	}
+
+	t, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	iter := git.NewTreeIter(r, t, true)
+	defer iter.Close()
+	for {
+		name, e, err := iter.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries[name] = e
+	}
+}
+
+// indexPathFilter builds the path filter from the .gitattributes staged in
+// the index.
+func indexPathFilter(r *git.Repository, idx *gitindex.Index, o *Options) (*pathFilter, error) {
+	attrs := &gitattributes.Attributes{}
+	for _, e := range idx.Entries {
+		if e.Name != ".gitattributes" || e.Stage > 0 {
+			continue
+		}
+
+		blob, err := r.Blob(e.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("staged .gitattributes: %s", err)
+		}
+
+		rd, err := blob.Reader()
+		if err != nil {
+			return nil, err
+		}
+		defer rd.Close()
+
+		if attrs, err = gitattributes.Parse(rd); err != nil {
+			return nil, fmt.Errorf(".gitattributes: %s", err)
+		}
+	}
+
+	return newPathFilter(attrs, o), nil
+}
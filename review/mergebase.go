@@ -0,0 +1,154 @@
+package review
+
+import (
+	"errors"
+
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// ErrNoMergeBase is returned by MergeBase for commits without a common
+// ancestor.
+var ErrNoMergeBase = errors.New("no common ancestor")
+
+// MergeBase returns the best common ancestor of the commits a and b, one
+// that isn't an ancestor of any other common ancestor. When there are
+// several, as after criss-cross merges, the first found walking from a is
+// returned.
+func MergeBase(r *git.Repository, a, b *git.Commit) (*git.Commit, error) {
+	ofB := make(map[core.Hash]bool)
+	err := walkHistory(r, b, ofB, func(*git.Commit) error {
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The common ancestors closest to a, their ancestors being skipped.
+	var candidates []*git.Commit
+	seen := make(map[core.Hash]bool)
+	stack := []*git.Commit{a}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[c.Hash] {
+			continue
+		}
+
+		seen[c.Hash] = true
+		if ofB[c.Hash] {
+			candidates = append(candidates, c)
+			continue
+		}
+
+		parents, err := existingParents(r, c)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := len(parents) - 1; i >= 0; i-- {
+			stack = append(stack, parents[i])
+		}
+	}
+
+	return bestCandidate(r, candidates)
+}
+
+// MergeBaseHash returns the hash of the merge base of the commits with the
+// hashes a and b, see MergeBase.
+func MergeBaseHash(r *git.Repository, a, b core.Hash) (core.Hash, error) {
+	ca, err := r.Commit(a)
+	if err != nil {
+		return core.ZeroHash, err
+	}
+
+	cb, err := r.Commit(b)
+	if err != nil {
+		return core.ZeroHash, err
+	}
+
+	mb, err := MergeBase(r, ca, cb)
+	if err != nil {
+		return core.ZeroHash, err
+	}
+
+	return mb.Hash, nil
+}
+
+// IsAncestor returns true if the commit with the hash a is b or one of its
+// ancestors. It returns false if a isn't in the repository, as the old head
+// of a branch whose history was rewritten and fetched again.
+func IsAncestor(r *git.Repository, a, b core.Hash) (bool, error) {
+	if _, err := r.Commit(a); err != nil {
+		if rules.IsMissing(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	mb, err := MergeBaseHash(r, a, b)
+	if err == ErrNoMergeBase {
+		return false, nil
+	}
+
+	return mb == a, err
+}
+
+// bestCandidate returns the first of the common ancestors that isn't an
+// ancestor of another.
+func bestCandidate(r *git.Repository, candidates []*git.Commit) (*git.Commit, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoMergeBase
+	}
+
+	redundant := make(map[core.Hash]bool)
+	for _, c := range candidates {
+		if redundant[c.Hash] {
+			continue
+		}
+
+		ancestors := make(map[core.Hash]bool)
+		err := walkHistory(r, c, ancestors, func(a *git.Commit) error {
+			if a.Hash != c.Hash {
+				redundant[a.Hash] = true
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range candidates {
+		if !redundant[c.Hash] {
+			return c, nil
+		}
+	}
+
+	return candidates[0], nil
+}
+
+// existingParents returns the parents of c, skipping those missing from the
+// object storage at the boundary of a shallow clone.
+func existingParents(r *git.Repository, c *git.Commit) ([]*git.Commit, error) {
+	var parents []*git.Commit
+	for _, h := range c.ParentHashes() {
+		p, err := r.Commit(h)
+		if rules.IsMissing(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		parents = append(parents, p)
+	}
+
+	return parents, nil
+}
@@ -0,0 +1,175 @@
+package review
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// Orders of the reviewed commits, see Options.Order. Every order shows the
+// children of a commit before it.
+const (
+	// OrderDate shows the commits by decreasing committer date, as git log
+	// --date-order.
+	OrderDate = "date"
+	// OrderAuthorDate shows the commits by decreasing author date, as git
+	// log --author-date-order.
+	OrderAuthorDate = "author-date"
+	// OrderTopo shows the commits of a line of history together, without
+	// interleaving those of merged branches, as git log --topo-order.
+	OrderTopo = "topo"
+)
+
+// checkOrder returns an error if order isn't a commit order.
+func checkOrder(order string) error {
+	switch order {
+	case "", OrderDate, OrderAuthorDate, OrderTopo:
+		return nil
+	}
+
+	return fmt.Errorf("invalid commit order %q, expected %s, %s or %s", order, OrderDate, OrderAuthorDate, OrderTopo)
+}
+
+// walkOrdered calls cb for the commits of the range, see walkRange, in the
+// order, OrderDate if empty. Every commit of the range is read before the
+// first call.
+func walkOrdered(r *git.Repository, head, since *git.Commit, merges, order string, cb func(*git.Commit) error) error {
+	var commits []*git.Commit
+	err := walkRange(r, head, since, merges, func(c *git.Commit) error {
+		commits = append(commits, c)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, c := range sortCommits(commits, order) {
+		if err := cb(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortCommits returns the commits in the order, every commit after its
+// children among them. A commit is taken once all its children were:
+// among those ready, the latest by committer or author date, or for
+// OrderTopo the last made ready, so that the parents of a commit follow it
+// closely.
+func sortCommits(commits []*git.Commit, order string) []*git.Commit {
+	in := make(map[core.Hash]*git.Commit, len(commits))
+	for _, c := range commits {
+		in[c.Hash] = c
+	}
+
+	children := make(map[core.Hash]int, len(commits))
+	for _, c := range commits {
+		for _, p := range c.ParentHashes() {
+			if in[p] != nil {
+				children[p]++
+			}
+		}
+	}
+
+	ready := &readyQueue{order: order}
+	for _, c := range commits {
+		if children[c.Hash] == 0 {
+			ready.push(c)
+		}
+	}
+
+	sorted := make([]*git.Commit, 0, len(commits))
+	for ready.Len() > 0 {
+		c := ready.pop()
+		sorted = append(sorted, c)
+		for _, p := range c.ParentHashes() {
+			if in[p] == nil {
+				continue
+			}
+
+			if children[p]--; children[p] == 0 {
+				ready.push(in[p])
+			}
+		}
+	}
+
+	return sorted
+}
+
+// readyQueue holds the commits whose children were all taken, a stack for
+// OrderTopo and a heap by date otherwise.
+type readyQueue struct {
+	order   string
+	commits []*git.Commit
+	// seq numbers the pushed commits, the first pushed winning ties.
+	seq []int
+	n   int
+}
+
+func (q *readyQueue) push(c *git.Commit) {
+	if q.order == OrderTopo {
+		q.commits = append(q.commits, c)
+		return
+	}
+
+	q.n++
+	heap.Push(q, queued{c, q.n})
+}
+
+func (q *readyQueue) pop() *git.Commit {
+	if q.order == OrderTopo {
+		c := q.commits[len(q.commits)-1]
+		q.commits = q.commits[:len(q.commits)-1]
+		return c
+	}
+
+	return heap.Pop(q).(queued).c
+}
+
+// queued is a commit of the heap and its push sequence number.
+type queued struct {
+	c   *git.Commit
+	seq int
+}
+
+func (q *readyQueue) when(i int) time.Time {
+	if q.order == OrderAuthorDate {
+		return q.commits[i].Author.When
+	}
+
+	return q.commits[i].Committer.When
+}
+
+func (q *readyQueue) Len() int { return len(q.commits) }
+
+func (q *readyQueue) Less(i, j int) bool {
+	a, b := q.when(i), q.when(j)
+	if !a.Equal(b) {
+		return a.After(b)
+	}
+
+	return q.seq[i] < q.seq[j]
+}
+
+func (q *readyQueue) Swap(i, j int) {
+	q.commits[i], q.commits[j] = q.commits[j], q.commits[i]
+	q.seq[i], q.seq[j] = q.seq[j], q.seq[i]
+}
+
+func (q *readyQueue) Push(x interface{}) {
+	e := x.(queued)
+	q.commits = append(q.commits, e.c)
+	q.seq = append(q.seq, e.seq)
+}
+
+func (q *readyQueue) Pop() interface{} {
+	n := len(q.commits) - 1
+	e := queued{q.commits[n], q.seq[n]}
+	q.commits, q.seq = q.commits[:n], q.seq[:n]
+	return e
+}
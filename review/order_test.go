@@ -0,0 +1,100 @@
+package review
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// commitAt writes an empty commit with the message, authored and committed
+// at the seconds given, and the parents.
+func (tr *testRepo) commitAt(msg string, author, committer int, parents ...core.Hash) core.Hash {
+	data := fmt.Sprintf("tree %s\n", tr.WriteTree(nil))
+	for _, p := range parents {
+		data += fmt.Sprintf("parent %s\n", p)
+	}
+
+	data += fmt.Sprintf("author A <a@example.com> %d +0000\n", 1500000000+author)
+	data += fmt.Sprintf("committer A <a@example.com> %d +0000\n\n%s\n", 1500000000+committer, msg)
+	return tr.Write(core.CommitObject, []byte(data))
+}
+
+func TestSortCommits(t *testing.T) {
+	tr := newTestRepo(t)
+
+	// root - a - c - m
+	//    \          /
+	//     b ---- d
+	root := tr.commitAt("root", 1, 1)
+	a := tr.commitAt("a", 6, 2, root)
+	b := tr.commitAt("b", 2, 3, root)
+	c := tr.commitAt("c", 3, 5, a)
+	d := tr.commitAt("d", 4, 6, b)
+	m := tr.commitAt("m", 9, 7, c, d)
+
+	// p and q, made at the same time, are shown in the order of the
+	// parents of their merges.
+	p := tr.commitAt("p", 8, 8, m)
+	q := tr.commitAt("q", 8, 8, m)
+	pq := tr.commitAt("pq", 9, 9, p, q)
+	qp := tr.commitAt("qp", 9, 9, q, p)
+
+	for _, tc := range []struct {
+		order   string
+		commits []core.Hash
+		want    []string
+	}{
+		{"", []core.Hash{root, a, b, c, d, m}, []string{"m", "d", "c", "b", "a", "root"}},
+		{OrderDate, []core.Hash{root, a, b, c, d, m}, []string{"m", "d", "c", "b", "a", "root"}},
+		{OrderAuthorDate, []core.Hash{root, a, b, c, d, m}, []string{"m", "d", "c", "a", "b", "root"}},
+		{OrderTopo, []core.Hash{root, a, b, c, d, m}, []string{"m", "d", "b", "c", "a", "root"}},
+		{"random", []core.Hash{root, a, b, c, d, m}, []string{"m", "d", "c", "b", "a", "root"}},
+		{OrderDate, []core.Hash{m, p, q, pq}, []string{"pq", "p", "q", "m"}},
+		{OrderDate, []core.Hash{m, p, q, qp}, []string{"qp", "q", "p", "m"}},
+		{OrderAuthorDate, []core.Hash{m, p, q, pq}, []string{"pq", "p", "q", "m"}},
+		{OrderTopo, []core.Hash{m, p, q, pq}, []string{"pq", "q", "p", "m"}},
+
+		// Commits without children among them are taken by date, the
+		// first given winning ties.
+		{OrderDate, []core.Hash{p, q}, []string{"p", "q"}},
+		{OrderDate, []core.Hash{q, p}, []string{"q", "p"}},
+		{OrderDate, []core.Hash{a, b, d}, []string{"d", "b", "a"}},
+		{OrderDate, nil, []string{}},
+	} {
+		commits := make([]*git.Commit, len(tc.commits))
+		for i, h := range tc.commits {
+			c, err := tr.Commit(h)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			commits[i] = c
+		}
+
+		got := []string{}
+		for _, c := range sortCommits(commits, tc.order) {
+			got = append(got, c.Message[:len(c.Message)-1])
+		}
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("sortCommits(%d commits, %q) = %q, want %q", len(commits), tc.order, got, tc.want)
+		}
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	for _, order := range []string{"", OrderDate, OrderAuthorDate, OrderTopo} {
+		if err := checkOrder(order); err != nil {
+			t.Errorf("checkOrder(%q) = %v", order, err)
+		}
+	}
+
+	for _, order := range []string{"random", "Date", "topo-order"} {
+		if err := checkOrder(order); err == nil {
+			t.Errorf("checkOrder(%q) succeeded", order)
+		}
+	}
+}
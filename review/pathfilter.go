@@ -0,0 +1,150 @@
+package review
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/gitattributes"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// generatedAttrs are the gitattributes, used by GitHub linguist, marking the
+// files not written by hand in the repository.
+var generatedAttrs = []string{"linguist-generated", "linguist-vendored"}
+
+// pathFilter selects the files to review. The files outside the pathspec
+// are skipped, and those marked as generated or vendored in the
+// .gitattributes unless includeGenerated is set.
+type pathFilter struct {
+	spec             pathspec
+	attrs            *gitattributes.Attributes
+	includeGenerated bool
+}
+
+func newPathFilter(attrs *gitattributes.Attributes, o *Options) *pathFilter {
+	return &pathFilter{spec: newPathspec(o), attrs: attrs, includeGenerated: o.IncludeGenerated}
+}
+
+// loadPathFilter builds the path filter from the .gitattributes of the tree.
+func loadPathFilter(t *git.Tree, o *Options) (*pathFilter, error) {
+	attrs, err := gitattributes.FromTree(t)
+	if err != nil {
+		return nil, fmt.Errorf(".gitattributes: %s", err)
+	}
+
+	return newPathFilter(attrs, o), nil
+}
+
+// Match returns true if the file at the path must be reviewed.
+func (f *pathFilter) Match(name string) bool {
+	if !f.spec.Match(name) {
+		return false
+	}
+
+	if f.includeGenerated {
+		return true
+	}
+
+	for _, attr := range generatedAttrs {
+		if f.attrs.IsTrue(name, attr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pathspec restricts the review to the paths matching one of the include
+// globs, all if there is none, and none of the exclude globs. A glob matches
+// a path when it matches the path itself or one of its directories, see
+// rules.MatchPath, so both src and src/** select the files under src.
+type pathspec struct {
+	include, exclude []string
+}
+
+func newPathspec(o *Options) pathspec {
+	return pathspec{include: o.Paths, exclude: o.ExcludedPaths}
+}
+
+// Match returns true if the path is selected by the pathspec.
+func (s pathspec) Match(name string) bool {
+	if len(s.include) > 0 && matchingGlob(s.include, name) == "" {
+		return false
+	}
+
+	return matchingGlob(s.exclude, name) == ""
+}
+
+// matchingGlob returns the first of the globs matching the path or one of
+// its directories, an empty string if none.
+func matchingGlob(globs []string, name string) string {
+	for _, glob := range globs {
+		for p := name; p != "." && p != "/"; p = path.Dir(p) {
+			if rules.MatchPath(glob, p) {
+				return glob
+			}
+		}
+	}
+
+	return ""
+}
+
+// touches returns true if the commit changes a file selected by the
+// pathspec. As git log does with paths, a merge must change such a file
+// compared to each of its parents, not only to the branch it merges. Parents
+// missing from a shallow clone are handled as empty trees. Every commit
+// touches a pathspec that doesn't restrict the paths.
+func (s pathspec) touches(r *git.Repository, c *git.Commit) (bool, error) {
+	if len(s.include) == 0 && len(s.exclude) == 0 {
+		return true, nil
+	}
+
+	t, err := c.Tree()
+	if err != nil {
+		return false, err
+	}
+
+	if c.NumParents() == 0 {
+		return s.changes(nil, t)
+	}
+
+	for _, h := range c.ParentHashes() {
+		var pt *git.Tree
+		p, err := r.Commit(h)
+		if err != nil && !rules.IsMissing(err) {
+			return false, err
+		}
+
+		if err == nil {
+			if pt, err = p.Tree(); err != nil {
+				return false, err
+			}
+		}
+
+		ok, err := s.changes(pt, t)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// changes returns true if a file selected by the pathspec differs between
+// the trees, the old one nil for an empty tree.
+func (s pathspec) changes(old, new *git.Tree) (bool, error) {
+	changes, err := diff.TreeDiffWith(old, new, &diff.Options{})
+	if err != nil {
+		return false, err
+	}
+
+	for _, ch := range changes {
+		if (ch.From.Path != "" && s.Match(ch.From.Path)) || (ch.To.Path != "" && s.Match(ch.To.Path)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
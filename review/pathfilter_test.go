@@ -0,0 +1,115 @@
+package review
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+func TestGeneratedFiles(t *testing.T) {
+	tr := newTestRepo(t)
+	files := map[string]string{
+		".gitattributes": "*.pb.go linguist-generated\nlib_*.go linguist-vendored\nlib_own.go -linguist-vendored\n",
+		"api.pb.go":      "package api \n",
+		"lib_json.go":    "package json \n",
+		"lib_own.go":     "package own \n",
+		"main.go":        "package main \n",
+	}
+
+	h := tr.commit(files, "Add the files")
+	tr.SetRef("refs/heads/main", h)
+	tr.SetHead("main")
+
+	for _, tc := range []struct {
+		include bool
+		want    []string
+	}{
+		{false, []string{"lib_own.go", "main.go"}},
+		{true, []string{"api.pb.go", "lib_json.go", "lib_own.go", "main.go"}},
+	} {
+		o := &Options{IncludeGenerated: tc.include}
+		o.NoTrailingWhitespace = true
+		res, err := Run(tr.Repository, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, v := range res.Violations {
+			if v.Rule == "trailing-whitespace" {
+				got = append(got, v.Path)
+			}
+		}
+
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("-include-generated=%v: violations in %v, want %v", tc.include, got, tc.want)
+		}
+	}
+}
+
+func TestPathspecTouches(t *testing.T) {
+	tr := newTestRepo(t)
+
+	// root - docs - merge
+	//    \          /
+	//     src -----
+	//
+	// evil merge has the same parents as merge but changes src/a.go again.
+	files := map[string]string{"src/a.go": "a", "src/gen/b.go": "b", "docs/x.md": "x"}
+	with := func(name, content string) map[string]string {
+		changed := make(map[string]string)
+		for n, c := range files {
+			changed[n] = c
+		}
+
+		changed[name] = content
+		return changed
+	}
+
+	root := tr.commit(files, "root")
+	docs := tr.commit(with("docs/x.md", "x2"), "docs", root)
+	src := tr.commit(with("src/a.go", "a2"), "src", root)
+	both := with("docs/x.md", "x2")
+	both["src/a.go"] = "a2"
+	merge := tr.commit(both, "merge", docs, src)
+	both["src/a.go"] = "a3"
+	evil := tr.commit(both, "evil merge", docs, src)
+	gen := tr.commit(with("src/gen/b.go", "b2"), "gen", root)
+	shallow := tr.commit(with("docs/x.md", "x2"), "shallow", core.NewHash("0123456789012345678901234567890123456789"))
+
+	for _, tc := range []struct {
+		name             string
+		commit           core.Hash
+		include, exclude []string
+		want             bool
+	}{
+		{"unrestricted", docs, nil, nil, true},
+		{"unrestricted merge", merge, nil, nil, true},
+		{"root", root, []string{"src"}, nil, true},
+		{"root excluded", root, []string{"src/gen"}, []string{"src/gen/b.go"}, false},
+		{"excluded only", docs, nil, []string{"docs"}, false},
+		{"included", src, []string{"src"}, nil, true},
+		{"not included", docs, []string{"src"}, nil, false},
+		{"not excluded", src, nil, []string{"docs"}, true},
+		{"excluded subdirectory", gen, []string{"src"}, []string{"src/gen"}, false},
+		{"included subdirectory", gen, []string{"src/gen/*.go"}, nil, true},
+		{"merge of a branch", merge, []string{"src"}, nil, false},
+		{"merge of a branch excluded", merge, nil, []string{"src"}, false},
+		{"merge changing the path", evil, []string{"src"}, nil, true},
+		{"missing parent", shallow, []string{"src"}, nil, true},
+		{"missing parent excluded", shallow, nil, []string{"docs", "src"}, false},
+	} {
+		c, err := tr.Commit(tc.commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		spec := newPathspec(&Options{Paths: tc.include, ExcludedPaths: tc.exclude})
+		if ok, err := spec.touches(tr.Repository, c); ok != tc.want || err != nil {
+			t.Errorf("%s: touches(%q, excluding %q) = %v, %v, want %v", tc.name, tc.include, tc.exclude, ok, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package review
+
+import (
+	"sync"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// fileQueue reviews files on up to Options.Jobs goroutines, the files being
+// reviewed one after the other when Jobs is less than 2. The violations are
+// returned in the order the files were added, whatever the order the
+// reviews finished in, so the result doesn't depend on the scheduling.
+type fileQueue struct {
+	o    *Options
+	sem  chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	err  error
+	done []*[]report.Violation
+	// Paths are the paths of the files added.
+	Paths []string
+}
+
+func newFileQueue(o *Options) *fileQueue {
+	q := &fileQueue{o: o}
+	if o.Jobs > 1 {
+		q.sem = make(chan struct{}, o.Jobs)
+	}
+
+	return q
+}
+
+// Add reviews f, waiting for a goroutine to be free, and returns the error
+// of a previous review if any failed, the remaining files then needn't be
+// added. At most Jobs files are held in memory at once.
+func (q *fileQueue) Add(f *git.File) error {
+	vs := new([]report.Violation)
+	q.done = append(q.done, vs)
+	q.Paths = append(q.Paths, f.Name)
+	if q.sem == nil {
+		var err error
+		if *vs, err = reviewFile(f, q.o); err != nil {
+			q.fail(err)
+		}
+
+		return err
+	}
+
+	q.sem <- struct{}{}
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		defer func() { <-q.sem }()
+		var err error
+		if *vs, err = reviewFile(f, q.o); err != nil {
+			q.fail(err)
+		}
+	}()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.err
+}
+
+func (q *fileQueue) fail(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// Wait waits for the reviews to finish and returns their violations, or the
+// first error.
+func (q *fileQueue) Wait() ([]report.Violation, error) {
+	q.wg.Wait()
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	var vs []report.Violation
+	for _, fvs := range q.done {
+		vs = append(vs, *fvs...)
+	}
+
+	return vs, nil
+}
@@ -0,0 +1,55 @@
+package review
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJobsSameViolations(t *testing.T) {
+	tr := newTestRepo(t)
+	files := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		content := strings.Repeat("line\n", i%7)
+		if i%3 == 0 {
+			content += "trailing \n"
+		}
+
+		if i%4 == 0 {
+			content += "\n\n\nend\n"
+		}
+
+		files[fmt.Sprintf("f%02d.txt", i)] = content
+	}
+
+	tr.SetRef("refs/heads/main", tr.commit(files, "Add the files"))
+	tr.SetHead("main")
+
+	var results [][]string
+	for _, jobs := range []int{1, 8} {
+		o := &Options{Jobs: jobs}
+		o.NoTrailingWhitespace = true
+		o.MaxBlankLines = 2
+		o.FlagEmptyFiles = true
+		res, err := Run(tr.Repository, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var vs []string
+		for _, v := range res.Violations {
+			vs = append(vs, fmt.Sprintf("%s:%d: %s [%s]", v.Path, v.Line, v.Message, v.Rule))
+		}
+
+		if len(vs) < 50 {
+			t.Fatalf("-jobs %d: %d violations, want more than 50", jobs, len(vs))
+		}
+
+		results = append(results, vs)
+	}
+
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("-jobs 1 violations:\n%s\n-jobs 8 violations:\n%s", strings.Join(results[0], "\n"), strings.Join(results[1], "\n"))
+	}
+}
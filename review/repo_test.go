@@ -0,0 +1,36 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/internal/testrepo"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// testRepo is a repository in memory whose objects are written by the tests.
+type testRepo struct {
+	*testrepo.Repo
+	t *testing.T
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	return &testRepo{Repo: testrepo.New(t), t: t}
+}
+
+// commit writes a commit of the files with the message and the parents.
+func (tr *testRepo) commit(files map[string]string, msg string, parents ...core.Hash) core.Hash {
+	return tr.WriteCommit(tr.WriteTree(files), msg+"\n", parents...)
+}
+
+// treeEntry is an entry of a tree written by testRepo.tree.
+type treeEntry testrepo.Entry
+
+// tree writes a tree of the entries, in the order given.
+func (tr *testRepo) tree(entries ...treeEntry) core.Hash {
+	es := make([]testrepo.Entry, len(entries))
+	for i, e := range entries {
+		es[i] = testrepo.Entry(e)
+	}
+
+	return tr.RawTree(es...)
+}
@@ -0,0 +1,230 @@
+package review
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gunjan5/code-review-bot/config"
+	"github.com/gunjan5/code-review-bot/gitindex"
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// loadRepoConfig returns the options merged with the repository
+// configuration of the reviewed commit head, or of the merge base since with
+// BaseBranch.
+func loadRepoConfig(head, since *git.Commit, o *Options) (*Options, error) {
+	if o.BaseBranch != "" {
+		return treeRepoConfig(since, o)
+	}
+
+	return treeRepoConfig(head, o)
+}
+
+// treeRepoConfig returns the options merged with the repository
+// configuration at the root of the tree of c, o itself if there is none or
+// with IgnoreRepoConfig.
+func treeRepoConfig(c *git.Commit, o *Options) (*Options, error) {
+	if o.IgnoreRepoConfig {
+		return o, nil
+	}
+
+	t, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := t.File(config.RepoFile)
+	if err == git.ErrFileNotFound {
+		return o, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := f.Contents()
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeRepoConfig([]byte(data), o)
+}
+
+// indexRepoConfig returns the options merged with the repository
+// configuration staged in the index, o itself if there is none or with
+// IgnoreRepoConfig.
+func indexRepoConfig(r *git.Repository, idx *gitindex.Index, o *Options) (*Options, error) {
+	if o.IgnoreRepoConfig {
+		return o, nil
+	}
+
+	for _, e := range idx.Entries {
+		if e.Name != config.RepoFile || e.Stage > 0 {
+			continue
+		}
+
+		blob, err := r.Blob(e.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("staged %s: %s", config.RepoFile, err)
+		}
+
+		rd, err := blob.Reader()
+		if err != nil {
+			return nil, err
+		}
+		defer rd.Close()
+
+		data, err := ioutil.ReadAll(rd)
+		if err != nil {
+			return nil, err
+		}
+
+		return mergeRepoConfig(data, o)
+	}
+
+	return o, nil
+}
+
+// worktreeRepoConfig returns the options merged with the repository
+// configuration of the working tree at root, o itself if there is none or
+// with IgnoreRepoConfig.
+func worktreeRepoConfig(root string, o *Options) (*Options, error) {
+	if o.IgnoreRepoConfig {
+		return o, nil
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(root, config.RepoFile))
+	if os.IsNotExist(err) {
+		return o, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeRepoConfig(data, o)
+}
+
+// mergeRepoConfig returns a copy of the options with the settings of the
+// repository configuration data: its rules replace DisabledRules and
+// Severity, its excluded paths are added to ExcludedPaths, its forbidden
+// files to ForbiddenFiles, or to rules.DefaultForbiddenFiles, turning
+// ForbidFiles on, and its overrides to Overrides.
+func mergeRepoConfig(data []byte, o *Options) (*Options, error) {
+	repo, err := config.ParseRepo(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", config.RepoFile, err)
+	}
+
+	merged := *o
+	merged.repoConfig = core.ComputeHash(core.BlobObject, data).String()
+	merged.ExcludedPaths = append(append([]string(nil), o.ExcludedPaths...), repo.Exclude...)
+	merged.Overrides = append([]rules.Override(nil), o.Overrides...)
+	if len(repo.Forbidden) > 0 {
+		forbidden := o.ForbiddenFiles
+		if len(forbidden) == 0 {
+			forbidden = rules.DefaultForbiddenFiles
+		}
+
+		merged.ForbidFiles = true
+		merged.ForbiddenFiles = append(append([]string(nil), forbidden...), repo.Forbidden...)
+	}
+
+	merged.Severity = make(map[string]report.Severity, len(o.Severity))
+	for id, s := range o.Severity {
+		merged.Severity[id] = s
+	}
+
+	disabled := make(map[string]bool)
+	for _, id := range o.DisabledRules {
+		disabled[id] = true
+	}
+
+	for _, s := range repo.Rules {
+		on, severity, ok, err := parseRuleSetting(s)
+		if err != nil {
+			return nil, err
+		}
+
+		disabled[s.ID] = !on
+		if ok {
+			merged.Severity[s.ID] = severity
+		}
+	}
+
+	merged.DisabledRules = nil
+	for _, r := range rules.All() {
+		if id := r.Meta().ID; disabled[id] {
+			merged.DisabledRules = append(merged.DisabledRules, id)
+		}
+	}
+
+	for _, ov := range repo.Overrides {
+		rov := rules.Override{
+			Paths:    ov.Paths,
+			Disabled: make(map[string]bool),
+			Severity: make(map[string]report.Severity),
+		}
+
+		for _, glob := range ov.Paths {
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("%s: line %d: invalid glob %q: %s", config.RepoFile, ov.Line, glob, err)
+			}
+		}
+
+		for _, s := range ov.Rules {
+			on, severity, ok, err := parseRuleSetting(s)
+			if err != nil {
+				return nil, err
+			}
+
+			rov.Disabled[s.ID] = !on
+			if ok {
+				rov.Severity[s.ID] = severity
+			}
+		}
+
+		merged.Overrides = append(merged.Overrides, rov)
+	}
+
+	for _, glob := range repo.Exclude {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("%s: invalid exclude glob %q: %s", config.RepoFile, glob, err)
+		}
+	}
+
+	for _, glob := range repo.Forbidden {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("%s: invalid forbidden glob %q: %s", config.RepoFile, glob, err)
+		}
+	}
+
+	return &merged, nil
+}
+
+// parseRuleSetting returns whether the setting turns its rule on, a severity
+// turning it on too, and the severity and true if it sets one.
+func parseRuleSetting(s config.RuleSetting) (on bool, severity report.Severity, ok bool, err error) {
+	if rules.Lookup(s.ID) == nil {
+		return false, 0, false, fmt.Errorf("%s: line %d: unknown rule %q", config.RepoFile, s.Line, s.ID)
+	}
+
+	switch s.Value {
+	case config.RuleOn:
+		return true, 0, false, nil
+	case config.RuleOff:
+		return false, 0, false, nil
+	}
+
+	if severity, err = report.ParseSeverity(s.Value); err != nil {
+		return false, 0, false, fmt.Errorf("%s: line %d: %s: expected %s, %s or a severity", config.RepoFile, s.Line, err, config.RuleOn, config.RuleOff)
+	}
+
+	return true, severity, true, nil
+}
@@ -0,0 +1,190 @@
+package review
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/rules"
+)
+
+const testRepoConfig = `rules:
+  ticket-reference: off
+  subject-length: error
+  todo-comment: on
+exclude: [third_party]
+forbidden: ["*.sqlite"]
+overrides:
+  - paths: [legacy/**]
+    rules:
+      trailing-whitespace: off
+      utf8: warning
+`
+
+func TestMergeRepoConfig(t *testing.T) {
+	o := &Options{ExcludedPaths: []string{"vendor"}}
+	merged, err := mergeRepoConfig([]byte(testRepoConfig), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"ticket-reference"}; !reflect.DeepEqual(merged.DisabledRules, want) {
+		t.Errorf("DisabledRules = %q, want %q", merged.DisabledRules, want)
+	}
+
+	if want := map[string]report.Severity{"subject-length": report.Error}; !reflect.DeepEqual(merged.Severity, want) {
+		t.Errorf("Severity = %v, want %v", merged.Severity, want)
+	}
+
+	if want := []string{"vendor", "third_party"}; !reflect.DeepEqual(merged.ExcludedPaths, want) {
+		t.Errorf("ExcludedPaths = %q, want %q", merged.ExcludedPaths, want)
+	}
+
+	wantForbidden := append(append([]string(nil), rules.DefaultForbiddenFiles...), "*.sqlite")
+	if !merged.ForbidFiles || !reflect.DeepEqual(merged.ForbiddenFiles, wantForbidden) {
+		t.Errorf("ForbidFiles = %v, ForbiddenFiles = %q, want true and %q", merged.ForbidFiles, merged.ForbiddenFiles, wantForbidden)
+	}
+
+	wantOverrides := []rules.Override{{
+		Paths:    []string{"legacy/**"},
+		Disabled: map[string]bool{"trailing-whitespace": true, "utf8": false},
+		Severity: map[string]report.Severity{"utf8": report.Warning},
+	}}
+	if !reflect.DeepEqual(merged.Overrides, wantOverrides) {
+		t.Errorf("Overrides = %+v, want %+v", merged.Overrides, wantOverrides)
+	}
+
+	for _, tc := range []struct {
+		id, path string
+		disabled bool
+	}{
+		{"trailing-whitespace", "main.go", false},
+		{"trailing-whitespace", "legacy/old/main.go", true},
+		{"utf8", "legacy/old/main.go", false},
+		{"ticket-reference", "", true},
+		{"subject-length", "", false},
+	} {
+		if got := merged.DisabledAt(tc.id, tc.path); got != tc.disabled {
+			t.Errorf("DisabledAt(%s, %q) = %v, want %v", tc.id, tc.path, got, tc.disabled)
+		}
+	}
+
+	if len(o.ExcludedPaths) != 1 || o.Severity != nil || o.DisabledRules != nil || o.Overrides != nil {
+		t.Errorf("mergeRepoConfig changed the options it was given: %+v", o)
+	}
+}
+
+func TestMergeRepoConfigPrecedence(t *testing.T) {
+	o := &Options{}
+	o.DisabledRules = []string{"todo-comment", "bom"}
+	o.Severity = map[string]report.Severity{"todo-comment": report.Info, "bom": report.Info, "utf8": report.Info}
+	o.ForbiddenFiles = []string{"*.bin"}
+	o.Overrides = []rules.Override{{Paths: []string{"docs/**"}, Disabled: map[string]bool{"utf8": true}}}
+
+	data := "rules:\n  todo-comment: error\n  utf8: off\nforbidden: [\"*.sqlite\"]\noverrides:\n  - paths: [docs/**]\n    rules:\n      utf8: on\n"
+	merged, err := mergeRepoConfig([]byte(data), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The repository configuration wins over the command line options,
+	// which are kept for the rules it doesn't set.
+	for _, tc := range []struct {
+		id       string
+		disabled bool
+		severity report.Severity
+	}{
+		{"todo-comment", false, report.Error},
+		{"bom", true, report.Info},
+		{"utf8", true, report.Info},
+	} {
+		if got := merged.Disabled(tc.id); got != tc.disabled {
+			t.Errorf("Disabled(%s) = %v, want %v", tc.id, got, tc.disabled)
+		}
+
+		if got := merged.Severity[tc.id]; got != tc.severity {
+			t.Errorf("Severity[%s] = %s, want %s", tc.id, got, tc.severity)
+		}
+	}
+
+	if want := []string{"*.bin", "*.sqlite"}; !reflect.DeepEqual(merged.ForbiddenFiles, want) {
+		t.Errorf("ForbiddenFiles = %q, want %q", merged.ForbiddenFiles, want)
+	}
+
+	// The overrides of the file come after those of the command line,
+	// winning over them.
+	if len(merged.Overrides) != 2 || merged.DisabledAt("utf8", "docs/a.md") {
+		t.Errorf("Overrides = %+v, want the repository override turning utf8 back on in docs", merged.Overrides)
+	}
+
+	if !reflect.DeepEqual(o.DisabledRules, []string{"todo-comment", "bom"}) || o.Severity["todo-comment"] != report.Info || len(o.Overrides) != 1 {
+		t.Errorf("mergeRepoConfig changed the options it was given: %+v", o)
+	}
+}
+
+func TestMergeRepoConfigErrors(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		err  string
+	}{
+		{"rules:\n  no-such-rule: off\n", `.codereview.yaml: line 2: unknown rule "no-such-rule"`},
+		{"rules:\n  todo-comment: fatal\n", `.codereview.yaml: line 2: unknown severity "fatal": expected on, off or a severity`},
+		{"overrides:\n  - paths: [a]\n    rules:\n      no-such-rule: off\n", `line 4: unknown rule "no-such-rule"`},
+		{"overrides:\n  - paths: [a]\n    rules:\n      bom: critical\n", `line 4: unknown severity "critical"`},
+		{"overrides:\n  - paths: [\"[a\"]\n", `line 2: invalid glob "[a"`},
+		{"exclude: [\"[a\"]\n", `invalid exclude glob "[a"`},
+		{"forbidden: [\"[a\"]\n", `invalid forbidden glob "[a"`},
+		{"rule: {}\n", `.codereview.yaml: line 1: unknown key "rule"`},
+	} {
+		o, err := mergeRepoConfig([]byte(tc.data), &Options{})
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("mergeRepoConfig(%q) = %+v, %v, want an error containing %q", tc.data, o, err, tc.err)
+		}
+	}
+}
+
+func TestRepoConfigMergeBase(t *testing.T) {
+	tr := newTestRepo(t)
+	base := tr.commit(map[string]string{
+		".codereview.yaml": "rules:\n  trailing-whitespace: off\n",
+		"a":                "a\n",
+	}, "Add a")
+	head := tr.commit(map[string]string{
+		".codereview.yaml": "rules:\n  trailing-whitespace: error\n",
+		"a":                "a \n",
+	}, "Change a", base)
+	tr.SetRef("refs/heads/main", base)
+	tr.SetRef("refs/heads/topic", head)
+	tr.SetHead("topic")
+
+	// A branch can't turn off the rules checking its own changes, the
+	// configuration is read from its merge base with the base branch.
+	for _, tc := range []struct {
+		base   string
+		ignore bool
+		want   []report.Severity
+	}{
+		{"", false, []report.Severity{report.Error}},
+		{"main", false, nil},
+		{"main", true, []report.Severity{report.Error}},
+	} {
+		o := &Options{BaseBranch: tc.base, IgnoreRepoConfig: tc.ignore}
+		o.NoTrailingWhitespace = true
+		res, err := Run(tr.Repository, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []report.Severity
+		for _, v := range res.Violations {
+			if v.Rule == "trailing-whitespace" {
+				got = append(got, v.Severity)
+			}
+		}
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("-base-branch %q, -ignore-repo-config %v: trailing-whitespace violations of severities %v, want %v", tc.base, tc.ignore, got, tc.want)
+		}
+	}
+}
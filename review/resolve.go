@@ -0,0 +1,249 @@
+package review
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// minAbbrev is the shortest abbreviated hash accepted, as in git.
+const minAbbrev = 4
+
+// Errors returned when resolving revisions.
+var (
+	ErrCommitNotFound = errors.New("commit not found")
+	ErrAmbiguousHash  = errors.New("abbreviated hash is ambiguous")
+	ErrShortHash      = errors.New("abbreviated hash is too short")
+	ErrUnknownRev     = errors.New("unknown revision")
+	ErrNoAncestor     = errors.New("ancestor out of range")
+)
+
+var refPrefixes = []string{"", "refs/", "refs/heads/", "refs/tags/", "refs/remotes/"}
+
+// ResolveRevision returns the hash of the commit named by rev, a reference
+// name, a short branch or tag name or a full or abbreviated commit hash,
+// optionally followed by ancestry suffixes as in git: ~N selects the N-th
+// first-parent ancestor and ^N the N-th parent, N defaulting to 1. HEAD is
+// used when rev is empty.
+func ResolveRevision(r *git.Repository, rev string) (core.Hash, error) {
+	c, err := ResolveCommit(r, rev)
+	if err != nil {
+		return core.ZeroHash, err
+	}
+
+	return c.Hash, nil
+}
+
+// ResolveCommit returns the commit named by rev, with the syntax accepted by
+// ResolveRevision.
+func ResolveCommit(r *git.Repository, rev string) (*git.Commit, error) {
+	name := rev
+	if i := strings.IndexAny(rev, "~^"); i >= 0 {
+		name = rev[:i]
+	}
+
+	c, err := resolveName(r, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for suffix := rev[len(name):]; suffix != ""; {
+		n := 1
+		op := suffix[0]
+		if op != '~' && op != '^' {
+			return nil, ErrUnknownRev
+		}
+
+		suffix = suffix[1:]
+		digits := len(suffix) - len(strings.TrimLeft(suffix, "0123456789"))
+		if digits > 0 {
+			if n, err = strconv.Atoi(suffix[:digits]); err != nil {
+				return nil, ErrUnknownRev
+			}
+
+			suffix = suffix[digits:]
+		}
+
+		switch {
+		case op == '^' && n == 0:
+			// ^0 names the commit itself.
+		case op == '^':
+			c, err = nthParent(r, c, n)
+		default:
+			for i := 0; i < n && err == nil; i++ {
+				c, err = nthParent(r, c, 1)
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Range is a range of commits to review, given as BASE..HEAD or
+// BASE...HEAD.
+type Range struct {
+	Base string
+	Head string
+	// MergeBase is set for BASE...HEAD, reviewing the changes made on HEAD
+	// since its merge base with BASE, as in a pull request, rather than
+	// since BASE.
+	MergeBase bool
+}
+
+// ParseRange parses BASE..HEAD, the commits reachable from HEAD but not from
+// BASE, or BASE...HEAD, the commits and files changed on HEAD since its merge
+// base with BASE. Either side may be omitted for HEAD, as in git.
+func ParseRange(rng string) (*Range, error) {
+	sep := "..."
+	i := strings.Index(rng, sep)
+	if i < 0 {
+		sep = ".."
+		i = strings.Index(rng, sep)
+	}
+
+	if i < 0 || rng == sep {
+		return nil, fmt.Errorf("invalid range %q, expected BASE..HEAD or BASE...HEAD", rng)
+	}
+
+	rg := &Range{Base: rng[:i], Head: rng[i+len(sep):], MergeBase: sep == "..."}
+	if rg.Base == "" {
+		rg.Base = string(core.HEAD)
+	}
+
+	if rg.Head == "" {
+		rg.Head = string(core.HEAD)
+	}
+
+	return rg, nil
+}
+
+// resolveName returns the commit named by a reference or a hash, without
+// ancestry suffixes.
+func resolveName(r *git.Repository, rev string) (*git.Commit, error) {
+	if rev == "" || rev == "@" {
+		rev = string(core.HEAD)
+	}
+
+	for _, prefix := range refPrefixes {
+		ref, err := r.Ref(core.ReferenceName(prefix+rev), true)
+		if err != nil {
+			continue
+		}
+
+		return PeelCommit(r, ref.Hash())
+	}
+
+	// Hashes shorter than minAbbrev are reported as such by CommitByPrefix.
+	if isHex(rev) && len(rev) <= 40 {
+		return CommitByPrefix(r, rev)
+	}
+
+	return nil, ErrUnknownRev
+}
+
+// PeelCommit returns the commit h, or the commit an annotated tag h points to,
+// following the tags of tags. An error is returned if the tag points to a
+// tree or a blob.
+func PeelCommit(r *git.Repository, h core.Hash) (*git.Commit, error) {
+	for {
+		obj, err := r.Object(core.AnyObject, h)
+		if err != nil {
+			return nil, err
+		}
+
+		switch o := obj.(type) {
+		case *git.Commit:
+			return o, nil
+		case *git.Tag:
+			h = o.Target
+		default:
+			return nil, fmt.Errorf("object %s is a %s, not a commit", h.String()[:7], obj.Type())
+		}
+	}
+}
+
+// nthParent returns the n-th parent of c, counting from 1.
+func nthParent(r *git.Repository, c *git.Commit, n int) (*git.Commit, error) {
+	parents := c.ParentHashes()
+	if n > len(parents) {
+		if len(parents) == 0 {
+			return nil, fmt.Errorf("%s: commit %s has no parent", ErrNoAncestor, c.Hash.String()[:7])
+		}
+
+		return nil, fmt.Errorf("%s: commit %s has no parent %d", ErrNoAncestor, c.Hash.String()[:7], n)
+	}
+
+	p, err := r.Commit(parents[n-1])
+	if rules.IsMissing(err) {
+		return nil, fmt.Errorf("%s: parent of commit %s is missing from the shallow clone", ErrNoAncestor, c.Hash.String()[:7])
+	}
+
+	return p, err
+}
+
+// CommitByPrefix returns the commit whose hash starts with the given full or
+// abbreviated hexadecimal hash. Abbreviated hashes are resolved by scanning
+// every commit in the object storage, ErrAmbiguousHash is returned when more
+// than one commit matches.
+func CommitByPrefix(r *git.Repository, prefix string) (*git.Commit, error) {
+	prefix = strings.ToLower(prefix)
+	if len(prefix) < minAbbrev {
+		return nil, ErrShortHash
+	}
+
+	if len(prefix) == 40 {
+		c, err := PeelCommit(r, core.NewHash(prefix))
+		if err == git.ErrObjectNotFound {
+			return nil, ErrCommitNotFound
+		}
+
+		return c, err
+	}
+
+	iter, err := r.Commits()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *git.Commit
+	err = iter.ForEach(func(c *git.Commit) error {
+		if !strings.HasPrefix(c.Hash.String(), prefix) {
+			return nil
+		}
+
+		if found != nil {
+			return ErrAmbiguousHash
+		}
+
+		found = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, ErrCommitNotFound
+	}
+
+	return found, nil
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	if len(s)%2 == 1 {
+		_, err = hex.DecodeString(s + "0")
+	}
+
+	return err == nil
+}
@@ -0,0 +1,170 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// history writes a history of a merge whose second parent is a side branch,
+// returning the commits from the oldest: root, second, side and merge,
+// main pointing to the merge and HEAD to main.
+func history(tr *testRepo) (root, second, side, merge core.Hash) {
+	root = tr.commit(map[string]string{"a": "1\n"}, "root")
+	second = tr.commit(map[string]string{"a": "2\n"}, "second", root)
+	side = tr.commit(map[string]string{"a": "1\n", "b": "1\n"}, "side", root)
+	merge = tr.commit(map[string]string{"a": "2\n", "b": "1\n"}, "merge", second, side)
+	tr.SetRef("refs/heads/main", merge)
+	tr.SetRef("refs/heads/side", side)
+	tr.SetRef("refs/tags/v1", second)
+	tr.SetHead("main")
+	return root, second, side, merge
+}
+
+func TestResolveRevision(t *testing.T) {
+	tr := newTestRepo(t)
+	root, second, side, merge := history(tr)
+	for _, tc := range []struct {
+		rev  string
+		want core.Hash
+	}{
+		{"", merge},
+		{"HEAD", merge},
+		{"@", merge},
+		{"main", merge},
+		{"refs/heads/main", merge},
+		{"side", side},
+		{"v1", second},
+		{merge.String(), merge},
+		{merge.String()[:7], merge},
+		{strings.ToUpper(side.String()[:10]), side},
+		{"HEAD~", second},
+		{"HEAD~1", second},
+		{"HEAD~2", root},
+		{"main~0", merge},
+		{"HEAD^", second},
+		{"HEAD^1", second},
+		{"HEAD^2", side},
+		{"HEAD^0", merge},
+		{"HEAD^2~1", root},
+		{"HEAD^^", root},
+		{"HEAD~1^", root},
+		{"v1~", root},
+		{side.String()[:8] + "^", root},
+	} {
+		got, err := ResolveRevision(tr.Repository, tc.rev)
+		if err != nil {
+			t.Errorf("ResolveRevision(%q): %s", tc.rev, err)
+			continue
+		}
+
+		if got != tc.want {
+			t.Errorf("ResolveRevision(%q) = %s, want %s", tc.rev, got, tc.want)
+		}
+	}
+}
+
+func TestResolveRevisionErrors(t *testing.T) {
+	tr := newTestRepo(t)
+	_, _, _, merge := history(tr)
+	for _, tc := range []struct {
+		rev  string
+		want error
+	}{
+		{merge.String()[:1], ErrShortHash},
+		{merge.String()[:3], ErrShortHash},
+		{merge.String()[:3] + "~1", ErrShortHash},
+		{"nope", ErrUnknownRev},
+		{"HEAD~x", ErrUnknownRev},
+		{unusedPrefix(tr, 4), ErrCommitNotFound},
+		{strings.Repeat("0", 40), ErrCommitNotFound},
+		{"HEAD~3", ErrNoAncestor},
+		{"HEAD^3", ErrNoAncestor},
+		{"side~2", ErrNoAncestor},
+	} {
+		_, err := ResolveRevision(tr.Repository, tc.rev)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.want.Error()) {
+			t.Errorf("ResolveRevision(%q) = %v, want %s", tc.rev, err, tc.want)
+		}
+	}
+}
+
+func TestResolveAmbiguousHash(t *testing.T) {
+	tr := newTestRepo(t)
+	seen := make(map[string]core.Hash)
+	var prefix string
+	for i := 0; prefix == ""; i++ {
+		h := tr.commit(map[string]string{"a": "1\n"}, fmt.Sprintf("commit %d", i))
+		p := h.String()[:minAbbrev]
+		if _, ok := seen[p]; ok {
+			prefix = p
+		}
+
+		seen[p] = h
+	}
+
+	if _, err := ResolveRevision(tr.Repository, prefix); err != ErrAmbiguousHash {
+		t.Errorf("ResolveRevision(%q) = %v, want %s", prefix, err, ErrAmbiguousHash)
+	}
+
+	h := seen[prefix]
+	if got, err := ResolveRevision(tr.Repository, h.String()[:12]); err != nil || got != h {
+		t.Errorf("ResolveRevision(%q) = %s, %v, want %s", h.String()[:12], got, err, h)
+	}
+}
+
+// unusedPrefix returns a hexadecimal prefix of n characters of no commit.
+func unusedPrefix(tr *testRepo, n int) string {
+	iter, err := tr.Commits()
+	if err != nil {
+		tr.t.Fatal(err)
+	}
+
+	used := make(map[string]bool)
+	for {
+		c, err := iter.Next()
+		if err != nil {
+			break
+		}
+
+		used[c.Hash.String()[:n]] = true
+	}
+
+	for i := 0; ; i++ {
+		p := fmt.Sprintf("%0*x", n, i)
+		if !used[p] {
+			return p
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	for _, tc := range []struct {
+		rng  string
+		want Range
+	}{
+		{"a..b", Range{Base: "a", Head: "b"}},
+		{"a...b", Range{Base: "a", Head: "b", MergeBase: true}},
+		{"..b", Range{Base: "HEAD", Head: "b"}},
+		{"a..", Range{Base: "a", Head: "HEAD"}},
+		{"HEAD~2..HEAD^", Range{Base: "HEAD~2", Head: "HEAD^"}},
+	} {
+		got, err := ParseRange(tc.rng)
+		if err != nil {
+			t.Errorf("ParseRange(%q): %s", tc.rng, err)
+			continue
+		}
+
+		if *got != tc.want {
+			t.Errorf("ParseRange(%q) = %+v, want %+v", tc.rng, *got, tc.want)
+		}
+	}
+
+	for _, rng := range []string{"", "a", "..", "..."} {
+		if _, err := ParseRange(rng); err == nil {
+			t.Errorf("ParseRange(%q) succeeded", rng)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Package review walks a repository and runs the configured rules over its
+// files and commits.
+package review
+
+import (
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/mailmap"
+	"github.com/gunjan5/code-review-bot/plugin"
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// Options configures a review run.
+type Options struct {
+	// Ref is the reference or commit hash to review, HEAD is used if empty.
+	Ref string
+	// Commit is the full or abbreviated hash of a single commit to review
+	// instead of the tree pointed by Ref.
+	Commit string
+	// SinceCommit is the revision of the last commit already reviewed, only
+	// the commits reachable from Ref but not from SinceCommit are reviewed.
+	// Every commit reachable from Ref is reviewed if empty.
+	SinceCommit string
+	rules.Options
+	// Author restricts the reviewed commits to those whose author, once
+	// normalized with the .mailmap of the reviewed tree, matches this
+	// regular expression on "Name <email>".
+	Author string
+	// Committer restricts the reviewed commits likewise by committer.
+	Committer string
+	// ExcludedAuthors skips the commits whose normalized author has one of
+	// these names, emails or "Name <email>", ignoring case, e.g. bots.
+	ExcludedAuthors []string
+	// Paths restricts the review to the files matching one of these globs,
+	// or under a directory matching one, and ExcludedPaths skips those
+	// matching one of its globs. The commits of the history are only
+	// reviewed if they change such a file.
+	Paths         []string
+	ExcludedPaths []string
+	// Merges is how merge commits of the history are reviewed: MergesAll or
+	// an empty string for every commit, MergesSkip to skip the merge
+	// commits but not the history they bring, MergesFirstParent to follow
+	// only the first parent of merges, skipping the commits they merged.
+	Merges string
+	// Order is the order in which the commits are reviewed, and their
+	// violations reported: OrderDate or an empty string, OrderAuthorDate or
+	// OrderTopo.
+	Order string
+	// Plugins are the paths of the external checks run on every reviewed
+	// file, see package plugin.
+	Plugins []string
+	// BaseBranch restricts the review to the changes made since the merge
+	// base of Ref and this branch, as in a pull request: the commits after
+	// the merge base and the files added or modified since. It can't be
+	// combined with SinceCommit or ChangedFiles.
+	BaseBranch string
+	// ChangedFiles restricts the file checks to these paths of the tree
+	// pointed by Ref when not empty, the tree isn't walked.
+	ChangedFiles []string
+	// Cache holds the violations found in the files of previous runs, it
+	// isn't used if nil.
+	Cache *Cache
+	// IncludeGenerated reviews the files marked as linguist-generated or
+	// linguist-vendored in the .gitattributes, skipped otherwise.
+	IncludeGenerated bool
+	// Snippets attaches to the violations with a line the excerpt of the
+	// file around it, with ContextLines lines before and after the line.
+	Snippets     bool
+	ContextLines int
+	// Jobs is the number of files checked concurrently, one at a time if
+	// less than 2.
+	Jobs int
+	// IgnoreRepoConfig ignores the .codereview.yaml of the reviewed
+	// repositories, see config.Repo, otherwise read from the reviewed tree,
+	// or from the merge base with BaseBranch so that a pull request can't
+	// relax its own review.
+	IgnoreRepoConfig bool
+
+	// repoConfig is the blob hash of the repository configuration merged
+	// in the options, if any.
+	repoConfig string
+}
+
+// Result is the outcome of a review run.
+type Result struct {
+	Violations []report.Violation
+	// Commit is the hash of the reviewed commit, the one pointed by the
+	// configured reference or the configured commit.
+	Commit string
+	// Commits is the number of commits checked.
+	Commits int
+	// Paths are the paths of the files checked.
+	Paths []string
+	// Warnings are the problems found that don't prevent the review, like
+	// changed files missing from the tree.
+	Warnings []string
+}
+
+// Run reviews the tree of the commit pointed by the configured reference and
+// the commits of the configured range, or only the configured commit if any.
+func Run(r *git.Repository, o *Options) (*Result, error) {
+	commit, since, err := resolveRange(r, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if o, err = loadRepoConfig(commit, since, o); err != nil {
+		return nil, err
+	}
+
+	filter, err := loadAuthorFilter(commit, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Commit != "" {
+		c, err := resolveSingleCommit(r, o)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, err := newPathspec(o).touches(r, c)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok || !filter.Match(c) {
+			return &Result{Commit: c.Hash.String()}, nil
+		}
+
+		return RunCommit(r, c, o)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := loadPathFilter(tree, o)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := o.ChangedFiles
+	if o.BaseBranch != "" {
+		if changed, err = changedPaths(since, commit); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &Result{Commit: commit.Hash.String()}
+	q := newFileQueue(o)
+	err = forEachFile(res, tree, changed, func(f *git.File) error {
+		if !paths.Match(f.Name) {
+			return nil
+		}
+
+		return q.Add(f)
+	})
+
+	fvs, qerr := q.Wait()
+	if err == nil {
+		err = qerr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.Violations = append(res.Violations, fvs...)
+	res.Paths = append(res.Paths, q.Paths...)
+
+	tvs, err := rules.CheckTree(r, tree, &o.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Violations = append(res.Violations, tvs...)
+	if err := reviewHistory(res, r, commit, since, filter, o); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// forEachFile calls cb for every file of the tree, or only for the changed
+// files if not nil. Changed files missing from the tree are added to the
+// warnings of the result.
+func forEachFile(res *Result, t *git.Tree, changed []string, cb func(*git.File) error) error {
+	if changed == nil {
+		return t.Files().ForEach(cb)
+	}
+
+	for _, name := range changed {
+		f, err := t.File(name)
+		if err == git.ErrFileNotFound {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("changed file %s is not in the reviewed tree", name))
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := cb(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkFile runs the file rules and the plugins on f.
+func checkFile(f *git.File, o *Options) ([]report.Violation, error) {
+	vs, err := rules.CheckFile(f, &o.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range o.Plugins {
+		p := &plugin.Plugin{Path: path}
+		pvs, err := p.Check(f)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %s: %s", path, f.Name, err)
+		}
+
+		for i := range pvs {
+			if url := o.DocURL(pvs[i].Rule); url != "" {
+				pvs[i].DocURL = url
+			}
+		}
+
+		vs = append(vs, pvs...)
+	}
+
+	return vs, nil
+}
+
+// loadAuthorFilter builds the author filter from the .mailmap of the tree of
+// the commit.
+func loadAuthorFilter(c *git.Commit, o *Options) (*authorFilter, error) {
+	t, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	mm, err := mailmap.FromTree(t)
+	if err != nil {
+		return nil, fmt.Errorf(".mailmap: %s", err)
+	}
+
+	return newAuthorFilter(mm, o)
+}
+
+// reviewFile runs the file rules and the plugins on f, or reuses their
+// violations from the cache, and drops those suppressed by the directives
+// of the file.
+func reviewFile(f *git.File, o *Options) ([]report.Violation, error) {
+	vs, ok := o.Cache.get(f, o.repoConfig)
+	if !ok {
+		var err error
+		if vs, err = checkFile(f, o); err != nil {
+			return nil, err
+		}
+
+		o.Cache.put(f, o.repoConfig, vs)
+	}
+
+	vs, err := suppress(f, vs)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Snippets {
+		if err := addSnippets(f, vs, o.ContextLines); err != nil {
+			return nil, err
+		}
+	}
+
+	return vs, nil
+}
@@ -0,0 +1,68 @@
+package review
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangedFiles(t *testing.T) {
+	tr := newTestRepo(t)
+	h := tr.commit(map[string]string{"a": "1\n", "b": "2\n", "c": "3\n"}, "Add the files")
+	tr.SetRef("refs/heads/main", h)
+	tr.SetHead("main")
+
+	for _, tc := range []struct {
+		changed  []string
+		paths    []string
+		warnings []string
+	}{
+		{nil, []string{"a", "b", "c"}, nil},
+		{[]string{"c", "a"}, []string{"c", "a"}, nil},
+		{[]string{"b", "gone"}, []string{"b"}, []string{"changed file gone is not in the reviewed tree"}},
+	} {
+		res, err := Run(tr.Repository, &Options{ChangedFiles: tc.changed})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(res.Paths, tc.paths) || !reflect.DeepEqual(res.Warnings, tc.warnings) {
+			t.Errorf("-changed-files %q: reviewed %q with warnings %q, want %q and %q", tc.changed, res.Paths, res.Warnings, tc.paths, tc.warnings)
+		}
+	}
+}
+
+func TestBaseBranch(t *testing.T) {
+	tr := newTestRepo(t)
+	root := tr.commit(map[string]string{"a": "1\n", "b": "1\n"}, "root")
+	fork := tr.commit(map[string]string{"a": "2\n", "b": "1\n"}, "fork", root)
+	main := tr.commit(map[string]string{"a": "2\n", "b": "1\n", "m": "1\n"}, "main", fork)
+	topic := tr.commit(map[string]string{"a": "2\n", "b": "2\n", "t": "1\n"}, "topic", fork)
+	topic = tr.commit(map[string]string{"a": "2\n", "b": "2\n", "t": "2\n", "u": "1\n"}, "topic 2", topic)
+	tr.SetRef("refs/heads/main", main)
+	tr.SetRef("refs/heads/topic", topic)
+	tr.SetHead("topic")
+
+	for _, tc := range []struct {
+		base    string
+		commits int
+		paths   []string
+	}{
+		{"", 4, []string{"a", "b", "t", "u"}},
+		{"main", 2, []string{"b", "t", "u"}},
+		{"topic", 0, nil},
+		{root.String(), 3, []string{"a", "b", "t", "u"}},
+	} {
+		res, err := Run(tr.Repository, &Options{BaseBranch: tc.base})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Commits != tc.commits || !reflect.DeepEqual(res.Paths, tc.paths) {
+			t.Errorf("-base-branch %q: %d commits, paths %q, want %d and %q", tc.base, res.Commits, res.Paths, tc.commits, tc.paths)
+		}
+	}
+
+	if _, err := Run(tr.Repository, &Options{BaseBranch: "main", ChangedFiles: []string{"a"}}); err == nil {
+		t.Errorf("-base-branch with -changed-files succeeded")
+	}
+}
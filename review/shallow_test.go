@@ -0,0 +1,52 @@
+package review
+
+import (
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+func TestShallowHistory(t *testing.T) {
+	tr := newTestRepo(t)
+	missing := core.ComputeHash(core.CommitObject, []byte("missing"))
+	boundary := tr.commit(map[string]string{"a": "x \n"}, "Add a", missing)
+	head := tr.commit(map[string]string{"a": "x \n", "b": "y\n"}, "Add b", boundary)
+	tr.SetRef("refs/heads/main", head)
+	tr.SetHead("main")
+
+	res, err := Run(tr.Repository, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Commits != 2 {
+		t.Errorf("Run() reviewed %d commits, want the 2 fetched", res.Commits)
+	}
+
+	// The files of the boundary commit are checked as if it added them.
+	o := &Options{Commit: boundary.String()}
+	o.NoTrailingWhitespace = true
+	if res, err = Run(tr.Repository, o); err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+	for _, v := range res.Violations {
+		if v.Rule == "trailing-whitespace" && v.Path == "a" {
+			n++
+		}
+	}
+
+	if n != 1 {
+		t.Errorf("-commit of the boundary: %+v, want trailing whitespace in a", res.Violations)
+	}
+
+	stats, err := Authors(tr.Repository, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stats) != 1 || stats[0].Commits != 2 || stats[0].Added != 2 {
+		t.Errorf("Authors() = %+v, want 2 commits adding 2 lines", stats)
+	}
+}
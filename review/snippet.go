@@ -0,0 +1,43 @@
+package review
+
+import (
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// DefaultContextLines is the number of lines shown before and after the line
+// of a violation in its snippet.
+const DefaultContextLines = 2
+
+// Snippet returns the excerpt of the file around the given line, with up to
+// context lines before and after it.
+func Snippet(f *git.File, line, context int) (*report.Snippet, error) {
+	lines, err := f.Lines()
+	if err != nil {
+		return nil, err
+	}
+
+	return report.NewSnippet(lines, line, context), nil
+}
+
+// addSnippets attaches a snippet to the violations of the file that have a
+// line, reading the file only if there is any.
+func addSnippets(f *git.File, vs []report.Violation, context int) error {
+	var lines []string
+	for i := range vs {
+		if vs[i].Line < 1 {
+			continue
+		}
+
+		if lines == nil {
+			var err error
+			if lines, err = f.Lines(); err != nil {
+				return err
+			}
+		}
+
+		vs[i].Snippet = report.NewSnippet(lines, vs[i].Line, context)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package review
+
+import (
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// Suppression directives, followed by a comma separated list of rule ids,
+// written in a comment of any syntax. The line directive drops the
+// violations of its line and of the next one, the file directive those of
+// the whole file when found in its first fileDirectiveLines lines.
+const (
+	lineDirective      = "nolint:"
+	fileDirective      = "nolint-file:"
+	fileDirectiveLines = 10
+)
+
+// suppress removes the violations of the file suppressed by directives in its
+// content, reading it only if there is any violation. Binary files aren't
+// searched for directives.
+func suppress(f *git.File, vs []report.Violation) ([]report.Violation, error) {
+	if len(vs) == 0 {
+		return vs, nil
+	}
+
+	if binary, err := rules.IsBinary(f); err != nil || binary {
+		return vs, err
+	}
+
+	var file []string
+	lines := make(map[int][]string)
+	_, err := rules.ScanLines(f, func(n int, l string) bool {
+		if n <= fileDirectiveLines {
+			file = append(file, directiveRules(l, fileDirective)...)
+		}
+
+		if ids := directiveRules(l, lineDirective); ids != nil {
+			lines[n] = ids
+		}
+
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	kept := vs[:0]
+	for _, v := range vs {
+		if !suppressed(v, lines, file) {
+			kept = append(kept, v)
+		}
+	}
+
+	return kept, nil
+}
+
+// suppressed returns true if the violation is suppressed by the file
+// directives or by a line directive on its line or the line above, lines
+// mapping line numbers to the rules of their directive.
+func suppressed(v report.Violation, lines map[int][]string, file []string) bool {
+	if contains(file, v.Rule) {
+		return true
+	}
+
+	return v.Line > 0 && (contains(lines[v.Line], v.Rule) || contains(lines[v.Line-1], v.Rule))
+}
+
+// directiveRules returns the rule ids listed after the directive in the
+// line, if any.
+func directiveRules(line, directive string) []string {
+	i := strings.Index(line, directive)
+	if i < 0 {
+		return nil
+	}
+
+	ids := line[i+len(directive):]
+	if j := strings.IndexAny(ids, " \t"); j >= 0 {
+		ids = ids[:j]
+	}
+
+	return strings.Split(ids, ",")
+}
+
+func contains(ids []string, id string) bool {
+	for _, s := range ids {
+		if s == id {
+			return true
+		}
+	}
+
+	return false
+}
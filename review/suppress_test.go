@@ -0,0 +1,65 @@
+package review
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// file returns the file of the content committed in a new repository.
+func file(t *testing.T, name, content string) *git.File {
+	tr := newTestRepo(t)
+	c, err := tr.Commit(tr.commit(map[string]string{name: content}, "Add "+name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := c.File(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+func TestSuppress(t *testing.T) {
+	vs := []report.Violation{
+		{Rule: "trailing-whitespace", Line: 2},
+		{Rule: "line-length", Line: 2},
+		{Rule: "trailing-whitespace", Line: 4},
+		{Rule: "blank-lines", Line: 5},
+		{Rule: "file-size"},
+	}
+
+	for _, tc := range []struct {
+		content string
+		want    []int
+	}{
+		{"a\nb\nc\nd\ne\n", []int{0, 1, 2, 3, 4}},
+		{"a\nb // nolint:trailing-whitespace\nc\nd\ne\n", []int{1, 2, 3, 4}},
+		{"# nolint:trailing-whitespace,line-length\nb\nc\nd\ne\n", []int{2, 3, 4}},
+		{"a\nb\nc\n<!-- nolint:trailing-whitespace -->\ne\n", []int{0, 1, 3, 4}},
+		{"a\nb\nc\nd\ne\nf // nolint:blank-lines\n", []int{0, 1, 2, 3, 4}},
+		{"/* nolint-file:trailing-whitespace,file-size */\nb\nc\nd\ne\n", []int{1, 3}},
+		{"a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n// nolint-file:line-length\n", []int{0, 1, 2, 3, 4}},
+		{"a\nb // nolint:trailing\nc\nd\ne\n", []int{0, 1, 2, 3, 4}},
+		{"\x00 nolint-file:file-size\n", []int{0, 1, 2, 3, 4}},
+	} {
+		in := append([]report.Violation{}, vs...)
+		got, err := suppress(file(t, "f.txt", tc.content), in)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var want []report.Violation
+		for _, i := range tc.want {
+			want = append(want, vs[i])
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("suppress(%q) = %+v, want %+v", tc.content, got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package review
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+func TestTruncatedTree(t *testing.T) {
+	tr := newTestRepo(t)
+	blob := tr.Write(core.BlobObject, []byte("1\n"))
+	entry := append([]byte("100644 a\x00"), blob[:]...)
+	for _, tc := range []struct {
+		data   []byte
+		reason string
+	}{
+		{[]byte("1006"), "truncated entry mode"},
+		{entry[:len("100644 a")], "missing its NUL terminator"},
+		{entry[:len(entry)-1], "its hash is incomplete"},
+		{append([]byte("100644 \x00"), blob[:]...), "empty name"},
+		{append(append([]byte{}, entry...), "100644 b"...), "missing its NUL terminator"},
+	} {
+		h := tr.Write(core.TreeObject, tc.data)
+		_, err := tr.Tree(h)
+		if merr, ok := err.(*git.MalformedTreeError); !ok || merr.Hash != h || !strings.Contains(merr.Reason, tc.reason) {
+			t.Errorf("Tree(%q) = %v, want a malformed tree error %q", tc.data, err, tc.reason)
+		}
+
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "tree %s\nauthor A <a@example.com> 1500000000 +0000\ncommitter A <a@example.com> 1500000000 +0000\n\nm\n", h)
+		c, err := tr.Commit(tr.Write(core.CommitObject, buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := c.Tree(); err == nil {
+			t.Errorf("Commit.Tree of the tree %q succeeded", tc.data)
+		}
+	}
+
+	h := tr.Write(core.TreeObject, entry)
+	tree, err := tr.Tree(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tree.Entries) != 1 || tree.Entries[0].Name != "a" || tree.Entries[0].Hash != blob {
+		t.Errorf("Tree(%q) entries = %+v", entry, tree.Entries)
+	}
+}
+
+func TestTreeDanglingSymlink(t *testing.T) {
+	tr := newTestRepo(t)
+	blob := tr.Write(core.BlobObject, []byte("1\n"))
+	missing := core.ComputeHash(core.BlobObject, []byte("missing"))
+	tree, err := tr.Tree(tr.tree(
+		treeEntry{"100644", "a", blob},
+		treeEntry{"120000", "link", missing},
+		treeEntry{"100644", "z", blob},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	err = tree.Files().ForEach(func(f *git.File) error {
+		names = append(names, f.Name)
+		return nil
+	})
+	if err == nil || err == io.EOF {
+		t.Errorf("ForEach visited %q and returned %v, want the error reading link", names, err)
+	}
+
+	if _, err := tree.Paths(); err == nil {
+		t.Error("Paths of a tree with a dangling symlink succeeded")
+	}
+
+	if _, err := tree.Size(); err == nil {
+		t.Error("Size of a tree with a dangling symlink succeeded")
+	}
+}
+
+func TestTreeSize(t *testing.T) {
+	tr := newTestRepo(t)
+	one := tr.Write(core.BlobObject, []byte("1\n"))
+	three := tr.Write(core.BlobObject, []byte("333\n"))
+	sub := tr.tree(
+		treeEntry{"100644", "a", one},
+		treeEntry{"100755", "b", three},
+	)
+	root := tr.tree(
+		treeEntry{"100644", "a", three},
+		treeEntry{"40000", "dir", tr.tree(treeEntry{"40000", "sub", sub})},
+		treeEntry{"160000", "module", core.ComputeHash(core.CommitObject, []byte("c"))},
+		treeEntry{"100644", "z", one},
+	)
+	for _, tc := range []struct {
+		tree core.Hash
+		size int64
+	}{
+		{tr.tree(), 0},
+		{sub, 6},
+		// The blobs of a and z are also in sub, and count at every path.
+		{root, 12},
+		{tr.tree(treeEntry{"100644", "a", three}, treeEntry{"100644", "b", three}, treeEntry{"100644", "c", three}), 12},
+	} {
+		tree, err := tr.Tree(tc.tree)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if size, err := tree.Size(); err != nil || size != tc.size {
+			t.Errorf("Size of %s = %d, %v, want %d", tc.tree, size, err, tc.size)
+		}
+	}
+
+	missing := core.ComputeHash(core.TreeObject, []byte("missing"))
+	tree, err := tr.Tree(tr.tree(
+		treeEntry{"100644", "a", one},
+		treeEntry{"40000", "dir", missing},
+		treeEntry{"100644", "z", three},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size, err := tree.Size(); err == nil {
+		t.Errorf("Size of a tree missing its subtree = %d, want an error", size)
+	}
+}
+
+func TestTreePaths(t *testing.T) {
+	tr := newTestRepo(t)
+	blob := tr.Write(core.BlobObject, []byte("1\n"))
+	target := tr.Write(core.BlobObject, []byte("dir/a"))
+	tree, err := tr.Tree(tr.tree(
+		treeEntry{"100644", "b", blob},
+		treeEntry{"40000", "dir", tr.tree(
+			treeEntry{"100644", "a", blob},
+			treeEntry{"40000", "empty", tr.tree()},
+			treeEntry{"40000", "sub", tr.tree(treeEntry{"100755", "c", blob})},
+		)},
+		treeEntry{"120000", "link", target},
+		treeEntry{"160000", "module", core.ComputeHash(core.CommitObject, []byte("c"))},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"b", "dir/a", "dir/sub/c", "link"}
+	if paths, err := tree.Paths(); err != nil || !reflect.DeepEqual(paths, want) {
+		t.Errorf("Paths() = %q, %v, want %q", paths, err, want)
+	}
+
+	broken, err := tr.Tree(tr.tree(
+		treeEntry{"100644", "a", blob},
+		treeEntry{"40000", "dir", tr.tree(
+			treeEntry{"40000", "sub", core.ComputeHash(core.TreeObject, []byte("missing"))},
+		)},
+		treeEntry{"100644", "z", blob},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if paths, err := broken.Paths(); err == nil {
+		t.Errorf("Paths of a tree missing a subtree = %q, want an error", paths)
+	}
+}
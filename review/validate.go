@@ -0,0 +1,136 @@
+package review
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// ErrEmptyRepository is returned when reviewing a repository without commits.
+var ErrEmptyRepository = errors.New("repository has no commits")
+
+// Validate resolves the revisions and patterns of the options without
+// running any check, so misconfigurations are reported before the review
+// starts. The first of the errors returned by CheckRevisions and
+// CheckPatterns is returned.
+func Validate(r *git.Repository, o *Options) error {
+	errs := append(CheckRevisions(r, o), CheckPatterns(o)...)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// CheckRevisions returns an error for each revision of the options that
+// can't be resolved in the repository, and for an invalid repository
+// configuration. ErrEmptyRepository is returned alone if the repository has
+// no commits.
+func CheckRevisions(r *git.Repository, o *Options) []error {
+	var errs []error
+	if head, since, err := resolveRange(r, o); err == ErrEmptyRepository {
+		return []error{err}
+	} else if err != nil {
+		errs = append(errs, err)
+	} else if _, err := loadRepoConfig(head, since, o); err != nil {
+		errs = append(errs, err)
+	}
+
+	if o.Commit != "" {
+		if _, err := resolveSingleCommit(r, o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// CheckPatterns returns an error for each invalid regular expression, glob
+// or mode of the options.
+func CheckPatterns(o *Options) []error {
+	var errs []error
+	if _, err := newAuthorFilter(nil, o); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := checkMerges(o.Merges); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := checkOrder(o.Order); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, glob := range append(append([]string{}, o.Paths...), o.ExcludedPaths...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid path glob %q: %s", glob, err))
+		}
+	}
+
+	return append(errs, o.Options.Check()...)
+}
+
+// resolveRange returns the commit pointed by Ref and the one pointed by
+// SinceCommit, or its merge base with BaseBranch, nil if neither is set. ErrEmptyRepository is returned if the
+// repository has no commits.
+func resolveRange(r *git.Repository, o *Options) (head, since *git.Commit, err error) {
+	ref := o.Ref
+	if ref == "" {
+		ref = string(core.HEAD)
+	}
+
+	if head, err = ResolveCommit(r, ref); err != nil {
+		if empty, _ := r.IsEmpty(); empty {
+			return nil, nil, ErrEmptyRepository
+		}
+
+		return nil, nil, fmt.Errorf("resolving ref %s: %s", ref, err)
+	}
+
+	if o.BaseBranch != "" {
+		since, err = resolveMergeBase(r, head, o)
+		return head, since, err
+	}
+
+	if o.SinceCommit == "" {
+		return head, nil, nil
+	}
+
+	if since, err = ResolveCommit(r, o.SinceCommit); err != nil {
+		return nil, nil, fmt.Errorf("resolving since commit %s: %s", o.SinceCommit, err)
+	}
+
+	return head, since, nil
+}
+
+// resolveMergeBase returns the merge base of head and the BaseBranch.
+func resolveMergeBase(r *git.Repository, head *git.Commit, o *Options) (*git.Commit, error) {
+	if o.SinceCommit != "" || len(o.ChangedFiles) > 0 {
+		return nil, errors.New("a base branch can't be combined with a since commit or changed files")
+	}
+
+	base, err := ResolveCommit(r, o.BaseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("resolving base branch %s: %s", o.BaseBranch, err)
+	}
+
+	mb, err := MergeBase(r, head, base)
+	if err != nil {
+		return nil, fmt.Errorf("merge base with %s: %s", o.BaseBranch, err)
+	}
+
+	return mb, nil
+}
+
+// resolveSingleCommit returns the commit named by the Commit option.
+func resolveSingleCommit(r *git.Repository, o *Options) (*git.Commit, error) {
+	c, err := ResolveCommit(r, o.Commit)
+	if err != nil {
+		return nil, fmt.Errorf("resolving commit %s: %s", o.Commit, err)
+	}
+
+	return c, nil
+}
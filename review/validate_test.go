@@ -0,0 +1,96 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckRevisions(t *testing.T) {
+	tr := newTestRepo(t)
+	_, second, _, _ := history(tr)
+	for _, tc := range []struct {
+		o    Options
+		want []string
+	}{
+		{Options{}, nil},
+		{Options{Ref: "side", SinceCommit: "v1", Commit: second.String()[:7]}, nil},
+		{Options{Ref: "nope"}, []string{"resolving ref nope: "}},
+		{Options{SinceCommit: "nope"}, []string{"resolving since commit nope: "}},
+		{Options{Ref: "nope", Commit: "0123abc"}, []string{"resolving ref nope: ", "resolving commit 0123abc: "}},
+		{Options{BaseBranch: "side", SinceCommit: "v1"}, []string{"a base branch can't be combined with a since commit or changed files"}},
+	} {
+		errs := CheckRevisions(tr.Repository, &tc.o)
+		if len(errs) != len(tc.want) {
+			t.Errorf("CheckRevisions(%+v) = %v, want %d errors", tc.o, errs, len(tc.want))
+			continue
+		}
+
+		for i, err := range errs {
+			if !strings.HasPrefix(err.Error(), tc.want[i]) {
+				t.Errorf("CheckRevisions(%+v) error %d = %s, want %s", tc.o, i, err, tc.want[i])
+			}
+		}
+	}
+}
+
+func TestCheckRevisionsEmptyRepository(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.SetHead("main")
+	o := &Options{SinceCommit: "nope", Commit: "0123abc"}
+	if errs := CheckRevisions(tr.Repository, o); len(errs) != 1 || errs[0] != ErrEmptyRepository {
+		t.Errorf("CheckRevisions of an empty repository = %v, want %s", errs, ErrEmptyRepository)
+	}
+
+	if _, err := Run(tr.Repository, &Options{}); err != ErrEmptyRepository {
+		t.Errorf("Run of an empty repository = %v, want %s", err, ErrEmptyRepository)
+	}
+}
+
+func TestCheckPatterns(t *testing.T) {
+	o := &Options{Author: "(", Merges: "some", Order: "random", Paths: []string{"[a"}, ExcludedPaths: []string{"ok/*"}}
+	want := []string{
+		`invalid author pattern "(": `,
+		`invalid merge handling "some"`,
+		`invalid commit order "random"`,
+		`invalid path glob "[a": `,
+	}
+
+	errs := CheckPatterns(o)
+	if len(errs) != len(want) {
+		t.Fatalf("CheckPatterns() = %v, want %d errors", errs, len(want))
+	}
+
+	for i, err := range errs {
+		if !strings.HasPrefix(err.Error(), want[i]) {
+			t.Errorf("CheckPatterns() error %d = %s, want %s", i, err, want[i])
+		}
+	}
+
+	if errs := CheckPatterns(&Options{}); len(errs) != 0 {
+		t.Errorf("CheckPatterns of the defaults = %v", errs)
+	}
+}
+
+func TestSinceCommit(t *testing.T) {
+	tr := newTestRepo(t)
+	root, second, _, _ := history(tr)
+	for _, tc := range []struct {
+		since string
+		want  int
+	}{
+		{"", 4},
+		{root.String(), 3},
+		{"v1", 2},
+		{second.String()[:7], 2},
+		{"main", 0},
+	} {
+		res, err := Run(tr.Repository, &Options{SinceCommit: tc.since})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Commits != tc.want {
+			t.Errorf("-since-commit %q: %d commits, want %d", tc.since, res.Commits, tc.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package review
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gunjan5/code-review-bot/gitattributes"
+	"github.com/gunjan5/code-review-bot/gitindex"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// Modes of the files read from the working tree, as in trees.
+const (
+	regularMode    = 0100644
+	executableMode = 0100755
+	symlinkMode    = 0120000
+)
+
+// RunWorktree reviews the files of the working tree at root that differ
+// from the tree of the commit pointed by Ref, HEAD by default, whether their
+// changes are staged or not: what git diff HEAD shows. Only the files
+// tracked in the index are read, untracked files being ignored as are
+// deleted files and submodules. Paths with unresolved conflicts are checked
+// as they are on disk. Only the file rules and the plugins are run, with the
+// .gitattributes of the working tree.
+func RunWorktree(r *git.Repository, idx *gitindex.Index, root string, o *Options) (*Result, error) {
+	o, err := worktreeRepoConfig(root, o)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := indexBase(r, o)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := worktreePathFilter(root, o)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	q := newFileQueue(o)
+	seen := make(map[string]bool)
+	for _, e := range idx.Entries {
+		if seen[e.Name] || e.IsSubmodule() || !paths.Match(e.Name) {
+			continue
+		}
+
+		seen[e.Name] = true
+		f, err := worktreeFile(root, e.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if f == nil {
+			continue
+		}
+
+		if committed, ok := head[e.Name]; ok && committed.Hash == f.Hash && committed.Mode == f.Mode {
+			continue
+		}
+
+		if err := q.Add(f); err != nil {
+			break
+		}
+	}
+
+	vs, err := q.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	res.Violations = vs
+	res.Paths = q.Paths
+	return res, nil
+}
+
+// worktreeFile reads the file at the path of the working tree, nil if it
+// was deleted or replaced by a directory. Symbolic links hold their target,
+// as in git.
+func worktreeFile(root, name string) (*git.File, error) {
+	full := filepath.Join(root, filepath.FromSlash(name))
+	fi, err := os.Lstat(full)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	var mode os.FileMode
+	switch {
+	case fi.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(full)
+		if err != nil {
+			return nil, err
+		}
+
+		data, mode = []byte(filepath.ToSlash(target)), symlinkMode|os.ModeSymlink
+	case fi.Mode().IsRegular():
+		if data, err = ioutil.ReadFile(full); err != nil {
+			return nil, err
+		}
+
+		mode = regularMode
+		if fi.Mode()&0111 != 0 {
+			mode = executableMode
+		}
+	default:
+		return nil, nil
+	}
+
+	obj := &core.MemoryObject{}
+	obj.SetType(core.BlobObject)
+	obj.SetSize(int64(len(data)))
+	obj.Write(data)
+	blob := &git.Blob{}
+	if err := blob.Decode(obj); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+
+	return git.NewFile(name, mode, blob), nil
+}
+
+// worktreePathFilter builds the path filter from the .gitattributes of the
+// working tree.
+func worktreePathFilter(root string, o *Options) (*pathFilter, error) {
+	attrs := &gitattributes.Attributes{}
+	f, err := os.Open(filepath.Join(root, ".gitattributes"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		defer f.Close()
+		if attrs, err = gitattributes.Parse(f); err != nil {
+			return nil, fmt.Errorf(".gitattributes: %s", err)
+		}
+	}
+
+	return newPathFilter(attrs, o), nil
+}
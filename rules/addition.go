@@ -0,0 +1,122 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/gitattributes"
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "large-addition",
+		Severity:    report.Error,
+		Description: "commits must not add files bigger than -max-added-file-size bytes, better stored with Git LFS",
+		Commit:      checkLargeAdditions,
+	})
+
+	Register(&Func{
+		ID:          "binary-addition",
+		Severity:    report.Warning,
+		Description: "binary files added by commits are pointed out, better stored with Git LFS, with -flag-binary-additions",
+		Commit:      checkBinaryAdditions,
+	})
+}
+
+func checkLargeAdditions(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if o.MaxAddedFileSize <= 0 {
+		return nil, nil
+	}
+
+	return checkAdditions(c, o, func(from, to *git.File) (string, error) {
+		if to.Size <= o.MaxAddedFileSize || (from != nil && from.Size > o.MaxAddedFileSize) {
+			return "", nil
+		}
+
+		return fmt.Sprintf("file of %d bytes added by commit %s, the limit is %d", to.Size, c.Hash.String()[:7], o.MaxAddedFileSize), nil
+	})
+}
+
+func checkBinaryAdditions(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.FlagBinaryAdditions {
+		return nil, nil
+	}
+
+	return checkAdditions(c, o, func(from, to *git.File) (string, error) {
+		if from != nil {
+			return "", nil
+		}
+
+		if binary, err := IsBinary(to); err != nil || !binary {
+			return "", err
+		}
+
+		return fmt.Sprintf("binary file added by commit %s", c.Hash.String()[:7]), nil
+	})
+}
+
+// checkAdditions calls check on the files added by the commit, from being
+// nil, or modified, compared to the trees chosen by DiffAgainst, and returns
+// a violation for each non-empty message. A renamed file is passed with its
+// old version as from.
+func checkAdditions(c *git.Commit, o *Options, check func(from, to *git.File) (string, error)) ([]report.Violation, error) {
+	changes, err := ChangesAgainst(c, o.DiffAgainst)
+	if err != nil {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	var attrs *gitattributes.Attributes
+	for _, ch := range changes {
+		if ch.Action == git.Delete {
+			continue
+		}
+
+		from, to, err := ch.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		name := changeName(ch)
+		msg, err := check(from, to)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		if msg == "" {
+			continue
+		}
+
+		if attrs == nil {
+			if attrs, err = commitAttributes(c); err != nil {
+				return nil, err
+			}
+		}
+
+		if attrs.Value(name, "filter") == "lfs" {
+			msg += ", committed without Git LFS though .gitattributes tracks it with filter=lfs"
+		} else {
+			msg += ", track it with Git LFS instead"
+		}
+
+		vs = append(vs, report.Violation{Path: name, Message: msg})
+	}
+
+	return vs, nil
+}
+
+// commitAttributes returns the .gitattributes of the tree of the commit.
+func commitAttributes(c *git.Commit) (*gitattributes.Attributes, error) {
+	t, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := gitattributes.FromTree(t)
+	if err != nil {
+		return nil, fmt.Errorf(".gitattributes: %s", err)
+	}
+
+	return attrs, nil
+}
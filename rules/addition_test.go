@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckLargeAdditionsRenames(t *testing.T) {
+	tr := newTestRepo(t)
+	big := strings.Repeat("x", 100)
+	parent := tr.commit(map[string]string{
+		"a/big.txt":   big,
+		"old.txt":     big,
+		"grown.txt":   "small\n",
+		"already.txt": big,
+	}, "Add the files")
+
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"renamed", map[string]string{"b/big.txt": big, "old.txt": big, "grown.txt": "small\n", "already.txt": big + "\n"}, nil},
+		// Only one of the copies is paired with the deleted file.
+		{"renamed and copied", map[string]string{"b/big.txt": big, "c/big.txt": big, "old.txt": big, "grown.txt": "small\n", "already.txt": big}, []string{"c/big.txt"}},
+		// grown.txt takes the content of the deleted old.txt, which isn't
+		// a rename of it.
+		{"grown to a deleted blob", map[string]string{"a/big.txt": big, "grown.txt": big, "already.txt": big}, []string{"grown.txt"}},
+	} {
+		c := tr.commit(tc.files, "Change the files", parent)
+		vs, err := checkLargeAdditions(c, &Options{MaxAddedFileSize: 50})
+		var paths []string
+		for _, v := range vs {
+			paths = append(paths, v.Path)
+		}
+
+		if err != nil || !reflect.DeepEqual(paths, tc.want) {
+			t.Errorf("%s: checkLargeAdditions reported %q, %v, want %q", tc.name, paths, err, tc.want)
+		}
+	}
+}
+
+func TestCheckForbiddenFilesRenames(t *testing.T) {
+	tr := newTestRepo(t)
+	parent := tr.commit(map[string]string{"keys/server.pem": "key\n", "notes.txt": "notes\n"}, "Add the files")
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"renamed", map[string]string{"certs/server.pem": "key\n", "notes.txt": "notes\n"}, nil},
+		// Only one of the copies is paired with the deleted file.
+		{"renamed and copied", map[string]string{"certs/a.pem": "key\n", "certs/b.pem": "key\n", "notes.txt": "notes\n"}, []string{"certs/b.pem"}},
+		{"added beside the original", map[string]string{"certs/server.pem": "key\n", "keys/server.pem": "key\n", "notes.txt": "notes\n"}, []string{"certs/server.pem"}},
+	} {
+		c := tr.commit(tc.files, "Change the files", parent)
+		vs, err := checkForbiddenFiles(c, &Options{ForbidFiles: true})
+		var paths []string
+		for _, v := range vs {
+			paths = append(paths, v.Path)
+		}
+
+		if err != nil || !reflect.DeepEqual(paths, tc.want) {
+			t.Errorf("%s: checkForbiddenFiles reported %q, %v, want %q", tc.name, paths, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "ascii-filename",
+		Severity:    report.Error,
+		Description: "file and directory names must only contain printable ASCII characters",
+		Tree:        checkASCIIFilenames,
+	})
+}
+
+// escapeName returns the name with its bytes outside printable ASCII escaped
+// as \xNN, and whether there was any.
+func escapeName(name string) (string, bool) {
+	var buf bytes.Buffer
+	escaped := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < 0x20 || c > 0x7e {
+			fmt.Fprintf(&buf, `\x%02x`, c)
+			escaped = true
+			continue
+		}
+
+		buf.WriteByte(c)
+	}
+
+	return buf.String(), escaped
+}
+
+func checkASCIIFilenames(r *git.Repository, t *git.Tree, o *Options) ([]report.Violation, error) {
+	if !o.ASCIIFilenames {
+		return nil, nil
+	}
+
+	var vs []report.Violation
+	iter := git.NewTreeIter(r, t, true)
+	defer iter.Close()
+	for {
+		name, entry, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Directories are reported once, not for each of their entries.
+		if escaped, ok := escapeName(entry.Name); ok {
+			vs = append(vs, report.Violation{
+				Path:    name,
+				Message: fmt.Sprintf("name %s has non-ASCII bytes", escaped),
+			})
+		}
+	}
+
+	return vs, nil
+}
@@ -0,0 +1,43 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckASCIIFilenames(t *testing.T) {
+	for _, tc := range []struct {
+		files map[string]string
+		want  []report.Violation
+	}{
+		{map[string]string{"README.md": "", "src/main_test.go": "", "a b~!.txt": ""}, nil},
+		{map[string]string{"café.txt": "", "ok.txt": ""}, []report.Violation{
+			{Path: "café.txt", Message: `name caf\xc3\xa9.txt has non-ASCII bytes`},
+		}},
+		{map[string]string{"données/a.txt": "", "données/b.txt": ""}, []report.Violation{
+			{Path: "données", Message: `name donn\xc3\xa9es has non-ASCII bytes`},
+		}},
+		{map[string]string{"tab\there": "", "new\nline": "", "del\x7f": ""}, []report.Violation{
+			{Path: "del\x7f", Message: `name del\x7f has non-ASCII bytes`},
+			{Path: "new\nline", Message: `name new\x0aline has non-ASCII bytes`},
+			{Path: "tab\there", Message: `name tab\x09here has non-ASCII bytes`},
+		}},
+	} {
+		tr := newTestRepo(t)
+		tree, err := tr.commit(tc.files, "Add the files").Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vs, err := checkASCIIFilenames(tr.Repository, tree, &Options{ASCIIFilenames: true})
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkASCIIFilenames(%q) = %+v, %v, want %+v", tc.files, vs, err, tc.want)
+		}
+
+		if vs, err := checkASCIIFilenames(tr.Repository, tree, &Options{}); err != nil || vs != nil {
+			t.Errorf("checkASCIIFilenames(%q) without -ascii-filenames = %+v, %v", tc.files, vs, err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package rules
+
+import (
+	"os"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// IsBinary returns true if the file has the extension of a binary format or
+// contains a NUL byte in its first bytes, see diff.IsBinary.
+func IsBinary(f *git.File) (bool, error) {
+	return diff.IsBinary(f)
+}
+
+// IsExecutable returns true if the mode of a tree entry has any of the
+// executable bits set. Tree entry modes keep the permission bits of the
+// file, so a regular executable file is 0100755.
+func IsExecutable(mode os.FileMode) bool {
+	return mode&os.ModeType == 0 && mode&0111 != 0
+}
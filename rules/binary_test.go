@@ -0,0 +1,43 @@
+package rules
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	for _, tc := range []struct {
+		name, content string
+		want          bool
+	}{
+		{"empty", "", false},
+		{"a.go", "package a\n", false},
+		{"large.txt", strings.Repeat("x", 1<<20) + "\n", false},
+		{"data.bin", "\x7fELF\x02\x01\x01\x00", true},
+		{"image.jpg", "", true},
+	} {
+		got, err := IsBinary(testFile(t, tc.name, tc.content))
+		if err != nil || got != tc.want {
+			t.Errorf("IsBinary(%s) = %v, %v, want %v", tc.name, got, err, tc.want)
+		}
+	}
+}
+
+func TestIsExecutable(t *testing.T) {
+	for _, tc := range []struct {
+		mode os.FileMode
+		want bool
+	}{
+		{0644, false},
+		{0755, true},
+		{0744, true},
+		{0645, true},
+		{os.ModeSymlink | 0777, false},
+		{os.ModeDir | 0755, false},
+	} {
+		if got := IsExecutable(tc.mode); got != tc.want {
+			t.Errorf("IsExecutable(%v) = %v, want %v", tc.mode, got, tc.want)
+		}
+	}
+}
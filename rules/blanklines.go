@@ -0,0 +1,59 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "consecutive-blank-lines",
+		Severity:    report.Error,
+		Description: "text files must not have -max-consecutive-blank-lines or more blank lines in a row",
+		File:        checkBlankLines,
+	})
+}
+
+func checkBlankLines(f *git.File, o *Options) ([]report.Violation, error) {
+	if o.MaxBlankLines <= 0 || (len(o.BlankLinesPaths) > 0 && !matchAny(o.BlankLinesPaths, f.Name)) {
+		return nil, nil
+	}
+
+	if binary, err := IsBinary(f); err != nil || binary {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	run := 0
+	endRun := func(next int) {
+		if run >= o.MaxBlankLines {
+			vs = append(vs, report.Violation{
+				Path:    f.Name,
+				Line:    next - run,
+				Message: fmt.Sprintf("%d consecutive blank lines, at most %d are allowed", run, o.MaxBlankLines-1),
+			})
+		}
+
+		run = 0
+	}
+
+	lines, err := ScanLinesLimit(f, o.MaxReadSize, func(n int, l string) bool {
+		if strings.TrimSpace(l) == "" {
+			run++
+		} else {
+			endRun(n)
+		}
+
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	endRun(lines + 1)
+	return vs, nil
+}
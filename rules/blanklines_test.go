@@ -0,0 +1,40 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckBlankLines(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		max     int
+		want    []report.Violation
+	}{
+		{"a\n\nb\n\nc\n", 2, nil},
+		{"a\n\n\nb\n", 2, []report.Violation{{Path: "f.txt", Line: 2, Message: "2 consecutive blank lines, at most 1 are allowed"}}},
+		{"a\n\n\nb\n", 3, nil},
+		{"a\n \n\t\n\nb\n", 3, []report.Violation{{Path: "f.txt", Line: 2, Message: "3 consecutive blank lines, at most 2 are allowed"}}},
+		{"a\n\n\n\nb\n", 2, []report.Violation{{Path: "f.txt", Line: 2, Message: "3 consecutive blank lines, at most 1 are allowed"}}},
+		{"\n\na\nb\n\n\n", 2, []report.Violation{
+			{Path: "f.txt", Line: 1, Message: "2 consecutive blank lines, at most 1 are allowed"},
+			{Path: "f.txt", Line: 5, Message: "2 consecutive blank lines, at most 1 are allowed"},
+		}},
+		{"a\n\nb\n", 1, []report.Violation{{Path: "f.txt", Line: 2, Message: "1 consecutive blank lines, at most 0 are allowed"}}},
+		{"a\n\n\n\nb\n", 0, nil},
+		{"\x00\n\n\n\n", 2, nil},
+	} {
+		o := &Options{MaxBlankLines: tc.max}
+		vs, err := checkBlankLines(testFile(t, "f.txt", tc.content), o)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkBlankLines(%q, %d) = %+v, %v, want %+v", tc.content, tc.max, vs, err, tc.want)
+		}
+	}
+
+	o := &Options{MaxBlankLines: 2, BlankLinesPaths: []string{"*.go"}}
+	if vs, err := checkBlankLines(testFile(t, "f.txt", "a\n\n\n\nb\n"), o); err != nil || vs != nil {
+		t.Errorf("checkBlankLines of a file not matching -blank-lines-path = %+v, %v", vs, err)
+	}
+}
@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "bom",
+		Severity:    report.Error,
+		Description: "files must not start with a byte order mark",
+		File:        checkBOM,
+	})
+}
+
+// boms are the byte order marks detected, by encoding.
+var boms = []struct {
+	encoding string
+	mark     []byte
+}{
+	{"UTF-8", []byte{0xef, 0xbb, 0xbf}},
+	{"UTF-16BE", []byte{0xfe, 0xff}},
+	{"UTF-16LE", []byte{0xff, 0xfe}},
+}
+
+// checkBOM only reads the first bytes of the file, UTF-16 files being
+// usually detected as binary files they aren't skipped.
+func checkBOM(f *git.File, o *Options) ([]report.Violation, error) {
+	if !o.CheckBOM {
+		return nil, nil
+	}
+
+	head, err := Head(f, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bom := range boms {
+		if bytes.HasPrefix(head, bom.mark) {
+			return []report.Violation{{
+				Path:    f.Name,
+				Line:    1,
+				Message: fmt.Sprintf("file starts with a %s byte order mark", bom.encoding),
+			}}, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,60 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckBOM(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		want    []report.Violation
+	}{
+		{"\xef\xbb\xbfpackage main\n", []report.Violation{{Path: "f.txt", Line: 1, Message: "file starts with a UTF-8 byte order mark"}}},
+		{"\xfe\xff\x00a", []report.Violation{{Path: "f.txt", Line: 1, Message: "file starts with a UTF-16BE byte order mark"}}},
+		{"\xff\xfea\x00", []report.Violation{{Path: "f.txt", Line: 1, Message: "file starts with a UTF-16LE byte order mark"}}},
+		{"package main\n", nil},
+		{"a\xef\xbb\xbf", nil},
+		{"\xef\xbb", nil},
+		{"", nil},
+	} {
+		vs, err := checkBOM(testFile(t, "f.txt", tc.content), &Options{CheckBOM: true})
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkBOM(%q) = %+v, %v, want %+v", tc.content, vs, err, tc.want)
+		}
+	}
+
+	if vs, err := checkBOM(testFile(t, "f.txt", "\xef\xbb\xbf"), &Options{}); err != nil || vs != nil {
+		t.Errorf("checkBOM without -check-bom = %+v, %v", vs, err)
+	}
+}
+
+func TestSeverityOverride(t *testing.T) {
+	f := testFile(t, "f.txt", "\xef\xbb\xbfa\n")
+	for _, tc := range []struct {
+		severity map[string]report.Severity
+		want     report.Severity
+	}{
+		{nil, report.Error},
+		{map[string]report.Severity{"bom": report.Info}, report.Info},
+		{map[string]report.Severity{"utf8": report.Info}, report.Error},
+	} {
+		vs, err := CheckFile(f, &Options{CheckBOM: true, Severity: tc.severity})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []report.Severity
+		for _, v := range vs {
+			if v.Rule == "bom" {
+				got = append(got, v.Severity)
+			}
+		}
+
+		if len(got) != 1 || got[0] != tc.want {
+			t.Errorf("-severity %v: bom severities %v, want %v", tc.severity, got, tc.want)
+		}
+	}
+}
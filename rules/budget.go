@@ -0,0 +1,151 @@
+package rules
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "file-lines",
+		Severity:    report.Warning,
+		Description: "files grown by commits must not have more than -max-file-lines lines",
+		Commit:      checkFileLines,
+	})
+
+	Register(&Func{
+		ID:          "func-size",
+		Severity:    report.Warning,
+		Description: "Go functions changed by commits must not have more than -max-func-lines lines or -max-func-statements statements",
+		Commit:      checkFuncSize,
+	})
+}
+
+func checkFileLines(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if o.MaxFileLines <= 0 {
+		return nil, nil
+	}
+
+	return checkChangedText(c, o, func(name, src string, p *diff.Patch) ([]report.Violation, error) {
+		var added, deleted int
+		for _, h := range p.Hunks {
+			for _, l := range h.Lines {
+				switch l.Kind {
+				case diff.Insert:
+					added++
+				case diff.Delete:
+					deleted++
+				}
+			}
+		}
+
+		n := countLines(src)
+		if n <= o.MaxFileLines || added <= deleted {
+			return nil, nil
+		}
+
+		return []report.Violation{{
+			Path:    name,
+			Message: fmt.Sprintf("file has %d lines, the limit is %d", n, o.MaxFileLines),
+		}}, nil
+	})
+}
+
+func checkFuncSize(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if o.MaxFuncLines <= 0 && o.MaxFuncStatements <= 0 {
+		return nil, nil
+	}
+
+	return checkChangedFuncs(c, o, func(fn *ast.FuncDecl, start, end int) string {
+		var problems []string
+		if n := end - start + 1; o.MaxFuncLines > 0 && n > o.MaxFuncLines {
+			problems = append(problems, fmt.Sprintf("%d lines, the limit is %d", n, o.MaxFuncLines))
+		}
+
+		if n := countStatements(fn.Body); o.MaxFuncStatements > 0 && n > o.MaxFuncStatements {
+			problems = append(problems, fmt.Sprintf("%d statements, the limit is %d", n, o.MaxFuncStatements))
+		}
+
+		return strings.Join(problems, " and ")
+	})
+}
+
+// checkChangedText calls check with the contents and the patch of each text
+// file added or modified by the commit, compared to the trees chosen by
+// DiffAgainst, renamed files to their old version. Files renamed without
+// changes, or only partly known, see PartialFiles, aren't checked.
+func checkChangedText(c *git.Commit, o *Options, check func(name, src string, p *diff.Patch) ([]report.Violation, error)) ([]report.Violation, error) {
+	changes, err := ChangesAgainst(c, o.DiffAgainst)
+	if err != nil {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	for _, ch := range changes {
+		name := changeName(ch)
+		if ch.Action == git.Delete || o.partial(name) {
+			continue
+		}
+
+		from, to, err := ch.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := diff.Unified(from, to)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		if p.Binary != nil || len(p.Hunks) == 0 {
+			continue
+		}
+
+		src, err := to.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		cvs, err := check(name, src, p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		vs = append(vs, cvs...)
+	}
+
+	return vs, nil
+}
+
+// countLines returns the number of lines of the text, as split by
+// ScanLines.
+func countLines(s string) int {
+	n := strings.Count(s, "\n")
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		n++
+	}
+
+	return n
+}
+
+// countStatements returns the number of statements in the block, nested ones
+// included, blocks themselves not counting.
+func countStatements(b *ast.BlockStmt) int {
+	n := 0
+	ast.Inspect(b, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.BlockStmt:
+		case ast.Stmt:
+			n++
+		}
+
+		return true
+	})
+
+	return n
+}
@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// numbered returns n numbered lines.
+func numbered(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+
+	return b.String()
+}
+
+func TestCheckFileLines(t *testing.T) {
+	tr := newTestRepo(t)
+	parent := tr.commit(map[string]string{
+		"big.txt":    numbered(12),
+		"gone.txt":   strings.Repeat("gone\n", 12),
+		"logo.png":   "\x89PNG\x00" + numbered(8),
+		"small.txt":  numbered(5),
+		"shrunk.txt": numbered(14),
+	}, "Add the files")
+
+	// gone.txt, over the limit, is deleted.
+	files := map[string]string{
+		// Grown to the limit and beyond it.
+		"small.txt": numbered(10),
+		"big.txt":   numbered(13),
+		// Over the limit, but shrunk.
+		"shrunk.txt": numbered(13),
+		// The lines of a binary file aren't counted.
+		"logo.png": "\x89PNG\x00" + numbered(20),
+		// Added just over the limit, whatever the line endings.
+		"added.txt": strings.Repeat("added\n", 11),
+		"crlf.txt":  strings.Replace(numbered(11), "\n", "\r\n", -1),
+		"last.txt":  strings.TrimSuffix(numbered(11), "\n"),
+	}
+
+	c := tr.commit(files, "Change the files", parent)
+	vs, err := checkFileLines(c, &Options{MaxFileLines: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	for _, v := range vs {
+		got[v.Path] = v.Message
+	}
+
+	want := map[string]string{
+		"big.txt":   "file has 13 lines, the limit is 10",
+		"added.txt": "file has 11 lines, the limit is 10",
+		"crlf.txt":  "file has 11 lines, the limit is 10",
+		"last.txt":  "file has 11 lines, the limit is 10",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkFileLines reported %q, want %q", got, want)
+	}
+
+	if vs, err := checkFileLines(c, &Options{}); err != nil || len(vs) != 0 {
+		t.Errorf("checkFileLines without -max-file-lines reported %+v, %v", vs, err)
+	}
+}
+
+func TestCheckFuncSize(t *testing.T) {
+	// f has 5 lines and 3 statements, g 7 lines and 4 statements, the
+	// statements of the if block counting.
+	const src = `package p
+
+func f() {
+	a := 1
+	b := a
+	_ = b
+}
+
+func g(a bool) {
+	if a {
+		println()
+		return
+	}
+	println()
+}
+`
+	tr := newTestRepo(t)
+	for _, tc := range []struct {
+		lines, statements int
+		want              []string
+	}{
+		{6, 3, []string{"p.go:9: function g has 7 lines, the limit is 6 and 4 statements, the limit is 3"}},
+		{7, 4, nil},
+		{7, 0, nil},
+		{0, 3, []string{"p.go:9: function g has 4 statements, the limit is 3"}},
+		{4, 0, []string{"p.go:3: function f has 5 lines, the limit is 4", "p.go:9: function g has 7 lines, the limit is 4"}},
+		{0, 0, nil},
+	} {
+		c := tr.commit(map[string]string{"p.go": src, "p.txt": src}, "Add p.go")
+		vs, err := checkFuncSize(c, &Options{MaxFuncLines: tc.lines, MaxFuncStatements: tc.statements})
+		var got []string
+		for _, v := range vs {
+			got = append(got, fmt.Sprintf("%s:%d: %s", v.Path, v.Line, v.Message))
+		}
+
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("-max-func-lines %d -max-func-statements %d: checkFuncSize reported %q, %v, want %q", tc.lines, tc.statements, got, err, tc.want)
+		}
+	}
+}
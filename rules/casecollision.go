@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "case-collision",
+		Severity:    report.Error,
+		Description: "entries of a directory must not differ only by case, they can't be checked out on case-insensitive filesystems",
+		Tree:        checkCaseCollisions,
+	})
+}
+
+func checkCaseCollisions(r *git.Repository, t *git.Tree, o *Options) ([]report.Violation, error) {
+	if !o.CaseCollisions {
+		return nil, nil
+	}
+
+	// Names are only compared with the entries of the same directory, the
+	// first entry seen for each lowercased name is kept by directory.
+	dirs := make(map[string]map[string]string)
+	var vs []report.Violation
+	iter := git.NewTreeIter(r, t, true)
+	defer iter.Close()
+	for {
+		name, _, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		dir, base := path.Split(name)
+		seen, ok := dirs[dir]
+		if !ok {
+			seen = make(map[string]string)
+			dirs[dir] = seen
+		}
+
+		lower := strings.ToLower(base)
+		other, ok := seen[lower]
+		// The same name twice is reported by duplicate-tree-entry.
+		if ok && other == name {
+			continue
+		}
+
+		if !ok {
+			seen[lower] = name
+			continue
+		}
+
+		vs = append(vs, report.Violation{
+			Path:    name,
+			Message: fmt.Sprintf("%s and %s differ only by case", other, name),
+		})
+	}
+
+	return vs, nil
+}
@@ -0,0 +1,38 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckCaseCollisions(t *testing.T) {
+	for _, tc := range []struct {
+		files map[string]string
+		want  []report.Violation
+	}{
+		{map[string]string{"README.md": "a", "Readme.md": "b", "docs/readme.md": "c"}, []report.Violation{
+			{Path: "Readme.md", Message: "README.md and Readme.md differ only by case"},
+		}},
+		{map[string]string{"a/Makefile": "a", "a/makefile": "b", "b/makefile": "c", "Makefile": "d"}, []report.Violation{
+			{Path: "a/makefile", Message: "a/Makefile and a/makefile differ only by case"},
+		}},
+		{map[string]string{"README.md": "a", "docs/README.md": "b", "docs/readme.txt": "c"}, nil},
+	} {
+		tr := newTestRepo(t)
+		tree, err := tr.commit(tc.files, "Add the files").Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vs, err := checkCaseCollisions(tr.Repository, tree, &Options{CaseCollisions: true})
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkCaseCollisions(%v) = %+v, %v, want %+v", tc.files, vs, err, tc.want)
+		}
+
+		if vs, err := checkCaseCollisions(tr.Repository, tree, &Options{}); err != nil || vs != nil {
+			t.Errorf("checkCaseCollisions(%v) without -case-collisions = %+v, %v", tc.files, vs, err)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// Trees a commit is compared to by ChangesAgainst, besides the ancestors
+// given as HEAD~N.
+const (
+	FirstParent = "first-parent"
+	AllParents  = "all-parents"
+)
+
+// Changes returns the changes made by the commit to the tree of its
+// first parent, or to an empty tree for a root commit. A commit whose parent
+// is missing, at the boundary of a shallow clone, is handled as a root
+// commit.
+func Changes(c *git.Commit) ([]*git.Change, error) {
+	return ChangesAgainst(c, FirstParent)
+}
+
+// ChangesAgainst returns the changes made by the commit compared to the tree
+// of its first parent with FirstParent or an empty mode, of its N-th
+// first-parent ancestor with HEAD~N, cumulating the changes of the N last
+// commits, or of each of its parents with AllParents, a file changed
+// compared to several parents being reported once. An empty tree is used in
+// place of the missing ancestors, before a root commit or at the boundary of
+// a shallow clone. The renames found by diff.TreeDiff are Modify changes
+// from the old path to the new one, submodules are left out.
+func ChangesAgainst(c *git.Commit, against string) ([]*git.Change, error) {
+	to, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	froms, err := againstTrees(c, against)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(froms) == 1 {
+		return treeChanges(froms[0], to)
+	}
+
+	var changes []*git.Change
+	seen := make(map[string]bool)
+	for _, from := range froms {
+		pchanges, err := treeChanges(from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ch := range pchanges {
+			name := changeName(ch)
+			if !seen[name] {
+				seen[name] = true
+				changes = append(changes, ch)
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// checkAddedLines calls check on each line added by the commit, compared to
+// the trees chosen by DiffAgainst, to the files not matching the ignored
+// globs, renamed files to their old version.
+func checkAddedLines(c *git.Commit, o *Options, ignored []string, check func(name string, l diff.Line) []report.Violation) ([]report.Violation, error) {
+	changes, err := ChangesAgainst(c, o.DiffAgainst)
+	if err != nil {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	for _, ch := range changes {
+		name := changeName(ch)
+		if ch.Action == git.Delete || matchAny(ignored, name) {
+			continue
+		}
+
+		from, to, err := ch.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := diff.Unified(from, to)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		for _, h := range p.Hunks {
+			for _, l := range h.Lines {
+				if l.Kind == diff.Insert {
+					vs = append(vs, check(name, l)...)
+				}
+			}
+		}
+	}
+
+	return vs, nil
+}
+
+// treeChanges returns the changes of diff.TreeDiff between the trees, from
+// being nil for an empty tree.
+func treeChanges(from, to *git.Tree) ([]*git.Change, error) {
+	dchanges, err := diff.TreeDiff(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*git.Change, 0, len(dchanges))
+	for _, dch := range dchanges {
+		ch := &git.Change{}
+		switch dch.Action {
+		case diff.Added:
+			ch.Action = git.Insert
+		case diff.Deleted:
+			ch.Action = git.Delete
+		default:
+			ch.Action = git.Modify
+		}
+
+		if dch.Action != diff.Added {
+			ch.From = changeEntry(from, dch.From)
+		}
+
+		if dch.Action != diff.Deleted {
+			ch.To = changeEntry(to, dch.To)
+		}
+
+		changes = append(changes, ch)
+	}
+
+	return changes, nil
+}
+
+// changeEntry returns the side of a git.Change of the entry of the tree.
+func changeEntry(t *git.Tree, e diff.Entry) git.ChangeEntry {
+	return git.ChangeEntry{
+		Name:      e.Path,
+		Tree:      t,
+		TreeEntry: git.TreeEntry{Name: path.Base(e.Path), Mode: e.Mode, Hash: e.Hash},
+	}
+}
+
+// againstTrees returns the trees the commit is compared to by
+// ChangesAgainst, nil for the missing ancestors.
+func againstTrees(c *git.Commit, against string) ([]*git.Tree, error) {
+	if against != AllParents || c.NumParents() < 2 {
+		depth, err := diffDepth(against)
+		if err != nil {
+			return nil, err
+		}
+
+		from, err := ancestorTree(c, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*git.Tree{from}, nil
+	}
+
+	var trees []*git.Tree
+	for i := 0; i < c.NumParents(); i++ {
+		var from *git.Tree
+		p, err := c.Parent(i)
+		if err != nil && !IsMissing(err) {
+			return nil, err
+		}
+
+		if p != nil {
+			if from, err = p.Tree(); err != nil {
+				return nil, err
+			}
+		}
+
+		trees = append(trees, from)
+	}
+
+	return trees, nil
+}
+
+// diffDepth returns the number of first-parent generations of the against
+// mode of ChangesAgainst, 1 for FirstParent.
+func diffDepth(against string) (int, error) {
+	switch {
+	case against == "" || against == FirstParent || against == AllParents:
+		return 1, nil
+	case against == "HEAD~":
+		return 1, nil
+	case strings.HasPrefix(against, "HEAD~"):
+		if n, err := strconv.Atoi(against[len("HEAD~"):]); err == nil && n > 0 {
+			return n, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid diff base %q, expected %s, %s or HEAD~N with N a positive number", against, FirstParent, AllParents)
+}
+
+// ancestorTree returns the tree of the depth-th first-parent ancestor of c,
+// nil if there is no such ancestor in the repository.
+func ancestorTree(c *git.Commit, depth int) (*git.Tree, error) {
+	for i := 0; i < depth; i++ {
+		if c.NumParents() == 0 {
+			return nil, nil
+		}
+
+		p, err := c.Parent(0)
+		if IsMissing(err) {
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		c = p
+	}
+
+	return c.Tree()
+}
+
+// changeName returns the path of the file of the change, its old path if it
+// was deleted, its new path if it was renamed.
+func changeName(ch *git.Change) string {
+	if ch.Action == git.Delete {
+		return ch.From.Name
+	}
+
+	return ch.To.Name
+}
+
+// IsMissing returns true if the error means an object isn't in the object
+// storage.
+func IsMissing(err error) bool {
+	return err == git.ErrObjectNotFound || err == core.ErrObjectNotFound
+}
@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// changed returns the changes as "action path".
+func changed(changes []*git.Change) []string {
+	var cs []string
+	for _, ch := range changes {
+		cs = append(cs, ch.Action.String()+" "+changeName(ch))
+	}
+
+	return cs
+}
+
+func TestChangesAgainst(t *testing.T) {
+	tr := newTestRepo(t)
+	root := tr.commit(map[string]string{"a": "1\n", "b": "1\n"}, "root")
+	main := tr.commit(map[string]string{"a": "2\n", "b": "1\n"}, "change a", root)
+	side := tr.commit(map[string]string{"a": "1\n", "b": "side\n", "s": "1\n"}, "change b, add s", root)
+	merge := tr.commit(map[string]string{"a": "2\n", "b": "side\n", "m": "1\n", "s": "1\n"}, "merge side, add m", main, side)
+	head := tr.commit(map[string]string{"a": "3\n", "b": "side\n", "m": "1\n", "s": "1\n"}, "change a", merge)
+
+	for _, tc := range []struct {
+		name    string
+		c       *git.Commit
+		against string
+		want    []string
+	}{
+		{"merge", merge, "", []string{"Modify b", "Insert m", "Insert s"}},
+		{"merge", merge, FirstParent, []string{"Modify b", "Insert m", "Insert s"}},
+		// The changes compared to main come first, a is only changed
+		// compared to side, m is added compared to both.
+		{"merge", merge, AllParents, []string{"Modify b", "Insert m", "Insert s", "Modify a"}},
+		{"merge", merge, "HEAD~", []string{"Modify b", "Insert m", "Insert s"}},
+		{"merge", merge, "HEAD~1", []string{"Modify b", "Insert m", "Insert s"}},
+		// HEAD~N follows the first parents only, side isn't an ancestor.
+		{"merge", merge, "HEAD~2", []string{"Modify a", "Modify b", "Insert m", "Insert s"}},
+		// Past the root, the commit is compared to an empty tree.
+		{"merge", merge, "HEAD~3", []string{"Insert a", "Insert b", "Insert m", "Insert s"}},
+		{"merge", merge, "HEAD~100", []string{"Insert a", "Insert b", "Insert m", "Insert s"}},
+		{"head", head, AllParents, []string{"Modify a"}},
+		{"head", head, "HEAD~2", []string{"Modify a", "Modify b", "Insert m", "Insert s"}},
+		{"root", root, AllParents, []string{"Insert a", "Insert b"}},
+		{"root", root, "HEAD~2", []string{"Insert a", "Insert b"}},
+	} {
+		changes, err := ChangesAgainst(tc.c, tc.against)
+		if got := changed(changes); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ChangesAgainst(%s, %q) = %q, %v, want %q", tc.name, tc.against, got, err, tc.want)
+		}
+	}
+
+	for _, against := range []string{"HEAD~0", "HEAD~-1", "HEAD~x", "HEAD^2", "second-parent"} {
+		if changes, err := ChangesAgainst(merge, against); err == nil {
+			t.Errorf("ChangesAgainst(merge, %q) = %q, want an error", against, changed(changes))
+		}
+
+		if errs := (&Options{DiffAgainst: against}).Check(); len(errs) == 0 {
+			t.Errorf("-diff-against %q accepted", against)
+		}
+	}
+}
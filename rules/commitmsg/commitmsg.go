@@ -0,0 +1,170 @@
+// Package commitmsg checks the commit messages: the length and the mood of
+// their subject, their Conventional Commit prefix and the wrapping of their
+// body. Each check returns the problems found in a message, reported by the
+// commit rules of package rules.
+package commitmsg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultTypes are the types of the Conventional Commits accepted when no
+// other types are given.
+var DefaultTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// conventionalPrefix matches the "type(scope)!: " prefix of a Conventional
+// Commit subject, the type and the scope being its submatches.
+var conventionalPrefix = regexp.MustCompile(`^(\w+)(?:\(([^()]*)\))?!?: `)
+
+// Subject returns the first line of a commit message.
+func Subject(msg string) string {
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		return msg[:i]
+	}
+
+	return msg
+}
+
+// SubjectLength returns a problem if the subject of the message, spaces
+// around it trimmed, is longer than max characters, an empty string
+// otherwise or if max isn't positive.
+func SubjectLength(msg string, max int) string {
+	n := utf8.RuneCountInString(strings.TrimSpace(Subject(msg)))
+	if max <= 0 || n <= max {
+		return ""
+	}
+
+	return fmt.Sprintf("subject is %d characters long, the limit is %d", n, max)
+}
+
+// Imperative returns a problem if the subject of the message, after its
+// Conventional Commit prefix, starts with a verb in the past tense, the
+// gerund or the third person, such as "Fixed", "Fixing" or "Fixes". Only
+// the forms of the verbs listed in verbs are recognized, so that words
+// merely ending likewise, like "Embed", "Alias" or "Process", aren't
+// reported.
+func Imperative(msg string) string {
+	subject := strings.TrimSpace(Subject(msg))
+	if m := conventionalPrefix.FindStringIndex(subject); m != nil {
+		subject = subject[m[1]:]
+	}
+
+	fields := strings.Fields(subject)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	word := strings.ToLower(strings.TrimRight(fields[0], ":,."))
+	if verbs[word] || !inflected(word) {
+		return ""
+	}
+
+	return fmt.Sprintf("subject starts with %q, use the imperative mood as in \"Fix bug\" rather than \"Fixed bug\" or \"Fixes bug\"", fields[0])
+}
+
+// inflected returns true if the word is the past tense, the past
+// participle, the gerund or the third person of a verb of verbs.
+func inflected(word string) bool {
+	if irregular[word] {
+		return true
+	}
+
+	for _, base := range bases(word) {
+		if verbs[base] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bases returns the verbs the word can be an inflection of by its suffix:
+// "fixes" can be "fixe" or "fix", "stopped" "stopp", "stoppe" or "stop".
+func bases(word string) []string {
+	var stems []string
+	switch {
+	case strings.HasSuffix(word, "ied"), strings.HasSuffix(word, "ies"):
+		return []string{word[:len(word)-3] + "y"}
+	case strings.HasSuffix(word, "ing"):
+		stem := word[:len(word)-3]
+		stems = []string{stem, stem + "e"}
+	case strings.HasSuffix(word, "ed"), strings.HasSuffix(word, "es"):
+		stems = []string{word[:len(word)-2], word[:len(word)-1]}
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return []string{word[:len(word)-1]}
+	default:
+		return nil
+	}
+
+	// The final consonant of short verbs is doubled, as in "stopped".
+	if s := stems[0]; len(s) > 2 && s[len(s)-1] == s[len(s)-2] {
+		stems = append(stems, s[:len(s)-1])
+	}
+
+	return stems
+}
+
+// Conventional returns a problem if the subject of the message doesn't
+// follow Conventional Commits, "type(scope): description", with one of the
+// types, DefaultTypes if empty, and a scope if requireScope.
+func Conventional(msg string, types []string, requireScope bool) string {
+	if len(types) == 0 {
+		types = DefaultTypes
+	}
+
+	subject := strings.TrimSpace(Subject(msg))
+	m := conventionalPrefix.FindStringSubmatch(subject)
+	switch {
+	case m == nil:
+		return fmt.Sprintf("subject doesn't start with a Conventional Commit prefix like \"%s: \" or \"%s(scope): \"", types[0], types[0])
+	case !containsString(types, m[1]):
+		return fmt.Sprintf("commit type %q isn't one of %s", m[1], strings.Join(types, ", "))
+	case requireScope && m[2] == "":
+		return fmt.Sprintf("commit type %q has no scope, as in \"%s(scope): \"", m[1], m[1])
+	case strings.TrimSpace(subject[len(m[0]):]) == "":
+		return "subject has no description after its Conventional Commit prefix"
+	}
+
+	return ""
+}
+
+// Body returns the problems of the body of the message: not separated from
+// the subject by a blank line, or with lines longer than max characters if
+// max is positive. Indented lines, like code or quotes, and lines without
+// spaces, like URLs, can't be wrapped and aren't reported.
+func Body(msg string, max int) []string {
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	if max <= 0 || len(lines) < 2 {
+		return nil
+	}
+
+	var problems []string
+	if strings.TrimSpace(lines[1]) != "" {
+		problems = append(problems, "commit message body isn't separated from the subject by a blank line")
+	}
+
+	for i, l := range lines[1:] {
+		n := utf8.RuneCountInString(l)
+		if n <= max || strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t") || !strings.Contains(strings.TrimSpace(l), " ") {
+			continue
+		}
+
+		problems = append(problems, fmt.Sprintf("line %d of the commit message is %d characters long, wrap the body at %d", i+2, n, max))
+	}
+
+	return problems
+}
+
+// containsString returns true if the list holds s.
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
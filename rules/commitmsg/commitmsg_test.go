@@ -0,0 +1,148 @@
+package commitmsg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImperative(t *testing.T) {
+	for _, tc := range []struct {
+		msg  string
+		word string
+	}{
+		{"Fixed the parser", "Fixed"},
+		{"Fixes the parser", "Fixes"},
+		{"Fixing the parser", "Fixing"},
+		{"added tests", "added"},
+		{"Updated docs", "Updated"},
+		{"Updates docs", "Updates"},
+		{"Updating docs", "Updating"},
+		{"Stopped the timer", "Stopped"},
+		{"Setting defaults", "Setting"},
+		{"Applied the patch", "Applied"},
+		{"Copies the files", "Copies"},
+		{"Processes the queue", "Processes"},
+		{"Uses the cache", "Uses"},
+		{"Made it faster", "Made"},
+		{"Wrote the docs", "Wrote"},
+		{"Removed.", "Removed."},
+		{"fix: added a check", "added"},
+		{"feat(api)!: Renamed the endpoint", "Renamed"},
+		{"Fixed\n\nbody", "Fixed"},
+
+		{"Fix the parser", ""},
+		{"Add tests", ""},
+		{"Update docs", ""},
+		{"Embed the templates", ""},
+		{"Alias the old name", ""},
+		{"Process the queue", ""},
+		{"Access the map once", ""},
+		{"Address review comments", ""},
+		{"Need more tests", ""},
+		{"Status of the build", ""},
+		{"Bring back the flag", ""},
+		{"String escaping in the parser", ""},
+		{"Docs for the API", ""},
+		{"Ping the server", ""},
+		{"Thing", ""},
+		{"Speed up the parser", ""},
+		{"fix: handle empty input", ""},
+		{"", ""},
+		{"   \n\nbody", ""},
+	} {
+		got := Imperative(tc.msg)
+		if tc.word == "" && got != "" {
+			t.Errorf("Imperative(%q) = %q, want no problem", tc.msg, got)
+		}
+
+		if tc.word != "" && !strings.HasPrefix(got, "subject starts with \""+tc.word+"\"") {
+			t.Errorf("Imperative(%q) = %q, want a problem with %q", tc.msg, got, tc.word)
+		}
+	}
+}
+
+func TestSubjectLength(t *testing.T) {
+	for _, tc := range []struct {
+		msg  string
+		max  int
+		want string
+	}{
+		{"Add tests", 9, ""},
+		{"Add tests!", 9, "subject is 10 characters long, the limit is 9"},
+		{"  Add tests  \n\n" + strings.Repeat("x", 100), 9, ""},
+		{"Ajouter les tests été", 21, ""},
+		{"Ajouter les tests été", 20, "subject is 21 characters long, the limit is 20"},
+		{strings.Repeat("x", 1000), 0, ""},
+	} {
+		if got := SubjectLength(tc.msg, tc.max); got != tc.want {
+			t.Errorf("SubjectLength(%q, %d) = %q, want %q", tc.msg, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestConventional(t *testing.T) {
+	for _, tc := range []struct {
+		msg   string
+		types []string
+		scope bool
+		want  string
+	}{
+		{"feat: add the flag", nil, false, ""},
+		{"fix(parser): handle tabs", nil, true, ""},
+		{"feat!: drop Go 1.6", nil, false, ""},
+		{"feat(api)!: rename the endpoint\n\nBREAKING CHANGE: renamed", nil, false, ""},
+		{"Add the flag", nil, false, `subject doesn't start with a Conventional Commit prefix like "feat: " or "feat(scope): "`},
+		{"feat:add the flag", nil, false, `subject doesn't start with a Conventional Commit prefix`},
+		{"feature: add the flag", nil, false, `commit type "feature" isn't one of feat, fix, docs`},
+		{"feat: add the flag", []string{"new", "bug"}, false, `commit type "feat" isn't one of new, bug`},
+		{"bug: fix it", []string{"new", "bug"}, false, ""},
+		{"fix: handle tabs", nil, true, `commit type "fix" has no scope, as in "fix(scope): "`},
+		{"fix(): handle tabs", nil, true, `commit type "fix" has no scope`},
+		{"fix:  ", nil, false, `subject doesn't start with a Conventional Commit prefix`},
+		{"fix(parser):  \n\nbody", nil, false, `subject doesn't start with a Conventional Commit prefix`},
+		{"fix(parser): \t.", nil, false, ""},
+	} {
+		got := Conventional(tc.msg, tc.types, tc.scope)
+		if tc.want == "" && got != "" || !strings.HasPrefix(got, tc.want) {
+			t.Errorf("Conventional(%q, %q, %v) = %q, want %q", tc.msg, tc.types, tc.scope, got, tc.want)
+		}
+	}
+}
+
+func TestBody(t *testing.T) {
+	long := strings.Repeat("word ", 15)
+	for _, tc := range []struct {
+		msg  string
+		max  int
+		want []string
+	}{
+		{"Add tests", 72, nil},
+		{"Add tests\n\nShort body.\n", 72, nil},
+		{"Add tests\n\n" + long + "\n", 72, []string{"line 3 of the commit message is 75 characters long, wrap the body at 72"}},
+		{"Add tests\n\n" + long + "\n", 0, nil},
+		{"Add tests\n" + long, 80, []string{"commit message body isn't separated from the subject by a blank line"}},
+		{"Add tests\n\n    " + long + "\n\t" + long + "\nhttps://example.com/" + strings.Repeat("x", 80), 72, nil},
+		{"Add tests\nbody\n\n" + long, 72, []string{
+			"commit message body isn't separated from the subject by a blank line",
+			"line 4 of the commit message is 75 characters long, wrap the body at 72",
+		}},
+	} {
+		if got := Body(tc.msg, tc.max); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Body(%q, %d) = %q, want %q", tc.msg, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestSubject(t *testing.T) {
+	for msg, want := range map[string]string{
+		"":                  "",
+		"Add tests":         "Add tests",
+		"Add tests\n\nbody": "Add tests",
+		"\nbody":            "",
+	} {
+		if got := Subject(msg); got != want {
+			t.Errorf("Subject(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package commitmsg
+
+// verbs are the imperative verbs commit subjects commonly start with, whose
+// other forms Imperative reports.
+var verbs = set(
+	"accept", "access", "add", "adjust", "alias", "align", "allow", "apply",
+	"assert", "avoid", "build", "bump", "cache", "call", "change", "check",
+	"clarify", "clean", "clear", "close", "collect", "comment", "compile",
+	"configure", "convert", "copy", "correct", "create", "decode",
+	"delete", "deprecate", "detect", "disable", "document", "drop", "embed",
+	"emit", "enable", "encode", "ensure", "escape", "exclude", "expand",
+	"export", "expose", "extend", "extract", "fail", "fetch", "filter", "fix",
+	"format", "generate", "handle", "hide", "ignore", "implement", "import",
+	"improve", "include", "increase", "initialize", "inline", "install",
+	"introduce", "limit", "list", "load", "log", "lower", "make", "mark",
+	"match", "merge", "migrate", "move", "normalize", "open", "optimize",
+	"parse", "pass", "pin", "polish", "prefer", "prepare", "prevent", "print",
+	"process", "read", "reduce", "refactor", "refresh", "reject", "release",
+	"reload", "remove", "rename", "reorder", "replace", "report", "require",
+	"reset", "resolve", "restore", "restrict", "retry", "return", "reuse",
+	"revert", "review", "rewrite", "rework", "run", "save", "set", "show",
+	"simplify", "skip", "sort", "split", "start", "stop", "store", "strip",
+	"support", "switch", "sync", "test", "tidy", "trim", "tweak", "unify",
+	"update", "upgrade", "use", "validate", "verify", "wrap", "write",
+)
+
+// irregular are the past forms of verbs that don't end in -ed.
+var irregular = set(
+	"built", "did", "done", "found", "kept", "made", "ran", "rebuilt",
+	"rewrote", "rewritten", "shown", "written", "wrote",
+)
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+
+	return m
+}
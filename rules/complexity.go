@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "cyclomatic-complexity",
+		Severity:    report.Warning,
+		Description: "Go functions changed by commits must not have a cyclomatic complexity above -max-complexity",
+		Commit:      checkComplexity,
+	})
+}
+
+func checkComplexity(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if o.MaxComplexity <= 0 {
+		return nil, nil
+	}
+
+	return checkChangedFuncs(c, o, func(fn *ast.FuncDecl, _, _ int) string {
+		if n := complexity(fn.Body); n > o.MaxComplexity {
+			return fmt.Sprintf("a cyclomatic complexity of %d, the limit is %d", n, o.MaxComplexity)
+		}
+
+		return ""
+	})
+}
+
+// complexity returns the cyclomatic complexity of a function body: 1 plus
+// the number of branches, counting the if, for and range statements, the
+// non-default cases of the switch and select statements, and the && and ||
+// operators. The function literals count towards the enclosing function.
+func complexity(body *ast.BlockStmt) int {
+	n := 1
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			n++
+		case *ast.CaseClause:
+			if node.List != nil {
+				n++
+			}
+		case *ast.CommClause:
+			if node.Comm != nil {
+				n++
+			}
+		case *ast.BinaryExpr:
+			if node.Op == token.LAND || node.Op == token.LOR {
+				n++
+			}
+		}
+
+		return true
+	})
+
+	return n
+}
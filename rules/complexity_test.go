@@ -0,0 +1,98 @@
+package rules
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"reflect"
+	"testing"
+)
+
+func TestComplexity(t *testing.T) {
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{"{}", 1},
+		{"{ if a { return } }", 2},
+		{"{ if a { return } else if b { return } else { return } }", 3},
+		{"{ for i := 0; i < n; i++ {}; for range s {} }", 3},
+		{"{ return a && b || c }", 3},
+		{"{ return a & b | c }", 1},
+		{"{ switch x { case 1, 2: case 3: default: } }", 3},
+		{"{ switch x.(type) { case int: case string: } }", 3},
+		{"{ select { case <-c: case c <- 1: default: } }", 3},
+		{"{ f := func() { if a {} }; f() }", 2},
+	} {
+		expr, err := parser.ParseExpr("func() " + tc.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := complexity(expr.(*ast.FuncLit).Body); got != tc.want {
+			t.Errorf("complexity(%s) = %d, want %d", tc.body, got, tc.want)
+		}
+	}
+}
+
+const complexityFuncs = `package p
+
+func under(a, b bool) int {
+	if a {
+		return 1
+	}
+
+	if b {
+		return 2
+	}
+
+	return 0
+}
+
+func over(a, b bool) int {
+	if a && b {
+		return 1
+	}
+
+	if b {
+		return 2
+	}
+
+	return 0
+}
+`
+
+func TestCheckComplexity(t *testing.T) {
+	tr := newTestRepo(t)
+	parent := tr.commit(map[string]string{"old.go": complexityFuncs}, "Add old.go")
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"added", map[string]string{"old.go": complexityFuncs, "p.go": complexityFuncs}, []string{"p.go:15: function over has a cyclomatic complexity of 4, the limit is 3"}},
+		{"unchanged", map[string]string{"old.go": complexityFuncs, "doc.go": "package p\n"}, nil},
+		{"not go", map[string]string{"old.go": complexityFuncs, "p.go.txt": complexityFuncs}, nil},
+		{"unparsable", map[string]string{"old.go": complexityFuncs, "p.go": complexityFuncs + "func broken( {\n"}, nil},
+	} {
+		c := tr.commit(tc.files, "Change the files", parent)
+		vs, err := checkComplexity(c, &Options{MaxComplexity: 3})
+		var got []string
+		for _, v := range vs {
+			got = append(got, fmt.Sprintf("%s:%d: %s", v.Path, v.Line, v.Message))
+		}
+
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: checkComplexity reported %q, %v, want %q", tc.name, got, err, tc.want)
+		}
+	}
+
+	// The limit applies to the changed functions only, and not at all
+	// without -max-complexity.
+	c := tr.commit(map[string]string{"old.go": complexityFuncs + "\nfunc added() {}\n"}, "Add a function", parent)
+	for _, limit := range []int{0, 1} {
+		if vs, err := checkComplexity(c, &Options{MaxComplexity: limit}); err != nil || len(vs) != 0 {
+			t.Errorf("-max-complexity %d: checkComplexity of an added simple function reported %+v, %v", limit, vs, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "conflict-marker",
+		Severity:    report.Error,
+		Description: "files must not contain merge conflict markers",
+		File:        checkConflictMarkers,
+	})
+}
+
+// conflictMarkers are the lines written by git around the sides of a
+// conflict, ||||||| being used by the diff3 style.
+var conflictMarkers = []string{"<<<<<<<", "|||||||", "=======", ">>>>>>>"}
+
+// conflictMarker returns the conflict marker starting the line, or an empty
+// string. Markers must start the line and be followed by a space or the end
+// of the line, so indented sequences and longer runs like the ========
+// underlining a heading are not markers. A line made of exactly seven equal
+// signs is always reported, even in a code block.
+func conflictMarker(line string) string {
+	line = strings.TrimSuffix(line, "\r")
+	for _, m := range conflictMarkers {
+		if !strings.HasPrefix(line, m) {
+			continue
+		}
+
+		if len(line) == len(m) || line[len(m)] == ' ' || line[len(m)] == '\t' {
+			return m
+		}
+	}
+
+	return ""
+}
+
+func checkConflictMarkers(f *git.File, o *Options) ([]report.Violation, error) {
+	if binary, err := IsBinary(f); err != nil || binary {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	_, err := ScanLinesLimit(f, o.MaxReadSize, func(n int, l string) bool {
+		if m := conflictMarker(l); m != "" {
+			vs = append(vs, report.Violation{
+				Path:    f.Name,
+				Line:    n,
+				Message: fmt.Sprintf("merge conflict marker %s", m),
+			})
+		}
+
+		return true
+	})
+
+	return vs, err
+}
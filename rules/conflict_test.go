@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckConflictMarkers(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		want    []report.Violation
+	}{
+		{"a\n<<<<<<< HEAD\nb\n=======\nc\n>>>>>>> topic\n", []report.Violation{
+			{Path: "f.txt", Line: 2, Message: "merge conflict marker <<<<<<<"},
+			{Path: "f.txt", Line: 4, Message: "merge conflict marker ======="},
+			{Path: "f.txt", Line: 6, Message: "merge conflict marker >>>>>>>"},
+		}},
+		{"<<<<<<<\r\n||||||| base\r\n", []report.Violation{
+			{Path: "f.txt", Line: 1, Message: "merge conflict marker <<<<<<<"},
+			{Path: "f.txt", Line: 2, Message: "merge conflict marker |||||||"},
+		}},
+		{">>>>>>>\tother\n", []report.Violation{{Path: "f.txt", Line: 1, Message: "merge conflict marker >>>>>>>"}}},
+		{"Title\n========\n", nil},
+		{"  <<<<<<< indented\n", nil},
+		{"<<<<<<<<\na <<<<<<< b\n", nil},
+		{"\x00<<<<<<< HEAD\n", nil},
+	} {
+		vs, err := checkConflictMarkers(testFile(t, "f.txt", tc.content), &Options{})
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkConflictMarkers(%q) = %+v, %v, want %+v", tc.content, vs, err, tc.want)
+		}
+	}
+}
+
+func TestExcludePath(t *testing.T) {
+	content := "<<<<<<< HEAD\n"
+	o := &Options{Exclude: map[string][]string{"conflict-marker": {"testdata/*"}}}
+	for _, tc := range []struct {
+		name string
+		want int
+	}{
+		{"testdata/merge.txt", 0},
+		{"testdata/sub/merge.txt", 1},
+		{"merge.txt", 1},
+	} {
+		vs, err := CheckFile(testFile(t, tc.name, content), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n := 0
+		for _, v := range vs {
+			if v.Rule == "conflict-marker" {
+				n++
+			}
+		}
+
+		if n != tc.want {
+			t.Errorf("%s excluding testdata/*: %d conflict-marker violations, want %d", tc.name, n, tc.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "script-crlf",
+		Severity:    report.Error,
+		Description: "executable files and shell scripts must not have CRLF line endings",
+		File:        checkScriptCRLF,
+	})
+}
+
+// isScript returns true for files that are run by an interpreter on Unix, so
+// a carriage return at the end of their lines breaks them.
+func isScript(f *git.File) bool {
+	return IsExecutable(f.Mode) || path.Ext(f.Name) == ".sh"
+}
+
+func checkScriptCRLF(f *git.File, o *Options) ([]report.Violation, error) {
+	if !isScript(f) {
+		return nil, nil
+	}
+
+	if binary, err := IsBinary(f); err != nil || binary {
+		return nil, err
+	}
+
+	first, count := 0, 0
+	lines, err := ScanLinesLimit(f, o.MaxReadSize, func(n int, l string) bool {
+		if strings.HasSuffix(l, "\r") {
+			if count == 0 {
+				first = n
+			}
+
+			count++
+		}
+
+		return true
+	})
+
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	return []report.Violation{{
+		Path:    f.Name,
+		Line:    first,
+		Message: fmt.Sprintf("script has CRLF line endings (%d of %d lines), it won't run on Unix", count, lines),
+	}}, nil
+}
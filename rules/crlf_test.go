@@ -0,0 +1,33 @@
+package rules
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckScriptCRLF(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		mode    os.FileMode
+		content string
+		want    []report.Violation
+	}{
+		{"run", 0755, "#!/bin/sh\r\necho a\r\n", []report.Violation{{Path: "run", Line: 1, Message: "script has CRLF line endings (2 of 2 lines), it won't run on Unix"}}},
+		{"build.sh", 0644, "#!/bin/sh\necho a\r\necho b\n", []report.Violation{{Path: "build.sh", Line: 2, Message: "script has CRLF line endings (1 of 3 lines), it won't run on Unix"}}},
+		{"run", 0755, "#!/bin/sh\necho a\n", nil},
+		{"build.sh", 0644, "#!/bin/sh\necho a\n", nil},
+		{"notes.txt", 0644, "a\r\nb\r\n", nil},
+		{"tool", 0755, "\x7fELF\x00\r\n", nil},
+		{"link", os.ModeSymlink | 0777, "target\r\n", nil},
+	} {
+		f := testFile(t, tc.name, tc.content)
+		f.Mode = tc.mode
+		vs, err := checkScriptCRLF(f, &Options{})
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkScriptCRLF(%s %v, %q) = %+v, %v, want %+v", tc.name, tc.mode, tc.content, vs, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "max-dir-entries",
+		Severity:    report.Warning,
+		Description: "directories must not have more direct entries than allowed",
+		Tree:        checkDirEntries,
+	})
+}
+
+// dirEntriesLimit returns the limit of the directory, the first matching
+// glob of DirEntryLimits taking precedence over MaxDirEntries. The root
+// directory is named ".".
+func (o *Options) dirEntriesLimit(dir string) int {
+	for _, l := range o.DirEntryLimits {
+		if ok, _ := path.Match(l.Glob, dir); ok {
+			return l.Max
+		}
+	}
+
+	return o.MaxDirEntries
+}
+
+func checkDirEntries(r *git.Repository, t *git.Tree, o *Options) ([]report.Violation, error) {
+	if o.MaxDirEntries == 0 && len(o.DirEntryLimits) == 0 {
+		return nil, nil
+	}
+
+	counts := make(map[string]int)
+	iter := git.NewTreeIter(r, t, true)
+	defer iter.Close()
+	for {
+		name, _, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		counts[path.Dir(name)]++
+	}
+
+	dirs := make([]string, 0, len(counts))
+	for dir := range counts {
+		dirs = append(dirs, dir)
+	}
+
+	sort.Strings(dirs)
+	var vs []report.Violation
+	for _, dir := range dirs {
+		max := o.dirEntriesLimit(dir)
+		if max == 0 || counts[dir] <= max {
+			continue
+		}
+
+		vs = append(vs, report.Violation{
+			Path:    dir,
+			Message: fmt.Sprintf("directory has %d entries, the limit is %d", counts[dir], max),
+		})
+	}
+
+	return vs, nil
+}
@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckDirEntries(t *testing.T) {
+	files := map[string]string{"a.txt": "", "b.txt": ""}
+	for i := 0; i < 5; i++ {
+		files[fmt.Sprintf("gen/f%d.go", i)] = ""
+		files[fmt.Sprintf("src/f%d.go", i)] = ""
+	}
+
+	for i := 0; i < 3; i++ {
+		files[fmt.Sprintf("src/pkg/f%d.go", i)] = ""
+	}
+
+	tr := newTestRepo(t)
+	tree, err := tr.commit(files, "Add the files").Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		max    int
+		limits []PathLimit
+		want   []report.Violation
+	}{
+		{0, nil, nil},
+		{5, nil, []report.Violation{{Path: "src", Message: "directory has 6 entries, the limit is 5"}}},
+		{4, nil, []report.Violation{
+			{Path: "gen", Message: "directory has 5 entries, the limit is 4"},
+			{Path: "src", Message: "directory has 6 entries, the limit is 4"},
+		}},
+		{4, []PathLimit{{Glob: "gen", Max: 0}, {Glob: "src", Max: 10}}, nil},
+		{0, []PathLimit{{Glob: "src/*", Max: 2}, {Glob: ".", Max: 3}}, []report.Violation{
+			{Path: ".", Message: "directory has 4 entries, the limit is 3"},
+			{Path: "src/pkg", Message: "directory has 3 entries, the limit is 2"},
+		}},
+		{6, nil, nil},
+	} {
+		o := &Options{MaxDirEntries: tc.max, DirEntryLimits: tc.limits}
+		vs, err := checkDirEntries(tr.Repository, tree, o)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkDirEntries(%d, %+v) = %+v, %v, want %+v", tc.max, tc.limits, vs, err, tc.want)
+		}
+	}
+}
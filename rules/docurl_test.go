@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func TestDocURL(t *testing.T) {
+	const id = "test-doc-url"
+	Register(&Func{
+		ID:       id,
+		Severity: report.Warning,
+		DocURL:   "https://example.com/rules/registered",
+		File: func(f *git.File, o *Options) ([]report.Violation, error) {
+			if f.Name != "doc-url.txt" {
+				return nil, nil
+			}
+
+			return []report.Violation{{Path: f.Name, Message: "m"}}, nil
+		},
+	})
+	defer delete(registry, id)
+
+	for _, tc := range []struct {
+		name string
+		urls map[string]string
+		want string
+	}{
+		{"registered", nil, "https://example.com/rules/registered"},
+		{"configured", map[string]string{id: "https://example.com/rules/configured"}, "https://example.com/rules/configured"},
+		{"other rule configured", map[string]string{"bom": "https://example.com/rules/bom"}, "https://example.com/rules/registered"},
+	} {
+		vs, err := CheckFile(testFile(t, "doc-url.txt", "a\n"), &Options{DocURLs: tc.urls})
+		want := []report.Violation{{Rule: id, Severity: report.Warning, Path: "doc-url.txt", Message: "m", DocURL: tc.want}}
+		if err != nil || !reflect.DeepEqual(vs, want) {
+			t.Errorf("%s: CheckFile = %+v, %v, want %+v", tc.name, vs, err, want)
+		}
+	}
+
+	// A rule without a URL, such as a plugin, only has the configured one.
+	o := &Options{DocURLs: map[string]string{"plugin-rule": "https://example.com/plugin"}}
+	if got := o.DocURL("plugin-rule"); got != "https://example.com/plugin" {
+		t.Errorf("DocURL(plugin-rule) = %q, want the configured URL", got)
+	}
+
+	if got := o.DocURL("bom"); got != "" {
+		t.Errorf("DocURL(bom) = %q, want none", got)
+	}
+}
@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "duplicate-tree-entry",
+		Severity:    report.Error,
+		Description: "tree objects must not have several entries with the same name, only one of them is seen",
+		Tree:        checkDuplicateEntries,
+	})
+}
+
+func checkDuplicateEntries(r *git.Repository, t *git.Tree, o *Options) ([]report.Violation, error) {
+	if !o.VerifyObjects {
+		return nil, nil
+	}
+
+	var vs []report.Violation
+	err := walkDuplicateEntries(r, t, "", &vs)
+	return vs, err
+}
+
+// walkDuplicateEntries appends a violation for each entry of the tree at dir
+// and of its subtrees named as a previous entry of the same tree. The
+// entries are read from the tree object as decoded, before the lookup map of
+// the tree keeps only one entry by name. Only the first of the duplicated
+// subtrees is walked.
+func walkDuplicateEntries(r *git.Repository, t *git.Tree, dir string, vs *[]report.Violation) error {
+	seen := make(map[string]bool, len(t.Entries))
+	for _, e := range t.Entries {
+		name := path.Join(dir, e.Name)
+		if seen[e.Name] {
+			*vs = append(*vs, report.Violation{
+				Path:    name,
+				Message: fmt.Sprintf("tree %s has several entries named %q", t.Hash.String()[:7], e.Name),
+			})
+
+			continue
+		}
+
+		seen[e.Name] = true
+		if e.Mode&os.ModeDir == 0 {
+			continue
+		}
+
+		sub, err := r.Tree(e.Hash)
+		if err != nil {
+			return err
+		}
+
+		if err := walkDuplicateEntries(r, sub, name, vs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/internal/testrepo"
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckDuplicateEntries(t *testing.T) {
+	tr := newTestRepo(t)
+	a, b := tr.Blob("a\n"), tr.Blob("b\n")
+	sub := tr.RawTree(
+		testrepo.Entry{Mode: "100644", Name: "x", Hash: a},
+		testrepo.Entry{Mode: "100644", Name: "x", Hash: b},
+		testrepo.Entry{Mode: "100644", Name: "y", Hash: a},
+	)
+	clean := tr.RawTree(testrepo.Entry{Mode: "100644", Name: "x", Hash: a})
+	root := tr.RawTree(
+		testrepo.Entry{Mode: "100644", Name: "a", Hash: a},
+		testrepo.Entry{Mode: "100644", Name: "a", Hash: b},
+		testrepo.Entry{Mode: "40000", Name: "clean", Hash: clean},
+		testrepo.Entry{Mode: "40000", Name: "d", Hash: sub},
+		testrepo.Entry{Mode: "100644", Name: "e", Hash: b},
+	)
+
+	tree, err := tr.Tree(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs, err := checkDuplicateEntries(tr.Repository, tree, &Options{VerifyObjects: true})
+	want := []report.Violation{
+		{Path: "a", Message: fmt.Sprintf("tree %s has several entries named %q", root.String()[:7], "a")},
+		{Path: "d/x", Message: fmt.Sprintf("tree %s has several entries named %q", sub.String()[:7], "x")},
+	}
+
+	if err != nil || !reflect.DeepEqual(vs, want) {
+		t.Errorf("checkDuplicateEntries = %+v, %v, want %+v", vs, err, want)
+	}
+
+	if vs, err := checkDuplicateEntries(tr.Repository, tree, &Options{}); err != nil || vs != nil {
+		t.Errorf("checkDuplicateEntries without -verify-objects = %+v, %v", vs, err)
+	}
+
+	tree, err = tr.commit(map[string]string{"a": "a\n", "d/x": "x\n"}, "m").Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vs, err := checkDuplicateEntries(tr.Repository, tree, &Options{VerifyObjects: true}); err != nil || vs != nil {
+		t.Errorf("checkDuplicateEntries of a valid tree = %+v, %v", vs, err)
+	}
+}
@@ -0,0 +1,39 @@
+package rules
+
+import (
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "empty-file",
+		Severity:    report.Warning,
+		Description: "empty files are pointed out unless they match -empty-file-allow, as placeholders keeping a directory",
+		File:        checkEmptyFile,
+	})
+}
+
+// DefaultEmptyFilesAllowed are the globs of the files allowed to be empty
+// when Options.EmptyFilesAllowed is empty.
+var DefaultEmptyFilesAllowed = []string{".gitkeep", ".keep"}
+
+func checkEmptyFile(f *git.File, o *Options) ([]report.Violation, error) {
+	if !o.FlagEmptyFiles || f.Size > 0 {
+		return nil, nil
+	}
+
+	allowed := o.EmptyFilesAllowed
+	if len(allowed) == 0 {
+		allowed = DefaultEmptyFilesAllowed
+	}
+
+	if matchAny(allowed, f.Name) {
+		return nil, nil
+	}
+
+	return []report.Violation{{
+		Path:    f.Name,
+		Message: "file is empty",
+	}}, nil
+}
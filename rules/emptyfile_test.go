@@ -0,0 +1,34 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckEmptyFile(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		allowed []string
+		want    []report.Violation
+	}{
+		{".gitkeep", "", nil, nil},
+		{"dir/.keep", "", nil, nil},
+		{"data.txt", "", nil, []report.Violation{{Path: "data.txt", Message: "file is empty"}}},
+		{"dir/data.txt", "", nil, []report.Violation{{Path: "dir/data.txt", Message: "file is empty"}}},
+		{"data.txt", "1\n", nil, nil},
+		{".gitkeep", "", []string{"__init__.py"}, []report.Violation{{Path: ".gitkeep", Message: "file is empty"}}},
+		{"pkg/__init__.py", "", []string{"__init__.py"}, nil},
+	} {
+		vs, err := checkEmptyFile(testFile(t, tc.name, tc.content), &Options{FlagEmptyFiles: true, EmptyFilesAllowed: tc.allowed})
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkEmptyFile(%s, %q) allowing %q = %+v, %v, want %+v", tc.name, tc.content, tc.allowed, vs, err, tc.want)
+		}
+	}
+
+	if vs, err := checkEmptyFile(testFile(t, "data.txt", ""), &Options{}); err != nil || vs != nil {
+		t.Errorf("checkEmptyFile without -flag-empty-files = %+v, %v", vs, err)
+	}
+}
@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "exec-bit",
+		Severity:    report.Error,
+		Description: "source files must not be executable, scripts with a shebang must be",
+		File:        checkExecBit,
+	})
+}
+
+// DefaultNonExecutable are the globs of the files that must not be
+// executable when Options.NonExecutable is empty.
+var DefaultNonExecutable = []string{"*.go", "*.py", "*.txt", "*.md"}
+
+// nonExecutable returns true if the file must not be executable.
+func (o *Options) nonExecutable(name string) bool {
+	globs := o.NonExecutable
+	if len(globs) == 0 {
+		globs = DefaultNonExecutable
+	}
+
+	return matchAny(globs, name)
+}
+
+func checkExecBit(f *git.File, o *Options) ([]report.Violation, error) {
+	if !o.CheckExecBit || !f.Mode.IsRegular() {
+		return nil, nil
+	}
+
+	executable := IsExecutable(f.Mode)
+	if executable && o.nonExecutable(f.Name) {
+		return []report.Violation{{
+			Path:    f.Name,
+			Message: fmt.Sprintf("file is executable (mode %o) but its path must not be", f.Mode),
+		}}, nil
+	}
+
+	if executable || !o.ShebangExec {
+		return nil, nil
+	}
+
+	shebang, err := hasShebang(f)
+	if err != nil || !shebang {
+		return nil, err
+	}
+
+	return []report.Violation{{
+		Path:    f.Name,
+		Line:    1,
+		Message: fmt.Sprintf("file starts with a shebang but isn't executable (mode %o)", f.Mode),
+	}}, nil
+}
+
+// hasShebang returns true if the file starts with #!.
+func hasShebang(f *git.File) (bool, error) {
+	head, err := Head(f, 2)
+	return bytes.Equal(head, []byte("#!")), err
+}
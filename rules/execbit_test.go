@@ -0,0 +1,45 @@
+package rules
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckExecBit(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		mode          os.FileMode
+		content       string
+		nonExecutable []string
+		shebang       bool
+		want          []report.Violation
+	}{
+		{"main.go", 0755, "package main\n", nil, false, []report.Violation{{Path: "main.go", Message: "file is executable (mode 755) but its path must not be"}}},
+		{"main.go", 0644, "package main\n", nil, true, nil},
+		{"run.sh", 0755, "#!/bin/sh\n", nil, true, nil},
+		{"tool", 0755, "\x7fELF", nil, false, nil},
+		{"run.sh", 0755, "#!/bin/sh\n", []string{"*.sh"}, false, []report.Violation{{Path: "run.sh", Message: "file is executable (mode 755) but its path must not be"}}},
+		{"main.go", 0755, "package main\n", []string{"*.sh"}, false, nil},
+		{"run.sh", 0644, "#!/bin/sh\n", nil, true, []report.Violation{{Path: "run.sh", Line: 1, Message: "file starts with a shebang but isn't executable (mode 644)"}}},
+		{"run.sh", 0644, "#!/bin/sh\n", nil, false, nil},
+		{"notes", 0644, "# !not a shebang\n", nil, true, nil},
+		{"link", os.ModeSymlink | 0777, "#!target", nil, true, nil},
+	} {
+		f := testFile(t, tc.name, tc.content)
+		f.Mode = tc.mode
+		o := &Options{CheckExecBit: true, NonExecutable: tc.nonExecutable, ShebangExec: tc.shebang}
+		vs, err := checkExecBit(f, o)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkExecBit(%s %v, %q, %q, %v) = %+v, %v, want %+v", tc.name, tc.mode, tc.content, tc.nonExecutable, tc.shebang, vs, err, tc.want)
+		}
+	}
+
+	f := testFile(t, "main.go", "")
+	f.Mode = 0755
+	if vs, err := checkExecBit(f, &Options{}); err != nil || vs != nil {
+		t.Errorf("checkExecBit without -check-exec-bit = %+v, %v", vs, err)
+	}
+}
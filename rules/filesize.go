@@ -0,0 +1,28 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "max-file-size",
+		Severity:    report.Error,
+		Description: "files must not be bigger than -max-file-size bytes",
+		File:        checkFileSize,
+	})
+}
+
+func checkFileSize(f *git.File, o *Options) ([]report.Violation, error) {
+	if o.MaxFileSize <= 0 || f.Size <= o.MaxFileSize {
+		return nil, nil
+	}
+
+	return []report.Violation{{
+		Path:    f.Name,
+		Message: fmt.Sprintf("file is %d bytes, the limit is %d", f.Size, o.MaxFileSize),
+	}}, nil
+}
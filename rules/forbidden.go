@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "forbidden-file",
+		Severity:    report.Error,
+		Description: "commits must not add files matching the -forbidden-file globs, such as private keys, .env files or build artifacts, with -forbid-files",
+		Commit:      checkForbiddenFiles,
+	})
+}
+
+// DefaultForbiddenFiles are the globs of the files commits can't add when
+// Options.ForbiddenFiles is empty: environment files and private keys holding
+// secrets, and build artifacts.
+var DefaultForbiddenFiles = []string{
+	".env", "*.pem", "*.key", "*.p12", "*.pfx", "id_rsa", "id_dsa", "id_ecdsa", "id_ed25519",
+	"*.o", "*.a", "*.so", "*.dylib", "*.dll", "*.exe", "*.class", "*.pyc",
+}
+
+func checkForbiddenFiles(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.ForbidFiles {
+		return nil, nil
+	}
+
+	globs := o.ForbiddenFiles
+	if len(globs) == 0 {
+		globs = DefaultForbiddenFiles
+	}
+
+	changes, err := ChangesAgainst(c, o.DiffAgainst)
+	if err != nil {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	for _, ch := range changes {
+		// Renamed files are modified from their old path, not added.
+		if ch.Action != git.Insert {
+			continue
+		}
+
+		name := changeName(ch)
+		for _, glob := range globs {
+			if MatchPath(glob, name) {
+				vs = append(vs, report.Violation{
+					Path:    name,
+					Message: fmt.Sprintf("file matching forbidden glob %q added by commit %s", glob, c.Hash.String()[:7]),
+				})
+				break
+			}
+		}
+	}
+
+	return vs, nil
+}
@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "gofmt",
+		Severity:    report.Error,
+		Description: "Go files changed by commits must be formatted with gofmt, with -check-gofmt",
+		Commit:      checkGofmt,
+	})
+}
+
+func checkGofmt(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.CheckGofmt {
+		return nil, nil
+	}
+
+	return checkChangedGo(c, o, func(name, src string, fset *token.FileSet, file *ast.File, _ *diff.Patch) ([]report.Violation, error) {
+		var buf bytes.Buffer
+		if err := format.Node(&buf, fset, file); err != nil {
+			return nil, fmt.Errorf("formatting: %s", err)
+		}
+
+		formatted := buf.Bytes()
+
+		p := diff.UnifiedText(name, src, string(formatted))
+		if len(p.Hunks) == 0 {
+			return nil, nil
+		}
+
+		v := report.Violation{
+			Path:    name,
+			Line:    firstChange(p.Hunks[0]),
+			Message: fmt.Sprintf("file isn't formatted with gofmt, run gofmt -w %s", name),
+			Diff:    p.String(),
+		}
+
+		if o.GofmtFix {
+			v.Fix = string(formatted)
+		}
+
+		return []report.Violation{v}, nil
+	})
+}
+
+// firstChange returns the number of the first line of the old file changed
+// by the hunk, that before which lines are inserted for an insertion.
+func firstChange(h diff.Hunk) int {
+	n := h.OldStart
+	for _, l := range h.Lines {
+		switch l.Kind {
+		case diff.Delete:
+			return l.OldLine
+		case diff.Insert:
+			return n
+		}
+
+		n = l.OldLine + 1
+	}
+
+	return n
+}
@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckGofmt(t *testing.T) {
+	const formatted = "package p\n\nfunc f() int {\n\treturn 1\n}\n"
+	const unformatted = "package p\n\nfunc f() int {\n\treturn 1\n}\n\nfunc g()  {\n  return\n}\n"
+	tr := newTestRepo(t)
+	parent := tr.commit(map[string]string{"old.go": unformatted}, "Add old.go")
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		// line is the line of the violation expected on p.go, 0 for none.
+		line int
+	}{
+		{"formatted", map[string]string{"old.go": unformatted, "p.go": formatted}, 0},
+		{"unformatted", map[string]string{"old.go": unformatted, "p.go": unformatted}, 7},
+		{"unformatted import", map[string]string{"old.go": unformatted, "p.go": "package p\nimport \"fmt\"\nvar _ = fmt.Sprint\n"}, 2},
+		{"invalid syntax", map[string]string{"old.go": unformatted, "p.go": "package p\n\nfunc f( {\n"}, 0},
+		{"not go", map[string]string{"old.go": unformatted, "p.txt": unformatted}, 0},
+		{"unchanged", map[string]string{"old.go": unformatted}, 0},
+		{"renamed", map[string]string{"p.go": unformatted}, 0},
+	} {
+		c := tr.commit(tc.files, "Change the files", parent)
+		vs, err := checkGofmt(c, &Options{CheckGofmt: true, GofmtFix: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tc.line == 0 {
+			if len(vs) != 0 {
+				t.Errorf("%s: checkGofmt reported %+v, want nothing", tc.name, vs)
+			}
+
+			continue
+		}
+
+		if len(vs) != 1 {
+			t.Errorf("%s: checkGofmt reported %+v, want a violation on p.go", tc.name, vs)
+			continue
+		}
+
+		v := vs[0]
+		if v.Path != "p.go" || v.Line != tc.line || v.Message != "file isn't formatted with gofmt, run gofmt -w p.go" {
+			t.Errorf("%s: checkGofmt reported %s:%d: %s, want p.go:%d", tc.name, v.Path, v.Line, v.Message, tc.line)
+		}
+
+		if !strings.HasPrefix(v.Diff, "--- a/p.go\n+++ b/p.go\n") || v.Fix == "" || v.Fix == tc.files["p.go"] {
+			t.Errorf("%s: checkGofmt diff %q, fix %q, want the diff and the formatted file", tc.name, v.Diff, v.Fix)
+		}
+	}
+
+	c := tr.commit(map[string]string{"p.go": unformatted}, "Add p.go")
+	if vs, err := checkGofmt(c, &Options{}); err != nil || len(vs) != 0 {
+		t.Errorf("checkGofmt without -check-gofmt reported %+v, %v", vs, err)
+	}
+
+	vs, err := checkGofmt(c, &Options{CheckGofmt: true})
+	if err != nil || len(vs) != 1 || vs[0].Fix != "" {
+		t.Errorf("checkGofmt without -gofmt-fix reported %+v, %v, want a violation without fix", vs, err)
+	}
+}
@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// checkChangedGo calls check with the contents, the syntax tree and the
+// patch of each Go file changed by the commit, as checkChangedText does.
+// Go files that don't parse are left to the compiler.
+func checkChangedGo(c *git.Commit, o *Options, check func(name, src string, fset *token.FileSet, file *ast.File, p *diff.Patch) ([]report.Violation, error)) ([]report.Violation, error) {
+	return checkChangedText(c, o, func(name, src string, p *diff.Patch) ([]report.Violation, error) {
+		if path.Ext(name) != ".go" {
+			return nil, nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			return nil, nil
+		}
+
+		return check(name, src, fset, file, p)
+	})
+}
+
+// checkChangedFuncs calls check on each function of the Go files changed by
+// the commit with a line added, start and end being its first and last
+// lines. A problem returned by check is reported on the function as
+// "function NAME has PROBLEM".
+func checkChangedFuncs(c *git.Commit, o *Options, check func(fn *ast.FuncDecl, start, end int) string) ([]report.Violation, error) {
+	return checkChangedGo(c, o, func(name, _ string, fset *token.FileSet, file *ast.File, p *diff.Patch) ([]report.Violation, error) {
+		var vs []report.Violation
+		for _, d := range file.Decls {
+			fn, ok := d.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+
+			start, end := fset.Position(fn.Pos()).Line, fset.Position(fn.End()).Line
+			if !insertedBetween(p, start, end) {
+				continue
+			}
+
+			if problem := check(fn, start, end); problem != "" {
+				vs = append(vs, report.Violation{
+					Path:    name,
+					Line:    start,
+					Message: "function " + funcName(fn) + " has " + problem,
+				})
+			}
+		}
+
+		return vs, nil
+	})
+}
+
+// insertedBetween returns true if the patch inserts a line between the lines
+// start and end of the new file, included.
+func insertedBetween(p *diff.Patch, start, end int) bool {
+	for _, h := range p.Hunks {
+		for _, l := range h.Lines {
+			if l.Kind == diff.Insert && l.NewLine >= start && l.NewLine <= end {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// funcName returns the name of the function, prefixed by the type of its
+// receiver for methods, as in Options.Check.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	t := fn.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name + "." + fn.Name.Name
+	}
+
+	return fn.Name.Name
+}
@@ -0,0 +1,125 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/vet"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "go-vet",
+		Severity:    report.Error,
+		Description: "lines added by commits to Go packages must not be reported by go vet, with -go-vet",
+		Commit:      checkGoVet,
+	})
+}
+
+func checkGoVet(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.GoVet {
+		return nil, nil
+	}
+
+	changes, err := ChangesAgainst(c, o.DiffAgainst)
+	if err != nil {
+		return nil, err
+	}
+
+	// The packages with a file only partly known don't type check.
+	partial := make(map[string]bool)
+	for _, name := range o.PartialFiles {
+		if path.Ext(name) == ".go" {
+			partial[path.Dir(name)] = true
+		}
+	}
+
+	// added maps the changed Go files to their added lines.
+	added := make(map[string]map[int]bool)
+	dirs := make(map[string]bool)
+	for _, ch := range changes {
+		name := changeName(ch)
+		if ch.Action == git.Delete || path.Ext(name) != ".go" || !goPackageDir(path.Dir(name)) || partial[path.Dir(name)] {
+			continue
+		}
+
+		from, to, err := ch.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := diff.Unified(from, to)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		lines := make(map[int]bool)
+		for _, h := range p.Hunks {
+			for _, l := range h.Lines {
+				if l.Kind == diff.Insert {
+					lines[l.NewLine] = true
+				}
+			}
+		}
+
+		if len(lines) > 0 {
+			added[name] = lines
+			dirs[path.Dir(name)] = true
+		}
+	}
+
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+
+	pkgs := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		pkgs = append(pkgs, dir)
+	}
+
+	sort.Strings(pkgs)
+	runner := &vet.Runner{Analyzers: o.GoVetAnalyzers}
+	ds, err := runner.Commit(c, pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	for _, d := range ds {
+		// The errors of a whole package are reported on its directory.
+		if !added[d.Path][d.Line] && (d.Line > 0 || !dirs[d.Path]) {
+			continue
+		}
+
+		vs = append(vs, report.Violation{
+			Path:    d.Path,
+			Line:    d.Line,
+			Column:  d.Column,
+			Message: fmt.Sprintf("%s: %s", d.Analyzer, d.Message),
+		})
+	}
+
+	return vs, nil
+}
+
+// goPackageDir returns true if the go command builds the packages of the
+// directory: those under vendor, testdata or a directory starting with . or
+// _ are left out.
+func goPackageDir(dir string) bool {
+	if dir == "." {
+		return true
+	}
+
+	for _, seg := range strings.Split(dir, "/") {
+		if seg == "vendor" || seg == "testdata" || strings.HasPrefix(seg, ".") || strings.HasPrefix(seg, "_") {
+			return false
+		}
+	}
+
+	return true
+}
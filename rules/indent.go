@@ -0,0 +1,98 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "indent-style",
+		Severity:    report.Error,
+		Description: "files must be indented with the style configured for their path",
+		File:        checkIndentStyle,
+	})
+}
+
+// Indentation styles of IndentStyle.
+const (
+	Spaces = "spaces"
+	Tabs   = "tabs"
+)
+
+// IndentStyle is the indentation, Spaces or Tabs, expected in the files
+// matching a glob.
+type IndentStyle struct {
+	Glob  string
+	Style string
+}
+
+// indentStyle returns the style expected in the file, an empty string if no
+// glob matches.
+func (o *Options) indentStyle(name string) string {
+	for _, s := range o.Indent {
+		if MatchPath(s.Glob, name) {
+			return s.Style
+		}
+	}
+
+	return ""
+}
+
+func checkIndentStyle(f *git.File, o *Options) ([]report.Violation, error) {
+	style := o.indentStyle(f.Name)
+	if style == "" {
+		return nil, nil
+	}
+
+	found := Tabs
+	if style == Tabs {
+		found = Spaces
+	}
+
+	if binary, err := IsBinary(f); err != nil || binary {
+		return nil, err
+	}
+
+	first, count := 0, 0
+	lines, err := ScanLinesLimit(f, o.MaxReadSize, func(n int, l string) bool {
+		if wrongIndent(l, style) {
+			if count == 0 {
+				first = n
+			}
+
+			count++
+		}
+
+		return true
+	})
+
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	return []report.Violation{{
+		Path:    f.Name,
+		Line:    first,
+		Message: fmt.Sprintf("indented with %s instead of %s (%d of %d lines)", found, style, count, lines),
+	}}, nil
+}
+
+// wrongIndent returns true if the line isn't indented with the style. Tabs
+// indented lines may be aligned with spaces after their tabs, as done in
+// Makefile recipes. Blank lines are ignored.
+func wrongIndent(line, style string) bool {
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	if len(indent) == 0 || len(indent) == len(line) {
+		return false
+	}
+
+	if style == Tabs {
+		return indent[0] == ' '
+	}
+
+	return strings.IndexByte(indent, '\t') >= 0
+}
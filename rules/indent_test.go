@@ -0,0 +1,35 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckIndentStyle(t *testing.T) {
+	o := &Options{Indent: []IndentStyle{
+		{Glob: "*.go", Style: Tabs},
+		{Glob: "Makefile", Style: Tabs},
+		{Glob: "*.py", Style: Spaces},
+	}}
+
+	for _, tc := range []struct {
+		name, content string
+		want          []report.Violation
+	}{
+		{"a.go", "func f() {\n\treturn\n}\n", nil},
+		{"a.go", "func f() {\n    a()\n\tb()\n    c()\n}\n", []report.Violation{{Path: "a.go", Line: 2, Message: "indented with spaces instead of tabs (2 of 5 lines)"}}},
+		{"Makefile", "all:\n\tgo build \\\n\t    ./...\n", nil},
+		{"a.py", "def f():\n    return\n", nil},
+		{"a.py", "def f():\n  \treturn\n", []report.Violation{{Path: "a.py", Line: 2, Message: "indented with tabs instead of spaces (1 of 2 lines)"}}},
+		{"a.py", "def f():\n    \n\t\n", nil},
+		{"a.txt", "\tany\n  thing\n", nil},
+		{"a.go", "\x00    binary\n", nil},
+	} {
+		vs, err := checkIndentStyle(testFile(t, tc.name, tc.content), o)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkIndentStyle(%s, %q) = %+v, %v, want %+v", tc.name, tc.content, vs, err, tc.want)
+		}
+	}
+}
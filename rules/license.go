@@ -0,0 +1,174 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "license-header",
+		Severity:    report.Error,
+		Description: "source files must start with the -require-license-header header",
+		File:        checkLicenseHeader,
+	})
+
+	Register(&Func{
+		ID:          "new-file-license",
+		Severity:    report.Error,
+		Description: "source files added by commits must start with the -license-template header, {year} being the year of the commit",
+		Commit:      checkNewFileLicense,
+	})
+}
+
+// LicenseYear is replaced in Options.LicenseTemplate by the year of the
+// commit adding the file.
+const LicenseYear = "{year}"
+
+// DefaultLicenseHeaderLines is the number of lines searched for the license
+// header when Options.LicenseHeaderLines isn't set.
+const DefaultLicenseHeaderLines = 10
+
+// generatedHeader marks the generated Go files, which don't need a license
+// header.
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// licenseHeader returns the expression matching the license header.
+func (o *Options) licenseHeader() (*regexp.Regexp, error) {
+	if o.LicenseHeaderRegexp {
+		return regexp.Compile(o.LicenseHeader)
+	}
+
+	return regexp.Compile(regexp.QuoteMeta(o.LicenseHeader))
+}
+
+func checkLicenseHeader(f *git.File, o *Options) ([]report.Violation, error) {
+	if o.LicenseHeader == "" {
+		return nil, nil
+	}
+
+	if len(o.LicenseHeaderPaths) > 0 && !matchAny(o.LicenseHeaderPaths, f.Name) {
+		return nil, nil
+	}
+
+	if binary, err := IsBinary(f); err != nil || binary {
+		return nil, err
+	}
+
+	re, err := o.licenseHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	max := o.LicenseHeaderLines
+	if max <= 0 {
+		max = DefaultLicenseHeaderLines
+	}
+
+	var head []string
+	generated := false
+	_, err = ScanLinesLimit(f, o.MaxReadSize, func(n int, l string) bool {
+		l = strings.TrimSuffix(l, "\r")
+		generated = generated || generatedHeader.MatchString(l)
+		head = append(head, l)
+		return n < max
+	})
+
+	if err != nil || generated || re.MatchString(strings.Join(head, "\n")) {
+		return nil, err
+	}
+
+	return []report.Violation{{
+		Path:    f.Name,
+		Line:    1,
+		Message: fmt.Sprintf("license header not found in the first %d lines", max),
+	}}, nil
+}
+
+func checkNewFileLicense(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if o.LicenseTemplate == "" {
+		return nil, nil
+	}
+
+	template := strings.TrimRight(o.LicenseTemplate, "\n")
+	re, err := licenseTemplate(template, c)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := ChangesAgainst(c, o.DiffAgainst)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := strings.Replace(template, LicenseYear, fmt.Sprint(c.Author.When.Year()), -1)
+	max := strings.Count(template, "\n") + 1
+	var vs []report.Violation
+	for _, ch := range changes {
+		name := changeName(ch)
+		if ch.Action != git.Insert || (len(o.LicenseHeaderPaths) > 0 && !matchAny(o.LicenseHeaderPaths, name)) {
+			continue
+		}
+
+		_, f, err := ch.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		binary, err := IsBinary(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		if binary {
+			continue
+		}
+
+		// A shebang line can come before the header.
+		var head []string
+		generated := false
+		_, err = ScanLinesLimit(f, o.MaxReadSize, func(n int, l string) bool {
+			l = strings.TrimSuffix(l, "\r")
+			if n == 1 && strings.HasPrefix(l, "#!") {
+				return true
+			}
+
+			generated = generated || generatedHeader.MatchString(l)
+			head = append(head, l)
+			return len(head) < max
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		if generated || re.MatchString(strings.Join(head, "\n")) {
+			continue
+		}
+
+		vs = append(vs, report.Violation{
+			Path:    name,
+			Line:    1,
+			Message: fmt.Sprintf("added file doesn't start with the license header %q", expected),
+		})
+	}
+
+	return vs, nil
+}
+
+// licenseTemplate returns the expression matching the start of a file with
+// the license template, its LicenseYear being the year the commit was
+// authored or committed.
+func licenseTemplate(template string, c *git.Commit) (*regexp.Regexp, error) {
+	years := fmt.Sprintf("(?:%d|%d)", c.Author.When.Year(), c.Committer.When.Year())
+	parts := strings.Split(template, LicenseYear)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+
+	return regexp.Compile(`\A` + strings.Join(parts, years))
+}
@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckLicenseHeader(t *testing.T) {
+	const header = "// Copyright 2017 The Authors"
+	missing := []report.Violation{{Path: "a.go", Line: 1, Message: "license header not found in the first 10 lines"}}
+	for _, tc := range []struct {
+		name    string
+		content string
+		o       Options
+		want    []report.Violation
+	}{
+		{"header", header + "\n\npackage a\n", Options{}, nil},
+		{"header after a build tag", "// +build linux\n\n" + header + "\npackage a\n", Options{}, nil},
+		{"missing", "package a\n", Options{}, missing},
+		{"empty", "", Options{}, missing},
+		{"header past the lines searched", "\n\n\n" + header + "\n", Options{LicenseHeaderLines: 3},
+			[]report.Violation{{Path: "a.go", Line: 1, Message: "license header not found in the first 3 lines"}}},
+		{"generated", "// Code generated by stringer; DO NOT EDIT.\n\npackage a\n", Options{}, nil},
+		{"generated mark past the lines searched", "package a\n\n// Code generated by stringer; DO NOT EDIT.\n", Options{LicenseHeaderLines: 2},
+			[]report.Violation{{Path: "a.go", Line: 1, Message: "license header not found in the first 2 lines"}}},
+		{"not matching the globs", "package a\n", Options{LicenseHeaderPaths: []string{"*.py"}}, nil},
+		{"matching the globs", "package a\n", Options{LicenseHeaderPaths: []string{"*.py", "*.go"}}, missing},
+		{"regexp", "// Copyright 2016 The Authors\npackage a\n", Options{LicenseHeader: `(?m)^// Copyright 20[0-9]{2} The Authors$`, LicenseHeaderRegexp: true}, nil},
+		{"regexp missing", "// Copyright The Authors\npackage a\n", Options{LicenseHeader: `(?m)^// Copyright 20[0-9]{2} The Authors$`, LicenseHeaderRegexp: true}, missing},
+		{"regexp characters taken literally", "// Copyright 2017 The Authors\n", Options{LicenseHeader: "// Copyright 20.. The Authors"},
+			missing},
+	} {
+		o := tc.o
+		if o.LicenseHeader == "" {
+			o.LicenseHeader = header
+		}
+
+		vs, err := checkLicenseHeader(testFile(t, "a.go", tc.content), &o)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("%s: checkLicenseHeader = %+v, %v, want %+v", tc.name, vs, err, tc.want)
+		}
+	}
+
+	if vs, err := checkLicenseHeader(testFile(t, "a.go", "package a\n"), &Options{}); err != nil || vs != nil {
+		t.Errorf("checkLicenseHeader without -require-license-header = %+v, %v", vs, err)
+	}
+}
+
+func TestCheckNewFileLicense(t *testing.T) {
+	tr := newTestRepo(t)
+	parent := tr.commit(map[string]string{"old.go": "package a\n"}, "Add old.go")
+	c := tr.commit(map[string]string{
+		"old.go":          "package a\n\nfunc f() {}\n",
+		"licensed.go":     "// Copyright 2017 The Authors\n\npackage a\n",
+		"script.sh":       "#!/bin/sh\n// Copyright 2017 The Authors\n",
+		"missing.go":      "package a\n",
+		"last_year.go":    "// Copyright 2016 The Authors\n",
+		"zz_generated.go": "// Code generated by stringer; DO NOT EDIT.\n\npackage a\n",
+		"docs/readme.txt": "readme\n",
+	}, "Add files", parent)
+
+	vs, err := checkNewFileLicense(c, &Options{LicenseTemplate: "// Copyright {year} The Authors\n", LicenseHeaderPaths: []string{"*.go", "*.sh"}})
+	var paths []string
+	for _, v := range vs {
+		paths = append(paths, v.Path)
+	}
+
+	// old.go is modified, not added, and the text file isn't matched.
+	if want := []string{"last_year.go", "missing.go"}; err != nil || !reflect.DeepEqual(paths, want) {
+		t.Errorf("checkNewFileLicense reported %q, %v, want %q", paths, err, want)
+	}
+}
@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"bufio"
+	"bytes"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// MaxLineLength is the length from which lines are truncated by ScanLines.
+const MaxLineLength = 1 << 20
+
+// ScanLines calls fn for every line of the file, numbered from 1, until it
+// returns false. The file is streamed from its blob so that its memory use is
+// bounded by the longest line, which is truncated to MaxLineLength bytes.
+// Lines are split as by File.Lines: only the \n is stripped and a last empty
+// line is ignored. It returns the number of lines scanned.
+func ScanLines(f *git.File, fn func(n int, line string) bool) (int, error) {
+	return ScanLinesLimit(f, 0, fn)
+}
+
+// ScanLinesLimit is like ScanLines but stops after the first max bytes of
+// the file, see OpenFile, the last line scanned being cut off with them.
+func ScanLinesLimit(f *git.File, max int64, fn func(n int, line string) bool) (int, error) {
+	r, err := OpenFile(f, max)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 4096), MaxLineLength)
+	s.Split(splitLines())
+
+	n := 0
+	for s.Scan() {
+		n++
+		if !fn(n, s.Text()) {
+			break
+		}
+	}
+
+	return n, s.Err()
+}
+
+// splitLines returns a bufio.SplitFunc splitting lines on \n only, keeping
+// the \r of CRLF line endings, and truncating the lines filling the buffer
+// of the scanner instead of failing.
+func splitLines() bufio.SplitFunc {
+	skipping := false
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		i := bytes.IndexByte(data, '\n')
+		switch {
+		case skipping && i >= 0:
+			skipping = false
+			return i + 1, nil, nil
+		case skipping:
+			return len(data), nil, nil
+		case i >= 0:
+			return i + 1, data[:i], nil
+		case atEOF && len(data) > 0:
+			return len(data), data, nil
+		case len(data) >= MaxLineLength:
+			skipping = true
+			return len(data), data, nil
+		default:
+			return 0, nil, nil
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanLines(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		max     int64
+		want    []string
+	}{
+		{"", 0, nil},
+		{"\n", 0, []string{""}},
+		{"a\nb\n", 0, []string{"a", "b"}},
+		{"a\nb", 0, []string{"a", "b"}},
+		{"a\n\n", 0, []string{"a", ""}},
+		{"a\r\nb\r\n", 0, []string{"a\r", "b\r"}},
+		{"a\rb\n", 0, []string{"a\rb"}},
+		{"one\ntwo\nthree\n", 6, []string{"one", "tw"}},
+		{"one\ntwo\nthree\n", 8, []string{"one", "two"}},
+		{"one\ntwo\n", 100, []string{"one", "two"}},
+	} {
+		var got []string
+		n, err := ScanLinesLimit(testFile(t, "f.txt", tc.content), tc.max, func(n int, l string) bool {
+			if n != len(got)+1 {
+				t.Errorf("%q: line %d numbered %d", tc.content, len(got)+1, n)
+			}
+
+			got = append(got, l)
+			return true
+		})
+
+		if err != nil || n != len(tc.want) || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ScanLinesLimit(%q, %d) = %q, %d, %v, want %q", tc.content, tc.max, got, n, err, tc.want)
+		}
+	}
+}
+
+func TestScanLinesStop(t *testing.T) {
+	var got []string
+	n, err := ScanLines(testFile(t, "f.txt", "a\nb\nc\n"), func(n int, l string) bool {
+		got = append(got, l)
+		return n < 2
+	})
+
+	if err != nil || n != 2 || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("ScanLines stopped at line 2 = %q, %d, %v", got, n, err)
+	}
+}
+
+func TestScanLinesLong(t *testing.T) {
+	long := strings.Repeat("x", 3*MaxLineLength)
+	var lens []int
+	n, err := ScanLines(testFile(t, "f.txt", "a\n"+long+"\nb\n"), func(n int, l string) bool {
+		lens = append(lens, len(l))
+		return true
+	})
+
+	if want := []int{1, MaxLineLength, 1}; err != nil || n != 3 || !reflect.DeepEqual(lens, want) {
+		t.Errorf("ScanLines of a long line = lengths %v, %d lines, %v, want %v", lens, n, err, want)
+	}
+}
@@ -0,0 +1,134 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/rules/commitmsg"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "empty-message",
+		Severity:    report.Error,
+		Description: "commit messages must have a subject",
+		Commit:      checkEmptyMessage,
+	})
+
+	Register(&Func{
+		ID:          "subject-length",
+		Severity:    report.Warning,
+		Description: "commit subjects must not be longer than -max-subject-length characters",
+		Commit:      checkSubjectLength,
+	})
+
+	Register(&Func{
+		ID:          "ticket-reference",
+		Severity:    report.Error,
+		Description: "commit messages must reference a ticket matching -ticket-pattern",
+		Commit:      checkTicketReference,
+	})
+
+	Register(&Func{
+		ID:          "imperative-subject",
+		Severity:    report.Warning,
+		Description: "commit subjects must start with a verb in the imperative mood, as in \"Fix\" rather than \"Fixed\" or \"Fixes\", with -imperative-subject",
+		Commit:      checkImperativeSubject,
+	})
+
+	Register(&Func{
+		ID:          "conventional-commit",
+		Severity:    report.Error,
+		Description: "commit subjects must follow Conventional Commits, \"type(scope): description\" with a type of -conventional-types, with -conventional-commits",
+		Commit:      checkConventionalCommit,
+	})
+
+	Register(&Func{
+		ID:          "body-line-length",
+		Severity:    report.Warning,
+		Description: "commit message bodies must be separated from the subject by a blank line and wrapped at -max-body-line-length characters",
+		Commit:      checkBodyLineLength,
+	})
+}
+
+// DefaultTicketPattern matches the ticket references like JIRA-123 or #456,
+// used when Options.TicketPattern is empty.
+const DefaultTicketPattern = `\b[A-Z]+-\d+\b|#\d+\b`
+
+// ticketPattern returns the expression matching the ticket references.
+func (o *Options) ticketPattern() (*regexp.Regexp, error) {
+	if o.TicketPattern == "" {
+		return regexp.Compile(DefaultTicketPattern)
+	}
+
+	return regexp.Compile(o.TicketPattern)
+}
+
+func checkSubjectLength(c *git.Commit, o *Options) ([]report.Violation, error) {
+	return messageViolations(commitmsg.SubjectLength(c.Message, o.MaxSubjectLength)), nil
+}
+
+func checkTicketReference(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.RequireTicket || (o.TicketExemptMerges && c.NumParents() > 1) {
+		return nil, nil
+	}
+
+	re, err := o.ticketPattern()
+	if err != nil || re.MatchString(c.Message) {
+		return nil, err
+	}
+
+	return []report.Violation{{
+		Message: fmt.Sprintf("commit message doesn't reference a ticket matching %s", re),
+	}}, nil
+}
+
+func checkEmptyMessage(c *git.Commit, o *Options) ([]report.Violation, error) {
+	var msg string
+	switch {
+	case strings.TrimSpace(c.Message) == "":
+		msg = "commit message is empty"
+	case strings.TrimSpace(commitmsg.Subject(c.Message)) == "":
+		msg = "commit subject is blank"
+	default:
+		return nil, nil
+	}
+
+	return []report.Violation{{Message: msg}}, nil
+}
+
+func checkImperativeSubject(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.ImperativeSubject || c.NumParents() > 1 {
+		return nil, nil
+	}
+
+	return messageViolations(commitmsg.Imperative(c.Message)), nil
+}
+
+func checkConventionalCommit(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.ConventionalCommits || c.NumParents() > 1 {
+		return nil, nil
+	}
+
+	return messageViolations(commitmsg.Conventional(c.Message, o.ConventionalTypes, o.ConventionalRequireScope)), nil
+}
+
+func checkBodyLineLength(c *git.Commit, o *Options) ([]report.Violation, error) {
+	return messageViolations(commitmsg.Body(c.Message, o.MaxBodyLineLength)...), nil
+}
+
+// messageViolations returns a violation of the commit for each of the
+// problems of its message, skipping empty ones.
+func messageViolations(problems ...string) []report.Violation {
+	var vs []report.Violation
+	for _, p := range problems {
+		if p != "" {
+			vs = append(vs, report.Violation{Message: p})
+		}
+	}
+
+	return vs
+}
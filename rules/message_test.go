@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// messages returns the messages of the violations.
+func messages(vs []report.Violation) []string {
+	var msgs []string
+	for _, v := range vs {
+		msgs = append(msgs, v.Message)
+	}
+
+	return msgs
+}
+
+func TestCheckEmptyMessage(t *testing.T) {
+	tr := newTestRepo(t)
+	for _, tc := range []struct {
+		msg  string
+		want []string
+	}{
+		{"", []string{"commit message is empty"}},
+		{" \n\t\n", []string{"commit message is empty"}},
+		{"  \n\nbody after a blank subject\n", []string{"commit subject is blank"}},
+		{"\t\nbody\n", []string{"commit subject is blank"}},
+		{"Add the parser\n", nil},
+		{"Add the parser", nil},
+		{"  Add the parser  \n\nbody\n", nil},
+	} {
+		vs, err := checkEmptyMessage(tr.commit(nil, tc.msg), &Options{})
+		if err != nil || !reflect.DeepEqual(messages(vs), tc.want) {
+			t.Errorf("checkEmptyMessage(%q) = %q, %v, want %q", tc.msg, messages(vs), err, tc.want)
+		}
+	}
+}
+
+func TestMessageRules(t *testing.T) {
+	tr := newTestRepo(t)
+	parent := tr.commit(nil, "Add the parser")
+	other := tr.commit(nil, "Add the lexer")
+	o := &Options{
+		MaxSubjectLength:    20,
+		ImperativeSubject:   true,
+		ConventionalCommits: true,
+		MaxBodyLineLength:   30,
+	}
+
+	for _, tc := range []struct {
+		check   func(*git.Commit, *Options) ([]report.Violation, error)
+		msg     string
+		parents []*git.Commit
+		want    []string
+	}{
+		{checkSubjectLength, "fix: handle the tabs", nil, nil},
+		{checkSubjectLength, "fix: handle the tabs!", nil, []string{"subject is 21 characters long, the limit is 20"}},
+		{checkImperativeSubject, "fix: handled the tabs", nil, []string{`subject starts with "handled", use the imperative mood as in "Fix bug" rather than "Fixed bug" or "Fixes bug"`}},
+		{checkImperativeSubject, "fix: embed the tabs", nil, nil},
+		{checkImperativeSubject, "Merged branch", []*git.Commit{parent, other}, nil},
+		{checkConventionalCommit, "Handle the tabs", nil, []string{`subject doesn't start with a Conventional Commit prefix like "feat: " or "feat(scope): "`}},
+		{checkConventionalCommit, "Merge branch", []*git.Commit{parent, other}, nil},
+		{checkBodyLineLength, "fix: tabs\n\nthe body wrapped at thirty chars\n", nil, []string{"line 3 of the commit message is 32 characters long, wrap the body at 30"}},
+		{checkBodyLineLength, "fix: tabs\nbody\n", nil, []string{"commit message body isn't separated from the subject by a blank line"}},
+	} {
+		vs, err := tc.check(tr.commit(nil, tc.msg, tc.parents...), o)
+		if err != nil || !reflect.DeepEqual(messages(vs), tc.want) {
+			t.Errorf("check of %q = %q, %v, want %q", tc.msg, messages(vs), err, tc.want)
+		}
+	}
+
+	for _, check := range []func(*git.Commit, *Options) ([]report.Violation, error){checkSubjectLength, checkImperativeSubject, checkConventionalCommit, checkBodyLineLength} {
+		if vs, err := check(tr.commit(nil, "Fixed things in a subject too long\nbody\n"), &Options{}); err != nil || vs != nil {
+			t.Errorf("disabled check = %+v, %v, want none", vs, err)
+		}
+	}
+}
+
+func TestCheckTicketReference(t *testing.T) {
+	tr := newTestRepo(t)
+	parent := tr.commit(nil, "Add the parser")
+	other := tr.commit(nil, "Add the lexer")
+	merge := []*git.Commit{parent, other}
+	missing := []string{`commit message doesn't reference a ticket matching \b[A-Z]+-\d+\b|#\d+\b`}
+	for _, tc := range []struct {
+		msg     string
+		parents []*git.Commit
+		o       Options
+		want    []string
+	}{
+		{"Fix the parser\n\nFixes JIRA-123.\n", nil, Options{RequireTicket: true}, nil},
+		{"Fix the parser (#456)", nil, Options{RequireTicket: true}, nil},
+		{"Fix the parser", nil, Options{RequireTicket: true}, missing},
+		{"Fix the parser for utf-8 and #hashtags", nil, Options{RequireTicket: true}, missing},
+		{"Fix the parser", nil, Options{}, nil},
+		{"Merge branch 'topic'", merge, Options{RequireTicket: true}, missing},
+		{"Merge branch 'topic'", merge, Options{RequireTicket: true, TicketExemptMerges: true}, nil},
+		{"Fix the parser", nil, Options{RequireTicket: true, TicketExemptMerges: true}, missing},
+		{"Fix the parser, gh-12", nil, Options{RequireTicket: true, TicketPattern: `gh-\d+`}, nil},
+		{"Fix the parser, JIRA-1", nil, Options{RequireTicket: true, TicketPattern: `gh-\d+`}, []string{`commit message doesn't reference a ticket matching gh-\d+`}},
+	} {
+		vs, err := checkTicketReference(tr.commit(nil, tc.msg, tc.parents...), &tc.o)
+		if err != nil || !reflect.DeepEqual(messages(vs), tc.want) {
+			t.Errorf("checkTicketReference(%q, %+v) = %q, %v, want %q", tc.msg, tc.o, messages(vs), err, tc.want)
+		}
+	}
+
+	if _, err := checkTicketReference(tr.commit(nil, "Fix"), &Options{RequireTicket: true, TicketPattern: "("}); err == nil {
+		t.Error("checkTicketReference with an invalid pattern succeeded")
+	}
+}
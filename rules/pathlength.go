@@ -0,0 +1,45 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "max-path-length",
+		Severity:    report.Error,
+		Description: "file paths and their components must not be longer than allowed",
+		File:        checkPathLength,
+	})
+}
+
+func checkPathLength(f *git.File, o *Options) ([]report.Violation, error) {
+	var vs []report.Violation
+	if o.MaxPathLength > 0 && len(f.Name) > o.MaxPathLength {
+		vs = append(vs, report.Violation{
+			Path:    f.Name,
+			Message: fmt.Sprintf("path is %d bytes long, the limit is %d", len(f.Name), o.MaxPathLength),
+		})
+	}
+
+	if o.MaxPathComponentLength == 0 {
+		return vs, nil
+	}
+
+	for _, c := range strings.Split(f.Name, "/") {
+		if len(c) <= o.MaxPathComponentLength {
+			continue
+		}
+
+		vs = append(vs, report.Violation{
+			Path:    f.Name,
+			Message: fmt.Sprintf("path component %q is %d bytes long, the limit is %d", c, len(c), o.MaxPathComponentLength),
+		})
+	}
+
+	return vs, nil
+}
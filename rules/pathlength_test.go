@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckPathLength(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		max, component int
+		want           []report.Violation
+	}{
+		{"src/main.go", 0, 0, nil},
+		{"src/main.go", 11, 7, nil},
+		{"src/main.go", 10, 0, []report.Violation{{Path: "src/main.go", Message: "path is 11 bytes long, the limit is 10"}}},
+		{"src/main.go", 0, 6, []report.Violation{{Path: "src/main.go", Message: `path component "main.go" is 7 bytes long, the limit is 6`}}},
+		{"long_dir/long_name", 10, 5, []report.Violation{
+			{Path: "long_dir/long_name", Message: "path is 18 bytes long, the limit is 10"},
+			{Path: "long_dir/long_name", Message: `path component "long_dir" is 8 bytes long, the limit is 5`},
+			{Path: "long_dir/long_name", Message: `path component "long_name" is 9 bytes long, the limit is 5`},
+		}},
+		{"é/ü", 4, 1, []report.Violation{
+			{Path: "é/ü", Message: "path is 5 bytes long, the limit is 4"},
+			{Path: "é/ü", Message: `path component "é" is 2 bytes long, the limit is 1`},
+			{Path: "é/ü", Message: `path component "ü" is 2 bytes long, the limit is 1`},
+		}},
+	} {
+		o := &Options{MaxPathLength: tc.max, MaxPathComponentLength: tc.component}
+		vs, err := checkPathLength(testFile(t, tc.name, ""), o)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkPathLength(%s, %d, %d) = %+v, %v, want %+v", tc.name, tc.max, tc.component, vs, err, tc.want)
+		}
+	}
+}
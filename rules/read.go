@@ -0,0 +1,54 @@
+package rules
+
+import (
+	"io"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// OpenFile returns a reader streaming the content of the file from its blob,
+// cut off after max bytes, or reading the whole file if max isn't positive.
+// Rules needing only the start of a file read it this way instead of
+// loading its whole content, see Truncated.
+func OpenFile(f *git.File, max int64) (io.ReadCloser, error) {
+	r, err := f.Reader()
+	if err != nil || max <= 0 {
+		return r, err
+	}
+
+	return &limitedReader{Reader: io.LimitReader(r, max), c: r}, nil
+}
+
+// Truncated returns true if OpenFile with max cuts the file off.
+func Truncated(f *git.File, max int64) bool {
+	return max > 0 && f.Size > max
+}
+
+// Head returns the first n bytes of the file, fewer if it is smaller, only
+// those being read.
+func Head(f *git.File, n int) ([]byte, error) {
+	r, err := OpenFile(f, int64(n))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	buf := make([]byte, n)
+	m, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	return buf[:m], nil
+}
+
+// limitedReader reads at most a number of bytes of a blob, closing the blob
+// reader when closed.
+type limitedReader struct {
+	io.Reader
+	c io.Closer
+}
+
+func (r *limitedReader) Close() error {
+	return r.c.Close()
+}
@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestOpenFile(t *testing.T) {
+	const content = "0123456789"
+	for _, tc := range []struct {
+		max       int64
+		want      string
+		truncated bool
+	}{
+		{0, content, false},
+		{-1, content, false},
+		{4, "0123", true},
+		{9, "012345678", true},
+		{10, content, false},
+		{11, content, false},
+		{1 << 20, content, false},
+	} {
+		f := testFile(t, "f.txt", content)
+		r, err := OpenFile(f, tc.max)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ioutil.ReadAll(r)
+		if cerr := r.Close(); err == nil {
+			err = cerr
+		}
+
+		if err != nil || string(got) != tc.want {
+			t.Errorf("OpenFile(%d) read %q, %v, want %q", tc.max, got, err, tc.want)
+		}
+
+		if truncated := Truncated(f, tc.max); truncated != tc.truncated {
+			t.Errorf("Truncated(%d) = %v, want %v", tc.max, truncated, tc.truncated)
+		}
+	}
+
+	if Truncated(testFile(t, "empty", ""), 1) {
+		t.Error("Truncated(empty file) = true")
+	}
+}
+
+func TestHead(t *testing.T) {
+	const content = "0123456789"
+	for _, tc := range []struct {
+		n    int
+		want string
+	}{
+		{0, ""},
+		{1, "0"},
+		{9, "012345678"},
+		{10, content},
+		{20, content},
+	} {
+		if got, err := Head(testFile(t, "f.txt", content), tc.n); err != nil || string(got) != tc.want {
+			t.Errorf("Head(%d) = %q, %v, want %q", tc.n, got, err, tc.want)
+		}
+	}
+
+	if got, err := Head(testFile(t, "empty", ""), 8); err != nil || len(got) != 0 {
+		t.Errorf("Head(empty file) = %q, %v", got, err)
+	}
+}
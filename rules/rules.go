@@ -0,0 +1,643 @@
+// Package rules implements the checks run by the review engine, each file
+// registering a group of related rules in its init function, such as the
+// commit message rules of message.go, reporting the problems found by
+// package commitmsg.
+package rules
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// Version identifies the behavior of the rules, results cached by an older
+// version are discarded. It must be increased whenever a rule changes the
+// violations it reports.
+const Version = 2
+
+// Options holds the settings of the rules. Rules whose settings are left to
+// their zero value are disabled.
+type Options struct {
+	// MaxFileSize is the biggest blob size accepted in bytes.
+	MaxFileSize int64
+	// FlagEmptyFiles enables the check for empty files, except those
+	// matching the EmptyFilesAllowed globs, DefaultEmptyFilesAllowed if
+	// empty.
+	FlagEmptyFiles    bool
+	EmptyFilesAllowed []string
+	// MaxSubjectLength is the longest commit subject accepted in characters.
+	MaxSubjectLength int
+	// ImperativeSubject enables the check for commit subjects not starting
+	// with a verb in the imperative mood.
+	ImperativeSubject bool
+	// ConventionalCommits enables the check for commit subjects not following
+	// Conventional Commits with one of the ConventionalTypes,
+	// commitmsg.DefaultTypes if empty, and with a scope if
+	// ConventionalRequireScope.
+	ConventionalCommits      bool
+	ConventionalTypes        []string
+	ConventionalRequireScope bool
+	// MaxBodyLineLength is the longest line of the commit message bodies
+	// accepted in characters, 0 disables the check.
+	MaxBodyLineLength int
+	// RequireTicket enables the check for commit messages without a
+	// reference matching TicketPattern, DefaultTicketPattern if empty. Merge
+	// commits are skipped with TicketExemptMerges.
+	RequireTicket      bool
+	TicketPattern      string
+	TicketExemptMerges bool
+	// SensitivePaths are the globs of the files whose changes are pointed
+	// out in the reviewed commits.
+	SensitivePaths []string
+	// FlagSubmodules enables the check for submodules added, removed or
+	// moved to another commit by the reviewed commits.
+	FlagSubmodules bool
+	// DiffAgainst chooses the trees the reviewed commits are compared to
+	// for their changed files, see ChangesAgainst.
+	DiffAgainst string
+	// MaxPathLength is the longest file path accepted in bytes, and
+	// MaxPathComponentLength the longest file or directory name.
+	MaxPathLength          int
+	MaxPathComponentLength int
+	// RequireUTF8 enables the check for text files not valid UTF-8.
+	RequireUTF8 bool
+	// ASCIIFilenames enables the check for file and directory names with
+	// bytes outside printable ASCII.
+	ASCIIFilenames bool
+	// CaseCollisions enables the check for paths differing only by case.
+	CaseCollisions bool
+	// VerifyObjects enables the integrity checks of the objects of the
+	// reviewed tree, such as trees with several entries of the same name.
+	VerifyObjects bool
+	// MaxDirEntries is the largest number of direct entries accepted in a
+	// directory, DirEntryLimits overriding it for some directories.
+	MaxDirEntries  int
+	DirEntryLimits []PathLimit
+	// Indent gives the indentation style of the files matching a glob, the
+	// first matching glob winning.
+	Indent []IndentStyle
+	// Sorted lists the files whose lines must be sorted, the first matching
+	// glob winning.
+	Sorted []SortedFiles
+	// CheckExecBit enables the check for executable files matching the
+	// NonExecutable globs, DefaultNonExecutable if empty, and with
+	// ShebangExec also for files starting with a shebang that aren't
+	// executable.
+	CheckExecBit  bool
+	NonExecutable []string
+	ShebangExec   bool
+	// MaxBlankLines is the length of the runs of consecutive blank lines
+	// reported, longer ones included, in the text files matching the
+	// BlankLinesPaths globs, or in every text file if there is none, 0
+	// disabling the check.
+	MaxBlankLines   int
+	BlankLinesPaths []string
+	// NoTrailingWhitespace enables the check for lines ending with spaces
+	// or tabs in the text files matching the TrailingWhitespacePaths globs,
+	// or in every text file if there is none.
+	NoTrailingWhitespace    bool
+	TrailingWhitespacePaths []string
+	// ChangedLineWhitespace enables the check for trailing whitespace,
+	// spaces before tabs in the indentation and missing newlines at the end
+	// of the files in the lines added by the reviewed commits only, leaving
+	// the existing lines alone.
+	ChangedLineWhitespace bool
+	// LicenseHeader is the header, or with LicenseHeaderRegexp the regular
+	// expression, to find in the first LicenseHeaderLines lines,
+	// DefaultLicenseHeaderLines if 0, of the text files matching the
+	// LicenseHeaderPaths globs, or of every text file if there is none.
+	LicenseHeader       string
+	LicenseHeaderRegexp bool
+	LicenseHeaderLines  int
+	LicenseHeaderPaths  []string
+	// LicenseTemplate is the header the text files added by the reviewed
+	// commits, matching the LicenseHeaderPaths globs if any, must start
+	// with, its LicenseYear being replaced by the year of the commit.
+	LicenseTemplate string
+	// MaxReadSize is the number of bytes of a file read by the rules
+	// scanning its lines or its content, the rest of larger files being
+	// ignored. Whole files are read if 0.
+	MaxReadSize int64
+	// CheckBOM enables the check for files starting with a byte order mark.
+	CheckBOM bool
+	// CheckSymlinks enables the check for symbolic links pointing outside
+	// the repository or to a file matching the SensitivePaths globs.
+	CheckSymlinks bool
+	// DetectSecrets enables the check for credentials in the lines added by
+	// the reviewed commits, except in the files matching the SecretsIgnored
+	// globs, DefaultSecretsIgnored if empty.
+	DetectSecrets  bool
+	SecretsIgnored []string
+	// ForbidFiles enables the check for files added by the reviewed commits
+	// matching one of the ForbiddenFiles globs, DefaultForbiddenFiles if
+	// empty.
+	ForbidFiles    bool
+	ForbiddenFiles []string
+	// CheckGofmt enables the check for Go files changed by the reviewed
+	// commits not formatted with gofmt, the violations giving the diff
+	// formatting them, and with GofmtFix the formatted content too.
+	CheckGofmt bool
+	GofmtFix   bool
+	// GoVet enables go vet on the Go packages changed by the reviewed
+	// commits, checked out on disk, reporting its diagnostics on the added
+	// lines. GoVetAnalyzers restricts it to these analyzers.
+	GoVet          bool
+	GoVetAnalyzers []string
+	// MaxFileLines is the most lines accepted in the files grown by the
+	// reviewed commits, 0 disables the check.
+	MaxFileLines int
+	// MaxFuncLines and MaxFuncStatements are the most lines and statements
+	// accepted in the Go functions changed by the reviewed commits, 0
+	// disabling the checks.
+	MaxFuncLines      int
+	MaxFuncStatements int
+	// MaxComplexity is the highest cyclomatic complexity accepted in the Go
+	// functions changed by the reviewed commits, 0 disables the check.
+	MaxComplexity int
+	// PartialFiles are the paths of the files whose content is only partly
+	// known, those of patches only holding the lines of their hunks, which
+	// gofmt, go vet and the complexity and size checks skip.
+	PartialFiles []string
+	// MaxAddedFileSize enables the check for files bigger than this size in
+	// bytes added or grown by the reviewed commits, 0 disables it.
+	MaxAddedFileSize int64
+	// FlagBinaryAdditions enables the check for binary files added by the
+	// reviewed commits.
+	FlagBinaryAdditions bool
+	// FlagTodos enables the check for TODO, FIXME and HACK comments added by
+	// the reviewed commits, and TodoRequireIssue restricts it to those not
+	// referencing an issue like TODO(#123).
+	FlagTodos        bool
+	TodoRequireIssue bool
+	// SigningKeyring enables the check for commits not signed by a key of
+	// this keyring, a file of public keys as exported by gpg --export.
+	SigningKeyring string
+	// DisabledRules are the ids of the rules not run.
+	DisabledRules []string
+	// Overrides change the settings of the file rules for the files
+	// matching their globs, the later ones winning over the earlier ones
+	// and over DisabledRules and Severity.
+	Overrides []Override
+	// Exclude maps rule ids to the globs of the paths they skip, see
+	// Excluded.
+	Exclude map[string][]string
+	// Severity maps rule ids to the severity of their violations, replacing
+	// the default severity of the rule.
+	Severity map[string]report.Severity
+	// DocURLs maps rule ids, of registered rules or of plugins, to the
+	// documentation URL of their violations, replacing the one of the rule.
+	DocURLs map[string]string
+}
+
+// PathLimit is a limit applying to the paths matching a glob.
+type PathLimit struct {
+	Glob string
+	Max  int
+}
+
+// Override changes the settings of the file rules for the files matching one
+// of its globs, see Options.Overrides.
+type Override struct {
+	Paths []string
+	// Disabled maps rule ids to true to turn them off for the files, or to
+	// false to turn them back on.
+	Disabled map[string]bool
+	// Severity maps rule ids to the severity of their violations in the
+	// files.
+	Severity map[string]report.Severity
+}
+
+// Excluded returns true if the file rule id must skip the path, matched with
+// MatchPath against the globs of the rule.
+func (o *Options) Excluded(id, name string) bool {
+	return matchAny(o.Exclude[id], name)
+}
+
+// partial returns true if the path is one of PartialFiles.
+func (o *Options) partial(name string) bool {
+	for _, p := range o.PartialFiles {
+		if p == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Disabled returns true if the rule id is disabled by DisabledRules.
+func (o *Options) Disabled(id string) bool {
+	for _, d := range o.DisabledRules {
+		if d == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DisabledAt returns true if the rule id is disabled for the file at name, by
+// DisabledRules or by the overrides matching it. Only DisabledRules applies
+// if name is empty.
+func (o *Options) DisabledAt(id, name string) bool {
+	disabled := o.Disabled(id)
+	if name == "" {
+		return disabled
+	}
+
+	for _, ov := range o.Overrides {
+		if d, ok := ov.Disabled[id]; ok && matchAny(ov.Paths, name) {
+			disabled = d
+		}
+	}
+
+	return disabled
+}
+
+// severityAt returns the severity of the violations of the rule in the file
+// at name, or in the other objects if name is empty.
+func (o *Options) severityAt(m Meta, name string) report.Severity {
+	severity, ok := o.Severity[m.ID]
+	if !ok {
+		severity = m.Severity
+	}
+
+	if name == "" {
+		return severity
+	}
+
+	for _, ov := range o.Overrides {
+		if s, ok := ov.Severity[m.ID]; ok && matchAny(ov.Paths, name) {
+			severity = s
+		}
+	}
+
+	return severity
+}
+
+// Validate returns an error if a pattern of the options is invalid, the
+// first of those returned by Check.
+func (o *Options) Validate() error {
+	if errs := o.Check(); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// Check returns an error for each invalid regular expression or glob of the
+// options.
+func (o *Options) Check() []error {
+	var errs []error
+	if _, err := o.licenseHeader(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid license header: %s", err))
+	}
+
+	if _, err := o.ticketPattern(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid ticket pattern: %s", err))
+	}
+
+	if _, err := diffDepth(o.DiffAgainst); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, id := range o.DisabledRules {
+		if Lookup(id) == nil {
+			errs = append(errs, fmt.Errorf("unknown disabled rule %q", id))
+		}
+	}
+
+	globs := [][]string{o.SensitivePaths, o.SecretsIgnored, o.ForbiddenFiles, o.EmptyFilesAllowed, o.NonExecutable, o.BlankLinesPaths,
+		o.TrailingWhitespacePaths, o.LicenseHeaderPaths}
+	for _, l := range o.DirEntryLimits {
+		globs = append(globs, []string{l.Glob})
+	}
+
+	for _, s := range o.Indent {
+		globs = append(globs, []string{s.Glob})
+	}
+
+	for _, s := range o.Sorted {
+		globs = append(globs, []string{s.Glob})
+	}
+
+	for _, ov := range o.Overrides {
+		globs = append(globs, ov.Paths)
+	}
+
+	ids := make([]string, 0, len(o.Exclude))
+	for id := range o.Exclude {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		globs = append(globs, o.Exclude[id])
+	}
+
+	for _, l := range globs {
+		for _, glob := range l {
+			if _, err := path.Match(glob, ""); err != nil {
+				errs = append(errs, fmt.Errorf("invalid glob %q: %s", glob, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// matchAny returns true if the path matches any of the globs, see MatchPath.
+func matchAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if MatchPath(glob, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchPath returns true if the path matches the glob, using the path.Match
+// syntax. A glob without slash matches the base name of the path in any
+// directory and other globs the whole path, a ** segment matching any
+// number of directories.
+func MatchPath(glob, name string) bool {
+	if !strings.Contains(glob, "/") {
+		ok, _ := path.Match(glob, path.Base(name))
+		return ok
+	}
+
+	if !strings.Contains(glob, "**") {
+		ok, _ := path.Match(glob, name)
+		return ok
+	}
+
+	return matchSegments(strings.Split(glob, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments returns true if the slash separated segments of a path match
+// those of a glob.
+func matchSegments(glob, name []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(glob[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(glob[0], name[0]); !ok {
+			return false
+		}
+
+		glob, name = glob[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// Kind is a kind of object checked by the rules, combined as a bit set by
+// the rules checking several kinds.
+type Kind int
+
+// Kinds of the objects checked by the rules.
+const (
+	// FileKind is a reviewed file.
+	FileKind Kind = 1 << iota
+	// CommitKind is a reviewed commit, its message and metadata.
+	CommitKind
+	// TreeKind is a whole reviewed tree, for the rules looking at several
+	// files at once.
+	TreeKind
+)
+
+// Meta describes a rule.
+type Meta struct {
+	ID          string
+	Severity    report.Severity
+	Description string
+	// DocURL is the page explaining the rule and how to fix its violations,
+	// if any.
+	DocURL string
+	// Kinds are the kinds of objects the rule checks, it is only run on
+	// those.
+	Kinds Kind
+}
+
+// ReviewContext is what a rule checks: an object of the given Kind, along
+// with the options of the review.
+type ReviewContext struct {
+	Kind Kind
+	// File, Commit or Tree is the checked object, depending on Kind, the
+	// others being nil.
+	File   *git.File
+	Commit *git.Commit
+	Tree   *git.Tree
+	// Repository holds the checked tree, it is only set for TreeKind.
+	Repository *git.Repository
+	Options    *Options
+}
+
+// Rule is a check run by the review engine. Every review goes through the
+// registered rules, those of this package registering themselves from their
+// init function and third-party ones from the init function of a package
+// linked in the bot. Check only fills the location and message of the
+// violations it returns, their rule, severity and documentation URL are
+// set from the Meta of the rule.
+type Rule interface {
+	Meta() Meta
+	Check(ctx context.Context, rc *ReviewContext) ([]report.Violation, error)
+}
+
+// Func is a Rule checking each kind of object with a function, as the rules
+// of this package do.
+type Func struct {
+	ID          string
+	Severity    report.Severity
+	Description string
+	// DocURL is the page explaining the rule and how to fix its violations,
+	// if any.
+	DocURL string
+
+	// File checks a file, it is nil for rules that don't look at files.
+	File func(f *git.File, o *Options) ([]report.Violation, error)
+	// Commit checks a commit, it is nil for rules that don't look at
+	// commits.
+	Commit func(c *git.Commit, o *Options) ([]report.Violation, error)
+	// Tree checks a whole tree, for rules that look at several files at
+	// once. It is nil for the other rules.
+	Tree func(r *git.Repository, t *git.Tree, o *Options) ([]report.Violation, error)
+}
+
+// Meta returns the metadata of the rule, its kinds being those with a
+// function.
+func (r *Func) Meta() Meta {
+	m := Meta{ID: r.ID, Severity: r.Severity, Description: r.Description, DocURL: r.DocURL}
+	if r.File != nil {
+		m.Kinds |= FileKind
+	}
+
+	if r.Commit != nil {
+		m.Kinds |= CommitKind
+	}
+
+	if r.Tree != nil {
+		m.Kinds |= TreeKind
+	}
+
+	return m
+}
+
+// Check runs the function of the kind of rc.
+func (r *Func) Check(ctx context.Context, rc *ReviewContext) ([]report.Violation, error) {
+	switch {
+	case rc.Kind == FileKind && r.File != nil:
+		return r.File(rc.File, rc.Options)
+	case rc.Kind == CommitKind && r.Commit != nil:
+		return r.Commit(rc.Commit, rc.Options)
+	case rc.Kind == TreeKind && r.Tree != nil:
+		return r.Tree(rc.Repository, rc.Tree, rc.Options)
+	}
+
+	return nil, nil
+}
+
+var registry = make(map[string]Rule)
+
+// Register adds a rule to the registry, it panics if a rule with the same id
+// is already registered.
+func Register(r Rule) {
+	id := r.Meta().ID
+	if _, ok := registry[id]; ok {
+		panic(fmt.Sprintf("rules: rule %q registered twice", id))
+	}
+
+	registry[id] = r
+}
+
+// Lookup returns the rule with the given id, or nil.
+func Lookup(id string) Rule {
+	return registry[id]
+}
+
+// All returns the registered rules sorted by id.
+func All() []Rule {
+	ids := make([]string, 0, len(registry))
+	for id := range registry {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	all := make([]Rule, len(ids))
+	for i, id := range ids {
+		all[i] = registry[id]
+	}
+
+	return all
+}
+
+// Checks returns true if the rule checks the objects of the kind.
+func Checks(r Rule, kind Kind) bool {
+	return r.Meta().Kinds&kind != 0
+}
+
+// Run runs the registered rules checking the kind of rc and not disabled
+// by the options, stopping when ctx is done.
+func Run(ctx context.Context, rc *ReviewContext) ([]report.Violation, error) {
+	var name string
+	if rc.Kind == FileKind {
+		name = rc.File.Name
+	}
+
+	var vs []report.Violation
+	for _, r := range All() {
+		m := r.Meta()
+		if m.Kinds&rc.Kind == 0 || rc.Options.DisabledAt(m.ID, name) {
+			continue
+		}
+
+		if rc.Kind == FileKind && rc.Options.Excluded(m.ID, name) {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rvs, err := r.Check(ctx, rc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %s", m.ID, rc.name(), err)
+		}
+
+		vs = append(vs, stamp(m, rvs, rc.Options.severityAt(m, name), rc.Options)...)
+	}
+
+	return vs, nil
+}
+
+// name names the checked object in errors.
+func (rc *ReviewContext) name() string {
+	switch rc.Kind {
+	case FileKind:
+		return rc.File.Name
+	case CommitKind:
+		return "commit " + rc.Commit.Hash.String()
+	}
+
+	return "tree " + rc.Tree.Hash.String()
+}
+
+// CheckFile runs the file rules on f.
+func CheckFile(f *git.File, o *Options) ([]report.Violation, error) {
+	return Run(context.Background(), &ReviewContext{Kind: FileKind, File: f, Options: o})
+}
+
+// CheckCommit runs the commit rules on c.
+func CheckCommit(c *git.Commit, o *Options) ([]report.Violation, error) {
+	vs, err := Run(context.Background(), &ReviewContext{Kind: CommitKind, Commit: c, Options: o})
+	for i := range vs {
+		vs[i].Commit = c.Hash.String()
+	}
+
+	return vs, err
+}
+
+// CheckTree runs the tree rules on t.
+func CheckTree(r *git.Repository, t *git.Tree, o *Options) ([]report.Violation, error) {
+	return Run(context.Background(), &ReviewContext{Kind: TreeKind, Tree: t, Repository: r, Options: o})
+}
+
+func stamp(m Meta, vs []report.Violation, severity report.Severity, o *Options) []report.Violation {
+	for i := range vs {
+		vs[i].Rule = m.ID
+		vs[i].Severity = severity
+		vs[i].DocURL = o.DocURL(m.ID)
+	}
+
+	return vs
+}
+
+// DocURL returns the documentation URL of the violations of the rule id, set
+// by DocURLs or by the registered rule, an empty string if there is none.
+func (o *Options) DocURL(id string) string {
+	if url, ok := o.DocURLs[id]; ok {
+		return url
+	}
+
+	if r := Lookup(id); r != nil {
+		return r.Meta().DocURL
+	}
+
+	return ""
+}
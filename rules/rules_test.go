@@ -0,0 +1,47 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/internal/testrepo"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// testFile returns a regular file of the content, out of any repository.
+func testFile(t *testing.T, name, content string) *git.File {
+	obj := &core.MemoryObject{}
+	obj.SetType(core.BlobObject)
+	obj.SetSize(int64(len(content)))
+	if _, err := obj.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &git.Blob{}
+	if err := b.Decode(obj); err != nil {
+		t.Fatal(err)
+	}
+
+	return git.NewFile(name, 0644, b)
+}
+
+// testRepo is a repository in memory whose commits are written by the
+// tests.
+type testRepo struct {
+	*testrepo.Repo
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	return &testRepo{testrepo.New(t)}
+}
+
+// commit writes a commit of the files, as testrepo.Repo.WriteTree, with the
+// message and the parents, and returns it.
+func (tr *testRepo) commit(files map[string]string, msg string, parents ...*git.Commit) *git.Commit {
+	hashes := make([]core.Hash, len(parents))
+	for i, p := range parents {
+		hashes[i] = p.Hash
+	}
+
+	return tr.CommitFiles(files, msg, hashes...)
+}
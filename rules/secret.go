@@ -0,0 +1,133 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "secret",
+		Severity:    report.Error,
+		Description: "lines added by commits must not hold credentials such as API keys or private keys, with -detect-secrets",
+		Commit:      checkSecrets,
+	})
+}
+
+// DefaultSecretsIgnored are the globs of the files not scanned for secrets
+// when Options.SecretsIgnored is empty, lock files holding checksums that
+// look like random strings.
+var DefaultSecretsIgnored = []string{"go.sum", "*.lock", "package-lock.json", "npm-shrinkwrap.json"}
+
+// secretPattern finds a kind of secret in a line, the value being the
+// submatch of the pattern if any, or the whole match.
+type secretPattern struct {
+	name string
+	re   *regexp.Regexp
+	// entropy is the lowest Shannon entropy in bits per character of the
+	// values reported, 0 for values recognized by their format alone.
+	entropy float64
+	// header is set when the match only starts the secret, such as the
+	// header of a private key, and isn't shown.
+	header bool
+}
+
+var secretPatterns = []secretPattern{
+	{name: "private key", re: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`), header: true},
+	{name: "AWS access key ID", re: regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`)},
+	{name: "AWS secret access key", re: regexp.MustCompile(`(?i)aws.{0,20}(?:secret|key).{0,20}?[=:]\s*["']?([A-Za-z0-9/+]{40})\b`)},
+	{name: "GitHub token", re: regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`)},
+	{name: "Slack token", re: regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})`)},
+	{name: "Google API key", re: regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})`)},
+	{name: "Stripe key", re: regexp.MustCompile(`\b((?:sk|rk)_live_[0-9A-Za-z]{24,})`)},
+	// Values assigned to a name like api_key or password, only reported
+	// when random enough not to be a placeholder or a variable name.
+	{
+		name:    "high-entropy secret",
+		re:      regexp.MustCompile(`(?i)(?:key|secret|token|passw(?:or)?d|credential)[\w.-]*["']?\s*(?::=|=>|[:=])\s*["']([A-Za-z0-9+/=_.~-]{16,})["']`),
+		entropy: 3.5,
+	},
+}
+
+func checkSecrets(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.DetectSecrets {
+		return nil, nil
+	}
+
+	ignored := o.SecretsIgnored
+	if len(ignored) == 0 {
+		ignored = DefaultSecretsIgnored
+	}
+
+	return checkAddedLines(c, o, ignored, findSecrets)
+}
+
+// findSecrets returns a violation for each secret found in the added line,
+// the first pattern matching a part of the line winning.
+func findSecrets(name string, l diff.Line) []report.Violation {
+	var vs []report.Violation
+	var found [][]int
+	for _, p := range secretPatterns {
+		for _, m := range p.re.FindAllStringSubmatchIndex(l.Text, -1) {
+			start, end := m[0], m[1]
+			if len(m) > 2 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+
+			value := l.Text[start:end]
+			if overlaps(found, start, end) || (p.entropy > 0 && entropy(value) < p.entropy) {
+				continue
+			}
+
+			msg := fmt.Sprintf("possible %s added", p.name)
+			if !p.header {
+				msg = fmt.Sprintf("possible %s %s added", p.name, redact(value))
+			}
+
+			found = append(found, []int{start, end})
+			vs = append(vs, report.Violation{Path: name, Line: l.NewLine, Column: start + 1, Message: msg})
+		}
+	}
+
+	return vs
+}
+
+// overlaps returns true if the range of bytes overlaps one of those found.
+func overlaps(found [][]int, start, end int) bool {
+	for _, f := range found {
+		if start < f[1] && f[0] < end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redact returns the secret as shown in the violations, only its first
+// characters so that the report doesn't leak it.
+func redact(value string) string {
+	return fmt.Sprintf("%q", value[:4]+"****")
+}
+
+// entropy returns the Shannon entropy of the string in bits per byte.
+func entropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+
+	var h float64
+	for _, n := range counts {
+		if n > 0 {
+			p := float64(n) / float64(len(s))
+			h -= p * math.Log2(p)
+		}
+	}
+
+	return h
+}
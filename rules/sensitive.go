@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "sensitive-path",
+		Severity:    report.Info,
+		Description: "changes to the files matching -sensitive-path are pointed out for a closer review",
+		Commit:      checkSensitivePaths,
+	})
+}
+
+func checkSensitivePaths(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if len(o.SensitivePaths) == 0 {
+		return nil, nil
+	}
+
+	changes, err := ChangesAgainst(c, o.DiffAgainst)
+	if err != nil {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	for _, ch := range changes {
+		name := changeName(ch)
+		if !matchAny(o.SensitivePaths, name) {
+			continue
+		}
+
+		vs = append(vs, report.Violation{
+			Path:    name,
+			Message: fmt.Sprintf("sensitive file %s by commit %s", actionVerb(ch), c.Hash.String()[:7]),
+		})
+	}
+
+	return vs, nil
+}
+
+// actionVerb returns the past participle describing the change.
+func actionVerb(ch *git.Change) string {
+	switch {
+	case ch.Action == git.Insert:
+		return "added"
+	case ch.Action == git.Delete:
+		return "deleted"
+	case ch.From.Name != ch.To.Name:
+		return "renamed"
+	default:
+		return "modified"
+	}
+}
@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckSensitivePaths(t *testing.T) {
+	tr := newTestRepo(t)
+	parent := tr.commit(map[string]string{
+		"Dockerfile":       "FROM scratch\n",
+		"infra/main.tf":    "a\n",
+		"infra/old.tf":     "b\n",
+		"docs/README.md":   "docs\n",
+		"ci/dockerfile":    "FROM scratch\n",
+		"deploy/prod.yaml": "a: 1\n",
+	}, "Add the files")
+
+	globs := []string{"Dockerfile", "*.tf", "go.mod", "deploy/**"}
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		globs []string
+		want  []string
+	}{
+		{"silent", map[string]string{
+			"Dockerfile":       "FROM scratch\n",
+			"infra/main.tf":    "a\n",
+			"infra/old.tf":     "b\n",
+			"docs/README.md":   "more docs\n",
+			"ci/dockerfile":    "FROM scratch\n",
+			"deploy/prod.yaml": "a: 1\n",
+		}, globs, nil},
+		{"alerted", map[string]string{
+			"Dockerfile":     "FROM alpine\n",
+			"infra/main.tf":  "a\n",
+			"infra/new.tf":   "b\n",
+			"go.mod":         "module a\n",
+			"docs/README.md": "docs\n",
+			"ci/dockerfile":  "FROM scratch\n",
+		}, globs, []string{
+			"Dockerfile: sensitive file modified by commit HASH",
+			"deploy/prod.yaml: sensitive file deleted by commit HASH",
+			"go.mod: sensitive file added by commit HASH",
+			"infra/new.tf: sensitive file renamed by commit HASH",
+		}},
+		// The globs match the paths with their case, as git does.
+		{"case", map[string]string{
+			"Dockerfile":       "FROM scratch\n",
+			"infra/main.tf":    "a\n",
+			"infra/old.tf":     "b\n",
+			"docs/README.md":   "docs\n",
+			"ci/dockerfile":    "FROM alpine\n",
+			"deploy/prod.yaml": "a: 1\n",
+			"INFRA.TF":         "c\n",
+		}, globs, nil},
+		{"both cases", map[string]string{
+			"Dockerfile":       "FROM alpine\n",
+			"infra/main.tf":    "a\n",
+			"infra/old.tf":     "b\n",
+			"docs/README.md":   "docs\n",
+			"ci/dockerfile":    "FROM alpine\n",
+			"deploy/prod.yaml": "a: 1\n",
+		}, []string{"[Dd]ockerfile"}, []string{"Dockerfile: sensitive file modified by commit HASH", "ci/dockerfile: sensitive file modified by commit HASH"}},
+	} {
+		c := tr.commit(tc.files, "Change the files", parent)
+		vs, err := checkSensitivePaths(c, &Options{SensitivePaths: tc.globs})
+		var got []string
+		for _, v := range vs {
+			got = append(got, v.Path+": "+strings.Replace(v.Message, c.Hash.String()[:7], "HASH", 1))
+		}
+
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: checkSensitivePaths reported %q, %v, want %q", tc.name, got, err, tc.want)
+		}
+	}
+
+	c := tr.commit(map[string]string{"Dockerfile": "FROM alpine\n"}, "Change the Dockerfile", parent)
+	if vs, err := checkSensitivePaths(c, &Options{}); err != nil || vs != nil {
+		t.Errorf("checkSensitivePaths without -sensitive-path = %+v, %v", vs, err)
+	}
+}
@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/signature"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "signature",
+		Severity:    report.Error,
+		Description: "commits must be signed by a key of the -signing-keyring keyring",
+		Commit:      checkSignature,
+	})
+}
+
+func checkSignature(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if o.SigningKeyring == "" {
+		return nil, nil
+	}
+
+	v := &signature.Verifier{Keyring: o.SigningKeyring}
+	res, err := v.Commit(c)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := signatureMessage(res)
+	if msg == "" {
+		return nil, nil
+	}
+
+	return []report.Violation{{Message: msg}}, nil
+}
+
+// signatureMessage returns the violation message of a failed verification,
+// or an empty string if the signature is good.
+func signatureMessage(res *signature.Result) string {
+	switch res.Status {
+	case signature.Good:
+		return ""
+	case signature.Unsigned:
+		return "commit isn't signed"
+	case signature.UnknownKey:
+		return fmt.Sprintf("commit is signed by the key %s, missing from the keyring", res.Key)
+	case signature.Bad:
+		return fmt.Sprintf("commit has a bad signature from %s", signer(res))
+	case signature.Expired:
+		return fmt.Sprintf("commit has an expired signature from %s", signer(res))
+	case signature.Revoked:
+		return fmt.Sprintf("commit is signed by the revoked key of %s", signer(res))
+	}
+
+	return fmt.Sprintf("commit signature can't be verified: %s", res.Status)
+}
+
+// signer returns the user id and key of a signature.
+func signer(res *signature.Result) string {
+	if res.Signer == "" {
+		return "key " + res.Key
+	}
+
+	return fmt.Sprintf("%s (key %s)", res.Signer, res.Key)
+}
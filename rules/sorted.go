@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "sorted-lines",
+		Severity:    report.Error,
+		Description: "the lines of the files matching -enforce-sorted must be sorted, blank lines and # comments aside",
+		File:        checkSortedLines,
+	})
+}
+
+// SortedFiles requires the lines of the files matching a glob to be sorted,
+// ignoring case with IgnoreCase.
+type SortedFiles struct {
+	Glob       string
+	IgnoreCase bool
+}
+
+// sortedFiles returns the first SortedFiles matching the file, nil if none.
+func (o *Options) sortedFiles(name string) *SortedFiles {
+	for i := range o.Sorted {
+		if MatchPath(o.Sorted[i].Glob, name) {
+			return &o.Sorted[i]
+		}
+	}
+
+	return nil
+}
+
+func checkSortedLines(f *git.File, o *Options) ([]report.Violation, error) {
+	s := o.sortedFiles(f.Name)
+	if s == nil {
+		return nil, nil
+	}
+
+	if binary, err := IsBinary(f); err != nil || binary {
+		return nil, err
+	}
+
+	var prev, prevKey string
+	var v *report.Violation
+	_, err := ScanLinesLimit(f, o.MaxReadSize, func(n int, l string) bool {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "#") {
+			return true
+		}
+
+		key := l
+		if s.IgnoreCase {
+			key = strings.ToLower(l)
+		}
+
+		if prev != "" && key < prevKey {
+			v = &report.Violation{
+				Path:    f.Name,
+				Line:    n,
+				Message: fmt.Sprintf("%q is not sorted, it must come before %q", l, prev),
+			}
+
+			return false
+		}
+
+		prev, prevKey = l, key
+		return true
+	})
+
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	return []report.Violation{*v}, nil
+}
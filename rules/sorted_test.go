@@ -0,0 +1,35 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckSortedLines(t *testing.T) {
+	o := &Options{Sorted: []SortedFiles{{Glob: "OWNERS"}, {Glob: ".gitignore", IgnoreCase: true}}}
+	for _, tc := range []struct {
+		name    string
+		content string
+		want    []report.Violation
+	}{
+		{"OWNERS", "alice\nbob\ncarol\n", nil},
+		{"OWNERS", "alice\ncarol\nbob\ndave\nanne\n", []report.Violation{{Path: "OWNERS", Line: 3, Message: `"bob" is not sorted, it must come before "carol"`}}},
+		{"OWNERS", "bob\nbob\n", nil},
+		// Comments and blank lines are ignored for the ordering.
+		{"OWNERS", "# Leads\nalice\n\n# Others\nbob\n  # zed\ncarol\n", nil},
+		{"OWNERS", "# Leads\ncarol\n\n# Others\nbob\n", []report.Violation{{Path: "OWNERS", Line: 5, Message: `"bob" is not sorted, it must come before "carol"`}}},
+		{"OWNERS", "alice\r\n  bob  \n", nil},
+		{"OWNERS", "Bob\nalice\n", nil},
+		{"OWNERS", "alice\nBob\n", []report.Violation{{Path: "OWNERS", Line: 2, Message: `"Bob" is not sorted, it must come before "alice"`}}},
+		{"dir/.gitignore", "*.o\nBuild/\nbin/\n", []report.Violation{{Path: "dir/.gitignore", Line: 3, Message: `"bin/" is not sorted, it must come before "Build/"`}}},
+		{"dir/.gitignore", "*.o\nbin/\nBuild/\n", nil},
+		{"NOTES", "b\na\n", nil},
+	} {
+		vs, err := checkSortedLines(testFile(t, tc.name, tc.content), o)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkSortedLines(%s, %q) = %+v, %v, want %+v", tc.name, tc.content, vs, err, tc.want)
+		}
+	}
+}
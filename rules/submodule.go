@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "submodule-change",
+		Severity:    report.Warning,
+		Description: "submodules added, removed or moved to another commit are pointed out, with -flag-submodule-changes",
+		Commit:      checkSubmodules,
+	})
+}
+
+func checkSubmodules(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.FlagSubmodules {
+		return nil, nil
+	}
+
+	to, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	froms, err := againstTrees(c, o.DiffAgainst)
+	if err != nil {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	seen := make(map[string]bool)
+	for _, from := range froms {
+		changes, err := diff.SubmoduleDiff(from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sc := range changes {
+			if seen[sc.Path] {
+				continue
+			}
+
+			seen[sc.Path] = true
+			vs = append(vs, report.Violation{
+				Path:    sc.Path,
+				Message: submoduleMessage(&sc, c),
+			})
+		}
+	}
+
+	return vs, nil
+}
+
+func submoduleMessage(sc *diff.SubmoduleChange, c *git.Commit) string {
+	short := c.Hash.String()[:7]
+	switch sc.Action() {
+	case diff.Added:
+		return fmt.Sprintf("submodule added at %s by commit %s", sc.NewHash.String()[:7], short)
+	case diff.Deleted:
+		return fmt.Sprintf("submodule removed by commit %s", short)
+	}
+
+	return fmt.Sprintf("submodule moved from %s to %s by commit %s", sc.OldHash.String()[:7], sc.NewHash.String()[:7], short)
+}
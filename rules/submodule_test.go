@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func TestCheckSubmodules(t *testing.T) {
+	const (
+		m1 = "1111111111111111111111111111111111111111"
+		m2 = "2222222222222222222222222222222222222222"
+	)
+
+	tr := newTestRepo(t)
+	root := tr.commit(map[string]string{"a.txt": "a\n"}, "Add a.txt")
+	added := tr.commit(map[string]string{"a.txt": "a\n", "160000 lib": m1}, "Add lib", root)
+	bumped := tr.commit(map[string]string{"a.txt": "a\n", "160000 lib": m2}, "Bump lib", added)
+	removed := tr.commit(map[string]string{"a.txt": "a\n"}, "Remove lib", bumped)
+	unchanged := tr.commit(map[string]string{"a.txt": "b\n", "160000 lib": m2}, "Change a.txt", bumped)
+	side := tr.commit(map[string]string{"a.txt": "a\n", "160000 lib": m1, "160000 other": m1}, "Add other", added)
+	merge := tr.commit(map[string]string{"a.txt": "a\n", "160000 lib": m2, "160000 other": m1}, "Merge", bumped, side)
+
+	for _, tc := range []struct {
+		name        string
+		commit      *git.Commit
+		diffAgainst string
+		want        []string
+	}{
+		{"added", added, "", []string{"lib: submodule added at 1111111 by commit " + added.Hash.String()[:7]}},
+		{"bumped", bumped, "", []string{"lib: submodule moved from 1111111 to 2222222 by commit " + bumped.Hash.String()[:7]}},
+		{"removed", removed, "", []string{"lib: submodule removed by commit " + removed.Hash.String()[:7]}},
+		{"unchanged", unchanged, "", nil},
+		{"root", root, "", nil},
+		{"merge", merge, "", []string{"other: submodule added at 1111111 by commit " + merge.Hash.String()[:7]}},
+		// Compared to each parent, lib is reported once.
+		{"merge against all parents", merge, AllParents, []string{
+			"other: submodule added at 1111111 by commit " + merge.Hash.String()[:7],
+			"lib: submodule moved from 1111111 to 2222222 by commit " + merge.Hash.String()[:7],
+		}},
+	} {
+		vs, err := checkSubmodules(tc.commit, &Options{FlagSubmodules: true, DiffAgainst: tc.diffAgainst})
+		var got []string
+		for _, v := range vs {
+			got = append(got, v.Path+": "+v.Message)
+		}
+
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: checkSubmodules reported %q, %v, want %q", tc.name, got, err, tc.want)
+		}
+	}
+
+	if vs, err := checkSubmodules(added, &Options{}); err != nil || len(vs) != 0 {
+		t.Errorf("checkSubmodules without -flag-submodule-changes reported %+v, %v", vs, err)
+	}
+}
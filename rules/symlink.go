@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "symlink",
+		Severity:    report.Error,
+		Description: "symbolic links must point inside the repository and not to a sensitive file, with -check-symlinks",
+		File:        checkSymlink,
+	})
+}
+
+func checkSymlink(f *git.File, o *Options) ([]report.Violation, error) {
+	if !o.CheckSymlinks || f.Mode&os.ModeSymlink == 0 {
+		return nil, nil
+	}
+
+	var msg string
+	target, inside := symlinkPath(f.Name, f.Target)
+	switch {
+	case path.IsAbs(f.Target):
+		msg = fmt.Sprintf("symbolic link to the absolute path %s, outside the repository", f.Target)
+	case !inside:
+		msg = fmt.Sprintf("symbolic link to %s, outside the repository", f.Target)
+	case matchAny(o.SensitivePaths, target):
+		msg = fmt.Sprintf("symbolic link to the sensitive file %s", target)
+	default:
+		return nil, nil
+	}
+
+	return []report.Violation{{Path: f.Name, Message: msg}}, nil
+}
+
+// symlinkPath returns the path of the repository a symbolic link at name
+// points to, and false if the target is outside the repository.
+func symlinkPath(name, target string) (string, bool) {
+	if path.IsAbs(target) {
+		return target, false
+	}
+
+	p := path.Join(path.Dir(name), target)
+	return p, p != ".." && !strings.HasPrefix(p, "../")
+}
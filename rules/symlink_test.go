@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"os"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// testSymlink returns a symbolic link at name to the target.
+func testSymlink(t *testing.T, name, target string) *git.File {
+	return git.NewFile(name, os.ModeSymlink|0777, &testFile(t, name, target).Blob)
+}
+
+func TestCheckSymlink(t *testing.T) {
+	o := &Options{CheckSymlinks: true, SensitivePaths: []string{"secrets/**", ".env"}}
+	for _, tc := range []struct {
+		name, target string
+		want         string
+	}{
+		{"link", "target.txt", ""},
+		{"docs/link", "../README.md", ""},
+		{"a/b/link", "../../c/d.txt", ""},
+		{"a/link", "./b/../c", ""},
+		{"a/link", ".", ""},
+		{"a/link", "..", ""},
+		{"link", "..", "symbolic link to .., outside the repository"},
+		{"link", "../other/file", "symbolic link to ../other/file, outside the repository"},
+		{"a/b/link", "../../../etc/passwd", "symbolic link to ../../../etc/passwd, outside the repository"},
+		// Targets are normalised before being checked, only those leaving
+		// the repository once normalised are reported.
+		{"a/link", "b/../../file", ""},
+		{"a/link", "../a/../../file", "symbolic link to ../a/../../file, outside the repository"},
+		{"a/link", "../../a/file", "symbolic link to ../../a/file, outside the repository"},
+		{"link", "/etc/passwd", "symbolic link to the absolute path /etc/passwd, outside the repository"},
+		{"a/link", "/a/file", "symbolic link to the absolute path /a/file, outside the repository"},
+		{"link", "secrets/key.pem", "symbolic link to the sensitive file secrets/key.pem"},
+		{"config/env", "../.env", "symbolic link to the sensitive file .env"},
+	} {
+		vs, err := checkSymlink(testSymlink(t, tc.name, tc.target), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got string
+		if len(vs) > 0 {
+			got = vs[0].Message
+		}
+
+		if len(vs) > 1 || got != tc.want {
+			t.Errorf("checkSymlink(%s -> %s) = %+v, want %q", tc.name, tc.target, vs, tc.want)
+		}
+	}
+
+	// Regular files, whatever their content, and links without
+	// -check-symlinks aren't checked.
+	if vs, err := checkSymlink(testFile(t, "link", "/etc/passwd"), o); err != nil || len(vs) != 0 {
+		t.Errorf("checkSymlink of a regular file = %+v, %v", vs, err)
+	}
+
+	if vs, err := checkSymlink(testSymlink(t, "link", "/etc/passwd"), &Options{}); err != nil || len(vs) != 0 {
+		t.Errorf("checkSymlink without -check-symlinks = %+v, %v", vs, err)
+	}
+}
@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "todo-comment",
+		Severity:    report.Warning,
+		Description: "TODO, FIXME and HACK comments added by commits are pointed out with -flag-todos, and must reference an issue like TODO(#123) with -todo-require-issue",
+		Commit:      checkTodos,
+	})
+}
+
+// todoPattern matches a TODO, FIXME or HACK marker following a comment
+// start on the line, with its issue reference as second submatch if any.
+var todoPattern = regexp.MustCompile(`(?://|/\*|#|--|;|<!--|^\s*\*).*?\b(TODO|FIXME|HACK)\b(\(#\d+\))?`)
+
+func checkTodos(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.FlagTodos && !o.TodoRequireIssue {
+		return nil, nil
+	}
+
+	return checkAddedLines(c, o, nil, func(name string, l diff.Line) []report.Violation {
+		m := todoPattern.FindStringSubmatchIndex(l.Text)
+		if m == nil {
+			return nil
+		}
+
+		marker := l.Text[m[2]:m[3]]
+		msg := fmt.Sprintf("%s comment added", marker)
+		if o.TodoRequireIssue {
+			if m[4] >= 0 {
+				return nil
+			}
+
+			msg = fmt.Sprintf("%s comment added without an issue reference like %s(#123)", marker, marker)
+		}
+
+		return []report.Violation{{Path: name, Line: l.NewLine, Column: m[2] + 1, Message: msg}}
+	})
+}
@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCheckTodos(t *testing.T) {
+	const old = "// TODO: kept from the parent\nx := 1\n"
+	const added = old +
+		"// TODO: handle errors\n" +
+		"# FIXME later\n" +
+		"/* HACK around the bug */\n" +
+		" * TODO in a block comment\n" +
+		"-- TODO in SQL\n" +
+		"<!-- TODO in HTML -->\n" +
+		"y := 2 // TODO(#12) tidy\n" +
+		"// TODOS are not markers\n" +
+		"// a todo in lower case\n" +
+		"s := \"TODO in a string\"\n" +
+		"TODO outside a comment\n"
+
+	tr := newTestRepo(t)
+	parent := tr.commit(map[string]string{"a.go": old}, "Add a.go")
+	c := tr.commit(map[string]string{"a.go": added}, "Change a.go", parent)
+	for _, tc := range []struct {
+		name string
+		o    Options
+		want []string
+	}{
+		{"flagged", Options{FlagTodos: true}, []string{
+			"3:4: TODO comment added",
+			"4:3: FIXME comment added",
+			"5:4: HACK comment added",
+			"6:4: TODO comment added",
+			"7:4: TODO comment added",
+			"8:6: TODO comment added",
+			"9:11: TODO comment added",
+		}},
+		{"issue required", Options{TodoRequireIssue: true}, []string{
+			"3:4: TODO comment added without an issue reference like TODO(#123)",
+			"4:3: FIXME comment added without an issue reference like FIXME(#123)",
+			"5:4: HACK comment added without an issue reference like HACK(#123)",
+			"6:4: TODO comment added without an issue reference like TODO(#123)",
+			"7:4: TODO comment added without an issue reference like TODO(#123)",
+			"8:6: TODO comment added without an issue reference like TODO(#123)",
+		}},
+		{"off", Options{}, nil},
+	} {
+		vs, err := checkTodos(c, &tc.o)
+		var got []string
+		for _, v := range vs {
+			if v.Path != "a.go" {
+				t.Errorf("%s: violation on %s, want a.go", tc.name, v.Path)
+			}
+
+			got = append(got, fmt.Sprintf("%d:%d: %s", v.Line, v.Column, v.Message))
+		}
+
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: checkTodos reported %q, %v, want %q", tc.name, got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"unicode/utf8"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "utf8",
+		Severity:    report.Error,
+		Description: "text files must be valid UTF-8",
+		File:        checkUTF8,
+	})
+}
+
+// invalidUTF8 returns the offset of the first byte of data that isn't part of
+// a valid UTF-8 sequence, or -1.
+func invalidUTF8(data []byte) int {
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+
+		i += size
+	}
+
+	return -1
+}
+
+// trimPartialRune drops the start of a multibyte sequence ending data.
+func trimPartialRune(data []byte) []byte {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return data[:i]
+			}
+
+			break
+		}
+	}
+
+	return data
+}
+
+func checkUTF8(f *git.File, o *Options) ([]report.Violation, error) {
+	if !o.RequireUTF8 {
+		return nil, nil
+	}
+
+	if binary, err := IsBinary(f); err != nil || binary {
+		return nil, err
+	}
+
+	r, err := OpenFile(f, o.MaxReadSize)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// A sequence cut off by MaxReadSize isn't invalid.
+	if Truncated(f, o.MaxReadSize) {
+		data = trimPartialRune(data)
+	}
+
+	if utf8.Valid(data) {
+		return nil, nil
+	}
+
+	offset := invalidUTF8(data)
+	return []report.Violation{{
+		Path:    f.Name,
+		Line:    bytes.Count(data[:offset], []byte("\n")) + 1,
+		Column:  offset - bytes.LastIndexByte(data[:offset], '\n'),
+		Message: fmt.Sprintf("invalid UTF-8 at byte offset %d (0x%02x)", offset, data[offset]),
+	}}, nil
+}
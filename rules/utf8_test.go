@@ -0,0 +1,34 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckUTF8(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		max     int64
+		want    []report.Violation
+	}{
+		{"héllo, 世界\n", 0, nil},
+		{"ok\nhé\xe9llo\n", 0, []report.Violation{{Path: "f.txt", Line: 2, Column: 4, Message: "invalid UTF-8 at byte offset 6 (0xe9)"}}},
+		{"\xffstart\n", 0, []report.Violation{{Path: "f.txt", Line: 1, Column: 1, Message: "invalid UTF-8 at byte offset 0 (0xff)"}}},
+		{"end\xe4\xb8", 0, []report.Violation{{Path: "f.txt", Line: 1, Column: 4, Message: "invalid UTF-8 at byte offset 3 (0xe4)"}}},
+		{"a世界", 3, nil},
+		{"a\xff世界", 3, []report.Violation{{Path: "f.txt", Line: 1, Column: 2, Message: "invalid UTF-8 at byte offset 1 (0xff)"}}},
+		{"\x00\xff\xfe", 0, nil},
+	} {
+		o := &Options{RequireUTF8: true, MaxReadSize: tc.max}
+		vs, err := checkUTF8(testFile(t, "f.txt", tc.content), o)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkUTF8(%q, %d) = %+v, %v, want %+v", tc.content, tc.max, vs, err, tc.want)
+		}
+	}
+
+	if vs, err := checkUTF8(testFile(t, "f.txt", "\xff"), &Options{}); err != nil || vs != nil {
+		t.Errorf("checkUTF8 without -require-utf8 = %+v, %v", vs, err)
+	}
+}
@@ -0,0 +1,88 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"github.com/gunjan5/code-review-bot/report"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+func init() {
+	Register(&Func{
+		ID:          "trailing-whitespace",
+		Severity:    report.Error,
+		Description: "lines of text files must not end with spaces or tabs",
+		File:        checkTrailingWhitespace,
+	})
+
+	Register(&Func{
+		ID:          "changed-line-whitespace",
+		Severity:    report.Error,
+		Description: "lines added by commits must not end with spaces or tabs, mix them in their indentation or end the file without a newline, with -changed-line-whitespace",
+		Commit:      checkChangedLineWhitespace,
+	})
+}
+
+func checkTrailingWhitespace(f *git.File, o *Options) ([]report.Violation, error) {
+	if !o.NoTrailingWhitespace {
+		return nil, nil
+	}
+
+	if len(o.TrailingWhitespacePaths) > 0 && !matchAny(o.TrailingWhitespacePaths, f.Name) {
+		return nil, nil
+	}
+
+	if binary, err := IsBinary(f); err != nil || binary {
+		return nil, err
+	}
+
+	var vs []report.Violation
+	_, err := ScanLinesLimit(f, o.MaxReadSize, func(n int, l string) bool {
+		// The \r of a CRLF line ending isn't trailing whitespace.
+		l = strings.TrimSuffix(l, "\r")
+		trimmed := strings.TrimRight(l, " \t")
+		if len(trimmed) < len(l) {
+			vs = append(vs, report.Violation{
+				Path:    f.Name,
+				Line:    n,
+				Column:  len(trimmed) + 1,
+				Message: "trailing whitespace",
+			})
+		}
+
+		return true
+	})
+
+	return vs, err
+}
+
+func checkChangedLineWhitespace(c *git.Commit, o *Options) ([]report.Violation, error) {
+	if !o.ChangedLineWhitespace {
+		return nil, nil
+	}
+
+	return checkAddedLines(c, o, nil, lineWhitespace)
+}
+
+// lineWhitespace returns the whitespace problems of an added line: trailing
+// whitespace, a space before a tab in the indentation, as reported by git
+// diff --check, and a missing newline at the end of the file.
+func lineWhitespace(name string, l diff.Line) []report.Violation {
+	var vs []report.Violation
+	text := strings.TrimSuffix(l.Text, "\r")
+	if trimmed := strings.TrimRight(text, " \t"); len(trimmed) < len(text) {
+		vs = append(vs, report.Violation{Path: name, Line: l.NewLine, Column: len(trimmed) + 1, Message: "trailing whitespace added"})
+	}
+
+	indent := text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+	if i := strings.Index(indent, " \t"); i >= 0 {
+		vs = append(vs, report.Violation{Path: name, Line: l.NewLine, Column: i + 1, Message: "indentation mixes spaces and tabs, a space before a tab"})
+	}
+
+	if l.NoNewline {
+		vs = append(vs, report.Violation{Path: name, Line: l.NewLine, Message: "no newline at end of file"})
+	}
+
+	return vs
+}
@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+)
+
+func TestCheckTrailingWhitespace(t *testing.T) {
+	for _, tc := range []struct {
+		name, content string
+		paths         []string
+		want          []report.Violation
+	}{
+		{"f.txt", "a\nb\n", nil, nil},
+		{"f.txt", "a \nb\t\nc\n", nil, []report.Violation{
+			{Path: "f.txt", Line: 1, Column: 2, Message: "trailing whitespace"},
+			{Path: "f.txt", Line: 2, Column: 2, Message: "trailing whitespace"},
+		}},
+		{"f.txt", "a\r\nb \r\n", nil, []report.Violation{{Path: "f.txt", Line: 2, Column: 2, Message: "trailing whitespace"}}},
+		{"f.txt", "  \n", nil, []report.Violation{{Path: "f.txt", Line: 1, Column: 1, Message: "trailing whitespace"}}},
+		{"f.md", "a  \n", []string{"*.go"}, nil},
+		{"f.go", "a  \n", []string{"*.go"}, []report.Violation{{Path: "f.go", Line: 1, Column: 2, Message: "trailing whitespace"}}},
+		{"f.bin", "\x00 \n", nil, nil},
+	} {
+		o := &Options{NoTrailingWhitespace: true, TrailingWhitespacePaths: tc.paths}
+		vs, err := checkTrailingWhitespace(testFile(t, tc.name, tc.content), o)
+		if err != nil || !reflect.DeepEqual(vs, tc.want) {
+			t.Errorf("checkTrailingWhitespace(%s, %q) = %+v, %v, want %+v", tc.name, tc.content, vs, err, tc.want)
+		}
+	}
+
+	if vs, err := checkTrailingWhitespace(testFile(t, "f.txt", "a \n"), &Options{}); err != nil || vs != nil {
+		t.Errorf("checkTrailingWhitespace without -no-trailing-whitespace = %+v, %v", vs, err)
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/review"
+	"github.com/gunjan5/code-review-bot/rules"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// maxRequestSize is the size in bytes of the largest body of a request.
+const maxRequestSize = 1 << 20
+
+// maxLastReviews is the number of refs whose last review the server keeps,
+// the least recently reviewed being forgotten first and reviewed from
+// scratch the next time.
+const maxLastReviews = 1024
+
+// reviewRequest is the body accepted by POST /review.
+type reviewRequest struct {
+	Repo string `json:"repo"`
+	Ref  string `json:"ref"`
+	// Base reviews only the changes since the merge base with this branch,
+	// as for a pull request whose head is Ref.
+	Base string `json:"base"`
+	// Depth overrides -clone-depth for a Repo URL.
+	Depth int `json:"depth"`
+}
+
+// reviewResponse is the body returned by POST /review.
+type reviewResponse struct {
+	Repo string `json:"repo"`
+	Ref  string `json:"ref,omitempty"`
+	// Rewritten is true when the history of Ref was rewritten since its
+	// last review, e.g. by a force-push, Notes explaining it.
+	Rewritten  bool               `json:"rewritten,omitempty"`
+	Notes      []string           `json:"notes,omitempty"`
+	Failing    bool               `json:"failing"`
+	Violations []report.Violation `json:"violations"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type server struct {
+	opts     *review.Options
+	baseline report.Baseline
+	// allow are the globs of the repositories that can be reviewed, any
+	// of them if empty, see allowed.
+	allow []string
+
+	mu sync.Mutex
+	// maxLast is the number of last reviews kept, maxLastReviews by
+	// default.
+	maxLast int
+	// last holds the last reviews of the repositories and refs, the most
+	// recent first, indexed by reviewKey in byKey.
+	last  *list.List
+	byKey map[string]*list.Element
+}
+
+// newServer returns a server reviewing with the options o, the baseline b
+// and the allowed repositories.
+func newServer(o *review.Options, b report.Baseline, allow []string) *server {
+	return &server{
+		opts:     o,
+		baseline: b,
+		allow:    allow,
+		maxLast:  maxLastReviews,
+		last:     list.New(),
+		byKey:    make(map[string]*list.Element),
+	}
+}
+
+// lastReview is the last review of a ref by the server.
+type lastReview struct {
+	key  string
+	head core.Hash
+	// commits are the violations of the commits reviewed so far. Once the
+	// ref moves forward, only its new commits are reviewed, these
+	// violations being reported again along with theirs.
+	commits []report.Violation
+}
+
+// serve runs the review API on addr until SIGINT or SIGTERM is received, then
+// stops accepting connections, closes the idle ones and waits for the reviews
+// in progress.
+func serve(addr string, o *review.Options, b report.Baseline) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s := newServer(o, b, serveAllow)
+	srv := &http.Server{Handler: s.handler()}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(l)
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	log.Printf("serving reviews on %s", l.Addr())
+	select {
+	case err := <-done:
+		return err
+	case <-sig:
+	}
+
+	log.Printf("shutting down, waiting for reviews in progress")
+	if err := srv.Shutdown(context.Background()); err != nil {
+		return err
+	}
+
+	if err := <-done; err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/review", s.handleReview)
+	mux.HandleFunc("/healthz", handleHealthz)
+
+	return mux
+}
+
+func (s *server) handleReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"method not allowed"})
+		return
+	}
+
+	var req reviewRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"invalid request: " + err.Error()})
+		return
+	}
+
+	if req.Repo == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"invalid request: repo is required"})
+		return
+	}
+
+	if req.Depth < 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"invalid request: depth must not be negative"})
+		return
+	}
+
+	if !allowed(s.allow, req.Repo) {
+		writeJSON(w, http.StatusForbidden, errorResponse{fmt.Sprintf("repository %s not allowed by -serve-allow", req.Repo)})
+		return
+	}
+
+	o := *s.opts
+	o.Ref = req.Ref
+	o.Commit = ""
+	if req.Base != "" {
+		o.BaseBranch = req.Base
+		o.SinceCommit = ""
+	}
+
+	depth := *cloneDepth
+	if req.Depth > 0 {
+		depth = req.Depth
+	}
+
+	resp := reviewResponse{Repo: req.Repo, Ref: req.Ref}
+	res, err := s.reviewRef(&req, depth, &o, &resp)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{err.Error()})
+		return
+	}
+
+	c := &report.Collector{}
+	c.Add(res.Violations...)
+	resp.Violations = s.baseline.Filter(c.Violations())
+	if resp.Violations == nil {
+		resp.Violations = []report.Violation{}
+	}
+
+	resp.Failing = report.Failing(resp.Violations)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// allowed returns true if the repository can be reviewed: any repository if
+// there are no globs, otherwise those whose URL or absolute path matches one
+// of the globs, as -path does.
+func allowed(globs []string, repo string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+
+	if !isRemote(repo) {
+		abs, err := filepath.Abs(repo)
+		if err != nil {
+			return false
+		}
+
+		repo = filepath.ToSlash(abs)
+	}
+
+	for _, glob := range globs {
+		if rules.MatchPath(glob, repo) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reviewRef reviews the ref of the request. If the ref was reviewed before
+// and only moved forward since, only its new commits are reviewed, the
+// violations of the commits already reviewed being added to the result. If
+// its history was rewritten, as by a force-push, the previous review is
+// dropped and the ref reviewed from scratch, which resp notes. The history
+// of shallow clones being incomplete, their rewrites go unnoticed.
+func (s *server) reviewRef(req *reviewRequest, depth int, o *review.Options, resp *reviewResponse) (*review.Result, error) {
+	repo, err := openRepo(req.Repo, depth, o)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := o.Ref
+	if ref == "" {
+		ref = string(core.HEAD)
+	}
+
+	head, err := review.ResolveCommit(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	key := reviewKey(req)
+	prev := s.lastReview(key)
+	if depth > 0 && isRemote(req.Repo) {
+		prev = nil
+	}
+
+	var kept []report.Violation
+	if prev != nil {
+		forward, err := review.IsAncestor(repo, prev.head, head.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !forward:
+			resp.Rewritten = true
+			resp.Notes = append(resp.Notes, fmt.Sprintf("the history of %s was rewritten since its last review at %s, e.g. by a force-push: the findings of that review were dropped and %s was reviewed from scratch", ref, prev.head.String()[:7], ref))
+			log.Printf("%s %s: history rewritten since %s, reviewing from scratch", req.Repo, ref, prev.head)
+		case o.BaseBranch == "":
+			o.SinceCommit = prev.head.String()
+			kept = prev.commits
+		}
+	}
+
+	res, err := review.Run(repo, o)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Violations = append(res.Violations, kept...)
+	last := &lastReview{key: key, head: head.Hash}
+	for _, v := range res.Violations {
+		if v.Commit != "" {
+			last.commits = append(last.commits, v)
+		}
+	}
+
+	s.setLastReview(last)
+	return res, nil
+}
+
+// reviewKey identifies the reviews of the same ref of a repository, against
+// the same base.
+func reviewKey(req *reviewRequest) string {
+	return req.Repo + "\x00" + req.Ref + "\x00" + req.Base
+}
+
+// lastReview returns the last review with the key, nil if there is none.
+func (s *server) lastReview(key string) *lastReview {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.byKey[key]
+	if !ok {
+		return nil
+	}
+
+	s.last.MoveToFront(e)
+	return e.Value.(*lastReview)
+}
+
+// setLastReview keeps the review as the last one of its key, forgetting the
+// least recently used reviews beyond maxLast.
+func (s *server) setLastReview(last *lastReview) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.byKey[last.key]; ok {
+		s.last.Remove(e)
+	}
+
+	s.byKey[last.key] = s.last.PushFront(last)
+	for s.last.Len() > s.maxLast {
+		old := s.last.Remove(s.last.Back()).(*lastReview)
+		delete(s.byKey, old.key)
+	}
+}
+
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"method not allowed"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("writing response: %s", err)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/review"
+)
+
+// post sends the body to the path of the server and decodes the response
+// into v, returning its status.
+func post(t *testing.T, s *httptest.Server, path, body string, v interface{}) int {
+	resp, err := http.Post(s.URL+path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("POST %s %s: Content-Type %q", path, body, ct)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Errorf("POST %s %s: %s", path, body, err)
+	}
+
+	return resp.StatusCode
+}
+
+func TestServeReview(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	first := r.commit(map[string]string{"a.txt": "1\n"}, "Add a")
+	second := r.commit(map[string]string{"b.txt": "2\n"}, "Add b, with a subject too long")
+
+	o := &review.Options{}
+	o.MaxSubjectLength = 20
+	srv := newServer(o, nil, []string{r.dir})
+	s := httptest.NewServer(srv.handler())
+	defer s.Close()
+
+	body, err := json.Marshal(reviewRequest{Repo: r.dir, Ref: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resp reviewResponse
+	if status := post(t, s, "/review", string(body), &resp); status != http.StatusOK {
+		t.Fatalf("POST /review = %d, want 200", status)
+	}
+
+	want := report.Violation{
+		Rule:     "subject-length",
+		Severity: report.Warning,
+		Commit:   second,
+		Message:  "subject is 30 characters long, the limit is 20",
+	}
+
+	if resp.Repo != r.dir || resp.Ref != "main" || resp.Failing || resp.Rewritten || len(resp.Violations) != 1 {
+		t.Fatalf("POST /review = %+v, want the violation %+v", resp, want)
+	}
+
+	if v := resp.Violations[0]; v.Rule != want.Rule || v.Severity != want.Severity || v.Commit != want.Commit || v.Message != want.Message {
+		t.Errorf("violation %+v, want %+v", v, want)
+	}
+
+	// The ref moved forward, only its new commit is reviewed, with a
+	// higher limit, the violation of the previous one being kept.
+	o.MaxSubjectLength = 35
+	subject := "Add c, with a subject longer than the new limit"
+	third := r.commit(map[string]string{"c.txt": "3\n"}, subject)
+	resp = reviewResponse{}
+	if status := post(t, s, "/review", string(body), &resp); status != http.StatusOK || resp.Rewritten {
+		t.Fatalf("POST /review after a new commit = %d, %+v", status, resp)
+	}
+
+	wantViolations := map[string]string{
+		second: "subject is 30 characters long, the limit is 20",
+		third:  fmt.Sprintf("subject is %d characters long, the limit is 35", len(subject)),
+	}
+
+	got := make(map[string]string)
+	for _, v := range resp.Violations {
+		got[v.Commit] = v.Message
+	}
+
+	if !reflect.DeepEqual(got, wantViolations) {
+		t.Errorf("POST /review after a new commit: violations %+v, want the messages %q by commit", resp.Violations, wantViolations)
+	}
+
+	// The history rewritten, the violations of the commits dropped are gone.
+	r.git("reset", "-q", "--hard", first)
+	resp = reviewResponse{}
+	if status := post(t, s, "/review", string(body), &resp); status != http.StatusOK || !resp.Rewritten || len(resp.Violations) != 0 {
+		t.Errorf("POST /review after a rewrite = %d, %+v, want no violations", status, resp)
+	}
+
+	if len(resp.Notes) != 1 || !strings.Contains(resp.Notes[0], "rewritten since its last review at "+third[:7]) {
+		t.Errorf("POST /review after a rewrite: notes %q, want the rewrite since %s", resp.Notes, third[:7])
+	}
+
+	// The review after the rewrite is the last one.
+	resp = reviewResponse{}
+	if status := post(t, s, "/review", string(body), &resp); status != http.StatusOK || resp.Rewritten || len(resp.Notes) != 0 {
+		t.Errorf("POST /review again = %d, %+v, want no rewrite", status, resp)
+	}
+}
+
+func TestServerLastReviews(t *testing.T) {
+	srv := newServer(&review.Options{}, nil, nil)
+	srv.maxLast = 2
+	for _, key := range []string{"a", "b", "a"} {
+		srv.setLastReview(&lastReview{key: key})
+	}
+
+	// a was reviewed again, b is the least recently used and c replaces it,
+	// then a looked up is the most recent again and d replaces c.
+	srv.setLastReview(&lastReview{key: "c"})
+	if srv.lastReview("b") != nil || srv.lastReview("a") == nil {
+		t.Errorf("last reviews %q, want a and c", keys(srv))
+	}
+
+	srv.setLastReview(&lastReview{key: "d"})
+	if want := []string{"d", "a"}; !reflect.DeepEqual(keys(srv), want) {
+		t.Errorf("last reviews %q, want %q", keys(srv), want)
+	}
+
+	if len(srv.byKey) != 2 {
+		t.Errorf("%d last reviews indexed, want 2", len(srv.byKey))
+	}
+}
+
+// keys returns the keys of the last reviews of the server, the most recent
+// first.
+func keys(srv *server) []string {
+	var keys []string
+	for e := srv.last.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*lastReview).key)
+	}
+
+	return keys
+}
+
+func TestServeReviewErrors(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	r.commit(map[string]string{"a.txt": "1\n"}, "Add a")
+
+	srv := newServer(&review.Options{}, nil, []string{r.dir})
+	s := httptest.NewServer(srv.handler())
+	defer s.Close()
+
+	for _, tc := range []struct {
+		body   string
+		status int
+		err    string
+	}{
+		{`{"repo": `, http.StatusBadRequest, "invalid request"},
+		{`{"ref": "main"}`, http.StatusBadRequest, "repo is required"},
+		{`{"repo": "` + r.dir + `", "depth": -1}`, http.StatusBadRequest, "depth must not be negative"},
+		{`{"repo": "` + r.dir + `", "ref": "` + strings.Repeat("x", maxRequestSize) + `"}`, http.StatusBadRequest, "too large"},
+		{`{"repo": "/etc"}`, http.StatusForbidden, "not allowed"},
+		{`{"repo": "` + r.dir + `/../` + "other" + `"}`, http.StatusForbidden, "not allowed"},
+		{`{"repo": "` + r.dir + `", "ref": "missing"}`, http.StatusUnprocessableEntity, "missing"},
+	} {
+		var resp errorResponse
+		if status := post(t, s, "/review", tc.body, &resp); status != tc.status || !strings.Contains(resp.Error, tc.err) {
+			t.Errorf("POST /review %.40s = %d %q, want %d %q", tc.body, status, resp.Error, tc.status, tc.err)
+		}
+	}
+
+	resp, err := http.Get(s.URL + "/review")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != "POST" {
+		t.Errorf("GET /review = %d, Allow %q, want 405", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	globs := []string{"/srv/checkouts/*", "https://github.com/org/**"}
+	for _, tc := range []struct {
+		repo string
+		ok   bool
+	}{
+		{"/srv/checkouts/project", true},
+		{"/srv/checkouts/project/", true},
+		{"/srv/checkouts/project/sub", false},
+		{"/srv/checkouts/../../etc", false},
+		{"/srv/other", false},
+		{"https://github.com/org/repo.git", true},
+		{"https://github.com/other/repo.git", false},
+		{"git@github.com:org/repo.git", false},
+	} {
+		if ok := allowed(globs, tc.repo); ok != tc.ok {
+			t.Errorf("allowed(%q) = %v, want %v", tc.repo, ok, tc.ok)
+		}
+	}
+
+	if !allowed(nil, "/etc") {
+		t.Error("allowed(nil, /etc) = false, want any repository allowed")
+	}
+}
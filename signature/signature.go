@@ -0,0 +1,181 @@
+// Package signature verifies the OpenPGP signatures of commits against a
+// keyring, with gpgv as git does.
+package signature
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// DefaultProgram is the program verifying the signatures, used when
+// Verifier.Program is empty.
+const DefaultProgram = "gpgv"
+
+// Status is the outcome of the verification of a signature, as shown by the
+// %G? format of git log.
+type Status string
+
+// Statuses of the verified signatures.
+const (
+	// Good is a valid signature made by a key of the keyring.
+	Good Status = "good"
+	// Bad is a signature that doesn't match the signed content.
+	Bad Status = "bad"
+	// UnknownKey is a signature made by a key missing from the keyring.
+	UnknownKey Status = "unknown-key"
+	// Expired is a valid signature that expired, or made by an expired key.
+	Expired Status = "expired"
+	// Revoked is a valid signature made by a revoked key.
+	Revoked Status = "revoked"
+	// Unsigned is an object without signature.
+	Unsigned Status = "unsigned"
+)
+
+// Result is the verification of the signature of an object.
+type Result struct {
+	Status Status
+	// Key is the id of the signing key, and Signer its primary user id,
+	// if known.
+	Key    string
+	Signer string
+	// Fingerprint is the fingerprint of the signing key, set for valid
+	// signatures.
+	Fingerprint string
+}
+
+// Verifier verifies signatures against the keys of a keyring.
+type Verifier struct {
+	// Keyring is the file holding the trusted public keys, as exported by
+	// gpg --export.
+	Keyring string
+	// Program is the gpgv compatible program verifying the signatures,
+	// DefaultProgram if empty.
+	Program string
+}
+
+// Commit verifies the signature of the commit c.
+func (v *Verifier) Commit(c *git.Commit) (*Result, error) {
+	return v.Verify(c.Payload(), c.PGPSignature)
+}
+
+// Verify verifies the armored signature sig of payload. Errors are only
+// returned if the verification can't be run, failed verifications are
+// given by the status of the result.
+func (v *Verifier) Verify(payload []byte, sig string) (*Result, error) {
+	if sig == "" {
+		return &Result{Status: Unsigned}, nil
+	}
+
+	keyring, err := filepath.Abs(v.Keyring)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(keyring); err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile("", "code-review-bot-sig-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(sig)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	program := v.Program
+	if program == "" {
+		program = DefaultProgram
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(program, "--status-fd", "1", "--keyring", keyring, f.Name(), "-")
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// gpgv exits with an error for bad or unverifiable signatures, the
+	// status lines tell them apart from failures to run it.
+	runErr := cmd.Run()
+	res := parseStatus(stdout.Bytes())
+	if res.Status == "" {
+		if runErr == nil {
+			runErr = errors.New("no signature status")
+		}
+
+		return nil, fmt.Errorf("%s: %s%s", program, runErr, diagnostic(&stderr))
+	}
+
+	return res, nil
+}
+
+// parseStatus returns the result given by the status lines printed by gpgv,
+// with an empty status if there is none.
+func parseStatus(out []byte) *Result {
+	res := &Result{}
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		fields := strings.SplitN(strings.TrimPrefix(s.Text(), "[GNUPG:] "), " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "GOODSIG":
+			res.signed(Good, fields)
+		case "BADSIG":
+			res.signed(Bad, fields)
+		case "EXPSIG", "EXPKEYSIG":
+			res.signed(Expired, fields)
+		case "REVKEYSIG":
+			res.signed(Revoked, fields)
+		case "ERRSIG", "NO_PUBKEY":
+			if res.Status == "" {
+				res.Status = UnknownKey
+			}
+
+			res.Key = fields[1]
+		case "VALIDSIG":
+			res.Fingerprint = fields[1]
+		}
+	}
+
+	return res
+}
+
+// signed sets the status of the result and the key and signer of the
+// fields of a status line.
+func (res *Result) signed(status Status, fields []string) {
+	res.Status = status
+	res.Key = fields[1]
+	if len(fields) > 2 {
+		res.Signer = fields[2]
+	}
+}
+
+// diagnostic returns the first line of the error output of gpgv, prefixed
+// with a colon, or an empty string.
+func diagnostic(stderr *bytes.Buffer) string {
+	line := strings.TrimSpace(strings.SplitN(stderr.String(), "\n", 2)[0])
+	if line == "" {
+		return ""
+	}
+
+	return ": " + line
+}
@@ -0,0 +1,147 @@
+package signature
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/internal/testrepo"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+func TestParseStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		out  string
+		want Result
+	}{{
+		name: "good",
+		out: "[GNUPG:] NEWSIG\n" +
+			"[GNUPG:] KEY_CONSIDERED 0123456789ABCDEF0123456789ABCDEF01234567 0\n" +
+			"[GNUPG:] GOODSIG 89ABCDEF01234567 Jane Doe <jane@example.com>\n" +
+			"[GNUPG:] VALIDSIG 0123456789ABCDEF0123456789ABCDEF01234567 2017-07-14 1500000000 0 4 0 1 8 00 0123456789ABCDEF0123456789ABCDEF01234567\n",
+		want: Result{
+			Status:      Good,
+			Key:         "89ABCDEF01234567",
+			Signer:      "Jane Doe <jane@example.com>",
+			Fingerprint: "0123456789ABCDEF0123456789ABCDEF01234567",
+		},
+	}, {
+		name: "bad",
+		out:  "[GNUPG:] NEWSIG\n[GNUPG:] BADSIG 89ABCDEF01234567 Jane Doe <jane@example.com>\n",
+		want: Result{Status: Bad, Key: "89ABCDEF01234567", Signer: "Jane Doe <jane@example.com>"},
+	}, {
+		name: "expired signature",
+		out:  "[GNUPG:] EXPSIG 89ABCDEF01234567 Jane Doe <jane@example.com>\n",
+		want: Result{Status: Expired, Key: "89ABCDEF01234567", Signer: "Jane Doe <jane@example.com>"},
+	}, {
+		name: "expired key",
+		out:  "[GNUPG:] KEYEXPIRED 1500000000\n[GNUPG:] EXPKEYSIG 89ABCDEF01234567 Jane Doe <jane@example.com>\n",
+		want: Result{Status: Expired, Key: "89ABCDEF01234567", Signer: "Jane Doe <jane@example.com>"},
+	}, {
+		name: "revoked key",
+		out:  "[GNUPG:] REVKEYSIG 89ABCDEF01234567 Jane Doe <jane@example.com>\n",
+		want: Result{Status: Revoked, Key: "89ABCDEF01234567", Signer: "Jane Doe <jane@example.com>"},
+	}, {
+		name: "missing key",
+		out:  "[GNUPG:] NEWSIG\n[GNUPG:] ERRSIG 89ABCDEF01234567 1 8 00 1500000000 9 -\n[GNUPG:] NO_PUBKEY 89ABCDEF01234567\n",
+		want: Result{Status: UnknownKey, Key: "89ABCDEF01234567"},
+	}, {
+		name: "signer without user id",
+		out:  "[GNUPG:] GOODSIG 89ABCDEF01234567\n",
+		want: Result{Status: Good, Key: "89ABCDEF01234567"},
+	}, {
+		name: "lines without status",
+		out:  "gpgv: Signature made Fri Jul 14 02:40:00 2017 UTC\n[GNUPG:] NEWSIG\n",
+		want: Result{},
+	}, {
+		name: "empty",
+		out:  "",
+		want: Result{},
+	}} {
+		if got := parseStatus([]byte(tc.out)); *got != tc.want {
+			t.Errorf("%s: parseStatus(%q) = %+v, want %+v", tc.name, tc.out, *got, tc.want)
+		}
+	}
+}
+
+func TestVerifyUnsigned(t *testing.T) {
+	// The keyring and program aren't used without a signature.
+	v := &Verifier{Keyring: "missing.gpg", Program: "missing-gpgv"}
+	res, err := v.Verify([]byte("payload"), "")
+	if err != nil || res.Status != Unsigned {
+		t.Errorf("Verify(unsigned) = %+v, %v, want status %s", res, err, Unsigned)
+	}
+}
+
+func TestVerifyMissingKeyring(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signature-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	v := &Verifier{Keyring: filepath.Join(dir, "missing.gpg")}
+	if res, err := v.Verify([]byte("payload"), testSignature); err == nil {
+		t.Errorf("Verify with a missing keyring = %+v, want an error", res)
+	}
+}
+
+const testSignature = "-----BEGIN PGP SIGNATURE-----\n" +
+	"\n" +
+	"iQEzBAABCAAdFiEEASNFZ4mrze8BI0VniavN7wEjRWcFAllo7/AACgkQiavN7wEj\n" +
+	"RWd0Cgf/cXVpY2sgYnJvd24gZm94IGp1bXBzIG92ZXIgdGhlIGxhenkgZG9n\n" +
+	"=AbCd\n" +
+	"-----END PGP SIGNATURE-----\n"
+
+func TestCommitPayload(t *testing.T) {
+	tr := testrepo.New(t)
+	tree := tr.WriteTree(map[string]string{"a": "a"})
+	payload := "tree " + tree.String() + "\n" +
+		"author A <a@example.com> 1500000000 +0000\n" +
+		"committer A <a@example.com> 1500000000 +0000\n" +
+		"\n" +
+		"Signed\n\nwith a body\n"
+
+	// git writes the signature as the last header, its lines after the
+	// first indented by a space, the blank one included.
+	signed := "tree " + tree.String() + "\n" +
+		"author A <a@example.com> 1500000000 +0000\n" +
+		"committer A <a@example.com> 1500000000 +0000\n" +
+		"gpgsig -----BEGIN PGP SIGNATURE-----\n" +
+		" \n" +
+		" iQEzBAABCAAdFiEEASNFZ4mrze8BI0VniavN7wEjRWcFAllo7/AACgkQiavN7wEj\n" +
+		" RWd0Cgf/cXVpY2sgYnJvd24gZm94IGp1bXBzIG92ZXIgdGhlIGxhenkgZG9n\n" +
+		" =AbCd\n" +
+		" -----END PGP SIGNATURE-----\n" +
+		"\n" +
+		"Signed\n\nwith a body\n"
+
+	for _, tc := range []struct {
+		name    string
+		object  string
+		sig     string
+		payload []byte
+	}{
+		{"signed", signed, testSignature, []byte(payload)},
+		{"unsigned", payload, "", nil},
+	} {
+		c, err := tr.Commit(tr.Write(core.CommitObject, []byte(tc.object)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.PGPSignature != tc.sig {
+			t.Errorf("%s: PGPSignature = %q, want %q", tc.name, c.PGPSignature, tc.sig)
+		}
+
+		if got := c.Payload(); string(got) != string(tc.payload) || (got == nil) != (tc.payload == nil) {
+			t.Errorf("%s: Payload() = %q, want %q", tc.name, got, tc.payload)
+		}
+
+		if c.Message != "Signed\n\nwith a body\n" {
+			t.Errorf("%s: Message = %q, want the message without the signature", tc.name, c.Message)
+		}
+	}
+}
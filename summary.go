@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/review"
+)
+
+// summary is the outcome of a run written by -summary-json for the tools
+// orchestrating the bot, whatever the format of the violations.
+type summary struct {
+	// Ref is the configured reference, Commit the hash it resolved to or
+	// the one of the commit given by -commit. Commit is left out when
+	// several repositories are reviewed.
+	Ref    string `json:"ref"`
+	Commit string `json:"commit,omitempty"`
+	// Violations counts the reported violations by severity, every
+	// severity being present.
+	Violations map[string]int `json:"violations"`
+	Total      int            `json:"total"`
+	Failing    bool           `json:"failing"`
+	Commits    int            `json:"commits"`
+	Files      int            `json:"files"`
+	Duration   float64        `json:"duration_seconds"`
+}
+
+func newSummary(o *review.Options, res *review.Result, vs []report.Violation, d time.Duration) *summary {
+	s := &summary{
+		Ref:    o.Ref,
+		Commit: res.Commit,
+		Violations: map[string]int{
+			report.Info.String():    0,
+			report.Warning.String(): 0,
+			report.Error.String():   0,
+		},
+		Total:    len(vs),
+		Failing:  report.Failing(vs),
+		Commits:  res.Commits,
+		Files:    len(res.Paths),
+		Duration: d.Seconds(),
+	}
+
+	if s.Ref == "" {
+		s.Ref = "HEAD"
+	}
+
+	for _, v := range vs {
+		s.Violations[v.Severity.String()]++
+	}
+
+	return s
+}
+
+// writeSummary writes the summary as JSON to the file at path, or to stderr
+// if path is -.
+func writeSummary(path string, s *summary) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+	if path == "-" {
+		_, err := os.Stderr.Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gunjan5/code-review-bot/report"
+	"github.com/gunjan5/code-review-bot/review"
+)
+
+func TestWriteSummary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	res := &review.Result{Commit: "0be77e079c17b4c95ddf68762338071427f66360", Commits: 3, Paths: []string{"a", "b"}}
+	for _, tc := range []struct {
+		name string
+		ref  string
+		vs   []report.Violation
+		want map[string]interface{}
+	}{
+		{
+			name: "clean",
+			want: map[string]interface{}{
+				"ref":              "HEAD",
+				"commit":           res.Commit,
+				"violations":       map[string]interface{}{"info": 0.0, "warning": 0.0, "error": 0.0},
+				"total":            0.0,
+				"failing":          false,
+				"commits":          3.0,
+				"files":            2.0,
+				"duration_seconds": 1.5,
+			},
+		},
+		{
+			name: "failing",
+			ref:  "main",
+			vs:   []report.Violation{{Severity: report.Warning}, {Severity: report.Error}, {Severity: report.Warning}},
+			want: map[string]interface{}{
+				"ref":              "main",
+				"commit":           res.Commit,
+				"violations":       map[string]interface{}{"info": 0.0, "warning": 2.0, "error": 1.0},
+				"total":            3.0,
+				"failing":          true,
+				"commits":          3.0,
+				"files":            2.0,
+				"duration_seconds": 1.5,
+			},
+		},
+	} {
+		path := filepath.Join(dir, tc.name+".json")
+		s := newSummary(&review.Options{Ref: tc.ref}, res, tc.vs, 1500*time.Millisecond)
+		if err := writeSummary(path, s); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got map[string]interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: summary %s, want %v", tc.name, data, tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gunjan5/code-review-bot/review"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// fileSize is the size in bytes of the blob of a file.
+type fileSize struct {
+	Path string
+	Size int64
+}
+
+// bySize sorts the files from the largest, then by path.
+type bySize []fileSize
+
+func (s bySize) Len() int      { return len(s) }
+func (s bySize) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s bySize) Less(i, j int) bool {
+	if s[i].Size != s[j].Size {
+		return s[i].Size > s[j].Size
+	}
+
+	return s[i].Path < s[j].Path
+}
+
+// writeTopFiles writes the n largest files of the reviewed tree with their
+// size, from the largest.
+func writeTopFiles(w io.Writer, repo *git.Repository, n int, o *review.Options) error {
+	c, err := review.ResolveCommit(repo, o.Ref)
+	if err != nil {
+		return err
+	}
+
+	t, err := c.Tree()
+	if err != nil {
+		return err
+	}
+
+	var files []fileSize
+	err = t.Files().ForEach(func(f *git.File) error {
+		files = append(files, fileSize{Path: f.Name, Size: f.Size})
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	sort.Sort(bySize(files))
+	if len(files) > n {
+		files = files[:n]
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SIZE\tPATH")
+	for _, f := range files {
+		fmt.Fprintf(tw, "%d\t%s\n", f.Size, f.Path)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/review"
+)
+
+func TestWriteTopFiles(t *testing.T) {
+	r, cleanup := newGitRepo(t)
+	defer cleanup()
+	r.commit(map[string]string{
+		"small.txt":      "a\n",
+		"big.bin":        strings.Repeat("x", 1000),
+		"dir/medium.txt": strings.Repeat("y", 100),
+		// b.txt and a.txt tie, and are ranked by path.
+		"b.txt":     strings.Repeat("z", 50),
+		"a.txt":     strings.Repeat("w", 50),
+		"empty.txt": "",
+	}, "Add the files")
+
+	repo, err := openRepo(r.dir, 0, &review.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		n    int
+		want string
+	}{
+		{1, "SIZE  PATH\n1000  big.bin\n"},
+		{3, "SIZE  PATH\n1000  big.bin\n100   dir/medium.txt\n50    a.txt\n"},
+		{4, "SIZE  PATH\n1000  big.bin\n100   dir/medium.txt\n50    a.txt\n50    b.txt\n"},
+		{10, "SIZE  PATH\n1000  big.bin\n100   dir/medium.txt\n50    a.txt\n50    b.txt\n2     small.txt\n0     empty.txt\n"},
+	} {
+		var buf bytes.Buffer
+		if err := writeTopFiles(&buf, repo, tc.n, &review.Options{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); got != tc.want {
+			t.Errorf("writeTopFiles(%d) =\n%s\nwant\n%s", tc.n, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Package vet runs the analyzers of go vet on the packages of a commit
+// checked out on disk, see package worktree.
+//
+// go vet is run as a command rather than its analyzers linked in: they type
+// check the packages with the export data of the reviewed tree's toolchain,
+// which the go command builds, and the bot would otherwise depend on
+// golang.org/x/tools and vet with its own Go version. The go command must be
+// installed where -go-vet is used, Run failing without it.
+package vet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/worktree"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// DefaultProgram is the go command running go vet, used when Runner.Program
+// is empty.
+const DefaultProgram = "go"
+
+// TypeCheck is the analyzer of the diagnostics reporting the packages that
+// don't type check, which go vet can't analyze.
+const TypeCheck = "typecheck"
+
+// Diagnostic is a problem found by an analyzer.
+type Diagnostic struct {
+	Analyzer string
+	// Path is the slash separated path of the file in the commit tree, or
+	// of the directory of the package for the errors of a whole package,
+	// such as files of several packages, Line being 0 then.
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Runner runs go vet.
+type Runner struct {
+	// Program is the go command, DefaultProgram if empty.
+	Program string
+	// Analyzers are the names of the analyzers run, such as printf or
+	// copylocks, the default suite of go vet if empty.
+	Analyzers []string
+}
+
+// Commit checks out the commit in a temporary directory and runs go vet on
+// its packages in the directories dirs, relative to the root of the tree.
+func (r *Runner) Commit(c *git.Commit, dirs []string) ([]Diagnostic, error) {
+	dir, cleanup, err := worktree.Temp(c)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	return r.Run(dir, dirs)
+}
+
+// Run runs go vet on the packages in the directories dirs of the tree
+// checked out at root. Trees without go.mod are vetted in GOPATH mode, only
+// their imports of the standard library resolving. Errors are only returned
+// if go vet can't be run, the packages that don't type check or can't be
+// loaded being reported as TypeCheck diagnostics.
+func (r *Runner) Run(root string, dirs []string) ([]Diagnostic, error) {
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+
+	program := r.Program
+	if program == "" {
+		program = DefaultProgram
+	}
+
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return nil, fmt.Errorf("go vet needs the go command: %s", err)
+	}
+
+	args := []string{"vet", "-json"}
+	for _, a := range r.Analyzers {
+		args = append(args, "-"+a)
+	}
+
+	for _, d := range dirs {
+		args = append(args, "./"+filepath.ToSlash(d))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Dir = root
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); os.IsNotExist(err) {
+		cmd.Env = append(cmd.Env, "GO111MODULE=off")
+	}
+
+	// go vet exits with an error for the packages that don't type check,
+	// their diagnostics tell them apart from failures to run it.
+	runErr := cmd.Run()
+	var ds []Diagnostic
+	for _, out := range [][]byte{stdout.Bytes(), stderr.Bytes()} {
+		pds, err := parseOutput(root, out)
+		if err != nil {
+			return nil, fmt.Errorf("%s vet: %s", program, err)
+		}
+
+		ds = append(ds, pds...)
+	}
+
+	sort.Sort(byPosition(ds))
+
+	if runErr != nil && len(ds) == 0 {
+		return nil, fmt.Errorf("%s vet: %s%s", program, runErr, diagnostic(&stderr))
+	}
+
+	return ds, nil
+}
+
+// jsonDiagnostic is a diagnostic in the -json output of go vet.
+type jsonDiagnostic struct {
+	Posn    string `json:"posn"`
+	Message string `json:"message"`
+}
+
+// parseOutput returns the diagnostics of an output of go vet -json: the JSON
+// objects mapping the packages to their analyzers and diagnostics, and the
+// "file:line:col: message" lines of the syntax and type errors, prefixed by
+// "vet: " for the latter, and the "message in dir" lines of the packages
+// that can't be loaded. The files are made relative to root.
+func parseOutput(root string, out []byte) ([]Diagnostic, error) {
+	var ds []Diagnostic
+	var object []string
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		l := s.Text()
+		switch {
+		case object != nil:
+			object = append(object, l)
+			if l != "}" {
+				continue
+			}
+
+			var pkgs map[string]map[string][]jsonDiagnostic
+			if err := json.Unmarshal([]byte(strings.Join(object, "\n")), &pkgs); err != nil {
+				return nil, err
+			}
+
+			object = nil
+			for _, analyzers := range pkgs {
+				for analyzer, jds := range analyzers {
+					for _, jd := range jds {
+						if d, ok := position(root, jd.Posn); ok {
+							d.Analyzer, d.Message = analyzer, jd.Message
+							ds = append(ds, d)
+						}
+					}
+				}
+			}
+		case l == "{":
+			object = []string{l}
+		default:
+			l = strings.TrimPrefix(l, "vet: ")
+			if i := strings.Index(l, ": "); i >= 0 {
+				if d, ok := position(root, l[:i]); ok {
+					d.Analyzer, d.Message = TypeCheck, l[i+2:]
+					ds = append(ds, d)
+					continue
+				}
+			}
+
+			if d, ok := packageError(root, l); ok {
+				ds = append(ds, d)
+			}
+		}
+	}
+
+	return ds, s.Err()
+}
+
+// position parses a file:line:col position, the column being optional,
+// returning false if it isn't one of a file under root.
+func position(root, posn string) (Diagnostic, bool) {
+	var d Diagnostic
+	parts := strings.Split(posn, ":")
+	if len(parts) > 2 {
+		if col, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			d.Column = col
+			parts = parts[:len(parts)-1]
+		}
+	}
+
+	line, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || len(parts) < 2 {
+		return d, false
+	}
+
+	name := strings.Join(parts[:len(parts)-1], ":")
+	if filepath.IsAbs(name) {
+		if name, err = filepath.Rel(root, name); err != nil {
+			return d, false
+		}
+	}
+
+	name = filepath.ToSlash(filepath.Clean(name))
+	if strings.HasPrefix(name, "../") {
+		return d, false
+	}
+
+	d.Path, d.Line = name, line
+	return d, true
+}
+
+// packageError parses an error of a package that can't be loaded, ending
+// with " in dir", such as "found packages a (a.go) and b (b.go) in dir",
+// returning false if dir isn't a directory under root.
+func packageError(root, l string) (Diagnostic, bool) {
+	var d Diagnostic
+	i := strings.LastIndex(l, " in ")
+	if i < 0 || !filepath.IsAbs(l[i+4:]) {
+		return d, false
+	}
+
+	dir, err := filepath.Rel(root, l[i+4:])
+	if err != nil {
+		return d, false
+	}
+
+	dir = filepath.ToSlash(dir)
+	if dir == ".." || strings.HasPrefix(dir, "../") {
+		return d, false
+	}
+
+	d.Analyzer, d.Path, d.Message = TypeCheck, dir, l[:i]
+	return d, true
+}
+
+// diagnostic returns the first line of the error output of go vet, package
+// headers aside, prefixed with a colon, or an empty string.
+func diagnostic(stderr *bytes.Buffer) string {
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "# ") {
+			return ": " + line
+		}
+	}
+
+	return ""
+}
+
+type byPosition []Diagnostic
+
+func (s byPosition) Len() int      { return len(s) }
+func (s byPosition) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byPosition) Less(i, j int) bool {
+	if s[i].Path != s[j].Path {
+		return s[i].Path < s[j].Path
+	}
+
+	if s[i].Line != s[j].Line {
+		return s[i].Line < s[j].Line
+	}
+
+	return s[i].Column < s[j].Column
+}
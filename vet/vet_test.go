@@ -0,0 +1,123 @@
+package vet
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTree writes the files, mapping slash separated names to contents, in
+// a temporary directory removed by the returned function.
+func writeTree(t *testing.T, files map[string]string) (string, func()) {
+	dir, err := ioutil.TempDir("", "vet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath(DefaultProgram); err != nil {
+		t.Skip("go not installed")
+	}
+
+	root, cleanup := writeTree(t, map[string]string{
+		"a.go":       "package m\n\nimport \"fmt\"\n\nfunc F() {\n\tfmt.Printf(\"%d\\n\", \"x\")\n}\n",
+		"sub/b.go":   "package sub\n\nfunc G() int {\n\treturn \"x\"\n}\n",
+		"clean/c.go": "package clean\n\nfunc H() {}\n",
+	})
+	defer cleanup()
+
+	ds, err := (&Runner{}).Run(root, []string{".", "clean", "sub"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ds) != 2 {
+		t.Fatalf("Run = %+v, want a printf and a typecheck diagnostic", ds)
+	}
+
+	if d := ds[0]; d.Analyzer != "printf" || d.Path != "a.go" || d.Line != 6 || !strings.Contains(d.Message, "%d") {
+		t.Errorf("diagnostic %+v, want the printf one of a.go:6", d)
+	}
+
+	if d := ds[1]; d.Analyzer != TypeCheck || d.Path != "sub/b.go" || d.Line != 4 {
+		t.Errorf("diagnostic %+v, want the typecheck one of sub/b.go:4", d)
+	}
+
+	if ds, err := (&Runner{Analyzers: []string{"copylocks"}}).Run(root, []string{"."}); err != nil || len(ds) != 0 {
+		t.Errorf("Run of copylocks = %+v, %v, want no diagnostics", ds, err)
+	}
+}
+
+func TestRunMissingProgram(t *testing.T) {
+	root, cleanup := writeTree(t, map[string]string{"a.go": "package m\n"})
+	defer cleanup()
+
+	r := &Runner{Program: filepath.Join(root, "missing-go")}
+	if ds, err := r.Run(root, []string{"."}); err == nil || !strings.Contains(err.Error(), "go vet needs the go command") {
+		t.Errorf("Run without go = %+v, %v, want an error", ds, err)
+	}
+
+	if ds, err := r.Run(root, nil); err != nil || ds != nil {
+		t.Errorf("Run of no packages = %+v, %v, want nothing run", ds, err)
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	root := filepath.FromSlash("/tmp/tree")
+	out := strings.Join([]string{
+		"# m/sub",
+		"{",
+		"\t\"m\": {",
+		"\t\t\"printf\": [",
+		"\t\t\t{",
+		"\t\t\t\t\"posn\": \"" + filepath.ToSlash(filepath.Join(root, "a.go")) + ":6:2\",",
+		"\t\t\t\t\"message\": \"bad verb\"",
+		"\t\t\t}",
+		"\t\t]",
+		"\t}",
+		"}",
+		"vet: sub/b.go:4:9: cannot use \"x\"",
+		"c.go:3: syntax error",
+		"../outside.go:1:1: ignored",
+		"found packages a (a.go) and b (b.go) in " + filepath.ToSlash(filepath.Join(root, "multi")),
+		"found packages a (a.go) and b (b.go) in /elsewhere",
+	}, "\n")
+
+	ds, err := parseOutput(root, []byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Diagnostic{
+		{Analyzer: "printf", Path: "a.go", Line: 6, Column: 2, Message: "bad verb"},
+		{Analyzer: TypeCheck, Path: "sub/b.go", Line: 4, Column: 9, Message: "cannot use \"x\""},
+		{Analyzer: TypeCheck, Path: "c.go", Line: 3, Message: "syntax error"},
+		{Analyzer: TypeCheck, Path: "multi", Message: "found packages a (a.go) and b (b.go)"},
+	}
+
+	if !reflect.DeepEqual(ds, want) {
+		t.Errorf("parseOutput = %+v, want %+v", ds, want)
+	}
+
+	if _, err := parseOutput(root, []byte("{\n\"m\": [\n}\n")); err == nil {
+		t.Error("parseOutput of malformed JSON succeeded")
+	}
+}
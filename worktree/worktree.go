@@ -0,0 +1,158 @@
+// Package worktree checks out the tree of a commit on disk, for the external
+// analyzers, such as go vet or linters, that only read files.
+package worktree
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunjan5/code-review-bot/diff"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// Materialize writes the files of the tree of the commit under dir, created
+// if needed, as git checkout does: executable files with mode 0755, other
+// files with 0644, symbolic links as links to their target and submodules
+// as empty directories. Existing files aren't overwritten, and entries with
+// an unsafe name, such as .. or .git, or sharing a name make it fail.
+func Materialize(c *git.Commit, dir string) error {
+	t, err := c.Tree()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	return writeTree(t, dir, "")
+}
+
+// Temp materializes the commit in a new temporary directory, returning it
+// and a function removing it once done.
+func Temp(c *git.Commit) (string, func() error, error) {
+	dir, err := ioutil.TempDir("", "code-review-bot-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	cleanup := func() error { return os.RemoveAll(dir) }
+	if err := Materialize(c, dir); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return dir, cleanup, nil
+}
+
+// writeTree writes the entries of the tree t, at the path base of the
+// commit tree, to the directory dir. All the names are checked before
+// writing: the tree finds its entries by name, so entries sharing a name,
+// such as a directory shadowing a symbolic link, would write one of them in
+// place of the other.
+func writeTree(t *git.Tree, dir, base string) error {
+	seen := make(map[string]bool, len(t.Entries))
+	for _, e := range t.Entries {
+		if !safeName(e.Name) {
+			return fmt.Errorf("%s: unsafe tree entry name", entryPath(base, e.Name))
+		}
+
+		if seen[e.Name] {
+			return fmt.Errorf("%s: duplicate tree entry name", entryPath(base, e.Name))
+		}
+
+		seen[e.Name] = true
+	}
+
+	for _, e := range t.Entries {
+		name := entryPath(base, e.Name)
+		p := filepath.Join(dir, e.Name)
+		var err error
+		switch {
+		case e.Mode.IsDir():
+			err = writeDir(t, e.Name, p, name)
+		case diff.IsSubmodule(e.Mode):
+			err = os.Mkdir(p, 0755)
+		default:
+			err = writeFile(t, e.Name, p)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// entryPath returns the path in the commit tree of the entry name of the
+// tree at the path base.
+func entryPath(base, name string) string {
+	if base == "" {
+		return name
+	}
+
+	return base + "/" + name
+}
+
+func writeDir(t *git.Tree, entry, p, name string) error {
+	sub, err := t.Dir(entry)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	// Mkdir fails on an existing path, so a directory is never written
+	// through a symbolic link of the tree.
+	if err := os.Mkdir(p, 0755); err != nil {
+		return err
+	}
+
+	return writeTree(sub, p, name)
+}
+
+func writeFile(t *git.Tree, entry, p string) (err error) {
+	f, err := t.File(entry)
+	if err != nil {
+		return err
+	}
+
+	if f.Mode&os.ModeSymlink != 0 {
+		return os.Symlink(f.Target, p)
+	}
+
+	perm := os.FileMode(0644)
+	if f.Mode&0111 != 0 {
+		perm = 0755
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if cerr := w.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// safeName returns true if the tree entry name can be written in a
+// directory without leaving it or writing the git directory.
+func safeName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.EqualFold(name, ".git") &&
+		!strings.ContainsAny(name, "/\\\x00")
+}
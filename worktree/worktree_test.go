@@ -0,0 +1,179 @@
+package worktree
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gunjan5/code-review-bot/internal/testrepo"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/core"
+)
+
+// testRepo is a repository in memory whose objects are written by the tests.
+type testRepo struct {
+	*testrepo.Repo
+	t *testing.T
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	return &testRepo{Repo: testrepo.New(t), t: t}
+}
+
+// treeEntry is an entry of a tree written by testRepo.tree.
+type treeEntry testrepo.Entry
+
+// tree writes a tree of the entries, in the order given.
+func (tr *testRepo) tree(entries ...treeEntry) core.Hash {
+	es := make([]testrepo.Entry, len(entries))
+	for i, e := range entries {
+		es[i] = testrepo.Entry(e)
+	}
+
+	return tr.RawTree(es...)
+}
+
+// commit writes a commit of the tree and returns it.
+func (tr *testRepo) commit(tree core.Hash) *git.Commit {
+	c, err := tr.Commit(tr.WriteCommit(tree, "m\n"))
+	if err != nil {
+		tr.t.Fatal(err)
+	}
+
+	return c
+}
+
+// tempDir returns a new temporary directory, removed by the returned
+// function.
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "worktree")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestMaterialize(t *testing.T) {
+	tr := newTestRepo(t)
+	sub := tr.tree(
+		treeEntry{"100644", "b.go", tr.Blob("package b\n")},
+		treeEntry{"100755", "run.sh", tr.Blob("#!/bin/sh\n")},
+	)
+	module := core.NewHash("0123456789012345678901234567890123456789")
+	c := tr.commit(tr.tree(
+		treeEntry{"100644", "a.txt", tr.Blob("a\n")},
+		treeEntry{"40000", "dir", sub},
+		treeEntry{"120000", "link", tr.Blob("dir/b.go")},
+		treeEntry{"160000", "module", module},
+	))
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	root := filepath.Join(dir, "checkout")
+	if err := Materialize(c, root); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path, content string
+		perm          os.FileMode
+	}{
+		{"a.txt", "a\n", 0644},
+		{"dir/b.go", "package b\n", 0644},
+		{"dir/run.sh", "#!/bin/sh\n", 0755},
+	} {
+		p := filepath.Join(root, filepath.FromSlash(tc.path))
+		fi, err := os.Lstat(p)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !fi.Mode().IsRegular() || fi.Mode().Perm() != tc.perm {
+			t.Errorf("%s has mode %s, want a regular file with %s", tc.path, fi.Mode(), tc.perm)
+		}
+
+		if data, err := ioutil.ReadFile(p); err != nil || string(data) != tc.content {
+			t.Errorf("%s = %q, %v, want %q", tc.path, data, err, tc.content)
+		}
+	}
+
+	if target, err := os.Readlink(filepath.Join(root, "link")); err != nil || target != "dir/b.go" {
+		t.Errorf("link points to %q, %v, want dir/b.go", target, err)
+	}
+
+	if fi, err := os.Lstat(filepath.Join(root, "module")); err != nil || !fi.IsDir() {
+		t.Errorf("submodule module = %v, %v, want a directory", fi, err)
+	} else if names, err := ioutil.ReadDir(filepath.Join(root, "module")); err != nil || len(names) != 0 {
+		t.Errorf("submodule module holds %d files, %v, want none", len(names), err)
+	}
+}
+
+func TestMaterializeUnsafe(t *testing.T) {
+	tr := newTestRepo(t)
+	blob := tr.Blob("x\n")
+	// target points to the parent of the checkout, where the test looks
+	// for the files written through it.
+	target := tr.Blob("..")
+	for _, tc := range []struct {
+		name    string
+		entries []treeEntry
+		reason  string
+	}{
+		{"dotdot", []treeEntry{{"100644", "..", blob}}, "..: unsafe tree entry name"},
+		{"dot", []treeEntry{{"100644", ".", blob}}, ".: unsafe tree entry name"},
+		{"git", []treeEntry{{"40000", ".git", tr.tree(treeEntry{"100644", "config", blob})}}, ".git: unsafe tree entry name"},
+		{"GIT", []treeEntry{{"100644", ".GIT", blob}}, ".GIT: unsafe tree entry name"},
+		{"slash", []treeEntry{{"100644", "a/b", blob}}, "a/b: unsafe tree entry name"},
+		{"backslash", []treeEntry{{"100644", `..\b`, blob}}, `..\b: unsafe tree entry name`},
+		{"nested", []treeEntry{{"40000", "dir", tr.tree(treeEntry{"100644", "..", blob})}}, "dir/..: unsafe tree entry name"},
+		{"shadowed symlink", []treeEntry{{"120000", "a", target}, {"40000", "a", tr.tree(treeEntry{"100644", "b", blob})}}, "a: duplicate tree entry name"},
+	} {
+		dir, cleanup := tempDir(t)
+		err := Materialize(tr.commit(tr.tree(tc.entries...)), filepath.Join(dir, "checkout"))
+		if err == nil || !strings.Contains(err.Error(), tc.reason) {
+			t.Errorf("Materialize of %s = %v, want %q", tc.name, err, tc.reason)
+		}
+
+		if _, err := os.Lstat(filepath.Join(dir, "b")); !os.IsNotExist(err) {
+			t.Errorf("Materialize of %s wrote outside of the checkout", tc.name)
+		}
+
+		cleanup()
+	}
+
+	for _, name := range []string{"", ".", "..", ".git", ".Git", "a/b", `a\b`, "a\x00b"} {
+		if safeName(name) {
+			t.Errorf("safeName(%q) = true", name)
+		}
+	}
+
+	for _, name := range []string{"a", ".gitignore", "...", "git", ".github"} {
+		if !safeName(name) {
+			t.Errorf("safeName(%q) = false", name)
+		}
+	}
+}
+
+func TestMaterializeExisting(t *testing.T) {
+	tr := newTestRepo(t)
+	c := tr.commit(tr.tree(treeEntry{"100644", "a.txt", tr.Blob("new\n")}))
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	p := filepath.Join(dir, "a.txt")
+	if err := ioutil.WriteFile(p, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Materialize(c, dir); !os.IsExist(err) {
+		t.Errorf("Materialize over an existing file = %v, want an existing file error", err)
+	}
+
+	if data, err := ioutil.ReadFile(p); err != nil || string(data) != "old\n" {
+		t.Errorf("existing file = %q, %v, want it unchanged", data, err)
+	}
+}